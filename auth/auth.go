@@ -0,0 +1,94 @@
+// /home/krylon/go/src/github.com/blicero/guangng/auth/auth.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 11:24:50 krylon>
+
+// Package auth provides the primitives for authenticating Users of the web
+// interface: password hashing and the generation of random tokens for
+// sessions, CSRF protection and API access.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasswordLength is the minimum number of characters we accept in a
+// password.
+const MinPasswordLength = 8
+
+const tokenLength = 32 // bytes
+
+// ErrPasswordTooShort is returned by HashPassword if the password is
+// shorter than MinPasswordLength.
+var ErrPasswordTooShort = fmt.Errorf("password must be at least %d characters long",
+	MinPasswordLength)
+
+// HashPassword returns the bcrypt hash of a password.
+func HashPassword(pw string) (string, error) {
+	if len(pw) < MinPasswordLength {
+		return "", ErrPasswordTooShort
+	}
+
+	var (
+		err  error
+		hash []byte
+	)
+
+	if hash, err = bcrypt.GenerateFromPassword([]byte(pw), bcrypt.DefaultCost); err != nil {
+		return "", fmt.Errorf("cannot hash password: %w", err)
+	}
+
+	return string(hash), nil
+} // func HashPassword(pw string) (string, error)
+
+// CheckPassword returns true if the password matches the given hash.
+func CheckPassword(hash, pw string) bool {
+	var err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(pw))
+
+	return err == nil
+} // func CheckPassword(hash, pw string) bool
+
+// NewToken generates a random token. It returns the token itself, which is
+// handed to the client, and its hash, which is what we store in the
+// database.
+func NewToken() (token, hash string, err error) {
+	var buf [tokenLength]byte
+
+	if _, err = rand.Read(buf[:]); err != nil {
+		return "", "", fmt.Errorf("cannot generate random token: %w", err)
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(buf[:])
+	hash = HashToken(token)
+	return token, hash, nil
+} // func NewToken() (token, hash string, err error)
+
+// HashToken returns the hash of a token as stored in the database.
+// Tokens carry enough entropy that a plain SHA-256 is sufficient.
+func HashToken(token string) string {
+	var sum = sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+} // func HashToken(token string) string
+
+// ErrTokenMismatch is returned by CheckCSRF if the token does not match.
+var ErrTokenMismatch = errors.New("CSRF token does not match")
+
+// CheckCSRF compares a CSRF token sent by the client to the one stored in
+// the Session in constant time.
+func CheckCSRF(expected, actual string) error {
+	if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(actual)) != 1 {
+		return ErrTokenMismatch
+	}
+
+	return nil
+} // func CheckCSRF(expected, actual string) error
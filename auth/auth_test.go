@@ -0,0 +1,61 @@
+// /home/krylon/go/src/github.com/blicero/guangng/auth/auth_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 11:31:12 krylon>
+
+package auth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPassword(t *testing.T) {
+	const pw = "correct horse battery staple"
+	var (
+		err  error
+		hash string
+	)
+
+	if _, err = HashPassword("short"); !errors.Is(err, ErrPasswordTooShort) {
+		t.Errorf("HashPassword accepted a short password: %v", err)
+	}
+
+	if hash, err = HashPassword(pw); err != nil {
+		t.Fatalf("Failed to hash password: %s", err.Error())
+	} else if hash == pw {
+		t.Fatal("HashPassword returned the password in the clear")
+	} else if !CheckPassword(hash, pw) {
+		t.Error("CheckPassword rejected the correct password")
+	} else if CheckPassword(hash, pw+"!") {
+		t.Error("CheckPassword accepted a wrong password")
+	}
+} // func TestPassword(t *testing.T)
+
+func TestToken(t *testing.T) {
+	var (
+		err              error
+		tok1, tok2, hash string
+	)
+
+	if tok1, hash, err = NewToken(); err != nil {
+		t.Fatalf("Failed to generate token: %s", err.Error())
+	} else if hash != HashToken(tok1) {
+		t.Errorf("NewToken returned hash %s, HashToken says %s",
+			hash,
+			HashToken(tok1))
+	} else if tok2, _, err = NewToken(); err != nil {
+		t.Fatalf("Failed to generate token: %s", err.Error())
+	} else if tok1 == tok2 {
+		t.Errorf("NewToken returned the same token twice: %s", tok1)
+	}
+
+	if err = CheckCSRF(tok1, tok1); err != nil {
+		t.Errorf("CheckCSRF rejected a matching token: %s", err.Error())
+	} else if err = CheckCSRF(tok1, tok2); err == nil {
+		t.Error("CheckCSRF accepted a wrong token")
+	} else if err = CheckCSRF("", ""); err == nil {
+		t.Error("CheckCSRF accepted an empty token")
+	}
+} // func TestToken(t *testing.T)
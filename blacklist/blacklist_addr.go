@@ -11,6 +11,8 @@ import (
 	"sort"
 	"sync"
 	"sync/atomic"
+
+	"github.com/blicero/guangng/metrics"
 )
 
 // BlacklistItemAddr is an item in the BlacklistAddr to match an IP address against
@@ -78,6 +80,7 @@ func (al *BlacklistAddr) Match(addr net.IP) bool {
 	for _, item := range al.items {
 		if item.Match(addr) {
 			al.lock.RUnlock()
+			metrics.BlacklistHits.With("addr").Inc()
 			al.lock.Lock()
 			sort.Sort(al.items)
 			al.lock.Unlock()
@@ -11,6 +11,8 @@ import (
 	"sort"
 	"sync"
 	"sync/atomic"
+
+	"github.com/blicero/guangng/metrics"
 )
 
 // BlacklistItemName is a pattern to match hostnames.
@@ -68,6 +70,7 @@ func (bl *BlacklistName) Match(name string) bool {
 	for _, i := range bl.items {
 		if status := i.Match(name); status {
 			bl.lock.RUnlock()
+			metrics.BlacklistHits.With("name").Inc()
 			bl.lock.Lock()
 			sort.Sort(bl.items)
 			bl.lock.Unlock()
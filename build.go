@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 01. 02. 2021 by Benjamin Walkenhorst
 // (c) 2021 Benjamin Walkenhorst
-// Time-stamp: <2026-02-10 20:19:30 krylon>
+// Time-stamp: <2026-10-19 15:29:17 krylon>
 
 //go:build ignore
 // +build ignore
@@ -62,39 +62,87 @@ var candidates = map[string][]string{
 		"database/query",
 		"model/hsrc",
 		"model/subsystem",
+		"model/role",
+		"model/genmode",
+		"model/outcome",
+		"model/probe",
+		"model/weakness",
+		"model/dnssec",
+		"export",
+		"events",
 	},
 	"test": {
+		"metrics",
+		"events",
+		"auth",
 		"blacklist",
 		"model",
+		"model/meta",
 		"database",
+		"geo",
+		"generator",
+		"export",
+		"importer",
 		"web",
+		"xfr",
+		"psl",
+		"scanner",
+		"mailsec",
 	},
 	"vet": {
 		"logdomain",
 		"common",
+		"metrics",
+		"events",
+		"auth",
 		"model",
 		"model/hsrc",
 		"model/subsystem",
+		"model/role",
+		"model/genmode",
+		"model/outcome",
+		"model/probe",
+		"model/weakness",
+		"model/dnssec",
 		"model/meta",
 		"blacklist",
 		"database",
 		"database/query",
 		"xfr",
+		"psl",
+		"mailsec",
 		"scanner",
+		"geo",
+		"export",
+		"importer",
 		"web",
 	},
 	"lint": {
 		"logdomain",
 		"common",
+		"metrics",
+		"events",
+		"auth",
 		"model",
 		"model/hsrc",
 		"model/subsystem",
+		"model/role",
+		"model/genmode",
+		"model/outcome",
+		"model/probe",
+		"model/weakness",
+		"model/dnssec",
 		"model/meta",
 		"blacklist",
 		"database",
 		"database/query",
 		"xfr",
+		"psl",
+		"mailsec",
 		"scanner",
+		"geo",
+		"export",
+		"importer",
 		"web",
 	},
 }
@@ -0,0 +1,92 @@
+// /home/krylon/go/src/github.com/blicero/guangng/classify_cmd.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 20:17:48 krylon>
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/meta"
+)
+
+// runClassify implements the "classify" subcommand, which guesses the
+// operating systems of all Hosts again, e.g. after the patterns in
+// common.OSPatternPath were changed. It returns the exit code for the
+// process.
+func runClassify(args []string) int {
+	type update struct {
+		host  *model.Host
+		guess *meta.OSGuess
+	}
+
+	var (
+		err     error
+		db      *database.Database
+		osg     *meta.OSGuesser
+		updates []update
+		path    string
+	)
+
+	if len(args) > 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s classify [PATTERN_FILE]\n", os.Args[0])
+		return 1
+	} else if len(args) == 1 {
+		path = args[0]
+	}
+
+	if osg, err = meta.NewOSGuesser(path); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		return 1
+	} else if db, err = database.Open(common.DbPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot open database %s: %s\n",
+			common.DbPath,
+			err.Error())
+		return 1
+	}
+
+	defer db.Close() // nolint: errcheck
+
+	// We collect the changes first and apply them after the walk, so we
+	// do not write to the host table while we are reading it.
+	err = db.HostWalk(nil, func(h *model.Host) error {
+		var (
+			ierr  error
+			svc   map[uint16]*model.Service
+			guess *meta.OSGuess
+		)
+
+		if svc, ierr = db.ServiceGetByHost(h); ierr != nil {
+			return ierr
+		} else if guess = osg.Guess(svc); guess == nil {
+			return nil
+		} else if guess.Name != h.Sysname || guess.Confidence != h.OSConfidence {
+			updates = append(updates, update{host: h, guess: guess})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to classify Hosts: %s\n", err.Error())
+		return 1
+	}
+
+	for _, u := range updates {
+		if err = db.HostSetSysname(u.host, u.guess.Name, u.guess.Confidence); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+			return 1
+		}
+	}
+
+	fmt.Printf("Updated the OS of %d Hosts using %d rules\n",
+		len(updates),
+		osg.RuleCount())
+	return 0
+} // func runClassify(args []string) int
@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 23. 07. 2021 by Benjamin Walkenhorst
 // (c) 2021 Benjamin Walkenhorst
-// Time-stamp: <2026-02-09 15:19:10 krylon>
+// Time-stamp: <2026-10-19 14:44:56 krylon>
 
 // Package common contains definitions used throughout the application
 package common
@@ -101,10 +101,28 @@ var DbPath = filepath.Join(BaseDir, fmt.Sprintf("%s.db", strings.ToLower(AppName
 
 var CachePath = filepath.Join(BaseDir, "cache.d")
 
+// BitmapPath is the file the Generator saves its cache of IPv4 addresses to.
+var BitmapPath = filepath.Join(BaseDir, "cache.bitmap")
+
 var CfgPath = filepath.Join(BaseDir, fmt.Sprintf("%s.toml", strings.ToLower(AppName)))
 
 var XfrDbgPath = filepath.Join(BaseDir, "xfr.d")
 
+// CertPath and KeyPath are the files the web UI keeps its self-signed TLS
+// certificate and its private key in, if no certificate was configured.
+var (
+	CertPath = filepath.Join(BaseDir, "cert.pem")
+	KeyPath  = filepath.Join(BaseDir, "key.pem")
+)
+
+// OSPatternPath is the file additional patterns for guessing the operating
+// systems of Hosts are loaded from, if it exists.
+var OSPatternPath = filepath.Join(BaseDir, "os_patterns.json")
+
+// PSLPath is the file an updated copy of the Public Suffix List is saved to
+// and loaded from, if it exists.
+var PSLPath = filepath.Join(BaseDir, "public_suffix_list.dat")
+
 // This needs a little refinement, but should clear up the race condition.
 var (
 	lock   sync.RWMutex
@@ -138,8 +156,13 @@ func InitApp() (e error) {
 	LogPath = filepath.Join(BaseDir, fmt.Sprintf("%s.log", strings.ToLower(AppName)))
 	DbPath = filepath.Join(BaseDir, fmt.Sprintf("%s.db", strings.ToLower(AppName)))
 	CachePath = filepath.Join(BaseDir, "cache.d")
+	BitmapPath = filepath.Join(BaseDir, "cache.bitmap")
 	CfgPath = filepath.Join(BaseDir, fmt.Sprintf("%s.toml", strings.ToLower(AppName)))
 	XfrDbgPath = filepath.Join(BaseDir, "xfr.d")
+	CertPath = filepath.Join(BaseDir, "cert.pem")
+	KeyPath = filepath.Join(BaseDir, "key.pem")
+	OSPatternPath = filepath.Join(BaseDir, "os_patterns.json")
+	PSLPath = filepath.Join(BaseDir, "public_suffix_list.dat")
 
 	if err = os.Mkdir(CachePath, 0700); err != nil && !os.IsExist(err) {
 		return fmt.Errorf("error creating cache directory %s: %s",
@@ -170,6 +193,10 @@ func SetBaseDir(path string) error {
 	LogPath = filepath.Join(BaseDir, fmt.Sprintf("%s.log", strings.ToLower(AppName)))
 	DbPath = filepath.Join(BaseDir, fmt.Sprintf("%s.db", strings.ToLower(AppName)))
 	CfgPath = filepath.Join(BaseDir, fmt.Sprintf("%s.toml", strings.ToLower(AppName)))
+	CertPath = filepath.Join(BaseDir, "cert.pem")
+	KeyPath = filepath.Join(BaseDir, "key.pem")
+	OSPatternPath = filepath.Join(BaseDir, "os_patterns.json")
+	PSLPath = filepath.Join(BaseDir, "public_suffix_list.dat")
 
 	var (
 		err error
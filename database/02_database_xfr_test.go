@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 19. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-01-19 19:52:21 krylon>
+// Time-stamp: <2026-10-19 15:10:30 krylon>
 
 package database
 
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/dnssec"
 )
 
 func TestXFRAdd(t *testing.T) {
@@ -31,3 +32,84 @@ func TestXFRAdd(t *testing.T) {
 			err.Error())
 	}
 } // func TestXFRAdd(t *testing.T)
+
+func TestXFRSchedule(t *testing.T) {
+	if tdb == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		due  []*model.Zone
+		zone *model.Zone
+		next = time.Now().Add(time.Hour)
+	)
+
+	if due, err = tdb.XFRGetDue(10); err != nil {
+		t.Fatalf("Failed to get zones that are due: %s", err.Error())
+	} else if len(due) != 1 {
+		t.Fatalf("Expected 1 zone to be due, got %d", len(due))
+	}
+
+	zone = due[0]
+
+	if err = tdb.XFRStart(zone, next); err != nil {
+		t.Fatalf("Failed to start XFR of %s: %s", zone.Name, err.Error())
+	} else if due, err = tdb.XFRGetDue(10); err != nil {
+		t.Fatalf("Failed to get zones that are due: %s", err.Error())
+	} else if len(due) != 0 {
+		t.Errorf("Zone %s is due while its XFR is under way", zone.Name)
+	}
+
+	if err = tdb.XFRFinish(zone, false, time.Now().Add(-time.Second), 3); err != nil {
+		t.Fatalf("Failed to finish XFR of %s: %s", zone.Name, err.Error())
+	} else if due, err = tdb.XFRGetDue(10); err != nil {
+		t.Fatalf("Failed to get zones that are due: %s", err.Error())
+	} else if len(due) != 1 {
+		t.Fatalf("Expected 1 zone to be due, got %d", len(due))
+	} else if due[0].Failures != 3 || due[0].Finished.IsZero() {
+		t.Errorf("Unexpected state of zone %s: %d failures, finished %s",
+			zone.Name,
+			due[0].Failures,
+			due[0].Finished)
+	}
+
+	// Leave the zone in a state later tests are not surprised by.
+	if err = tdb.XFRFinish(zone, true, next, 0); err != nil {
+		t.Fatalf("Failed to finish XFR of %s: %s", zone.Name, err.Error())
+	} else if zone, err = tdb.XFRGetByID(zone.ID); err != nil {
+		t.Fatalf("Failed to look up zone #%d: %s", due[0].ID, err.Error())
+	} else if !zone.Status || zone.NextAttempt.Unix() != next.Unix() {
+		t.Errorf("Unexpected state of zone %s: status %t, next attempt %s",
+			zone.Name,
+			zone.Status,
+			zone.NextAttempt)
+	}
+} // func TestXFRSchedule(t *testing.T)
+
+func TestXFRSetDNSSEC(t *testing.T) {
+	if tdb == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		zone *model.Zone
+	)
+
+	if zone, err = tdb.XFRGetByName("example.com"); err != nil {
+		t.Fatalf("Failed to look up zone: %s", err.Error())
+	} else if zone == nil {
+		t.Fatal("Zone example.com was not found")
+	} else if zone.DNSSEC != 0 {
+		t.Errorf("Zone has DNSSEC mode %s before we checked", zone.DNSSEC)
+	} else if err = tdb.XFRSetDNSSEC(zone, dnssec.NSEC); err != nil {
+		t.Fatalf("Failed to set DNSSEC mode: %s", err.Error())
+	} else if zone, err = tdb.XFRGetByID(zone.ID); err != nil {
+		t.Fatalf("Failed to look up zone: %s", err.Error())
+	} else if zone.DNSSEC != dnssec.NSEC {
+		t.Errorf("Zone has DNSSEC mode %s, expected %s",
+			zone.DNSSEC,
+			dnssec.NSEC)
+	}
+} // func TestXFRSetDNSSEC(t *testing.T)
@@ -2,13 +2,14 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 23. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-01-23 16:00:50 krylon>
+// Time-stamp: <2026-10-21 13:52:40 krylon>
 
 package database
 
 import (
 	"net"
 	"testing"
+	"time"
 
 	"github.com/blicero/guangng/model"
 	"github.com/blicero/guangng/model/hsrc"
@@ -17,7 +18,7 @@ import (
 var tHosts map[int64]model.Host
 
 func TestHostSource(t *testing.T) {
-	for s := hsrc.Generator; s <= hsrc.User; s++ {
+	for s := hsrc.Generator; s <= hsrc.Import; s++ {
 		t.Logf("HostSource.%s = %d",
 			s,
 			s)
@@ -74,3 +75,91 @@ func TestHostAdd(t *testing.T) {
 		}
 	}
 } // func TestHostAdd(t *testing.T)
+
+func TestHostGeoPending(t *testing.T) {
+	if tdb == nil || len(tHosts) == 0 {
+		t.SkipNow()
+	}
+
+	var (
+		err     error
+		pending []*model.Host
+		cutoff  = time.Now().Add(-time.Hour)
+		loc     = &model.GeoLocation{
+			CountryCode: "JP",
+			Country:     "Japan",
+			City:        "Chiba",
+		}
+	)
+
+	if pending, err = tdb.HostGetGeoPending(100, cutoff); err != nil {
+		t.Fatalf("Cannot get Hosts pending geolocation: %s", err.Error())
+	} else if len(pending) != len(tHosts) {
+		t.Fatalf("Expected %d Hosts pending geolocation, got %d",
+			len(tHosts),
+			len(pending))
+	} else if err = tdb.HostSetLocation(pending[0], loc); err != nil {
+		t.Fatalf("Cannot set location of Host %s: %s",
+			pending[0].AStr(),
+			err.Error())
+	} else if pending[0].Location != "Chiba, Japan" {
+		t.Errorf("Unexpected location %q", pending[0].Location)
+	} else if err = tdb.HostSetLocation(pending[1], nil); err != nil {
+		t.Fatalf("Cannot record unknown location of Host %s: %s",
+			pending[1].AStr(),
+			err.Error())
+	}
+
+	if pending, err = tdb.HostGetGeoPending(100, cutoff); err != nil {
+		t.Fatalf("Cannot get Hosts pending geolocation: %s", err.Error())
+	} else if len(pending) != 0 {
+		t.Errorf("%d Hosts are still pending after geolocation",
+			len(pending))
+	}
+} // func TestHostGeoPending(t *testing.T)
+
+func TestHostSetSysname(t *testing.T) {
+	if tdb == nil || len(tHosts) == 0 {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		host *model.Host
+	)
+
+	for id := range tHosts {
+		if host, err = tdb.HostGetByID(id); err != nil {
+			t.Fatalf("Cannot get Host #%d: %s", id, err.Error())
+		}
+		break
+	}
+
+	if err = tdb.HostSetSysname(host, "OpenBSD", 0.75); err != nil {
+		t.Fatalf("Cannot set OS of Host %s: %s", host.AStr(), err.Error())
+	} else if host, err = tdb.HostGetByID(host.ID); err != nil {
+		t.Fatalf("Cannot get Host #%d: %s", host.ID, err.Error())
+	} else if host.Sysname != "OpenBSD" || host.OSConfidence != 0.75 {
+		t.Errorf("Host %s runs %s (%.2f), expected OpenBSD (0.75)",
+			host.AStr(),
+			host.Sysname,
+			host.OSConfidence)
+	}
+} // func TestHostSetSysname(t *testing.T)
+
+func TestHostGetRandom(t *testing.T) {
+	if tdb == nil || len(tHosts) == 0 {
+		t.SkipNow()
+	}
+
+	var (
+		err   error
+		hosts []*model.Host
+	)
+
+	if hosts, err = tdb.HostGetRandom(1); err != nil {
+		t.Fatalf("Cannot get random Host: %s", err.Error())
+	} else if len(hosts) > 1 {
+		t.Errorf("Asked for 1 random Host, got %d", len(hosts))
+	}
+} // func TestHostGetRandom(t *testing.T)
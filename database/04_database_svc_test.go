@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/hsrc"
 )
 
 func TestServiceAdd(t *testing.T) {
@@ -68,3 +69,42 @@ func TestServiceAdd(t *testing.T) {
 		}
 	}
 } // func TestServiceAdd(t *testing.T)
+
+func TestHostGetFiltered(t *testing.T) {
+	if tdb == nil {
+		t.SkipNow()
+	}
+
+	type filterTest struct {
+		filter HostFilter
+		cnt    int
+	}
+
+	var testCases = []filterTest{
+		{filter: HostFilter{Limit: -1}, cnt: 2},
+		{filter: HostFilter{Limit: 1}, cnt: 1},
+		{filter: HostFilter{Name: "wintermute", Limit: -1}, cnt: 1},
+		{filter: HostFilter{Source: hsrc.User, Limit: -1}, cnt: 2},
+		{filter: HostFilter{Source: hsrc.XFR, Limit: -1}, cnt: 0},
+		{filter: HostFilter{Port: 80, Limit: -1}, cnt: 1},
+		{filter: HostFilter{Port: 23, Limit: -1}, cnt: 0},
+	}
+
+	for _, c := range testCases {
+		var (
+			err   error
+			hosts []*model.Host
+		)
+
+		if hosts, err = tdb.HostGetFiltered(&c.filter); err != nil {
+			t.Errorf("Failed to get Hosts for filter %#v: %s",
+				c.filter,
+				err.Error())
+		} else if len(hosts) != c.cnt {
+			t.Errorf("Unexpected number of Hosts for filter %#v: %d (expected %d)",
+				c.filter,
+				len(hosts),
+				c.cnt)
+		}
+	}
+} // func TestHostGetFiltered(t *testing.T)
@@ -0,0 +1,265 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/05_database_portqueue_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:28:27 krylon>
+
+package database
+
+import (
+	"database/sql"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/hsrc"
+	"github.com/blicero/guangng/model/probe"
+)
+
+func TestHostGetByAddr(t *testing.T) {
+	if tdb == nil {
+		t.SkipNow()
+	}
+
+	for _, h := range tHosts {
+		var (
+			err  error
+			host *model.Host
+		)
+
+		if host, err = tdb.HostGetByAddr(h.Addr); err != nil {
+			t.Errorf("Failed to look up Host %s: %s",
+				h.AStr(),
+				err.Error())
+		} else if host == nil {
+			t.Errorf("Host %s was not found", h.AStr())
+		} else if host.ID != h.ID || host.Name != h.Name {
+			t.Errorf("Looking up %s returned the wrong Host: %d/%s (expected %d/%s)",
+				h.AStr(),
+				host.ID,
+				host.Name,
+				h.ID,
+				h.Name)
+		}
+	}
+
+	if host, err := tdb.HostGetByAddr(net.ParseIP("192.0.2.42")); err != nil {
+		t.Errorf("Failed to look up unknown Host: %s", err.Error())
+	} else if host != nil {
+		t.Errorf("Looking up unknown address returned Host %d/%s",
+			host.ID,
+			host.Name)
+	}
+} // func TestHostGetByAddr(t *testing.T)
+
+func TestPortQueue(t *testing.T) {
+	if tdb == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err     error
+		pending []*model.PendingPort
+		host    = &model.Host{
+			Addr:   net.ParseIP("192.0.2.23"),
+			Source: hsrc.Import,
+		}
+	)
+
+	if err = tdb.HostAdd(host); err != nil {
+		t.Fatalf("Failed to add imported Host: %s", err.Error())
+	}
+
+	// Queueing the same port twice must not fail or produce a duplicate.
+	for _, port := range []uint16{22, 80, 80} {
+		if err = tdb.PortQueueAdd(host, port, "tcp", probe.Auto); err != nil {
+			t.Errorf("Failed to queue port %d: %s", port, err.Error())
+		}
+	}
+
+	if err = tdb.PortQueueAdd(host, 53, "sctp", probe.DNS); err == nil {
+		t.Error("Queueing a port with an invalid protocol should have failed")
+	}
+
+	if err = tdb.PortQueueAdd(host, 5269, "tcp", probe.XMPPServer); err != nil {
+		t.Errorf("Failed to queue port 5269: %s", err.Error())
+	}
+
+	if pending, err = tdb.PortQueueGetPending(10); err != nil {
+		t.Fatalf("Failed to get pending ports: %s", err.Error())
+	} else if len(pending) != 3 {
+		t.Fatalf("Unexpected number of pending ports: %d (expected 3)",
+			len(pending))
+	} else if pending[0].Host.ID != host.ID {
+		t.Errorf("Pending port belongs to Host %d (expected %d)",
+			pending[0].Host.ID,
+			host.ID)
+	}
+
+	for _, p := range pending {
+		var expected = probe.Auto
+
+		if p.Port == 5269 {
+			expected = probe.XMPPServer
+		}
+
+		if p.Probe != expected {
+			t.Errorf("Port %d has Probe %s (expected %s)",
+				p.Port,
+				p.Probe,
+				expected)
+		}
+	}
+
+	if err = tdb.PortQueueDispatch(pending[0]); err != nil {
+		t.Errorf("Failed to mark port as dispatched: %s", err.Error())
+	} else if pending, err = tdb.PortQueueGetPending(10); err != nil {
+		t.Errorf("Failed to get pending ports: %s", err.Error())
+	} else if len(pending) != 2 {
+		t.Errorf("Unexpected number of pending ports after dispatch: %d (expected 2)",
+			len(pending))
+	}
+} // func TestPortQueue(t *testing.T)
+
+// qSchemaV0 is the schema as it was before we started keeping track of
+// schema versions.
+var qSchemaV0 = []string{
+	`
+CREATE TABLE host (
+    id INTEGER PRIMARY KEY,
+    addr TEXT UNIQUE NOT NULL,
+    name TEXT NOT NULL,
+    added INTEGER NOT NULL,
+    last_contact INTEGER NOT NULL DEFAULT 0,
+    sysname TEXT NOT NULL DEFAULT '',
+    location TEXT NOT NULL DEFAULT '',
+    source INTEGER NOT NULL,
+    CHECK (source BETWEEN 1 AND 5)
+) STRICT
+`,
+	"CREATE INDEX host_contact_idx ON host (last_contact)",
+	"CREATE UNIQUE INDEX host_addr_idx ON host (addr)",
+	`
+CREATE TABLE svc (
+    id INTEGER PRIMARY KEY,
+    host_id INTEGER NOT NULL,
+    port INTEGER NOT NULL,
+    success INTEGER NOT NULL,
+    response TEXT,
+    timestamp INTEGER NOT NULL,
+    CHECK (port BETWEEN 1 AND 65535),
+    FOREIGN KEY (host_id) REFERENCES host (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+	`
+CREATE TRIGGER host_contact_tr
+AFTER INSERT ON svc
+BEGIN
+    UPDATE host
+    SET last_contact = unixepoch()
+    WHERE id = NEW.host_id;
+END
+`,
+	`
+CREATE TABLE xfr (
+    id INTEGER PRIMARY KEY,
+    name TEXT UNIQUE NOT NULL,
+    added INTEGER NOT NULL,
+    start INTEGER,
+    end INTEGER,
+    status INTEGER NOT NULL DEFAULT 0,
+    CHECK ((end IS NULL) OR (start IS NOT NULL))
+) STRICT
+`,
+	"CREATE INDEX xfr_start_idx ON xfr (start)",
+	"CREATE INDEX xfr_end_idx ON xfr (end)",
+	"CREATE INDEX xfr_end_null_idx ON xfr (end IS NULL)",
+	"INSERT INTO host (addr, name, added, source) VALUES ('192.0.2.1', 'old.example.com', 0, 1)",
+	"INSERT INTO svc (host_id, port, success, timestamp) VALUES (1, 22, 1, 0)",
+	"INSERT INTO xfr (name, added, start, end, status) VALUES ('example.com', 0, 100, 200, 1)",
+}
+
+func TestMigrate(t *testing.T) {
+	var (
+		err  error
+		raw  *sql.DB
+		db   *Database
+		svc  map[uint16]*model.Service
+		host *model.Host
+		zone *model.Zone
+		path = filepath.Join(common.BaseDir, "migrate.db")
+	)
+
+	if raw, err = sql.Open("sqlite3", path+"?_fk=1"); err != nil {
+		t.Fatalf("Cannot open %s: %s", path, err.Error())
+	}
+
+	for _, q := range qSchemaV0 {
+		if _, err = raw.Exec(q); err != nil {
+			raw.Close() // nolint: errcheck
+			t.Fatalf("Cannot execute query: %s\n%s", err.Error(), q)
+		}
+	}
+
+	raw.Close() // nolint: errcheck
+
+	if db, err = Open(path); err != nil {
+		t.Fatalf("Failed to open and upgrade old database: %s", err.Error())
+	}
+
+	defer db.Close() // nolint: errcheck
+
+	var ver int
+
+	if ver, err = db.getSchemaVersion(); err != nil {
+		t.Fatalf("Cannot get schema version: %s", err.Error())
+	} else if ver != schemaVersion {
+		t.Errorf("Schema version after upgrade is %d (expected %d)",
+			ver,
+			schemaVersion)
+	}
+
+	if host, err = db.HostGetByAddr(net.ParseIP("192.0.2.1")); err != nil {
+		t.Fatalf("Cannot look up Host: %s", err.Error())
+	} else if host == nil {
+		t.Fatal("Host was lost during upgrade")
+	} else if svc, err = db.ServiceGetByHost(host); err != nil {
+		t.Fatalf("Cannot get Services of Host: %s", err.Error())
+	} else if len(svc) != 1 {
+		t.Errorf("Host has %d Services after upgrade (expected 1)", len(svc))
+	}
+
+	if zone, err = db.XFRGetByName("example.com"); err != nil {
+		t.Fatalf("Cannot look up zone: %s", err.Error())
+	} else if zone == nil {
+		t.Fatal("Zone was lost during upgrade")
+	} else if zone.NextAttempt.Unix() != 200+7*24*3600 {
+		t.Errorf("Zone is due for another transfer at %s after upgrade",
+			zone.NextAttempt)
+	}
+
+	var imported = &model.Host{
+		Addr:   net.ParseIP("192.0.2.2"),
+		Source: hsrc.Import,
+	}
+
+	if err = db.HostAdd(imported); err != nil {
+		t.Errorf("Cannot add imported Host after upgrade: %s", err.Error())
+	} else if err = db.PortQueueAdd(imported, 443, "tcp", probe.HTTP); err != nil {
+		t.Errorf("Cannot queue port after upgrade: %s", err.Error())
+	}
+
+	var walked = &model.Host{
+		Name:   "www.example.com.",
+		Addr:   net.ParseIP("192.0.2.3"),
+		Source: hsrc.NSEC,
+	}
+
+	if err = db.HostAdd(walked); err != nil {
+		t.Errorf("Cannot add Host found in NSEC chain after upgrade: %s", err.Error())
+	}
+} // func TestMigrate(t *testing.T)
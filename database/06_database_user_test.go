@@ -0,0 +1,208 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/06_database_user_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 11:48:19 krylon>
+
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/role"
+)
+
+var tUser *model.User
+
+func TestUserAdd(t *testing.T) {
+	if tdb == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err   error
+		u     *model.User
+		users []*model.User
+	)
+
+	tUser = &model.User{
+		Name:   "case",
+		PwHash: "not really a hash",
+		Role:   role.Operator,
+	}
+
+	if err = tdb.UserAdd(tUser); err != nil {
+		t.Fatalf("Failed to add User: %s", err.Error())
+	} else if tUser.ID == 0 {
+		t.Fatal("User has no ID after being added")
+	} else if err = tdb.UserAdd(&model.User{Name: tUser.Name, PwHash: "x", Role: role.ReadOnly}); err == nil {
+		t.Error("Adding a User with a duplicate name did not fail")
+	}
+
+	if u, err = tdb.UserGetByName(tUser.Name); err != nil {
+		t.Fatalf("Failed to look up User %s: %s", tUser.Name, err.Error())
+	} else if u == nil {
+		t.Fatalf("User %s was not found", tUser.Name)
+	} else if u.ID != tUser.ID || u.Role != role.Operator || !u.LastLogin.IsZero() {
+		t.Errorf("UserGetByName returned unexpected User: %#v", u)
+	}
+
+	if err = tdb.UserSetLastLogin(u); err != nil {
+		t.Errorf("Failed to set last login: %s", err.Error())
+	} else if u, err = tdb.UserGetByID(tUser.ID); err != nil {
+		t.Fatalf("Failed to look up User #%d: %s", tUser.ID, err.Error())
+	} else if u == nil || u.LastLogin.IsZero() {
+		t.Errorf("Last login of User was not recorded: %#v", u)
+	}
+
+	if err = tdb.UserSetPassword(tUser, "another hash"); err != nil {
+		t.Errorf("Failed to set password: %s", err.Error())
+	} else if u, err = tdb.UserGetByName(tUser.Name); err != nil {
+		t.Fatalf("Failed to look up User %s: %s", tUser.Name, err.Error())
+	} else if u.PwHash != "another hash" {
+		t.Errorf("Password hash was not updated: %q", u.PwHash)
+	}
+
+	if u, err = tdb.UserGetByName("armitage"); err != nil {
+		t.Errorf("Failed to look up unknown User: %s", err.Error())
+	} else if u != nil {
+		t.Errorf("Looking up unknown User returned %#v", u)
+	}
+
+	if users, err = tdb.UserGetAll(); err != nil {
+		t.Errorf("Failed to get all Users: %s", err.Error())
+	} else if len(users) != 1 {
+		t.Errorf("Expected 1 User, got %d", len(users))
+	}
+} // func TestUserAdd(t *testing.T)
+
+func TestSession(t *testing.T) {
+	if tdb == nil || tUser == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		cnt  int64
+		s    *model.Session
+		live = &model.Session{
+			User:      tUser,
+			TokenHash: "live",
+			CSRF:      "csrf",
+			Expires:   time.Now().Add(time.Hour),
+		}
+		dead = &model.Session{
+			User:      tUser,
+			TokenHash: "dead",
+			CSRF:      "csrf",
+			Expires:   time.Now().Add(-time.Hour),
+		}
+	)
+
+	for _, sess := range []*model.Session{live, dead} {
+		if err = tdb.SessionAdd(sess); err != nil {
+			t.Fatalf("Failed to add Session: %s", err.Error())
+		}
+	}
+
+	if s, err = tdb.SessionGetByToken(live.TokenHash); err != nil {
+		t.Fatalf("Failed to look up Session: %s", err.Error())
+	} else if s == nil {
+		t.Fatal("Live Session was not found")
+	} else if s.ID != live.ID || s.User.ID != tUser.ID || s.User.Name != tUser.Name {
+		t.Errorf("SessionGetByToken returned unexpected Session: %#v", s)
+	}
+
+	if s, err = tdb.SessionGetByToken(dead.TokenHash); err != nil {
+		t.Errorf("Failed to look up Session: %s", err.Error())
+	} else if s != nil {
+		t.Error("SessionGetByToken returned an expired Session")
+	}
+
+	if cnt, err = tdb.SessionPurge(); err != nil {
+		t.Errorf("Failed to purge expired Sessions: %s", err.Error())
+	} else if cnt != 1 {
+		t.Errorf("Expected to purge 1 Session, purged %d", cnt)
+	}
+
+	if err = tdb.SessionDelete(live); err != nil {
+		t.Errorf("Failed to delete Session: %s", err.Error())
+	} else if err = tdb.SessionDelete(live); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Deleting a Session twice returned %v", err)
+	}
+} // func TestSession(t *testing.T)
+
+func TestToken(t *testing.T) {
+	if tdb == nil || tUser == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err    error
+		tok    *model.APIToken
+		tokens []*model.APIToken
+		orig   = &model.APIToken{
+			User:      tUser,
+			Name:      "cron",
+			TokenHash: "0123456789abcdef",
+		}
+	)
+
+	if err = tdb.TokenAdd(orig); err != nil {
+		t.Fatalf("Failed to add API token: %s", err.Error())
+	} else if tok, err = tdb.TokenGetByHash(orig.TokenHash); err != nil {
+		t.Fatalf("Failed to look up API token: %s", err.Error())
+	} else if tok == nil {
+		t.Fatal("API token was not found")
+	} else if tok.ID != orig.ID || tok.User.ID != tUser.ID || !tok.LastUsed.IsZero() {
+		t.Errorf("TokenGetByHash returned unexpected token: %#v", tok)
+	} else if err = tdb.TokenTouch(tok); err != nil {
+		t.Errorf("Failed to update API token: %s", err.Error())
+	}
+
+	if tokens, err = tdb.TokenGetByUser(tUser); err != nil {
+		t.Errorf("Failed to get API tokens of User: %s", err.Error())
+	} else if len(tokens) != 1 {
+		t.Errorf("Expected 1 API token, got %d", len(tokens))
+	} else if tokens[0].LastUsed.IsZero() {
+		t.Error("Use of API token was not recorded")
+	}
+
+	if err = tdb.TokenDelete(tUser, orig.Name); err != nil {
+		t.Errorf("Failed to delete API token: %s", err.Error())
+	} else if tok, err = tdb.TokenGetByHash(orig.TokenHash); err != nil {
+		t.Errorf("Failed to look up API token: %s", err.Error())
+	} else if tok != nil {
+		t.Error("API token still exists after being deleted")
+	}
+} // func TestToken(t *testing.T)
+
+func TestUserDelete(t *testing.T) {
+	if tdb == nil || tUser == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		s    *model.Session
+		sess = &model.Session{
+			User:      tUser,
+			TokenHash: "orphan",
+			CSRF:      "csrf",
+			Expires:   time.Now().Add(time.Hour),
+		}
+	)
+
+	if err = tdb.SessionAdd(sess); err != nil {
+		t.Fatalf("Failed to add Session: %s", err.Error())
+	} else if err = tdb.UserDelete(tUser); err != nil {
+		t.Fatalf("Failed to delete User: %s", err.Error())
+	} else if s, err = tdb.SessionGetByToken(sess.TokenHash); err != nil {
+		t.Errorf("Failed to look up Session: %s", err.Error())
+	} else if s != nil {
+		t.Error("Session of deleted User still exists")
+	}
+} // func TestUserDelete(t *testing.T)
@@ -0,0 +1,80 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/07_database_asn_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 21:26:32 krylon>
+
+package database
+
+import (
+	"testing"
+
+	"github.com/blicero/guangng/model"
+)
+
+const testASN uint32 = 64496
+
+func TestASNAggregate(t *testing.T) {
+	if tdb == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err      error
+		host     *model.Host
+		info     *model.ASNInfo
+		list     []*model.ASNInfo
+		products []*model.ProductCount
+		hosts    []*model.Host
+	)
+
+	// Host #1 has a successful service on port 80, see TestServiceAdd.
+	if host, err = tdb.HostGetByID(1); err != nil {
+		t.Fatalf("Cannot get Host #1: %s", err.Error())
+	} else if err = tdb.HostSetLocation(host, &model.GeoLocation{
+		CountryCode: "JP",
+		Country:     "Japan",
+		ASN:         testASN,
+		ASOrg:       "Sense/Net",
+	}); err != nil {
+		t.Fatalf("Cannot set location of Host #1: %s", err.Error())
+	}
+
+	if list, err = tdb.ASNGetAll(-1); err != nil {
+		t.Fatalf("Cannot get autonomous systems: %s", err.Error())
+	} else if len(list) != 1 {
+		t.Fatalf("Expected 1 autonomous system, got %d", len(list))
+	} else if list[0].ASN != testASN || list[0].Org != "Sense/Net" || list[0].HostCnt != 1 {
+		t.Errorf("Unexpected ASN summary: %#v", list[0])
+	}
+
+	if info, err = tdb.ASNGetByNumber(testASN); err != nil {
+		t.Fatalf("Cannot get AS%d: %s", testASN, err.Error())
+	} else if info == nil {
+		t.Fatalf("AS%d was not found", testASN)
+	} else if info.SvcCnt < 1 {
+		t.Errorf("AS%d should have at least one service", testASN)
+	}
+
+	if info, err = tdb.ASNGetByNumber(testASN + 1); err != nil {
+		t.Errorf("Cannot get AS%d: %s", testASN+1, err.Error())
+	} else if info != nil {
+		t.Errorf("Unknown AS%d was found: %#v", testASN+1, info)
+	}
+
+	if products, err = tdb.ASNGetProducts(testASN, 10); err != nil {
+		t.Fatalf("Cannot get products in AS%d: %s", testASN, err.Error())
+	} else if len(products) == 0 {
+		t.Error("Found no products in AS", testASN)
+	} else if products[0].Port != 80 || products[0].Product != "1337 h4x0r 5ty1e" {
+		t.Errorf("Unexpected product %#v", products[0])
+	}
+
+	if hosts, err = tdb.HostGetFiltered(&HostFilter{ASN: testASN, Limit: -1}); err != nil {
+		t.Fatalf("Cannot get Hosts in AS%d: %s", testASN, err.Error())
+	} else if len(hosts) != 1 {
+		t.Errorf("Expected 1 Host in AS%d, got %d", testASN, len(hosts))
+	} else if hosts[0].ASOrg != "Sense/Net" {
+		t.Errorf("Host in AS%d has organization %q", testASN, hosts[0].ASOrg)
+	}
+} // func TestASNAggregate(t *testing.T)
@@ -0,0 +1,76 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/08_database_stats_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 22:04:17 krylon>
+
+package database
+
+import (
+	"testing"
+
+	"github.com/blicero/guangng/model"
+)
+
+// Host #1 has been placed in Japan by TestASNAggregate.
+
+func TestStatsByCountry(t *testing.T) {
+	if tdb == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err   error
+		list  []*model.CountryStats
+		hosts []*model.Host
+	)
+
+	if list, err = tdb.StatsByCountry(); err != nil {
+		t.Fatalf("Cannot get statistics by country: %s", err.Error())
+	} else if len(list) != 1 {
+		t.Fatalf("Expected 1 country, got %d", len(list))
+	} else if list[0].CountryCode != "JP" || list[0].Country != "Japan" || list[0].HostCnt != 1 {
+		t.Errorf("Unexpected country statistics: %#v", list[0])
+	} else if list[0].SvcCnt < 1 {
+		t.Error("Japan should have at least one service")
+	}
+
+	if hosts, err = tdb.HostGetFiltered(&HostFilter{Country: "JP", Limit: -1}); err != nil {
+		t.Fatalf("Cannot get Hosts in Japan: %s", err.Error())
+	} else if len(hosts) != 1 || hosts[0].ID != 1 {
+		t.Errorf("Expected Host #1 in Japan, got %d Hosts", len(hosts))
+	}
+} // func TestStatsByCountry(t *testing.T)
+
+func TestStatsPerCountry(t *testing.T) {
+	if tdb == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err    error
+		cities []*model.CityStats
+		ports  []*model.PortStats
+		srcs   []*model.SourceStats
+	)
+
+	if cities, err = tdb.StatsByCity(); err != nil {
+		t.Errorf("Cannot get statistics by city: %s", err.Error())
+	} else if len(cities) != 0 {
+		t.Errorf("Host #1 has no city, but got %d cities", len(cities))
+	}
+
+	if ports, err = tdb.StatsTopPorts(5); err != nil {
+		t.Errorf("Cannot get top ports: %s", err.Error())
+	} else if len(ports) == 0 {
+		t.Error("Found no ports in Japan")
+	} else if ports[0].CountryCode != "JP" || ports[0].Port != 80 {
+		t.Errorf("Unexpected top port: %#v", ports[0])
+	}
+
+	if srcs, err = tdb.StatsBySource(); err != nil {
+		t.Errorf("Cannot get statistics by source: %s", err.Error())
+	} else if len(srcs) != 1 || srcs[0].HostCnt != 1 {
+		t.Errorf("Expected 1 source with 1 Host, got %d", len(srcs))
+	}
+} // func TestStatsPerCountry(t *testing.T)
@@ -0,0 +1,62 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/09_database_permutation_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-21 00:04:33 krylon>
+
+package database
+
+import (
+	"testing"
+
+	"github.com/blicero/guangng/model"
+)
+
+func TestPermutation(t *testing.T) {
+	if tdb == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err error
+		p   *model.Permutation
+		q   = &model.Permutation{
+			Seed:     4711,
+			Root:     1<<32 + 3,
+			Position: 1 << 32,
+		}
+	)
+
+	if p, err = tdb.PermutationGetLatest(); err != nil {
+		t.Fatalf("Cannot get latest permutation: %s", err.Error())
+	} else if p != nil {
+		t.Fatalf("Found permutation in empty database: %#v", p)
+	} else if err = tdb.PermutationSave(q); err != nil {
+		t.Fatalf("Cannot save permutation: %s", err.Error())
+	}
+
+	q.Position = 17
+	q.Count = 23
+
+	if err = tdb.PermutationSave(q); err != nil {
+		t.Fatalf("Cannot update permutation: %s", err.Error())
+	} else if p, err = tdb.PermutationGet(q.Seed); err != nil {
+		t.Fatalf("Cannot get permutation %d: %s", q.Seed, err.Error())
+	} else if p == nil {
+		t.Fatalf("Permutation %d was not found", q.Seed)
+	} else if p.Root != q.Root || p.Position != 17 || p.Count != 23 {
+		t.Errorf("Unexpected permutation state: %#v", p)
+	}
+
+	if p, err = tdb.PermutationGetLatest(); err != nil {
+		t.Fatalf("Cannot get latest permutation: %s", err.Error())
+	} else if p == nil || p.Seed != q.Seed {
+		t.Errorf("Latest permutation should be %d, got %#v", q.Seed, p)
+	}
+
+	if p, err = tdb.PermutationGet(q.Seed + 1); err != nil {
+		t.Errorf("Cannot get permutation %d: %s", q.Seed+1, err.Error())
+	} else if p != nil {
+		t.Errorf("Unknown permutation %d was found: %#v", q.Seed+1, p)
+	}
+} // func TestPermutation(t *testing.T)
@@ -0,0 +1,59 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/10_database_yield_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-21 11:48:02 krylon>
+
+package database
+
+import (
+	"testing"
+
+	"github.com/blicero/guangng/model"
+)
+
+func TestNetYield(t *testing.T) {
+	if tdb == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		list []*model.NetYield
+		nets = []*model.NetYield{
+			{Prefix: 0xC6336400, Bits: 24, Attempts: 10, Hits: 4},
+			{Prefix: 0xC6330000, Bits: 16, Attempts: 100, Hits: 4, Blocked: 2},
+			{Prefix: 0xCB000000, Bits: 16, Attempts: 100, Hits: 9},
+		}
+	)
+
+	for _, n := range nets {
+		if err = tdb.NetYieldSave(n); err != nil {
+			t.Fatalf("Cannot save yield of %s: %s", n.Net(), err.Error())
+		}
+	}
+
+	nets[0].Attempts = 20
+
+	if err = tdb.NetYieldSave(nets[0]); err != nil {
+		t.Fatalf("Cannot update yield of %s: %s", nets[0].Net(), err.Error())
+	} else if list, err = tdb.NetYieldGetAll(); err != nil {
+		t.Fatalf("Cannot get yield statistics: %s", err.Error())
+	} else if len(list) != len(nets) {
+		t.Errorf("Expected %d networks, got %d", len(nets), len(list))
+	}
+
+	if list, err = tdb.NetYieldGetTop(16, 1); err != nil {
+		t.Fatalf("Cannot get top /16s: %s", err.Error())
+	} else if len(list) != 1 {
+		t.Fatalf("Expected 1 network, got %d", len(list))
+	} else if s := list[0].Net().String(); s != "203.0.0.0/16" {
+		t.Errorf("Expected 203.0.0.0/16 to be the most productive, got %s", s)
+	}
+
+	if list, err = tdb.NetYieldGetTop(24, -1); err != nil {
+		t.Fatalf("Cannot get top /24s: %s", err.Error())
+	} else if len(list) != 1 || list[0].Attempts != 20 || list[0].Yield() != 0.2 {
+		t.Errorf("Unexpected /24 statistics: %#v", list)
+	}
+} // func TestNetYield(t *testing.T)
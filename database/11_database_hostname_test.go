@@ -0,0 +1,67 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/11_database_hostname_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:25:10 krylon>
+
+package database
+
+import (
+	"net"
+	"testing"
+
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/hsrc"
+)
+
+func TestHostName(t *testing.T) {
+	if tdb == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err   error
+		list  []*model.HostName
+		dummy *model.Host
+		host  = &model.Host{
+			Addr:      net.ParseIP("10.10.0.17"),
+			Name:      "mail.straylight.test.",
+			Source:    hsrc.Generator,
+			Confirmed: true,
+		}
+		names = []*model.HostName{
+			{Name: "www.straylight.test."},
+			{Name: "mail.straylight.test.", Confirmed: true},
+		}
+	)
+
+	if err = tdb.HostAdd(host); err != nil {
+		t.Fatalf("Cannot add Host %s: %s", host.Name, err.Error())
+	} else if dummy, err = tdb.HostGetByID(host.ID); err != nil {
+		t.Fatalf("Cannot load Host %d: %s", host.ID, err.Error())
+	} else if dummy == nil || !dummy.Confirmed {
+		t.Errorf("Host %s was not loaded as confirmed", host.Name)
+	}
+
+	for _, n := range names {
+		if err = tdb.HostNameAdd(host, n); err != nil {
+			t.Fatalf("Cannot add name %s: %s", n.Name, err.Error())
+		}
+	}
+
+	// Adding a name again updates it.
+	names[0].Confirmed = true
+	if err = tdb.HostNameAdd(host, names[0]); err != nil {
+		t.Fatalf("Cannot update name %s: %s", names[0].Name, err.Error())
+	} else if list, err = tdb.HostNameGetByHost(host); err != nil {
+		t.Fatalf("Cannot get names of Host %s: %s", host.Name, err.Error())
+	} else if len(list) != len(names) {
+		t.Fatalf("Expected %d names, got %d", len(names), len(list))
+	}
+
+	for i, n := range list {
+		if n.Name != names[i].Name || !n.Confirmed {
+			t.Errorf("Unexpected name #%d: %s (%t)", i, n.Name, n.Confirmed)
+		}
+	}
+} // func TestHostName(t *testing.T)
@@ -0,0 +1,73 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/12_database_xfrresult_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:47:45 krylon>
+
+package database
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/outcome"
+)
+
+func TestXFRResult(t *testing.T) {
+	if tdb == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err     error
+		list    []*model.XFRResult
+		z       = &model.Zone{Name: "straylight.test", Added: time.Now()}
+		results = []*model.XFRResult{
+			{NS: "ns1.straylight.test.", Addr: net.ParseIP("192.0.2.53"), Outcome: outcome.Refused},
+			{NS: "ns1.straylight.test.", Addr: net.ParseIP("2001:db8::53"), Outcome: outcome.Success, RRCnt: 42},
+			{NS: "ns2.straylight.test.", Addr: net.ParseIP("198.51.100.53"), Outcome: outcome.Timeout},
+		}
+	)
+
+	if err = tdb.XFRAdd(z); err != nil {
+		t.Fatalf("Cannot add zone %s: %s", z.Name, err.Error())
+	}
+
+	for _, r := range results {
+		if err = tdb.XFRResultAdd(z, r); err != nil {
+			t.Fatalf("Cannot add result for %s: %s", r.Addr, err.Error())
+		}
+	}
+
+	if list, err = tdb.XFRResultGetByZone(z); err != nil {
+		t.Fatalf("Cannot get results for %s: %s", z.Name, err.Error())
+	} else if len(list) != len(results) {
+		t.Fatalf("Expected %d results, got %d", len(results), len(list))
+	}
+
+	for i, r := range list {
+		if !r.Addr.Equal(results[i].Addr) || r.Outcome != results[i].Outcome || r.RRCnt != results[i].RRCnt {
+			t.Errorf("Unexpected result #%d: %s %s %d",
+				i,
+				r.Addr,
+				r.Outcome,
+				r.RRCnt)
+		}
+	}
+
+	if list, err = tdb.XFRResultGetOpen(10); err != nil {
+		t.Fatalf("Cannot get open servers: %s", err.Error())
+	} else if len(list) != 1 {
+		t.Fatalf("Expected 1 open server, got %d", len(list))
+	} else if list[0].Zone != z.Name || !list[0].Addr.Equal(results[1].Addr) || !list[0].Open() {
+		t.Errorf("Unexpected open server: %s %s %s", list[0].Zone, list[0].Addr, list[0].Outcome)
+	}
+
+	if list, err = tdb.XFRResultGetRecent(2); err != nil {
+		t.Fatalf("Cannot get recent results: %s", err.Error())
+	} else if len(list) != 2 {
+		t.Errorf("Expected 2 recent results, got %d", len(list))
+	}
+} // func TestXFRResult(t *testing.T)
@@ -0,0 +1,101 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/13_database_zonerecord_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:02:25 krylon>
+
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blicero/guangng/model"
+)
+
+func TestZoneRecord(t *testing.T) {
+	if tdb == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err     error
+		list    []*model.ZoneRecord
+		first   = time.Now().Add(-time.Hour)
+		z       = &model.Zone{Name: "villa.straylight.test", Added: first}
+		records = []*model.ZoneRecord{
+			{Owner: "villa.straylight.test.", Type: "NS", TTL: 3600, RData: "ns.villa.straylight.test.", Added: first},
+			{Owner: "ns.villa.straylight.test.", Type: "A", TTL: 3600, RData: "192.0.2.53", Added: first},
+			{Owner: "www.villa.straylight.test.", Type: "A", TTL: 300, RData: "192.0.2.80", Added: first},
+		}
+		fresh = &model.ZoneRecord{Owner: "www.villa.straylight.test.", Type: "A", TTL: 300, RData: "192.0.2.81"}
+	)
+
+	if err = tdb.XFRAdd(z); err != nil {
+		t.Fatalf("Cannot add zone %s: %s", z.Name, err.Error())
+	}
+
+	for _, r := range records {
+		if err = tdb.ZoneRecordAdd(z, r); err != nil {
+			t.Fatalf("Cannot add record %s: %s", r.Key(), err.Error())
+		}
+	}
+
+	// A second transfer: www moved to another address and got a new TTL.
+	if err = tdb.ZoneRecordRemove(records[2]); err != nil {
+		t.Fatalf("Cannot remove record %s: %s", records[2].Key(), err.Error())
+	} else if err = tdb.ZoneRecordAdd(z, fresh); err != nil {
+		t.Fatalf("Cannot add record %s: %s", fresh.Key(), err.Error())
+	} else if err = tdb.ZoneRecordSetTTL(records[1], 7200); err != nil {
+		t.Fatalf("Cannot update TTL of %s: %s", records[1].Key(), err.Error())
+	}
+
+	if list, err = tdb.ZoneRecordGetCurrent(z); err != nil {
+		t.Fatalf("Cannot get records of %s: %s", z.Name, err.Error())
+	} else if len(list) != 3 {
+		t.Fatalf("Expected 3 current records, got %d", len(list))
+	}
+
+	for _, r := range list {
+		if r.IsRemoved() {
+			t.Errorf("Removed record %s is still current", r.Key())
+		} else if r.Key() == records[1].Key() && r.TTL != 7200 {
+			t.Errorf("TTL of %s was not updated", r.Key())
+		}
+	}
+
+	if list, err = tdb.ZoneRecordGetByType(z, "A"); err != nil {
+		t.Fatalf("Cannot get A records of %s: %s", z.Name, err.Error())
+	} else if len(list) != 2 {
+		t.Fatalf("Expected 2 current A records, got %d", len(list))
+	} else if list[0].Owner != records[1].Owner || list[1].RData != fresh.RData {
+		t.Errorf("Unexpected A records: %s, %s", list[0], list[1])
+	}
+
+	if list, err = tdb.ZoneRecordGetByOwner("www.villa.straylight.test.", "A"); err != nil {
+		t.Fatalf("Cannot get records by owner: %s", err.Error())
+	} else if len(list) != 1 || list[0].RData != fresh.RData || list[0].ZoneID != z.ID {
+		t.Errorf("Unexpected records for www.villa.straylight.test.: %v", list)
+	}
+
+	if list, err = tdb.ZoneRecordGetChanges(z, 10); err != nil {
+		t.Fatalf("Cannot get changes to %s: %s", z.Name, err.Error())
+	} else if len(list) != 2 {
+		t.Fatalf("Expected 2 changes, got %d", len(list))
+	}
+
+	for _, r := range list {
+		switch r.Key() {
+		case fresh.Key():
+			if r.IsRemoved() {
+				t.Errorf("Record %s is marked as removed", r.Key())
+			}
+		case records[2].Key():
+			if !r.IsRemoved() {
+				t.Errorf("Record %s is not marked as removed", r.Key())
+			}
+		default:
+			t.Errorf("Unexpected change: %s", r.Key())
+		}
+	}
+} // func TestZoneRecord(t *testing.T)
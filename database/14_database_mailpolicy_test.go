@@ -0,0 +1,79 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/14_database_mailpolicy_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:02:25 krylon>
+
+package database
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/weakness"
+)
+
+func TestMailPolicy(t *testing.T) {
+	if tdb == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		p    *model.MailPolicy
+		list []*model.MailPolicy
+		z    = &model.Zone{Name: "marienbad.test", Added: time.Now()}
+		pol  = &model.MailPolicy{
+			HasMX:       true,
+			SPF:         "v=spf1 mx +all",
+			SPFAll:      "+",
+			SPFLookups:  1,
+			DMARC:       "v=DMARC1; p=none",
+			DMARCPolicy: "none",
+			Weaknesses:  []weakness.Weakness{weakness.SPFPassAll, weakness.DMARCNone, weakness.NoMTASTS},
+			Checked:     time.Now(),
+		}
+	)
+
+	if err = tdb.XFRAdd(z); err != nil {
+		t.Fatalf("Cannot add zone %s: %s", z.Name, err.Error())
+	} else if p, err = tdb.MailPolicyGetByZone(z); err != nil {
+		t.Fatalf("Cannot get mail policy of %s: %s", z.Name, err.Error())
+	} else if p != nil {
+		t.Fatalf("Zone %s has a mail policy before we stored one", z.Name)
+	}
+
+	if err = tdb.MailPolicySet(z, pol); err != nil {
+		t.Fatalf("Cannot store mail policy of %s: %s", z.Name, err.Error())
+	} else if p, err = tdb.MailPolicyGetByZone(z); err != nil {
+		t.Fatalf("Cannot get mail policy of %s: %s", z.Name, err.Error())
+	} else if p == nil {
+		t.Fatalf("Mail policy of %s was not stored", z.Name)
+	} else if p.ID != pol.ID || p.Zone != z.Name || p.SPF != pol.SPF || !p.Has(weakness.DMARCNone) {
+		t.Errorf("Unexpected mail policy: %#v", p)
+	}
+
+	// The zone fixed its SPF record.
+	pol.SPF, pol.SPFAll = "v=spf1 mx -all", "-"
+	pol.Weaknesses = []weakness.Weakness{weakness.DMARCNone}
+
+	if err = tdb.MailPolicySet(z, pol); err != nil {
+		t.Fatalf("Cannot update mail policy of %s: %s", z.Name, err.Error())
+	} else if list, err = tdb.MailPolicyGetAll(); err != nil {
+		t.Fatalf("Cannot get mail policies: %s", err.Error())
+	} else if len(list) != 1 {
+		t.Fatalf("Expected 1 mail policy, got %d", len(list))
+	} else if p = list[0]; p.SPFAll != "-" || !slices.Equal(p.Weaknesses, pol.Weaknesses) {
+		t.Errorf("Mail policy was not updated: %#v", p)
+	}
+
+	if err = tdb.MailPolicyDelete(z); err != nil {
+		t.Fatalf("Cannot delete mail policy of %s: %s", z.Name, err.Error())
+	} else if p, err = tdb.MailPolicyGetByZone(z); err != nil {
+		t.Fatalf("Cannot get mail policy of %s: %s", z.Name, err.Error())
+	} else if p != nil {
+		t.Errorf("Mail policy of %s was not deleted", z.Name)
+	}
+} // func TestMailPolicy(t *testing.T)
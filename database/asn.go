@@ -0,0 +1,174 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/asn.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 21:31:09 krylon>
+
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/blicero/guangng/database/query"
+	"github.com/blicero/guangng/model"
+)
+
+// ASNGetAll returns up to <max> autonomous systems we know Hosts in, those
+// with the most Hosts first. A max of -1 means no limit.
+func (db *Database) ASNGetAll(max int) ([]*model.ASNInfo, error) {
+	const qid query.ID = query.ASNGetAll
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(max); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query autonomous systems: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var list = make([]*model.ASNInfo, 0, 32)
+
+	for rows.Next() {
+		var info = new(model.ASNInfo)
+
+		if err = rows.Scan(&info.ASN, &info.Org, &info.HostCnt, &info.SvcCnt); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		list = append(list, info)
+	}
+
+	return list, rows.Err()
+} // func (db *Database) ASNGetAll(max int) ([]*model.ASNInfo, error)
+
+// ASNGetByNumber returns a summary of the Hosts in the given autonomous
+// system. If we know no Hosts in it, it returns nil without an error.
+func (db *Database) ASNGetByNumber(asn uint32) (*model.ASNInfo, error) {
+	const qid query.ID = query.ASNGetByNumber
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(asn); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query AS%d: %s\n",
+			asn,
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var (
+		org  sql.NullString
+		info = &model.ASNInfo{ASN: asn}
+	)
+
+	if err = rows.Scan(&org, &info.HostCnt, &info.SvcCnt); err != nil {
+		var ex = fmt.Errorf("failed to scan row: %w", err)
+		db.log.Printf("[ERROR] %s\n", ex.Error())
+		return nil, ex
+	} else if info.HostCnt == 0 {
+		return nil, nil
+	}
+
+	info.Org = org.String
+	return info, nil
+} // func (db *Database) ASNGetByNumber(asn uint32) (*model.ASNInfo, error)
+
+// ASNGetProducts returns the most common service responses in the given
+// autonomous system, up to <max> of them.
+func (db *Database) ASNGetProducts(asn uint32, max int) ([]*model.ProductCount, error) {
+	const qid query.ID = query.ASNGetProducts
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(asn, max); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query products in AS%d: %s\n",
+			asn,
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var list = make([]*model.ProductCount, 0, 16)
+
+	for rows.Next() {
+		var p = new(model.ProductCount)
+
+		if err = rows.Scan(&p.Port, &p.Product, &p.Count); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		list = append(list, p)
+	}
+
+	return list, rows.Err()
+} // func (db *Database) ASNGetProducts(asn uint32, max int) ([]*model.ProductCount, error)
@@ -19,6 +19,7 @@ import (
 	"github.com/blicero/guangng/common"
 	"github.com/blicero/guangng/database/query"
 	"github.com/blicero/guangng/logdomain"
+	"github.com/blicero/guangng/metrics"
 	"github.com/blicero/krylib"
 	_ "github.com/mattn/go-sqlite3" // Import the database driver
 )
@@ -66,6 +67,7 @@ func worthARetry(e error) bool {
 const retryDelay = 25 * time.Millisecond
 
 func waitForRetry() {
+	metrics.DBRetries.Inc()
 	time.Sleep(retryDelay)
 } // func waitForRetry()
 
@@ -140,6 +142,11 @@ func Open(path string) (*Database, error) {
 		}
 		db.log.Printf("[INFO] Database at %s has been initialized\n",
 			path)
+	} else if err = db.migrate(); err != nil {
+		db.log.Printf("[ERROR] Failed to upgrade database schema: %s\n",
+			err.Error())
+		db.db.Close() // nolint: errcheck
+		return nil, err
 	}
 
 	return db, nil
@@ -176,7 +183,16 @@ func (db *Database) initialize() error {
 		}
 	}
 
-	if err = tx.Commit(); err != nil {
+	if _, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", schemaVersion)); err != nil {
+		db.log.Printf("[ERROR] Cannot set schema version: %s\n",
+			err.Error())
+		if rbErr := tx.Rollback(); rbErr != nil {
+			db.log.Printf("[CANTHAPPEN] Cannot rollback transaction: %s\n",
+				rbErr.Error())
+			return rbErr
+		}
+		return err
+	} else if err = tx.Commit(); err != nil {
 		db.log.Printf("[CANTHAPPEN] Failed to commit init transaction: %s\n",
 			err.Error())
 		return err
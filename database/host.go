@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 15. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-02-07 16:48:35 krylon>
+// Time-stamp: <2026-10-19 14:25:10 krylon>
 
 package database
 
@@ -15,6 +15,7 @@ import (
 
 	"github.com/blicero/guangng/database/query"
 	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/hsrc"
 )
 
 // HostAdd adds a Host to the Database.
@@ -40,7 +41,7 @@ func (db *Database) HostAdd(host *model.Host) error {
 	)
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(host.AStr(), host.Name, now.Unix(), host.Source); err != nil {
+	if rows, err = stmt.Query(host.AStr(), host.Name, now.Unix(), host.Source, host.Confirmed); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
@@ -115,7 +116,7 @@ EXEC_QUERY:
 			host           = &model.Host{ID: id}
 		)
 
-		if err = rows.Scan(&addr, &host.Name, &added, &contact, &host.Sysname, &host.Location, &host.Source); err != nil {
+		if err = rows.Scan(&addr, &host.Name, &added, &contact, &host.Sysname, &host.OSConfidence, &host.ASN, &host.ASOrg, &host.Location, &host.Source, &host.Confirmed); err != nil {
 			var ex = fmt.Errorf("failed to scan row: %w", err)
 			db.log.Printf("[ERROR] %s\n", ex.Error())
 			return nil, ex
@@ -134,6 +135,58 @@ EXEC_QUERY:
 	return nil, nil
 } // func (db *Database) HostGetByID(id int64) (*model.Host, error)
 
+// HostGetByAddr looks up a Host by its IP address.
+// If no such Host exists, it returns nil without an error.
+func (db *Database) HostGetByAddr(addr net.IP) (*model.Host, error) {
+	const qid query.ID = query.HostGetByAddr
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(addr.String()); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if rows.Next() {
+		var (
+			added, contact int64
+			host           = &model.Host{Addr: addr}
+		)
+
+		if err = rows.Scan(&host.ID, &host.Name, &added, &contact, &host.Sysname, &host.OSConfidence, &host.ASN, &host.ASOrg, &host.Location, &host.Source, &host.Confirmed); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		host.Added = time.Unix(added, 0)
+		host.LastContact = time.Unix(contact, 0)
+		return host, nil
+	}
+
+	return nil, nil
+} // func (db *Database) HostGetByAddr(addr net.IP) (*model.Host, error)
+
 // HostGetMap returns a map of all Hosts, using their IDs as keys.
 func (db *Database) HostGetMap() (map[int64]*model.Host, error) {
 	const qid query.ID = query.HostGetAll
@@ -146,7 +199,7 @@ func (db *Database) HostGetMap() (map[int64]*model.Host, error) {
 GET_QUERY:
 	if stmt, err = db.getQuery(qid); err != nil {
 		if worthARetry(err) {
-			time.Sleep(retryDelay)
+			waitForRetry()
 			goto GET_QUERY
 		} else {
 			db.log.Printf("[ERROR] Error getting query %s: %s",
@@ -163,7 +216,7 @@ GET_QUERY:
 EXEC_QUERY:
 	if rows, err = stmt.Query(-1); err != nil {
 		if worthARetry(err) {
-			time.Sleep(retryDelay)
+			waitForRetry()
 			goto EXEC_QUERY
 		} else {
 			msg = fmt.Sprintf("Error querying all hosts: %s",
@@ -189,8 +242,12 @@ EXEC_QUERY:
 			&added,
 			&contact,
 			&host.Sysname,
+			&host.OSConfidence,
+			&host.ASN,
+			&host.ASOrg,
 			&host.Location,
-			&host.Source); err != nil {
+			&host.Source,
+			&host.Confirmed); err != nil {
 			msg = fmt.Sprintf("Error scanning row: %s", err.Error())
 			db.log.Printf("[ERROR] %s\n", msg)
 			return nil, errors.New(msg)
@@ -217,7 +274,7 @@ func (db *Database) HostGetRandom(max int) ([]*model.Host, error) {
 GET_QUERY:
 	if stmt, err = db.getQuery(qid); err != nil {
 		if worthARetry(err) {
-			time.Sleep(retryDelay)
+			waitForRetry()
 			goto GET_QUERY
 		} else {
 			db.log.Printf("[ERROR] Error getting query %s: %s",
@@ -234,7 +291,7 @@ GET_QUERY:
 EXEC_QUERY:
 	if rows, err = stmt.Query(max); err != nil {
 		if worthARetry(err) {
-			time.Sleep(retryDelay)
+			waitForRetry()
 			goto EXEC_QUERY
 		} else {
 			msg = fmt.Sprintf("Error querying %d random hosts: %s",
@@ -260,8 +317,12 @@ EXEC_QUERY:
 			&added,
 			&contact,
 			&host.Sysname,
+			&host.OSConfidence,
+			&host.ASN,
+			&host.ASOrg,
 			&host.Location,
-			&host.Source); err != nil {
+			&host.Source,
+			&host.Confirmed); err != nil {
 			msg = fmt.Sprintf("Error scanning row: %s", err.Error())
 			db.log.Printf("[ERROR] %s\n", msg)
 			return nil, errors.New(msg)
@@ -320,3 +381,239 @@ EXEC_QUERY:
 
 	return -1, nil
 } // func (db *Database) HostGetCnt() (int64, error)
+
+// HostFilter narrows down the set of Hosts returned by HostWalk and
+// HostGetFiltered. The zero value of each field matches all Hosts.
+type HostFilter struct {
+	Source  hsrc.HostSource // Only Hosts from this source
+	Name    string          // Only Hosts whose name contains this string
+	Port    uint16          // Only Hosts with a successful response on this port
+	ASN     uint32          // Only Hosts in this autonomous system
+	Country string          // Only Hosts in this country (ISO 3166 code)
+	Limit   int             // Return at most this many Hosts, -1 means no limit
+}
+
+// HostWalk calls fn for every Host matched by the filter, in the order they
+// were added to the Database. If fn returns an error, the walk is aborted and
+// that error is returned.
+// A nil filter matches all Hosts.
+func (db *Database) HostWalk(f *HostFilter, fn func(*model.Host) error) error {
+	const qid query.ID = query.HostGetFiltered
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if f == nil {
+		f = &HostFilter{Limit: -1}
+	} else if f.Limit < 1 && f.Limit != -1 {
+		err = fmt.Errorf("invalid limit %d", f.Limit)
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return err
+	}
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(f.Source, f.Name, f.Port, f.Limit, f.ASN, f.Country); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query filtered Hosts: %s\n",
+			err.Error())
+		return err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	for rows.Next() {
+		var (
+			added, contact int64
+			addrStr        string
+			host           = new(model.Host)
+		)
+
+		if err = rows.Scan(
+			&host.ID,
+			&addrStr,
+			&host.Name,
+			&added,
+			&contact,
+			&host.Sysname,
+			&host.OSConfidence,
+			&host.ASN,
+			&host.ASOrg,
+			&host.Location,
+			&host.Source,
+			&host.Confirmed); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return ex
+		}
+
+		host.Addr = net.ParseIP(addrStr)
+		host.Added = time.Unix(added, 0)
+		host.LastContact = time.Unix(contact, 0)
+
+		if err = fn(host); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+} // func (db *Database) HostWalk(f *HostFilter, fn func(*model.Host) error) error
+
+// HostGetFiltered returns all Hosts matched by the filter.
+func (db *Database) HostGetFiltered(f *HostFilter) ([]*model.Host, error) {
+	var (
+		err   error
+		hosts = make([]*model.Host, 0, 64)
+	)
+
+	err = db.HostWalk(f, func(h *model.Host) error {
+		hosts = append(hosts, h)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return hosts, nil
+} // func (db *Database) HostGetFiltered(f *HostFilter) ([]*model.Host, error)
+
+// HostGetGeoPending returns up to <max> Hosts whose location has not been
+// looked up since <before>, those never looked up first.
+func (db *Database) HostGetGeoPending(max int, before time.Time) ([]*model.Host, error) {
+	const qid query.ID = query.HostGetGeoPending
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(before.Unix(), max); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var hosts = make([]*model.Host, 0, max)
+
+	for rows.Next() {
+		var (
+			added, contact int64
+			addrStr        string
+			host           = new(model.Host)
+		)
+
+		if err = rows.Scan(
+			&host.ID,
+			&addrStr,
+			&host.Name,
+			&added,
+			&contact,
+			&host.Sysname,
+			&host.OSConfidence,
+			&host.ASN,
+			&host.ASOrg,
+			&host.Location,
+			&host.Source,
+			&host.Confirmed); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		} else if host.Addr = net.ParseIP(addrStr); host.Addr == nil {
+			err = fmt.Errorf("could not parse IP address %q",
+				addrStr)
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return nil, err
+		}
+
+		host.Added = time.Unix(added, 0)
+		host.LastContact = time.Unix(contact, 0)
+		hosts = append(hosts, host)
+	}
+
+	return hosts, nil
+} // func (db *Database) HostGetGeoPending(max int, before time.Time) ([]*model.Host, error)
+
+// HostSetLocation records the location of a Host. A nil location records
+// that we looked, but the GeoIP database knows nothing about the Host.
+func (db *Database) HostSetLocation(h *model.Host, loc *model.GeoLocation) error {
+	var (
+		err  error
+		desc string
+		geo  = loc
+	)
+
+	if geo == nil {
+		geo = new(model.GeoLocation)
+	}
+
+	desc = geo.String()
+
+	if err = db.execOne(
+		query.HostUpdateLocation,
+		desc,
+		geo.CountryCode,
+		geo.City,
+		geo.ASN,
+		geo.ASOrg,
+		time.Now().Unix(),
+		h.ID); err != nil {
+		return fmt.Errorf("cannot set location of Host %s to %q: %w",
+			h.AStr(),
+			desc,
+			err)
+	}
+
+	h.Location = desc
+	h.ASN = geo.ASN
+	h.ASOrg = geo.ASOrg
+	return nil
+} // func (db *Database) HostSetLocation(h *model.Host, loc *model.GeoLocation) error
+
+// HostSetSysname records the operating system we believe a Host runs, and
+// how confident we are about it.
+func (db *Database) HostSetSysname(h *model.Host, sysname string, confidence float64) error {
+	var err error
+
+	if err = db.execOne(query.HostUpdateSysname, sysname, confidence, h.ID); err != nil {
+		return fmt.Errorf("cannot set OS of Host %s to %q: %w",
+			h.AStr(),
+			sysname,
+			err)
+	}
+
+	h.Sysname = sysname
+	h.OSConfidence = confidence
+	return nil
+} // func (db *Database) HostSetSysname(h *model.Host, sysname string, confidence float64) error
@@ -0,0 +1,123 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/hostname.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:25:10 krylon>
+
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/blicero/guangng/database/query"
+	"github.com/blicero/guangng/model"
+)
+
+// HostNameAdd records one of the names of a Host. If the Host already has
+// that name, we update whether it is confirmed.
+func (db *Database) HostNameAdd(h *model.Host, n *model.HostName) error {
+	const qid query.ID = query.HostNameAdd
+	var (
+		err  error
+		stmt *sql.Stmt
+		rows *sql.Rows
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	if n.Checked.IsZero() {
+		n.Checked = time.Now()
+	}
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(h.ID, n.Name, n.Confirmed, n.Checked.Unix()); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		err = fmt.Errorf("cannot add name %s to Host %s: %w",
+			n.Name,
+			h.AStr(),
+			err)
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if !rows.Next() {
+		// CANTHAPPEN
+		return fmt.Errorf("query %s did not return a value", qid)
+	} else if err = rows.Scan(&n.ID); err != nil {
+		var ex = fmt.Errorf("failed to get ID of name %s: %w", n.Name, err)
+		db.log.Printf("[ERROR] %s\n", ex.Error())
+		return ex
+	}
+
+	n.HostID = h.ID
+	return nil
+} // func (db *Database) HostNameAdd(h *model.Host, n *model.HostName) error
+
+// HostNameGetByHost loads the names of a Host, the confirmed ones first.
+func (db *Database) HostNameGetByHost(h *model.Host) ([]*model.HostName, error) {
+	const qid query.ID = query.HostNameGetByHost
+	var (
+		err  error
+		stmt *sql.Stmt
+		rows *sql.Rows
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(h.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query names of Host %s: %s\n",
+			h.AStr(),
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var list = make([]*model.HostName, 0, 2)
+
+	for rows.Next() {
+		var (
+			checked int64
+			n       = &model.HostName{HostID: h.ID}
+		)
+
+		if err = rows.Scan(&n.ID, &n.Name, &n.Confirmed, &checked); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		n.Checked = time.Unix(checked, 0)
+		list = append(list, n)
+	}
+
+	return list, rows.Err()
+} // func (db *Database) HostNameGetByHost(h *model.Host) ([]*model.HostName, error)
@@ -0,0 +1,245 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/mailpolicy.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:02:25 krylon>
+
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blicero/guangng/database/query"
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/weakness"
+)
+
+// MailPolicySet stores the e-mail security policy of the zone z, replacing
+// the one we had before. The caller should wrap this in a transaction, as
+// the Weaknesses are stored separately.
+func (db *Database) MailPolicySet(z *model.Zone, p *model.MailPolicy) error {
+	const qid query.ID = query.MailPolicySet
+	var (
+		err  error
+		stmt *sql.Stmt
+		rows *sql.Rows
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(
+		z.ID,
+		p.HasMX,
+		p.SPF,
+		p.SPFAll,
+		p.SPFLookups,
+		p.DMARC,
+		p.DMARCPolicy,
+		p.MTASTS,
+		p.TLSRPT,
+		p.Checked.Unix()); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		err = fmt.Errorf("cannot store mail policy of %s: %w",
+			z.Name,
+			err)
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if !rows.Next() {
+		// CANTHAPPEN
+		return fmt.Errorf("query %s did not return a value", qid)
+	} else if err = rows.Scan(&p.ID); err != nil {
+		var ex = fmt.Errorf("failed to get ID of mail policy: %w", err)
+		db.log.Printf("[ERROR] %s\n", ex.Error())
+		return ex
+	}
+
+	rows.Close() // nolint: errcheck,gosec
+
+	p.ZoneID = z.ID
+	p.Zone = z.Name
+
+	if err = db.mailPolicyExec(query.MailWeaknessClear, p.ID); err != nil {
+		return err
+	}
+
+	for _, w := range p.Weaknesses {
+		if err = db.mailPolicyExec(query.MailWeaknessAdd, p.ID, w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+} // func (db *Database) MailPolicySet(z *model.Zone, p *model.MailPolicy) error
+
+// MailPolicyDelete removes the e-mail security policy of the zone z, e.g.
+// because it no longer receives mail.
+func (db *Database) MailPolicyDelete(z *model.Zone) error {
+	return db.mailPolicyExec(query.MailPolicyDelete, z.ID)
+} // func (db *Database) MailPolicyDelete(z *model.Zone) error
+
+func (db *Database) mailPolicyExec(qid query.ID, args ...any) error {
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(args...); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		err = fmt.Errorf("query %s failed: %w", qid, err)
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return err
+	}
+
+	return nil
+} // func (db *Database) mailPolicyExec(qid query.ID, args ...any) error
+
+// MailPolicyGetByZone returns the e-mail security policy of the zone z, or
+// nil if we have none.
+func (db *Database) MailPolicyGetByZone(z *model.Zone) (*model.MailPolicy, error) {
+	var (
+		err  error
+		list []*model.MailPolicy
+	)
+
+	if list, err = db.mailPolicyQuery(query.MailPolicyGetByZone, z.ID); err != nil || len(list) == 0 {
+		return nil, err
+	}
+
+	return list[0], nil
+} // func (db *Database) MailPolicyGetByZone(z *model.Zone) (*model.MailPolicy, error)
+
+// MailPolicyGetAll returns the e-mail security policies of all zones,
+// sorted by the name of the zone.
+func (db *Database) MailPolicyGetAll() ([]*model.MailPolicy, error) {
+	return db.mailPolicyQuery(query.MailPolicyGetAll)
+} // func (db *Database) MailPolicyGetAll() ([]*model.MailPolicy, error)
+
+func (db *Database) mailPolicyQuery(qid query.ID, args ...any) ([]*model.MailPolicy, error) {
+	var (
+		err  error
+		stmt *sql.Stmt
+		rows *sql.Rows
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(args...); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query mail policies: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var list = make([]*model.MailPolicy, 0, 16)
+
+	for rows.Next() {
+		var (
+			checked    int64
+			weaknesses string
+			p          = new(model.MailPolicy)
+		)
+
+		if err = rows.Scan(
+			&p.ID,
+			&p.ZoneID,
+			&p.Zone,
+			&p.HasMX,
+			&p.SPF,
+			&p.SPFAll,
+			&p.SPFLookups,
+			&p.DMARC,
+			&p.DMARCPolicy,
+			&p.MTASTS,
+			&p.TLSRPT,
+			&checked,
+			&weaknesses); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		} else if p.Weaknesses, err = parseWeaknesses(weaknesses); err != nil {
+			db.log.Printf("[ERROR] Invalid weaknesses of mail policy %d: %s\n",
+				p.ID,
+				err.Error())
+			return nil, err
+		}
+
+		p.Checked = time.Unix(checked, 0)
+		list = append(list, p)
+	}
+
+	return list, rows.Err()
+} // func (db *Database) mailPolicyQuery(qid query.ID, args ...any) ([]*model.MailPolicy, error)
+
+// parseWeaknesses parses the comma-separated list GROUP_CONCAT gives us.
+func parseWeaknesses(s string) ([]weakness.Weakness, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var (
+		parts = strings.Split(s, ",")
+		list  = make([]weakness.Weakness, len(parts))
+	)
+
+	for i, part := range parts {
+		var n, err = strconv.ParseUint(part, 10, 8)
+
+		if err != nil {
+			return nil, err
+		}
+
+		list[i] = weakness.Weakness(n)
+	}
+
+	slices.Sort(list)
+	return list, nil
+} // func parseWeaknesses(s string) ([]weakness.Weakness, error)
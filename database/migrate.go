@@ -0,0 +1,119 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/migrate.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 18:20:14 krylon>
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Fresh databases are created from qInit, which always describes the
+// current schema. Databases created by an older version are brought up to
+// date by running the steps in qMigrate, where qMigrate[n] upgrades a
+// database from schema version n to n+1.
+// We keep track of the schema version in SQLite's user_version pragma, so
+// databases created before we had migrations are version 0.
+//
+// Some steps need to rebuild a table, which means foreign key enforcement
+// has to be off while they run, otherwise dropping the old table would
+// cascade to every table referencing it. So each step runs on its own
+// connection with foreign keys disabled.
+
+// schemaVersion is the version of the schema described by qInit.
+var schemaVersion = len(qMigrate)
+
+func (db *Database) getSchemaVersion() (int, error) {
+	var (
+		err error
+		ver int
+	)
+
+	if err = db.db.QueryRow("PRAGMA user_version").Scan(&ver); err != nil {
+		db.log.Printf("[ERROR] Cannot query schema version: %s\n",
+			err.Error())
+		return -1, err
+	}
+
+	return ver, nil
+} // func (db *Database) getSchemaVersion() (int, error)
+
+// migrate upgrades the database schema to the current version.
+func (db *Database) migrate() error {
+	var (
+		err  error
+		ver  int
+		conn *sql.Conn
+		ctx  = context.Background()
+	)
+
+	if ver, err = db.getSchemaVersion(); err != nil {
+		return err
+	} else if ver == schemaVersion {
+		return nil
+	} else if ver > schemaVersion {
+		err = fmt.Errorf("database schema version %d is newer than ours (%d)",
+			ver,
+			schemaVersion)
+		db.log.Printf("[CRITICAL] %s\n", err.Error())
+		return err
+	}
+
+	if conn, err = db.db.Conn(ctx); err != nil {
+		db.log.Printf("[ERROR] Cannot get connection for migration: %s\n",
+			err.Error())
+		return err
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	if _, err = conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		db.log.Printf("[ERROR] Cannot disable foreign keys: %s\n",
+			err.Error())
+		return err
+	}
+
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON") // nolint: errcheck
+
+	for ; ver < schemaVersion; ver++ {
+		var tx *sql.Tx
+
+		db.log.Printf("[INFO] Upgrade database schema from version %d to %d\n",
+			ver,
+			ver+1)
+
+		if tx, err = conn.BeginTx(ctx, nil); err != nil {
+			db.log.Printf("[ERROR] Cannot begin transaction: %s\n",
+				err.Error())
+			return err
+		}
+
+		for _, q := range qMigrate[ver] {
+			if _, err = tx.Exec(q); err != nil {
+				db.log.Printf("[ERROR] Cannot execute migration query: %s\n%s\n",
+					err.Error(),
+					q)
+				tx.Rollback() // nolint: errcheck
+				return err
+			}
+		}
+
+		if _, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", ver+1)); err != nil {
+			db.log.Printf("[ERROR] Cannot update schema version: %s\n",
+				err.Error())
+			tx.Rollback() // nolint: errcheck
+			return err
+		} else if err = tx.Commit(); err != nil {
+			db.log.Printf("[ERROR] Cannot commit migration to version %d: %s\n",
+				ver+1,
+				err.Error())
+			return err
+		}
+	}
+
+	return nil
+} // func (db *Database) migrate() error
@@ -0,0 +1,154 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/permutation.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 23:14:50 krylon>
+
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/blicero/guangng/database/query"
+	"github.com/blicero/guangng/model"
+)
+
+// PermutationGet returns the state of the permutation with the given seed.
+// If there is none, it returns nil.
+func (db *Database) PermutationGet(seed int64) (*model.Permutation, error) {
+	const qid query.ID = query.PermutationGetBySeed
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(seed); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query permutation %d: %s\n",
+			seed,
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var (
+		started, updated int64
+		p                = &model.Permutation{Seed: seed}
+	)
+
+	if err = rows.Scan(&p.Root, &p.Position, &p.Count, &started, &updated); err != nil {
+		var ex = fmt.Errorf("failed to scan row: %w", err)
+		db.log.Printf("[ERROR] %s\n", ex.Error())
+		return nil, ex
+	}
+
+	p.Started = time.Unix(started, 0)
+	p.Updated = time.Unix(updated, 0)
+
+	return p, nil
+} // func (db *Database) PermutationGet(seed int64) (*model.Permutation, error)
+
+// PermutationGetLatest returns the permutation that was updated most
+// recently, or nil if there is none.
+func (db *Database) PermutationGetLatest() (*model.Permutation, error) {
+	const qid query.ID = query.PermutationGetLatest
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query latest permutation: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var (
+		started, updated int64
+		p                = new(model.Permutation)
+	)
+
+	if err = rows.Scan(&p.Seed, &p.Root, &p.Position, &p.Count, &started, &updated); err != nil {
+		var ex = fmt.Errorf("failed to scan row: %w", err)
+		db.log.Printf("[ERROR] %s\n", ex.Error())
+		return nil, ex
+	}
+
+	p.Started = time.Unix(started, 0)
+	p.Updated = time.Unix(updated, 0)
+
+	return p, nil
+} // func (db *Database) PermutationGetLatest() (*model.Permutation, error)
+
+// PermutationSave stores the state of a permutation, creating it if it does
+// not exist yet. Root and Started of an existing permutation never change.
+func (db *Database) PermutationSave(p *model.Permutation) error {
+	var (
+		err error
+		now = time.Now()
+	)
+
+	if p.Started.IsZero() {
+		p.Started = now
+	}
+
+	if err = db.execOne(
+		query.PermutationSave,
+		p.Seed,
+		int64(p.Root),
+		int64(p.Position),
+		int64(p.Count),
+		p.Started.Unix(),
+		now.Unix()); err != nil {
+		return fmt.Errorf("cannot save permutation %d: %w", p.Seed, err)
+	}
+
+	p.Updated = now
+	return nil
+} // func (db *Database) PermutationSave(p *model.Permutation) error
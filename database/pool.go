@@ -10,9 +10,11 @@ import (
 	"fmt"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/blicero/guangng/common"
 	"github.com/blicero/guangng/logdomain"
+	"github.com/blicero/guangng/metrics"
 )
 
 type dblink struct {
@@ -84,7 +86,10 @@ func (pool *Pool) Close() error {
 // Get returns a DB connection from the pool.
 // If the pool is empty, it waits for a connection to be returned.
 func (pool *Pool) Get() *Database {
-	var link *dblink
+	var (
+		link  *dblink
+		begin time.Time
+	)
 
 	pool.lock.Lock()
 	defer pool.lock.Unlock()
@@ -96,9 +101,19 @@ WAIT_FOR_LINK:
 		pool.cnt--
 
 		link.next = nil
+
+		if !begin.IsZero() {
+			metrics.PoolWaitTime.Add(time.Since(begin))
+		}
+
 		return link.db
 	}
 
+	if begin.IsZero() {
+		begin = time.Now()
+		metrics.PoolWaits.Inc()
+	}
+
 	// Wait for it!!!
 	pool.empty.Wait()
 	goto WAIT_FOR_LINK
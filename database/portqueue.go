@@ -0,0 +1,183 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/portqueue.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:56:18 krylon>
+
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/blicero/guangng/database/query"
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/probe"
+)
+
+// PortQueueAdd queues a port on a Host to be probed by the Scanner using
+// the Probe p. If the port is already queued for that Host, this is a no-op.
+func (db *Database) PortQueueAdd(h *model.Host, port uint16, proto string, p probe.Probe) error {
+	const qid query.ID = query.PortQueueAdd
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Failed to prepare query %s: %s\n",
+			qid,
+			err.Error())
+		panic(err)
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(h.ID, port, proto, time.Now().Unix(), p); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		err = fmt.Errorf("cannot queue port %s:%d/%s: %w",
+			h.AStr(),
+			port,
+			proto,
+			err)
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return err
+	}
+
+	return nil
+} // func (db *Database) PortQueueAdd(h *model.Host, port uint16, proto string, p probe.Probe) error
+
+// PortQueueGetPending returns up to <max> queued ports that have not been
+// handed to the Scanner, yet, oldest first.
+func (db *Database) PortQueueGetPending(max int) ([]*model.PendingPort, error) {
+	const qid query.ID = query.PortQueueGetPending
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(max); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var list = make([]*model.PendingPort, 0, max)
+
+	for rows.Next() {
+		var (
+			added, hAdded, contact int64
+			addr                   string
+			p                      = &model.PendingPort{Host: new(model.Host)}
+		)
+
+		if err = rows.Scan(
+			&p.ID,
+			&p.Port,
+			&p.Proto,
+			&added,
+			&p.Probe,
+			&p.Host.ID,
+			&addr,
+			&p.Host.Name,
+			&hAdded,
+			&contact,
+			&p.Host.Sysname,
+			&p.Host.OSConfidence,
+			&p.Host.ASN,
+			&p.Host.ASOrg,
+			&p.Host.Location,
+			&p.Host.Source,
+			&p.Host.Confirmed); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		} else if p.Host.Addr = net.ParseIP(addr); p.Host.Addr == nil {
+			err = fmt.Errorf("could not parse IP address %q",
+				addr)
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return nil, err
+		}
+
+		p.Added = time.Unix(added, 0)
+		p.Host.Added = time.Unix(hAdded, 0)
+		p.Host.LastContact = time.Unix(contact, 0)
+		list = append(list, p)
+	}
+
+	return list, nil
+} // func (db *Database) PortQueueGetPending(max int) ([]*model.PendingPort, error)
+
+// PortQueueDispatch marks a queued port as handed to the Scanner.
+func (db *Database) PortQueueDispatch(p *model.PendingPort) error {
+	const qid query.ID = query.PortQueueDispatch
+	var (
+		err  error
+		stmt *sql.Stmt
+		res  sql.Result
+		now  = time.Now()
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Failed to prepare query %s: %s\n",
+			qid,
+			err.Error())
+		panic(err)
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if res, err = stmt.Exec(now.Unix(), p.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		err = fmt.Errorf("cannot mark queued port %d as dispatched: %w",
+			p.ID,
+			err)
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return err
+	}
+
+	var cnt int64
+
+	if cnt, err = res.RowsAffected(); err != nil {
+		db.log.Printf("[ERROR] Cannot get number of affected rows: %s\n",
+			err.Error())
+		return err
+	} else if cnt != 1 {
+		db.log.Printf("[ERROR] Marking queued port %d as dispatched affected %d rows\n",
+			p.ID,
+			cnt)
+		return ErrObjectNotFound
+	}
+
+	p.Dispatched = now
+	return nil
+} // func (db *Database) PortQueueDispatch(p *model.PendingPort) error
@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 12. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-02-07 16:56:15 krylon>
+// Time-stamp: <2026-10-19 15:10:30 krylon>
 
 package database
 
@@ -10,8 +10,8 @@ import "github.com/blicero/guangng/database/query"
 
 var qdb = map[query.ID]string{
 	query.HostAdd: `
-INSERT INTO host (addr, name, added, source)
-          VALUES (   ?,    ?,     ?,      ?)
+INSERT INTO host (addr, name, added, source, confirmed)
+          VALUES (   ?,    ?,     ?,      ?,         ?)
 RETURNING id
 `,
 	query.HostGetByID: `
@@ -21,8 +21,12 @@ SELECT
     added,
     last_contact,
     sysname,
+    os_confidence,
+    asn,
+    as_org,
     location,
-    source
+    source,
+    confirmed
 FROM host
 WHERE id = ?
 `,
@@ -33,8 +37,12 @@ SELECT
     added,
     last_contact,
     sysname,
+    os_confidence,
+    asn,
+    as_org,
     location,
-    source
+    source,
+    confirmed
 FROM host
 WHERE addr = ?
 `,
@@ -46,8 +54,12 @@ SELECT
     added,
     last_contact,
     sysname,
+    os_confidence,
+    asn,
+    as_org,
     location,
-    source
+    source,
+    confirmed
 FROM host
 LIMIT ?
 `,
@@ -58,24 +70,80 @@ SELECT id,
        added,
        last_contact,
        sysname,
+       os_confidence,
+       asn,
+       as_org,
        location,
-       source
+       source,
+       confirmed
 FROM host
 LIMIT ?
 OFFSET ABS(RANDOM()) % MAX((SELECT COUNT(*) FROM host), 1)
 `,
 	query.HostGetCnt: `
 SELECT COUNT(id) FROM host
+`,
+	query.HostGetFiltered: `
+SELECT
+    h.id,
+    h.addr,
+    h.name,
+    h.added,
+    h.last_contact,
+    h.sysname,
+    h.os_confidence,
+    h.asn,
+    h.as_org,
+    h.location,
+    h.source,
+    h.confirmed
+FROM host h
+WHERE (?1 = 0 OR h.source = ?1)
+  AND (?2 = '' OR h.name LIKE '%' || ?2 || '%')
+  AND (?3 = 0 OR EXISTS (SELECT 1
+                         FROM svc s
+                         WHERE s.host_id = h.id
+                           AND s.port = ?3
+                           AND s.success <> 0))
+  AND (?5 = 0 OR h.asn = ?5)
+  AND (?6 = '' OR h.country = ?6)
+ORDER BY h.id
+LIMIT ?4
 `,
 	query.HostUpdateSysname: `
 UPDATE host
-SET sysname = ?
+SET sysname = ?,
+    os_confidence = ?
 WHERE id = ?
 `,
 	query.HostUpdateLocation: `
 UPDATE host
-SET location = ?
+SET location = ?,
+    country = ?,
+    city = ?,
+    asn = ?,
+    as_org = ?,
+    geo_checked = ?
 WHERE id = ?
+`,
+	query.HostGetGeoPending: `
+SELECT
+    id,
+    addr,
+    name,
+    added,
+    last_contact,
+    sysname,
+    os_confidence,
+    asn,
+    as_org,
+    location,
+    source,
+    confirmed
+FROM host
+WHERE geo_checked < ?
+ORDER BY geo_checked, id
+LIMIT ?
 `,
 	query.XFRAdd: `
 INSERT INTO xfr (name, added)
@@ -88,24 +156,53 @@ SELECT
     added,
     COALESCE(start, -1),
     COALESCE(end, -1),
-    status
+    status,
+    COALESCE(next_attempt, -1),
+    failures,
+    dnssec
 FROM xfr
 WHERE name = ?
 `,
-	query.XFRGetUnfinished: `
+	query.XFRGetDue: `
 SELECT
     id,
     name,
     added,
-    COALESCE(start, -1)
+    COALESCE(start, -1),
+    COALESCE(end, -1),
+    status,
+    COALESCE(next_attempt, -1),
+    failures,
+    dnssec
 FROM xfr
-WHERE end IS NULL
-ORDER BY added
+WHERE next_attempt IS NULL OR next_attempt <= ?
+ORDER BY next_attempt, added
 LIMIT ?
+`,
+	query.XFRGetByID: `
+SELECT
+    name,
+    added,
+    COALESCE(start, -1),
+    COALESCE(end, -1),
+    status,
+    COALESCE(next_attempt, -1),
+    failures,
+    dnssec
+FROM xfr
+WHERE id = ?
 `,
 	query.XFRGetCnt: "SELECT COUNT(id) FROM xfr",
-	query.XFRStart:  "UPDATE xfr SET start = ? WHERE id = ?",
-	query.XFRFinish: "UPDATE xfr SET end = ?, status = ? WHERE id = ?",
+	query.XFRStart:  "UPDATE xfr SET start = ?, next_attempt = ? WHERE id = ?",
+	query.XFRFinish: `
+UPDATE xfr
+SET end = ?,
+    status = ?,
+    next_attempt = ?,
+    failures = ?
+WHERE id = ?
+`,
+	query.XFRSetDNSSEC: "UPDATE xfr SET dnssec = ? WHERE id = ?",
 	query.ServiceAdd: `
 INSERT INTO svc (host_id, port, success, response, timestamp)
          VALUES (      ?,    ?,       ?,        ?,         ?)
@@ -136,5 +233,491 @@ SELECT
     timestamp
 FROM svc
 WHERE response IS NOT NULL AND response <> ''
+`,
+	query.PortQueueAdd: `
+INSERT INTO port_queue (host_id, port, proto, added, probe)
+                VALUES (      ?,    ?,     ?,     ?,     ?)
+ON CONFLICT (host_id, port, proto) DO NOTHING
+`,
+	query.PortQueueGetPending: `
+SELECT
+    q.id,
+    q.port,
+    q.proto,
+    q.added,
+    q.probe,
+    h.id,
+    h.addr,
+    h.name,
+    h.added,
+    h.last_contact,
+    h.sysname,
+    h.os_confidence,
+    h.asn,
+    h.as_org,
+    h.location,
+    h.source,
+    h.confirmed
+FROM port_queue q
+INNER JOIN host h ON q.host_id = h.id
+WHERE q.dispatched IS NULL
+ORDER BY q.added, q.id
+LIMIT ?
+`,
+	query.PortQueueDispatch: "UPDATE port_queue SET dispatched = ? WHERE id = ?",
+	query.UserAdd: `
+INSERT INTO user (name, pwhash, role, created)
+          VALUES (   ?,      ?,    ?,       ?)
+RETURNING id
+`,
+	query.UserGetByID: `
+SELECT
+    name,
+    pwhash,
+    role,
+    created,
+    COALESCE(last_login, 0)
+FROM user
+WHERE id = ?
+`,
+	query.UserGetByName: `
+SELECT
+    id,
+    pwhash,
+    role,
+    created,
+    COALESCE(last_login, 0)
+FROM user
+WHERE name = ?
+`,
+	query.UserGetAll: `
+SELECT
+    id,
+    name,
+    pwhash,
+    role,
+    created,
+    COALESCE(last_login, 0)
+FROM user
+ORDER BY name
+`,
+	query.UserGetCnt:       "SELECT COUNT(id) FROM user",
+	query.UserSetPassword:  "UPDATE user SET pwhash = ? WHERE id = ?",
+	query.UserSetLastLogin: "UPDATE user SET last_login = ? WHERE id = ?",
+	query.UserDelete:       "DELETE FROM user WHERE id = ?",
+	query.SessionAdd: `
+INSERT INTO session (user_id, token_hash, csrf, created, expires)
+             VALUES (      ?,          ?,    ?,       ?,       ?)
+RETURNING id
+`,
+	query.SessionGetByToken: `
+SELECT
+    s.id,
+    s.csrf,
+    s.created,
+    s.expires,
+    u.id,
+    u.name,
+    u.pwhash,
+    u.role,
+    u.created,
+    COALESCE(u.last_login, 0)
+FROM session s
+INNER JOIN user u ON s.user_id = u.id
+WHERE s.token_hash = ? AND s.expires > ?
+`,
+	query.SessionDelete: "DELETE FROM session WHERE id = ?",
+	query.SessionPurge:  "DELETE FROM session WHERE expires <= ?",
+	query.TokenAdd: `
+INSERT INTO api_token (user_id, name, token_hash, created)
+               VALUES (      ?,    ?,          ?,       ?)
+RETURNING id
+`,
+	query.TokenGetByHash: `
+SELECT
+    t.id,
+    t.name,
+    t.created,
+    COALESCE(t.last_used, 0),
+    u.id,
+    u.name,
+    u.pwhash,
+    u.role,
+    u.created,
+    COALESCE(u.last_login, 0)
+FROM api_token t
+INNER JOIN user u ON t.user_id = u.id
+WHERE t.token_hash = ?
+`,
+	query.TokenGetByUser: `
+SELECT
+    id,
+    name,
+    token_hash,
+    created,
+    COALESCE(last_used, 0)
+FROM api_token
+WHERE user_id = ?
+ORDER BY name
+`,
+	query.TokenTouch:  "UPDATE api_token SET last_used = ? WHERE id = ?",
+	query.TokenDelete: "DELETE FROM api_token WHERE user_id = ? AND name = ?",
+	query.ASNGetAll: `
+SELECT
+    h.asn,
+    MAX(h.as_org),
+    COUNT(DISTINCT h.id),
+    COUNT(s.id)
+FROM host h
+LEFT OUTER JOIN svc s ON s.host_id = h.id AND s.success <> 0
+WHERE h.asn <> 0
+GROUP BY h.asn
+ORDER BY 3 DESC, h.asn
+LIMIT ?
+`,
+	query.ASNGetByNumber: `
+SELECT
+    MAX(h.as_org),
+    COUNT(DISTINCT h.id),
+    COUNT(s.id)
+FROM host h
+LEFT OUTER JOIN svc s ON s.host_id = h.id AND s.success <> 0
+WHERE h.asn = ?
+`,
+	query.ASNGetProducts: `
+SELECT
+    s.port,
+    s.response,
+    COUNT(s.id)
+FROM svc s
+INNER JOIN host h ON s.host_id = h.id
+WHERE h.asn = ?
+  AND s.success <> 0
+  AND COALESCE(s.response, '') <> ''
+GROUP BY s.port, s.response
+ORDER BY 3 DESC, s.port
+LIMIT ?
+`,
+	// The host table only has the name of the country as part of the
+	// location, which is either "City, Country" or just "Country".
+	query.StatsByCountry: `
+SELECT
+    h.country,
+    MAX(CASE WHEN h.city = '' THEN h.location
+             ELSE SUBSTR(h.location, LENGTH(h.city) + 3)
+        END),
+    COUNT(DISTINCT h.id),
+    COUNT(s.id)
+FROM host h
+LEFT OUTER JOIN svc s ON s.host_id = h.id AND s.success <> 0
+WHERE h.country <> ''
+GROUP BY h.country
+ORDER BY 3 DESC, h.country
+`,
+	query.StatsByCity: `
+SELECT
+    h.country,
+    h.city,
+    COUNT(DISTINCT h.id),
+    COUNT(s.id)
+FROM host h
+LEFT OUTER JOIN svc s ON s.host_id = h.id AND s.success <> 0
+WHERE h.city <> ''
+GROUP BY h.country, h.city
+ORDER BY 3 DESC, h.country, h.city
+`,
+	query.StatsTopPorts: `
+SELECT
+    country,
+    port,
+    cnt
+FROM (
+    SELECT
+        h.country AS country,
+        s.port AS port,
+        COUNT(s.id) AS cnt,
+        ROW_NUMBER() OVER (PARTITION BY h.country
+                           ORDER BY COUNT(s.id) DESC, s.port) AS rank
+    FROM svc s
+    INNER JOIN host h ON s.host_id = h.id
+    WHERE s.success <> 0 AND h.country <> ''
+    GROUP BY h.country, s.port
+)
+WHERE rank <= ?
+ORDER BY country, cnt DESC, port
+`,
+	query.PermutationGetBySeed: `
+SELECT
+    root,
+    position,
+    cnt,
+    started,
+    updated
+FROM permutation
+WHERE seed = ?
+`,
+	query.PermutationGetLatest: `
+SELECT
+    seed,
+    root,
+    position,
+    cnt,
+    started,
+    updated
+FROM permutation
+ORDER BY updated DESC
+LIMIT 1
+`,
+	query.PermutationSave: `
+INSERT INTO permutation (seed, root, position, cnt, started, updated)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (seed) DO UPDATE
+SET position = excluded.position,
+    cnt = excluded.cnt,
+    updated = excluded.updated
+`,
+	query.NetYieldGetAll: `
+SELECT
+    prefix,
+    bits,
+    attempts,
+    hits,
+    blocked,
+    updated
+FROM net_yield
+`,
+	query.NetYieldGetTop: `
+SELECT
+    prefix,
+    bits,
+    attempts,
+    hits,
+    blocked,
+    updated
+FROM net_yield
+WHERE bits = ?
+ORDER BY hits DESC, CAST(hits AS REAL) / attempts DESC, prefix
+LIMIT ?
+`,
+	query.NetYieldSave: `
+INSERT INTO net_yield (prefix, bits, attempts, hits, blocked, updated)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (prefix, bits) DO UPDATE
+SET attempts = excluded.attempts,
+    hits = excluded.hits,
+    blocked = excluded.blocked,
+    updated = excluded.updated
+`,
+	query.HostNameAdd: `
+INSERT INTO host_name (host_id, name, confirmed, checked)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (host_id, name) DO UPDATE
+SET confirmed = excluded.confirmed,
+    checked = excluded.checked
+RETURNING id
+`,
+	query.HostNameGetByHost: `
+SELECT
+    id,
+    name,
+    confirmed,
+    checked
+FROM host_name
+WHERE host_id = ?
+ORDER BY confirmed DESC, id
+`,
+	query.XFRResultAdd: `
+INSERT INTO xfr_server (xfr_id, ns, addr, outcome, rr_cnt, timestamp)
+                VALUES (     ?,  ?,    ?,       ?,      ?,         ?)
+RETURNING id
+`,
+	query.XFRResultGetByZone: `
+SELECT
+    id,
+    ns,
+    addr,
+    outcome,
+    rr_cnt,
+    timestamp
+FROM xfr_server
+WHERE xfr_id = ?
+ORDER BY timestamp, id
+`,
+	query.XFRResultGetRecent: `
+SELECT
+    r.id,
+    r.xfr_id,
+    x.name,
+    r.ns,
+    r.addr,
+    r.outcome,
+    r.rr_cnt,
+    r.timestamp
+FROM xfr_server r
+INNER JOIN xfr x ON r.xfr_id = x.id
+ORDER BY r.timestamp DESC, r.id DESC
+LIMIT ?
+`,
+	query.XFRResultGetOpen: `
+SELECT
+    r.id,
+    r.xfr_id,
+    x.name,
+    r.ns,
+    r.addr,
+    r.outcome,
+    r.rr_cnt,
+    r.timestamp
+FROM xfr_server r
+INNER JOIN xfr x ON r.xfr_id = x.id
+WHERE r.outcome = ?
+ORDER BY r.timestamp DESC, r.id DESC
+LIMIT ?
+`,
+	query.ZoneRecordAdd: `
+INSERT INTO zone_record (xfr_id, owner, type, ttl, rdata, added)
+                 VALUES (     ?,     ?,    ?,   ?,     ?,     ?)
+RETURNING id
+`,
+	query.ZoneRecordGetCurrent: `
+SELECT
+    id,
+    xfr_id,
+    owner,
+    type,
+    ttl,
+    rdata,
+    added,
+    COALESCE(removed, -1)
+FROM zone_record
+WHERE xfr_id = ? AND removed IS NULL
+ORDER BY owner, type, id
+`,
+	query.ZoneRecordGetChanges: `
+SELECT
+    id,
+    xfr_id,
+    owner,
+    type,
+    ttl,
+    rdata,
+    added,
+    COALESCE(removed, -1)
+FROM zone_record
+WHERE xfr_id = ?
+  AND (removed IS NOT NULL
+       OR added > (SELECT MIN(added) FROM zone_record WHERE xfr_id = ?))
+ORDER BY COALESCE(removed, added) DESC, owner, type
+LIMIT ?
+`,
+	query.ZoneRecordGetByType: `
+SELECT
+    id,
+    xfr_id,
+    owner,
+    type,
+    ttl,
+    rdata,
+    added,
+    COALESCE(removed, -1)
+FROM zone_record
+WHERE xfr_id = ? AND type = ? AND removed IS NULL
+ORDER BY owner, id
+`,
+	query.ZoneRecordGetByOwner: `
+SELECT
+    id,
+    xfr_id,
+    owner,
+    type,
+    ttl,
+    rdata,
+    added,
+    COALESCE(removed, -1)
+FROM zone_record
+WHERE owner = ? AND type = ? AND removed IS NULL
+ORDER BY xfr_id, id
+`,
+	query.ZoneRecordSetTTL: "UPDATE zone_record SET ttl = ? WHERE id = ?",
+	query.ZoneRecordRemove: "UPDATE zone_record SET removed = ? WHERE id = ?",
+	query.MailPolicySet: `
+INSERT INTO mail_policy (
+    xfr_id,
+    has_mx,
+    spf,
+    spf_all,
+    spf_lookups,
+    dmarc,
+    dmarc_policy,
+    mta_sts,
+    tls_rpt,
+    checked)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (xfr_id) DO UPDATE
+SET has_mx = excluded.has_mx,
+    spf = excluded.spf,
+    spf_all = excluded.spf_all,
+    spf_lookups = excluded.spf_lookups,
+    dmarc = excluded.dmarc,
+    dmarc_policy = excluded.dmarc_policy,
+    mta_sts = excluded.mta_sts,
+    tls_rpt = excluded.tls_rpt,
+    checked = excluded.checked
+RETURNING id
+`,
+	query.MailPolicyDelete:  "DELETE FROM mail_policy WHERE xfr_id = ?",
+	query.MailWeaknessClear: "DELETE FROM mail_weakness WHERE policy_id = ?",
+	query.MailWeaknessAdd:   "INSERT INTO mail_weakness (policy_id, weakness) VALUES (?, ?)",
+	query.MailPolicyGetByZone: `
+SELECT
+    p.id,
+    p.xfr_id,
+    x.name,
+    p.has_mx,
+    p.spf,
+    p.spf_all,
+    p.spf_lookups,
+    p.dmarc,
+    p.dmarc_policy,
+    p.mta_sts,
+    p.tls_rpt,
+    p.checked,
+    COALESCE(GROUP_CONCAT(w.weakness), '')
+FROM mail_policy p
+INNER JOIN xfr x ON p.xfr_id = x.id
+LEFT OUTER JOIN mail_weakness w ON w.policy_id = p.id
+WHERE p.xfr_id = ?
+GROUP BY p.id
+`,
+	query.MailPolicyGetAll: `
+SELECT
+    p.id,
+    p.xfr_id,
+    x.name,
+    p.has_mx,
+    p.spf,
+    p.spf_all,
+    p.spf_lookups,
+    p.dmarc,
+    p.dmarc_policy,
+    p.mta_sts,
+    p.tls_rpt,
+    p.checked,
+    COALESCE(GROUP_CONCAT(w.weakness), '')
+FROM mail_policy p
+INNER JOIN xfr x ON p.xfr_id = x.id
+LEFT OUTER JOIN mail_weakness w ON w.policy_id = p.id
+GROUP BY p.id
+ORDER BY x.name
+`,
+	query.StatsBySource: `
+SELECT
+    country,
+    source,
+    COUNT(id)
+FROM host
+WHERE country <> ''
+GROUP BY country, source
+ORDER BY country, source
 `,
 }
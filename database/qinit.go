@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 12. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-01-21 19:13:33 krylon>
+// Time-stamp: <2026-10-19 15:10:30 krylon>
 
 package database
 
@@ -17,11 +17,21 @@ CREATE TABLE host (
     sysname TEXT NOT NULL DEFAULT '',
     location TEXT NOT NULL DEFAULT '',
     source INTEGER NOT NULL,
-    CHECK (source BETWEEN 1 AND 5)
+    country TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL DEFAULT '',
+    geo_checked INTEGER NOT NULL DEFAULT 0,
+    os_confidence REAL NOT NULL DEFAULT 0,
+    asn INTEGER NOT NULL DEFAULT 0,
+    as_org TEXT NOT NULL DEFAULT '',
+    confirmed INTEGER NOT NULL DEFAULT 0,
+    CHECK (source BETWEEN 1 AND 7)
 ) STRICT
 `,
 	"CREATE INDEX host_contact_idx ON host (last_contact)",
 	"CREATE UNIQUE INDEX host_addr_idx ON host (addr)",
+	"CREATE INDEX host_geo_checked_idx ON host (geo_checked)",
+	"CREATE INDEX host_country_idx ON host (country)",
+	"CREATE INDEX host_asn_idx ON host (asn)",
 	`
 CREATE TABLE svc (
     id INTEGER PRIMARY KEY,
@@ -54,10 +64,494 @@ CREATE TABLE xfr (
     start INTEGER,
     end INTEGER,
     status INTEGER NOT NULL DEFAULT 0,
-    CHECK ((end IS NULL) OR (start IS NOT NULL))
+    next_attempt INTEGER,
+    failures INTEGER NOT NULL DEFAULT 0,
+    dnssec INTEGER NOT NULL DEFAULT 0,
+    CHECK ((end IS NULL) OR (start IS NOT NULL)),
+    CHECK (dnssec BETWEEN 0 AND 3)
 ) STRICT
 `,
 	"CREATE INDEX xfr_start_idx ON xfr (start)",
 	"CREATE INDEX xfr_end_idx ON xfr (end)",
 	"CREATE INDEX xfr_end_null_idx ON xfr (end IS NULL)",
+	"CREATE INDEX xfr_next_idx ON xfr (next_attempt)",
+	`
+CREATE TABLE port_queue (
+    id INTEGER PRIMARY KEY,
+    host_id INTEGER NOT NULL,
+    port INTEGER NOT NULL,
+    proto TEXT NOT NULL DEFAULT 'tcp',
+    added INTEGER NOT NULL,
+    dispatched INTEGER,
+    probe INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (host_id, port, proto),
+    CHECK (port BETWEEN 1 AND 65535),
+    CHECK (proto IN ('tcp', 'udp')),
+    FOREIGN KEY (host_id) REFERENCES host (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+	"CREATE INDEX port_queue_pending_idx ON port_queue (dispatched IS NULL)",
+	`
+CREATE TABLE user (
+    id INTEGER PRIMARY KEY,
+    name TEXT UNIQUE NOT NULL,
+    pwhash TEXT NOT NULL,
+    role INTEGER NOT NULL,
+    created INTEGER NOT NULL,
+    last_login INTEGER,
+    CHECK (role BETWEEN 1 AND 2)
+) STRICT
+`,
+	`
+CREATE TABLE session (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    token_hash TEXT UNIQUE NOT NULL,
+    csrf TEXT NOT NULL,
+    created INTEGER NOT NULL,
+    expires INTEGER NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES user (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+	"CREATE INDEX session_expires_idx ON session (expires)",
+	`
+CREATE TABLE api_token (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    token_hash TEXT UNIQUE NOT NULL,
+    created INTEGER NOT NULL,
+    last_used INTEGER,
+    UNIQUE (user_id, name),
+    FOREIGN KEY (user_id) REFERENCES user (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+	`
+CREATE TABLE permutation (
+    seed INTEGER PRIMARY KEY,
+    root INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    cnt INTEGER NOT NULL DEFAULT 0,
+    started INTEGER NOT NULL,
+    updated INTEGER NOT NULL
+) STRICT
+`,
+	"CREATE INDEX permutation_updated_idx ON permutation (updated)",
+	`
+CREATE TABLE net_yield (
+    prefix INTEGER NOT NULL,
+    bits INTEGER NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    hits INTEGER NOT NULL DEFAULT 0,
+    blocked INTEGER NOT NULL DEFAULT 0,
+    updated INTEGER NOT NULL,
+    PRIMARY KEY (prefix, bits),
+    CHECK (bits IN (16, 24))
+) STRICT
+`,
+	`
+CREATE TABLE host_name (
+    id INTEGER PRIMARY KEY,
+    host_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    confirmed INTEGER NOT NULL DEFAULT 0,
+    checked INTEGER NOT NULL,
+    UNIQUE (host_id, name),
+    FOREIGN KEY (host_id) REFERENCES host (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+	"CREATE INDEX host_name_name_idx ON host_name (name)",
+	`
+CREATE TABLE xfr_server (
+    id INTEGER PRIMARY KEY,
+    xfr_id INTEGER NOT NULL,
+    ns TEXT NOT NULL,
+    addr TEXT NOT NULL,
+    outcome INTEGER NOT NULL,
+    rr_cnt INTEGER NOT NULL DEFAULT 0,
+    timestamp INTEGER NOT NULL,
+    FOREIGN KEY (xfr_id) REFERENCES xfr (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+	"CREATE INDEX xfr_server_xfr_idx ON xfr_server (xfr_id)",
+	"CREATE INDEX xfr_server_outcome_idx ON xfr_server (outcome, timestamp)",
+	`
+CREATE TABLE zone_record (
+    id INTEGER PRIMARY KEY,
+    xfr_id INTEGER NOT NULL,
+    owner TEXT NOT NULL,
+    type TEXT NOT NULL,
+    ttl INTEGER NOT NULL,
+    rdata TEXT NOT NULL,
+    added INTEGER NOT NULL,
+    removed INTEGER,
+    CHECK ((removed IS NULL) OR (removed >= added)),
+    FOREIGN KEY (xfr_id) REFERENCES xfr (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+	"CREATE INDEX zone_record_owner_idx ON zone_record (owner)",
+	`
+CREATE UNIQUE INDEX zone_record_current_idx
+ON zone_record (xfr_id, owner, type, rdata)
+WHERE removed IS NULL
+`,
+	"CREATE INDEX zone_record_removed_idx ON zone_record (xfr_id, removed)",
+	`
+CREATE TABLE mail_policy (
+    id INTEGER PRIMARY KEY,
+    xfr_id INTEGER UNIQUE NOT NULL,
+    has_mx INTEGER NOT NULL DEFAULT 0,
+    spf TEXT NOT NULL DEFAULT '',
+    spf_all TEXT NOT NULL DEFAULT '',
+    spf_lookups INTEGER NOT NULL DEFAULT 0,
+    dmarc TEXT NOT NULL DEFAULT '',
+    dmarc_policy TEXT NOT NULL DEFAULT '',
+    mta_sts TEXT NOT NULL DEFAULT '',
+    tls_rpt TEXT NOT NULL DEFAULT '',
+    checked INTEGER NOT NULL,
+    CHECK (spf_all IN ('', '+', '-', '~', '?')),
+    CHECK (dmarc_policy IN ('', 'none', 'quarantine', 'reject')),
+    FOREIGN KEY (xfr_id) REFERENCES xfr (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+	`
+CREATE TABLE mail_weakness (
+    id INTEGER PRIMARY KEY,
+    policy_id INTEGER NOT NULL,
+    weakness INTEGER NOT NULL,
+    UNIQUE (policy_id, weakness),
+    FOREIGN KEY (policy_id) REFERENCES mail_policy (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+	"CREATE INDEX mail_weakness_weakness_idx ON mail_weakness (weakness)",
+}
+
+var qMigrate = [][]string{
+	// 0 -> 1: Allow hsrc.Import as a Host source, add the port queue.
+	{
+		"DROP TRIGGER host_contact_tr",
+		`
+CREATE TABLE host_new (
+    id INTEGER PRIMARY KEY,
+    addr TEXT UNIQUE NOT NULL,
+    name TEXT NOT NULL,
+    added INTEGER NOT NULL,
+    last_contact INTEGER NOT NULL DEFAULT 0,
+    sysname TEXT NOT NULL DEFAULT '',
+    location TEXT NOT NULL DEFAULT '',
+    source INTEGER NOT NULL,
+    CHECK (source BETWEEN 1 AND 6)
+) STRICT
+`,
+		`
+INSERT INTO host_new (id, addr, name, added, last_contact, sysname, location, source)
+SELECT id, addr, name, added, last_contact, sysname, location, source
+FROM host
+`,
+		"DROP TABLE host",
+		"ALTER TABLE host_new RENAME TO host",
+		"CREATE INDEX host_contact_idx ON host (last_contact)",
+		"CREATE UNIQUE INDEX host_addr_idx ON host (addr)",
+		`
+CREATE TRIGGER host_contact_tr
+AFTER INSERT ON svc
+BEGIN
+    UPDATE host
+    SET last_contact = unixepoch()
+    WHERE id = NEW.host_id;
+END
+`,
+		`
+CREATE TABLE port_queue (
+    id INTEGER PRIMARY KEY,
+    host_id INTEGER NOT NULL,
+    port INTEGER NOT NULL,
+    proto TEXT NOT NULL DEFAULT 'tcp',
+    added INTEGER NOT NULL,
+    dispatched INTEGER,
+    UNIQUE (host_id, port, proto),
+    CHECK (port BETWEEN 1 AND 65535),
+    CHECK (proto IN ('tcp', 'udp')),
+    FOREIGN KEY (host_id) REFERENCES host (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+		"CREATE INDEX port_queue_pending_idx ON port_queue (dispatched IS NULL)",
+	},
+	// 1 -> 2: User accounts, sessions and API tokens for the web interface.
+	{
+		`
+CREATE TABLE user (
+    id INTEGER PRIMARY KEY,
+    name TEXT UNIQUE NOT NULL,
+    pwhash TEXT NOT NULL,
+    role INTEGER NOT NULL,
+    created INTEGER NOT NULL,
+    last_login INTEGER,
+    CHECK (role BETWEEN 1 AND 2)
+) STRICT
+`,
+		`
+CREATE TABLE session (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    token_hash TEXT UNIQUE NOT NULL,
+    csrf TEXT NOT NULL,
+    created INTEGER NOT NULL,
+    expires INTEGER NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES user (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+		"CREATE INDEX session_expires_idx ON session (expires)",
+		`
+CREATE TABLE api_token (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    token_hash TEXT UNIQUE NOT NULL,
+    created INTEGER NOT NULL,
+    last_used INTEGER,
+    UNIQUE (user_id, name),
+    FOREIGN KEY (user_id) REFERENCES user (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+	},
+	// 2 -> 3: Keep track of where Hosts are located.
+	{
+		"ALTER TABLE host ADD COLUMN country TEXT NOT NULL DEFAULT ''",
+		"ALTER TABLE host ADD COLUMN city TEXT NOT NULL DEFAULT ''",
+		"ALTER TABLE host ADD COLUMN geo_checked INTEGER NOT NULL DEFAULT 0",
+		"CREATE INDEX host_geo_checked_idx ON host (geo_checked)",
+		"CREATE INDEX host_country_idx ON host (country)",
+	},
+	// 3 -> 4: Remember how sure we are about a Host's operating system.
+	{
+		"ALTER TABLE host ADD COLUMN os_confidence REAL NOT NULL DEFAULT 0",
+	},
+	// 4 -> 5: Keep track of the networks Hosts belong to. We reset
+	// geo_checked, so the Geo subsystem looks up the ASNs of the Hosts
+	// we already located.
+	{
+		"ALTER TABLE host ADD COLUMN asn INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE host ADD COLUMN as_org TEXT NOT NULL DEFAULT ''",
+		"CREATE INDEX host_asn_idx ON host (asn)",
+		"UPDATE host SET geo_checked = 0",
+	},
+	// 5 -> 6: Remember how far the Generator has walked through the
+	// permutations of the address space.
+	{
+		`
+CREATE TABLE permutation (
+    seed INTEGER PRIMARY KEY,
+    root INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    cnt INTEGER NOT NULL DEFAULT 0,
+    started INTEGER NOT NULL,
+    updated INTEGER NOT NULL
+) STRICT
+`,
+		"CREATE INDEX permutation_updated_idx ON permutation (updated)",
+	},
+	// 6 -> 7: Remember which networks have been worth generating addresses
+	// in.
+	{
+		`
+CREATE TABLE net_yield (
+    prefix INTEGER NOT NULL,
+    bits INTEGER NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    hits INTEGER NOT NULL DEFAULT 0,
+    blocked INTEGER NOT NULL DEFAULT 0,
+    updated INTEGER NOT NULL,
+    PRIMARY KEY (prefix, bits),
+    CHECK (bits IN (16, 24))
+) STRICT
+`,
+	},
+	// 7 -> 8: Forward-confirmed reverse DNS, and all the names of a Host.
+	{
+		"ALTER TABLE host ADD COLUMN confirmed INTEGER NOT NULL DEFAULT 0",
+		`
+CREATE TABLE host_name (
+    id INTEGER PRIMARY KEY,
+    host_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    confirmed INTEGER NOT NULL DEFAULT 0,
+    checked INTEGER NOT NULL,
+    UNIQUE (host_id, name),
+    FOREIGN KEY (host_id) REFERENCES host (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+		"CREATE INDEX host_name_name_idx ON host_name (name)",
+	},
+	// 8 -> 9: Results of zone transfers per name server.
+	{
+		`
+CREATE TABLE xfr_server (
+    id INTEGER PRIMARY KEY,
+    xfr_id INTEGER NOT NULL,
+    ns TEXT NOT NULL,
+    addr TEXT NOT NULL,
+    outcome INTEGER NOT NULL,
+    rr_cnt INTEGER NOT NULL DEFAULT 0,
+    timestamp INTEGER NOT NULL,
+    FOREIGN KEY (xfr_id) REFERENCES xfr (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+		"CREATE INDEX xfr_server_xfr_idx ON xfr_server (xfr_id)",
+		"CREATE INDEX xfr_server_outcome_idx ON xfr_server (outcome, timestamp)",
+	},
+	// 9 -> 10: The records of transferred zones.
+	{
+		`
+CREATE TABLE zone_record (
+    id INTEGER PRIMARY KEY,
+    xfr_id INTEGER NOT NULL,
+    owner TEXT NOT NULL,
+    type TEXT NOT NULL,
+    ttl INTEGER NOT NULL,
+    rdata TEXT NOT NULL,
+    added INTEGER NOT NULL,
+    removed INTEGER,
+    CHECK ((removed IS NULL) OR (removed >= added)),
+    FOREIGN KEY (xfr_id) REFERENCES xfr (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+		"CREATE INDEX zone_record_owner_idx ON zone_record (owner)",
+		`
+CREATE UNIQUE INDEX zone_record_current_idx
+ON zone_record (xfr_id, owner, type, rdata)
+WHERE removed IS NULL
+`,
+		"CREATE INDEX zone_record_removed_idx ON zone_record (xfr_id, removed)",
+	},
+	// 10 -> 11: Remember which probe to use on a queued port.
+	{
+		"ALTER TABLE port_queue ADD COLUMN probe INTEGER NOT NULL DEFAULT 0",
+	},
+	// 11 -> 12: The e-mail security policies of transferred zones.
+	{
+		`
+CREATE TABLE mail_policy (
+    id INTEGER PRIMARY KEY,
+    xfr_id INTEGER UNIQUE NOT NULL,
+    has_mx INTEGER NOT NULL DEFAULT 0,
+    spf TEXT NOT NULL DEFAULT '',
+    spf_all TEXT NOT NULL DEFAULT '',
+    spf_lookups INTEGER NOT NULL DEFAULT 0,
+    dmarc TEXT NOT NULL DEFAULT '',
+    dmarc_policy TEXT NOT NULL DEFAULT '',
+    mta_sts TEXT NOT NULL DEFAULT '',
+    tls_rpt TEXT NOT NULL DEFAULT '',
+    checked INTEGER NOT NULL,
+    CHECK (spf_all IN ('', '+', '-', '~', '?')),
+    CHECK (dmarc_policy IN ('', 'none', 'quarantine', 'reject')),
+    FOREIGN KEY (xfr_id) REFERENCES xfr (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+		`
+CREATE TABLE mail_weakness (
+    id INTEGER PRIMARY KEY,
+    policy_id INTEGER NOT NULL,
+    weakness INTEGER NOT NULL,
+    UNIQUE (policy_id, weakness),
+    FOREIGN KEY (policy_id) REFERENCES mail_policy (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+		"CREATE INDEX mail_weakness_weakness_idx ON mail_weakness (weakness)",
+	},
+	// 12 -> 13: Zones are transferred again on a schedule. Zones we have
+	// transferred before are due a week after that, zones we failed to
+	// transfer get another chance a day after the failed attempt.
+	{
+		"ALTER TABLE xfr ADD COLUMN next_attempt INTEGER",
+		"ALTER TABLE xfr ADD COLUMN failures INTEGER NOT NULL DEFAULT 0",
+		"CREATE INDEX xfr_next_idx ON xfr (next_attempt)",
+		`
+UPDATE xfr
+SET next_attempt = end + CASE WHEN status THEN 604800 ELSE 86400 END,
+    failures = CASE WHEN status THEN 0 ELSE 1 END
+WHERE end IS NOT NULL
+`,
+	},
+	// 13 -> 14: Allow hsrc.NSEC as a Host source, remember how zones are
+	// signed.
+	{
+		"DROP TRIGGER host_contact_tr",
+		`
+CREATE TABLE host_new (
+    id INTEGER PRIMARY KEY,
+    addr TEXT UNIQUE NOT NULL,
+    name TEXT NOT NULL,
+    added INTEGER NOT NULL,
+    last_contact INTEGER NOT NULL DEFAULT 0,
+    sysname TEXT NOT NULL DEFAULT '',
+    location TEXT NOT NULL DEFAULT '',
+    source INTEGER NOT NULL,
+    country TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL DEFAULT '',
+    geo_checked INTEGER NOT NULL DEFAULT 0,
+    os_confidence REAL NOT NULL DEFAULT 0,
+    asn INTEGER NOT NULL DEFAULT 0,
+    as_org TEXT NOT NULL DEFAULT '',
+    confirmed INTEGER NOT NULL DEFAULT 0,
+    CHECK (source BETWEEN 1 AND 7)
+) STRICT
+`,
+		`
+INSERT INTO host_new (id, addr, name, added, last_contact, sysname, location, source,
+                      country, city, geo_checked, os_confidence, asn, as_org, confirmed)
+SELECT id, addr, name, added, last_contact, sysname, location, source,
+       country, city, geo_checked, os_confidence, asn, as_org, confirmed
+FROM host
+`,
+		"DROP TABLE host",
+		"ALTER TABLE host_new RENAME TO host",
+		"CREATE INDEX host_contact_idx ON host (last_contact)",
+		"CREATE UNIQUE INDEX host_addr_idx ON host (addr)",
+		"CREATE INDEX host_geo_checked_idx ON host (geo_checked)",
+		"CREATE INDEX host_country_idx ON host (country)",
+		"CREATE INDEX host_asn_idx ON host (asn)",
+		`
+CREATE TRIGGER host_contact_tr
+AFTER INSERT ON svc
+BEGIN
+    UPDATE host
+    SET last_contact = unixepoch()
+    WHERE id = NEW.host_id;
+END
+`,
+		"ALTER TABLE xfr ADD COLUMN dnssec INTEGER NOT NULL DEFAULT 0 CHECK (dnssec BETWEEN 0 AND 3)",
+	},
 }
@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 12. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-01-30 17:16:00 krylon>
+// Time-stamp: <2026-10-19 15:10:30 krylon>
 
 package query
 
@@ -18,18 +18,73 @@ const (
 	HostGetAll
 	HostGetRandom
 	HostGetCnt
+	HostGetFiltered
 	HostUpdateSysname
 	HostUpdateLocation
 	XFRAdd
 	XFRGetByID
 	XFRGetByName
-	XFRGetUnfinished
+	XFRGetDue
 	XFRGetCnt
 	XFRStart
 	XFRFinish
+	XFRSetDNSSEC
 	ServiceAdd
 	ServiceGetByHost
 	ServiceGetByPort
 	ServiceGetSuccess
 	ServiceGetCnt
+	PortQueueAdd
+	PortQueueGetPending
+	PortQueueDispatch
+	UserAdd
+	UserGetByID
+	UserGetByName
+	UserGetAll
+	UserGetCnt
+	UserSetPassword
+	UserSetLastLogin
+	UserDelete
+	SessionAdd
+	SessionGetByToken
+	SessionDelete
+	SessionPurge
+	TokenAdd
+	TokenGetByHash
+	TokenGetByUser
+	TokenTouch
+	TokenDelete
+	HostGetGeoPending
+	ASNGetAll
+	ASNGetByNumber
+	ASNGetProducts
+	StatsByCountry
+	StatsByCity
+	StatsTopPorts
+	StatsBySource
+	PermutationGetBySeed
+	PermutationGetLatest
+	PermutationSave
+	NetYieldGetAll
+	NetYieldGetTop
+	NetYieldSave
+	HostNameAdd
+	HostNameGetByHost
+	XFRResultAdd
+	XFRResultGetByZone
+	XFRResultGetRecent
+	XFRResultGetOpen
+	ZoneRecordAdd
+	ZoneRecordGetCurrent
+	ZoneRecordGetChanges
+	ZoneRecordGetByType
+	ZoneRecordGetByOwner
+	ZoneRecordSetTTL
+	ZoneRecordRemove
+	MailPolicySet
+	MailPolicyDelete
+	MailPolicyGetByZone
+	MailPolicyGetAll
+	MailWeaknessClear
+	MailWeaknessAdd
 )
@@ -87,7 +87,7 @@ func (db *Database) ServiceGetByHost(h *model.Host) (map[uint16]*model.Service,
 GET_QUERY:
 	if stmt, err = db.getQuery(qid); err != nil {
 		if worthARetry(err) {
-			time.Sleep(retryDelay)
+			waitForRetry()
 			goto GET_QUERY
 		} else {
 			db.log.Printf("[ERROR] Error getting query %s: %s",
@@ -107,7 +107,7 @@ GET_QUERY:
 EXEC_QUERY:
 	if rows, err = stmt.Query(h.ID); err != nil {
 		if worthARetry(err) {
-			time.Sleep(retryDelay)
+			waitForRetry()
 			goto EXEC_QUERY
 		} else {
 			msg = fmt.Sprintf("Error querying services for Host %s (%s): %s",
@@ -131,6 +131,7 @@ EXEC_QUERY:
 			&svc.ID,
 			&port,
 			&svc.Success,
+			&svc.Response,
 			&tstamp); err != nil {
 			msg = fmt.Sprintf("Error scanning row: %s", err.Error())
 			db.log.Printf("[ERROR] %s\n", msg)
@@ -199,7 +200,7 @@ func (db *Database) ServiceGetSuccess() (map[uint16][]*model.Service, error) {
 GET_QUERY:
 	if stmt, err = db.getQuery(qid); err != nil {
 		if worthARetry(err) {
-			time.Sleep(retryDelay)
+			waitForRetry()
 			goto GET_QUERY
 		} else {
 			db.log.Printf("[ERROR] Error getting query %s: %s",
@@ -219,7 +220,7 @@ GET_QUERY:
 EXEC_QUERY:
 	if rows, err = stmt.Query(); err != nil {
 		if worthARetry(err) {
-			time.Sleep(retryDelay)
+			waitForRetry()
 			goto EXEC_QUERY
 		} else {
 			msg = fmt.Sprintf("Error querying services: %s",
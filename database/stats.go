@@ -0,0 +1,168 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/stats.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 21:55:06 krylon>
+
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/blicero/guangng/database/query"
+	"github.com/blicero/guangng/model"
+)
+
+// statsQuery prepares and runs one of the aggregate queries, retrying if
+// the database is busy.
+func (db *Database) statsQuery(qid query.ID, args ...any) (*sql.Rows, error) {
+	var (
+		err  error
+		stmt *sql.Stmt
+		rows *sql.Rows
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(args...); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to run query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	}
+
+	return rows, nil
+} // func (db *Database) statsQuery(qid query.ID, args ...any) (*sql.Rows, error)
+
+// StatsByCountry returns the number of Hosts and services per country,
+// the countries with the most Hosts first.
+func (db *Database) StatsByCountry() ([]*model.CountryStats, error) {
+	var (
+		err  error
+		rows *sql.Rows
+		list = make([]*model.CountryStats, 0, 64)
+	)
+
+	if rows, err = db.statsQuery(query.StatsByCountry); err != nil {
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	for rows.Next() {
+		var c = new(model.CountryStats)
+
+		if err = rows.Scan(&c.CountryCode, &c.Country, &c.HostCnt, &c.SvcCnt); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		list = append(list, c)
+	}
+
+	return list, rows.Err()
+} // func (db *Database) StatsByCountry() ([]*model.CountryStats, error)
+
+// StatsByCity returns the number of Hosts and services per city, the
+// cities with the most Hosts first.
+func (db *Database) StatsByCity() ([]*model.CityStats, error) {
+	var (
+		err  error
+		rows *sql.Rows
+		list = make([]*model.CityStats, 0, 256)
+	)
+
+	if rows, err = db.statsQuery(query.StatsByCity); err != nil {
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	for rows.Next() {
+		var c = new(model.CityStats)
+
+		if err = rows.Scan(&c.CountryCode, &c.City, &c.HostCnt, &c.SvcCnt); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		list = append(list, c)
+	}
+
+	return list, rows.Err()
+} // func (db *Database) StatsByCity() ([]*model.CityStats, error)
+
+// StatsTopPorts returns the <max> ports with the most successfully scanned
+// services for each country.
+func (db *Database) StatsTopPorts(max int) ([]*model.PortStats, error) {
+	var (
+		err  error
+		rows *sql.Rows
+		list = make([]*model.PortStats, 0, 256)
+	)
+
+	if rows, err = db.statsQuery(query.StatsTopPorts, max); err != nil {
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	for rows.Next() {
+		var p = new(model.PortStats)
+
+		if err = rows.Scan(&p.CountryCode, &p.Port, &p.Count); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		list = append(list, p)
+	}
+
+	return list, rows.Err()
+} // func (db *Database) StatsTopPorts(max int) ([]*model.PortStats, error)
+
+// StatsBySource returns the number of Hosts per country and source.
+func (db *Database) StatsBySource() ([]*model.SourceStats, error) {
+	var (
+		err  error
+		rows *sql.Rows
+		list = make([]*model.SourceStats, 0, 256)
+	)
+
+	if rows, err = db.statsQuery(query.StatsBySource); err != nil {
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	for rows.Next() {
+		var s = new(model.SourceStats)
+
+		if err = rows.Scan(&s.CountryCode, &s.Source, &s.HostCnt); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		list = append(list, s)
+	}
+
+	return list, rows.Err()
+} // func (db *Database) StatsBySource() ([]*model.SourceStats, error)
@@ -0,0 +1,738 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/user.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 11:02:37 krylon>
+
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/blicero/guangng/database/query"
+	"github.com/blicero/guangng/model"
+)
+
+// UserAdd adds a User to the Database.
+func (db *Database) UserAdd(u *model.User) error {
+	const qid query.ID = query.UserAdd
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Failed to prepare query %s: %s\n",
+			qid,
+			err.Error())
+		panic(err)
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var (
+		rows *sql.Rows
+		now  = time.Now()
+	)
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(u.Name, u.PwHash, u.Role, now.Unix()); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		err = fmt.Errorf("cannot add User %s to database: %w",
+			u.Name,
+			err)
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return err
+	}
+
+	defer rows.Close() // nolint: errcheck
+
+	if !rows.Next() {
+		// CANTHAPPEN
+		db.log.Printf("[ERROR] Query %s did not return a value\n",
+			qid)
+		return fmt.Errorf("query %s did not return a value", qid)
+	} else if err = rows.Scan(&u.ID); err != nil {
+		var ex = fmt.Errorf("failed to get ID for newly added User %s: %w",
+			u.Name,
+			err)
+		db.log.Printf("[ERROR] %s\n", ex.Error())
+		return ex
+	}
+
+	u.Created = now
+	return nil
+} // func (db *Database) UserAdd(u *model.User) error
+
+// UserGetByID looks up a User by its ID.
+// If no such User exists, it returns nil without an error.
+func (db *Database) UserGetByID(id int64) (*model.User, error) {
+	const qid query.ID = query.UserGetByID
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(id); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if rows.Next() {
+		var (
+			created, login int64
+			u              = &model.User{ID: id}
+		)
+
+		if err = rows.Scan(&u.Name, &u.PwHash, &u.Role, &created, &login); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		u.Created = time.Unix(created, 0)
+		u.LastLogin = unixOrZero(login)
+		return u, nil
+	}
+
+	return nil, nil
+} // func (db *Database) UserGetByID(id int64) (*model.User, error)
+
+// UserGetByName looks up a User by its name.
+// If no such User exists, it returns nil without an error.
+func (db *Database) UserGetByName(name string) (*model.User, error) {
+	const qid query.ID = query.UserGetByName
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(name); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if rows.Next() {
+		var (
+			created, login int64
+			u              = &model.User{Name: name}
+		)
+
+		if err = rows.Scan(&u.ID, &u.PwHash, &u.Role, &created, &login); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		u.Created = time.Unix(created, 0)
+		u.LastLogin = unixOrZero(login)
+		return u, nil
+	}
+
+	return nil, nil
+} // func (db *Database) UserGetByName(name string) (*model.User, error)
+
+// UserGetAll returns all Users, ordered by name.
+func (db *Database) UserGetAll() ([]*model.User, error) {
+	const qid query.ID = query.UserGetAll
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var users = make([]*model.User, 0)
+
+	for rows.Next() {
+		var (
+			created, login int64
+			u              = new(model.User)
+		)
+
+		if err = rows.Scan(&u.ID, &u.Name, &u.PwHash, &u.Role, &created, &login); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		u.Created = time.Unix(created, 0)
+		u.LastLogin = unixOrZero(login)
+		users = append(users, u)
+	}
+
+	return users, nil
+} // func (db *Database) UserGetAll() ([]*model.User, error)
+
+// UserGetCnt returns the number of Users in the Database.
+func (db *Database) UserGetCnt() (int64, error) {
+	const qid query.ID = query.UserGetCnt
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return -1, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return -1, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if rows.Next() {
+		var cnt int64
+		if err = rows.Scan(&cnt); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return -1, ex
+		}
+
+		return cnt, nil
+	}
+
+	return -1, nil
+} // func (db *Database) UserGetCnt() (int64, error)
+
+// UserSetPassword replaces the password hash of a User.
+func (db *Database) UserSetPassword(u *model.User, pwhash string) error {
+	if err := db.execOne(query.UserSetPassword, pwhash, u.ID); err != nil {
+		return fmt.Errorf("cannot set password for User %s: %w",
+			u.Name,
+			err)
+	}
+
+	u.PwHash = pwhash
+	return nil
+} // func (db *Database) UserSetPassword(u *model.User, pwhash string) error
+
+// UserSetLastLogin records that a User has just logged in.
+func (db *Database) UserSetLastLogin(u *model.User) error {
+	var now = time.Now()
+
+	if err := db.execOne(query.UserSetLastLogin, now.Unix(), u.ID); err != nil {
+		return fmt.Errorf("cannot set last login for User %s: %w",
+			u.Name,
+			err)
+	}
+
+	u.LastLogin = now
+	return nil
+} // func (db *Database) UserSetLastLogin(u *model.User) error
+
+// UserDelete removes a User from the Database, along with all its Sessions
+// and API tokens.
+func (db *Database) UserDelete(u *model.User) error {
+	if err := db.execOne(query.UserDelete, u.ID); err != nil {
+		return fmt.Errorf("cannot delete User %s: %w",
+			u.Name,
+			err)
+	}
+
+	return nil
+} // func (db *Database) UserDelete(u *model.User) error
+
+// SessionAdd adds a Session to the Database.
+func (db *Database) SessionAdd(s *model.Session) error {
+	const qid query.ID = query.SessionAdd
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Failed to prepare query %s: %s\n",
+			qid,
+			err.Error())
+		panic(err)
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var (
+		rows *sql.Rows
+		now  = time.Now()
+	)
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(s.User.ID, s.TokenHash, s.CSRF, now.Unix(), s.Expires.Unix()); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		err = fmt.Errorf("cannot add Session for User %s to database: %w",
+			s.User.Name,
+			err)
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return err
+	}
+
+	defer rows.Close() // nolint: errcheck
+
+	if !rows.Next() {
+		// CANTHAPPEN
+		db.log.Printf("[ERROR] Query %s did not return a value\n",
+			qid)
+		return fmt.Errorf("query %s did not return a value", qid)
+	} else if err = rows.Scan(&s.ID); err != nil {
+		var ex = fmt.Errorf("failed to get ID for new Session of User %s: %w",
+			s.User.Name,
+			err)
+		db.log.Printf("[ERROR] %s\n", ex.Error())
+		return ex
+	}
+
+	s.Created = now
+	return nil
+} // func (db *Database) SessionAdd(s *model.Session) error
+
+// SessionGetByToken looks up a Session by the hash of its token.
+// If no such Session exists, or if it has expired, it returns nil without
+// an error.
+func (db *Database) SessionGetByToken(hash string) (*model.Session, error) {
+	const qid query.ID = query.SessionGetByToken
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(hash, time.Now().Unix()); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if rows.Next() {
+		var (
+			created, expires, uCreated, login int64
+			s                                 = &model.Session{
+				TokenHash: hash,
+				User:      new(model.User),
+			}
+		)
+
+		if err = rows.Scan(
+			&s.ID,
+			&s.CSRF,
+			&created,
+			&expires,
+			&s.User.ID,
+			&s.User.Name,
+			&s.User.PwHash,
+			&s.User.Role,
+			&uCreated,
+			&login); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		s.Created = time.Unix(created, 0)
+		s.Expires = time.Unix(expires, 0)
+		s.User.Created = time.Unix(uCreated, 0)
+		s.User.LastLogin = unixOrZero(login)
+		return s, nil
+	}
+
+	return nil, nil
+} // func (db *Database) SessionGetByToken(hash string) (*model.Session, error)
+
+// SessionDelete removes a Session from the Database, i.e. logs it out.
+func (db *Database) SessionDelete(s *model.Session) error {
+	if err := db.execOne(query.SessionDelete, s.ID); err != nil {
+		return fmt.Errorf("cannot delete Session %d: %w",
+			s.ID,
+			err)
+	}
+
+	return nil
+} // func (db *Database) SessionDelete(s *model.Session) error
+
+// SessionPurge removes all expired Sessions from the Database and returns
+// how many there were.
+func (db *Database) SessionPurge() (int64, error) {
+	const qid query.ID = query.SessionPurge
+	var (
+		err  error
+		stmt *sql.Stmt
+		res  sql.Result
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Failed to prepare query %s: %s\n",
+			qid,
+			err.Error())
+		panic(err)
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if res, err = stmt.Exec(time.Now().Unix()); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		err = fmt.Errorf("cannot purge expired Sessions: %w", err)
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return 0, err
+	}
+
+	return res.RowsAffected()
+} // func (db *Database) SessionPurge() (int64, error)
+
+// TokenAdd adds an APIToken to the Database.
+func (db *Database) TokenAdd(t *model.APIToken) error {
+	const qid query.ID = query.TokenAdd
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Failed to prepare query %s: %s\n",
+			qid,
+			err.Error())
+		panic(err)
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var (
+		rows *sql.Rows
+		now  = time.Now()
+	)
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(t.User.ID, t.Name, t.TokenHash, now.Unix()); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		err = fmt.Errorf("cannot add API token %s for User %s to database: %w",
+			t.Name,
+			t.User.Name,
+			err)
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return err
+	}
+
+	defer rows.Close() // nolint: errcheck
+
+	if !rows.Next() {
+		// CANTHAPPEN
+		db.log.Printf("[ERROR] Query %s did not return a value\n",
+			qid)
+		return fmt.Errorf("query %s did not return a value", qid)
+	} else if err = rows.Scan(&t.ID); err != nil {
+		var ex = fmt.Errorf("failed to get ID for new API token %s: %w",
+			t.Name,
+			err)
+		db.log.Printf("[ERROR] %s\n", ex.Error())
+		return ex
+	}
+
+	t.Created = now
+	return nil
+} // func (db *Database) TokenAdd(t *model.APIToken) error
+
+// TokenGetByHash looks up an APIToken by its hash.
+// If no such token exists, it returns nil without an error.
+func (db *Database) TokenGetByHash(hash string) (*model.APIToken, error) {
+	const qid query.ID = query.TokenGetByHash
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(hash); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if rows.Next() {
+		var (
+			created, used, uCreated, login int64
+			t                              = &model.APIToken{
+				TokenHash: hash,
+				User:      new(model.User),
+			}
+		)
+
+		if err = rows.Scan(
+			&t.ID,
+			&t.Name,
+			&created,
+			&used,
+			&t.User.ID,
+			&t.User.Name,
+			&t.User.PwHash,
+			&t.User.Role,
+			&uCreated,
+			&login); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		t.Created = time.Unix(created, 0)
+		t.LastUsed = unixOrZero(used)
+		t.User.Created = time.Unix(uCreated, 0)
+		t.User.LastLogin = unixOrZero(login)
+		return t, nil
+	}
+
+	return nil, nil
+} // func (db *Database) TokenGetByHash(hash string) (*model.APIToken, error)
+
+// TokenGetByUser returns all APITokens belonging to the given User.
+func (db *Database) TokenGetByUser(u *model.User) ([]*model.APIToken, error) {
+	const qid query.ID = query.TokenGetByUser
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(u.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var tokens = make([]*model.APIToken, 0)
+
+	for rows.Next() {
+		var (
+			created, used int64
+			t             = &model.APIToken{User: u}
+		)
+
+		if err = rows.Scan(&t.ID, &t.Name, &t.TokenHash, &created, &used); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		t.Created = time.Unix(created, 0)
+		t.LastUsed = unixOrZero(used)
+		tokens = append(tokens, t)
+	}
+
+	return tokens, nil
+} // func (db *Database) TokenGetByUser(u *model.User) ([]*model.APIToken, error)
+
+// TokenTouch records that an APIToken has just been used.
+func (db *Database) TokenTouch(t *model.APIToken) error {
+	var now = time.Now()
+
+	if err := db.execOne(query.TokenTouch, now.Unix(), t.ID); err != nil {
+		return fmt.Errorf("cannot update API token %s: %w",
+			t.Name,
+			err)
+	}
+
+	t.LastUsed = now
+	return nil
+} // func (db *Database) TokenTouch(t *model.APIToken) error
+
+// TokenDelete revokes the API token of the given User with the given name.
+func (db *Database) TokenDelete(u *model.User, name string) error {
+	if err := db.execOne(query.TokenDelete, u.ID, name); err != nil {
+		return fmt.Errorf("cannot delete API token %s of User %s: %w",
+			name,
+			u.Name,
+			err)
+	}
+
+	return nil
+} // func (db *Database) TokenDelete(u *model.User, name string) error
+
+// execOne executes a query that is expected to modify exactly one row.
+// If it does not, it returns ErrObjectNotFound.
+func (db *Database) execOne(qid query.ID, args ...any) error {
+	var (
+		err  error
+		stmt *sql.Stmt
+		res  sql.Result
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Failed to prepare query %s: %s\n",
+			qid,
+			err.Error())
+		panic(err)
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if res, err = stmt.Exec(args...); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Query %s failed: %s\n",
+			qid,
+			err.Error())
+		return err
+	}
+
+	var cnt int64
+
+	if cnt, err = res.RowsAffected(); err != nil {
+		db.log.Printf("[ERROR] Cannot get number of affected rows: %s\n",
+			err.Error())
+		return err
+	} else if cnt != 1 {
+		db.log.Printf("[ERROR] Query %s affected %d rows, expected 1\n",
+			qid,
+			cnt)
+		return ErrObjectNotFound
+	}
+
+	return nil
+} // func (db *Database) execOne(qid query.ID, args ...any) error
+
+// unixOrZero converts a Unix timestamp to a time.Time, mapping 0 (which we
+// use for "never") to the zero Time.
+func unixOrZero(t int64) time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(t, 0)
+} // func unixOrZero(t int64) time.Time
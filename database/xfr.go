@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 15. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-01-30 17:26:39 krylon>
+// Time-stamp: <2026-10-19 15:10:30 krylon>
 
 package database
 
@@ -13,6 +13,7 @@ import (
 
 	"github.com/blicero/guangng/database/query"
 	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/dnssec"
 )
 
 // XFRAdd adds a zone to the database.
@@ -102,11 +103,11 @@ EXEC_QUERY:
 
 	if rows.Next() {
 		var (
-			added, start, finish int64
-			zone                 = &model.Zone{Name: name}
+			added, start, finish, next int64
+			zone                       = &model.Zone{Name: name}
 		)
 
-		if err = rows.Scan(&zone.ID, &added, &start, &finish, &zone.Status); err != nil {
+		if err = rows.Scan(&zone.ID, &added, &start, &finish, &zone.Status, &next, &zone.Failures, &zone.DNSSEC); err != nil {
 			var ex = fmt.Errorf("failed to scan row: %w", err)
 			db.log.Printf("[ERROR] %s\n", ex.Error())
 			return nil, ex
@@ -119,6 +120,9 @@ EXEC_QUERY:
 		if finish != -1 {
 			zone.Finished = time.Unix(finish, 0)
 		}
+		if next != -1 {
+			zone.NextAttempt = time.Unix(next, 0)
+		}
 
 		return zone, nil
 	}
@@ -126,10 +130,71 @@ EXEC_QUERY:
 	return nil, nil
 } // func (db *Database) XFRGetByName(name string) (*model.Zone, error)
 
-// XFRGetUnfinished returns up <lim> unfinished XFRs from the database,
-// ordered by age (so the oldest ones will be returned first).
-func (db *Database) XFRGetUnfinished(lim int) ([]*model.Zone, error) {
-	const qid query.ID = query.XFRGetUnfinished
+// XFRGetByID looks up a zone by its ID.
+func (db *Database) XFRGetByID(id int64) (*model.Zone, error) {
+	const qid query.ID = query.XFRGetByID
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(id); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if rows.Next() {
+		var (
+			added, start, finish, next int64
+			zone                       = &model.Zone{ID: id}
+		)
+
+		if err = rows.Scan(&zone.Name, &added, &start, &finish, &zone.Status, &next, &zone.Failures, &zone.DNSSEC); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		zone.Added = time.Unix(added, 0)
+		if start != -1 {
+			zone.Started = time.Unix(start, 0)
+		}
+		if finish != -1 {
+			zone.Finished = time.Unix(finish, 0)
+		}
+		if next != -1 {
+			zone.NextAttempt = time.Unix(next, 0)
+		}
+
+		return zone, nil
+	}
+
+	return nil, nil
+} // func (db *Database) XFRGetByID(id int64) (*model.Zone, error)
+
+// XFRGetDue returns up to <lim> zones that are due for a transfer attempt,
+// those that have never been attempted first, then the ones that have been
+// waiting the longest.
+func (db *Database) XFRGetDue(lim int) ([]*model.Zone, error) {
+	const qid query.ID = query.XFRGetDue
 	var (
 		err  error
 		stmt *sql.Stmt
@@ -151,7 +216,7 @@ func (db *Database) XFRGetUnfinished(lim int) ([]*model.Zone, error) {
 	var rows *sql.Rows
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(lim); err != nil {
+	if rows, err = stmt.Query(time.Now().Unix(), lim); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
@@ -162,15 +227,15 @@ EXEC_QUERY:
 
 	defer rows.Close() // nolint: errcheck,gosec
 
-	var xlist = make([]*model.Zone, 0, lim)
+	var xlist = make([]*model.Zone, 0, max(lim, 0))
 
 	for rows.Next() {
 		var (
-			added, start int64
-			zone         = new(model.Zone)
+			added, start, finish, next int64
+			zone                       = new(model.Zone)
 		)
 
-		if err = rows.Scan(&zone.ID, &zone.Name, &added, &start); err != nil {
+		if err = rows.Scan(&zone.ID, &zone.Name, &added, &start, &finish, &zone.Status, &next, &zone.Failures, &zone.DNSSEC); err != nil {
 			db.log.Printf("[ERROR] Failed to scan row: %s\n",
 				err.Error())
 			return nil, err
@@ -180,12 +245,18 @@ EXEC_QUERY:
 		if start != -1 {
 			zone.Started = time.Unix(start, 0)
 		}
+		if finish != -1 {
+			zone.Finished = time.Unix(finish, 0)
+		}
+		if next != -1 {
+			zone.NextAttempt = time.Unix(next, 0)
+		}
 
 		xlist = append(xlist, zone)
 	}
 
 	return xlist, nil
-} // func (db *Database) XFRGetUnfinished(lim int) ([]*model.Zone, error)
+} // func (db *Database) XFRGetDue(lim int) ([]*model.Zone, error)
 
 // XFRGetCnt returns the total number of XFRs in the Database.
 func (db *Database) XFRGetCnt() (int64, error) {
@@ -233,7 +304,10 @@ EXEC_QUERY:
 } // func (db *Database) XFRGetCnt() (int64, error)
 
 // XFRStart registers the beginning of an attempt to do a transfer of a DNS zone.
-func (db *Database) XFRStart(zone *model.Zone) error {
+// Until retry, the zone is not due again, so it will not be handed out twice
+// while the attempt is under way. Should we never finish the attempt, say
+// because we crashed, it becomes due again after that.
+func (db *Database) XFRStart(zone *model.Zone, retry time.Time) error {
 	const qid query.ID = query.XFRStart
 	var (
 		err  error
@@ -251,7 +325,7 @@ func (db *Database) XFRStart(zone *model.Zone) error {
 	}
 
 EXEC_QUERY:
-	if _, err = stmt.Exec(now.Unix(), zone.ID); err != nil {
+	if _, err = stmt.Exec(now.Unix(), retry.Unix(), zone.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
@@ -265,11 +339,13 @@ EXEC_QUERY:
 	}
 
 	zone.Started = now
+	zone.NextAttempt = retry
 	return nil
-} // func (db *Database) XFRStart(z *model.Zone) error
+} // func (db *Database) XFRStart(zone *model.Zone, retry time.Time) error
 
-// XFRFinish registers the completion (successful or not) of an attempted AXFR.
-func (db *Database) XFRFinish(zone *model.Zone, status bool) error {
+// XFRFinish registers the completion (successful or not) of an attempted AXFR,
+// along with when to try again and how many attempts have failed in a row.
+func (db *Database) XFRFinish(zone *model.Zone, status bool, next time.Time, failures int) error {
 	const qid query.ID = query.XFRFinish
 	var (
 		err  error
@@ -287,7 +363,7 @@ func (db *Database) XFRFinish(zone *model.Zone, status bool) error {
 	}
 
 EXEC_QUERY:
-	if _, err = stmt.Exec(now.Unix(), status, zone.ID); err != nil {
+	if _, err = stmt.Exec(now.Unix(), status, next.Unix(), failures, zone.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
@@ -302,5 +378,43 @@ EXEC_QUERY:
 
 	zone.Finished = now
 	zone.Status = status
+	zone.NextAttempt = next
+	zone.Failures = failures
+	return nil
+} // func (db *Database) XFRFinish(zone *model.Zone, status bool, next time.Time, failures int) error
+
+// XFRSetDNSSEC records how a zone is signed.
+func (db *Database) XFRSetDNSSEC(zone *model.Zone, mode dnssec.Mode) error {
+	const qid query.ID = query.XFRSetDNSSEC
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Failed to prepare query %s: %s\n",
+			qid,
+			err.Error())
+		panic(err)
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(mode, zone.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("cannot set DNSSEC mode of zone %s to %s: %w",
+				zone.Name,
+				mode,
+				err)
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	zone.DNSSEC = mode
 	return nil
-} // func (db *Database) XFRFinish(zone *model.Zone) error
+} // func (db *Database) XFRSetDNSSEC(zone *model.Zone, mode dnssec.Mode) error
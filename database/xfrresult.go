@@ -0,0 +1,211 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/xfrresult.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:47:45 krylon>
+
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/blicero/guangng/database/query"
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/outcome"
+)
+
+// XFRResultAdd records the outcome of a zone transfer attempt against one
+// name server.
+func (db *Database) XFRResultAdd(z *model.Zone, r *model.XFRResult) error {
+	const qid query.ID = query.XFRResultAdd
+	var (
+		err  error
+		stmt *sql.Stmt
+		rows *sql.Rows
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	if r.Timestamp.IsZero() {
+		r.Timestamp = time.Now()
+	}
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(
+		z.ID,
+		r.NS,
+		r.Addr.String(),
+		r.Outcome,
+		r.RRCnt,
+		r.Timestamp.Unix()); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		err = fmt.Errorf("cannot add result of AXFR of %s from %s (%s): %w",
+			z.Name,
+			r.NS,
+			r.Addr,
+			err)
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if !rows.Next() {
+		// CANTHAPPEN
+		return fmt.Errorf("query %s did not return a value", qid)
+	} else if err = rows.Scan(&r.ID); err != nil {
+		var ex = fmt.Errorf("failed to get ID of AXFR result: %w", err)
+		db.log.Printf("[ERROR] %s\n", ex.Error())
+		return ex
+	}
+
+	r.ZoneID = z.ID
+	r.Zone = z.Name
+	return nil
+} // func (db *Database) XFRResultAdd(z *model.Zone, r *model.XFRResult) error
+
+// XFRResultGetByZone returns the results of all transfer attempts of a zone,
+// the oldest first.
+func (db *Database) XFRResultGetByZone(z *model.Zone) ([]*model.XFRResult, error) {
+	const qid query.ID = query.XFRResultGetByZone
+	var (
+		err  error
+		stmt *sql.Stmt
+		rows *sql.Rows
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(z.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query AXFR results of %s: %s\n",
+			z.Name,
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var list = make([]*model.XFRResult, 0, 4)
+
+	for rows.Next() {
+		var (
+			addr      string
+			timestamp int64
+			r         = &model.XFRResult{ZoneID: z.ID, Zone: z.Name}
+		)
+
+		if err = rows.Scan(&r.ID, &r.NS, &addr, &r.Outcome, &r.RRCnt, &timestamp); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		r.Addr = net.ParseIP(addr)
+		r.Timestamp = time.Unix(timestamp, 0)
+		list = append(list, r)
+	}
+
+	return list, rows.Err()
+} // func (db *Database) XFRResultGetByZone(z *model.Zone) ([]*model.XFRResult, error)
+
+// XFRResultGetRecent returns the results of the most recent transfer
+// attempts, the newest first.
+func (db *Database) XFRResultGetRecent(lim int) ([]*model.XFRResult, error) {
+	return db.xfrResultQuery(query.XFRResultGetRecent, lim)
+} // func (db *Database) XFRResultGetRecent(lim int) ([]*model.XFRResult, error)
+
+// XFRResultGetOpen returns the most recent transfer attempts that
+// succeeded, i.e. the servers that allow anyone to transfer their zones.
+func (db *Database) XFRResultGetOpen(lim int) ([]*model.XFRResult, error) {
+	return db.xfrResultQuery(query.XFRResultGetOpen, outcome.Success, lim)
+} // func (db *Database) XFRResultGetOpen(lim int) ([]*model.XFRResult, error)
+
+// xfrResultQuery runs one of the queries that return AXFR results along
+// with the name of their zone.
+func (db *Database) xfrResultQuery(qid query.ID, args ...any) ([]*model.XFRResult, error) {
+	var (
+		err  error
+		stmt *sql.Stmt
+		rows *sql.Rows
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(args...); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query AXFR results: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var list = make([]*model.XFRResult, 0, 16)
+
+	for rows.Next() {
+		var (
+			addr      string
+			timestamp int64
+			r         = new(model.XFRResult)
+		)
+
+		if err = rows.Scan(
+			&r.ID,
+			&r.ZoneID,
+			&r.Zone,
+			&r.NS,
+			&addr,
+			&r.Outcome,
+			&r.RRCnt,
+			&timestamp); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		r.Addr = net.ParseIP(addr)
+		r.Timestamp = time.Unix(timestamp, 0)
+		list = append(list, r)
+	}
+
+	return list, rows.Err()
+} // func (db *Database) xfrResultQuery(qid query.ID, args ...any) ([]*model.XFRResult, error)
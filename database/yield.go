@@ -0,0 +1,97 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/yield.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-21 10:17:25 krylon>
+
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/blicero/guangng/database/query"
+	"github.com/blicero/guangng/model"
+)
+
+// NetYieldGetAll returns the yield statistics of all networks.
+func (db *Database) NetYieldGetAll() ([]*model.NetYield, error) {
+	var (
+		err  error
+		rows *sql.Rows
+	)
+
+	if rows, err = db.statsQuery(query.NetYieldGetAll); err != nil {
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	return db.scanNetYield(rows)
+} // func (db *Database) NetYieldGetAll() ([]*model.NetYield, error)
+
+// NetYieldGetTop returns the <max> networks with the given prefix length
+// that have produced the most Hosts. A max of -1 means no limit.
+func (db *Database) NetYieldGetTop(bits uint8, max int) ([]*model.NetYield, error) {
+	var (
+		err  error
+		rows *sql.Rows
+	)
+
+	if rows, err = db.statsQuery(query.NetYieldGetTop, bits, max); err != nil {
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	return db.scanNetYield(rows)
+} // func (db *Database) NetYieldGetTop(bits uint8, max int) ([]*model.NetYield, error)
+
+func (db *Database) scanNetYield(rows *sql.Rows) ([]*model.NetYield, error) {
+	var (
+		err  error
+		list = make([]*model.NetYield, 0, 64)
+	)
+
+	for rows.Next() {
+		var (
+			updated int64
+			y       = new(model.NetYield)
+		)
+
+		if err = rows.Scan(&y.Prefix, &y.Bits, &y.Attempts, &y.Hits, &y.Blocked, &updated); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		y.Updated = time.Unix(updated, 0)
+		list = append(list, y)
+	}
+
+	return list, rows.Err()
+} // func (db *Database) scanNetYield(rows *sql.Rows) ([]*model.NetYield, error)
+
+// NetYieldSave stores the yield statistics of a network, replacing what we
+// knew about it before.
+func (db *Database) NetYieldSave(y *model.NetYield) error {
+	var (
+		err error
+		now = time.Now()
+	)
+
+	if err = db.execOne(
+		query.NetYieldSave,
+		y.Prefix,
+		y.Bits,
+		y.Attempts,
+		y.Hits,
+		y.Blocked,
+		now.Unix()); err != nil {
+		return fmt.Errorf("cannot save yield of %s: %w", y.Net(), err)
+	}
+
+	y.Updated = now
+	return nil
+} // func (db *Database) NetYieldSave(y *model.NetYield) error
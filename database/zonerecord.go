@@ -0,0 +1,223 @@
+// /home/krylon/go/src/github.com/blicero/guangng/database/zonerecord.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:02:25 krylon>
+
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/blicero/guangng/database/query"
+	"github.com/blicero/guangng/model"
+)
+
+// ZoneRecordAdd adds a record we received in a transfer of the zone z.
+func (db *Database) ZoneRecordAdd(z *model.Zone, r *model.ZoneRecord) error {
+	const qid query.ID = query.ZoneRecordAdd
+	var (
+		err  error
+		stmt *sql.Stmt
+		rows *sql.Rows
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	if r.Added.IsZero() {
+		r.Added = time.Now()
+	}
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(z.ID, r.Owner, r.Type, r.TTL, r.RData, r.Added.Unix()); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		err = fmt.Errorf("cannot add record %s to zone %s: %w",
+			r.Key(),
+			z.Name,
+			err)
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if !rows.Next() {
+		// CANTHAPPEN
+		return fmt.Errorf("query %s did not return a value", qid)
+	} else if err = rows.Scan(&r.ID); err != nil {
+		var ex = fmt.Errorf("failed to get ID of record %s: %w", r.Key(), err)
+		db.log.Printf("[ERROR] %s\n", ex.Error())
+		return ex
+	}
+
+	r.ZoneID = z.ID
+	return nil
+} // func (db *Database) ZoneRecordAdd(z *model.Zone, r *model.ZoneRecord) error
+
+// ZoneRecordGetCurrent returns the records of the zone z as of its most
+// recent transfer, sorted by owner and type.
+func (db *Database) ZoneRecordGetCurrent(z *model.Zone) ([]*model.ZoneRecord, error) {
+	return db.zoneRecordQuery(query.ZoneRecordGetCurrent, z.Name, z.ID)
+} // func (db *Database) ZoneRecordGetCurrent(z *model.Zone) ([]*model.ZoneRecord, error)
+
+// ZoneRecordGetChanges returns up to lim records that were added to or
+// removed from the zone z after its first transfer, the latest changes
+// first.
+func (db *Database) ZoneRecordGetChanges(z *model.Zone, lim int) ([]*model.ZoneRecord, error) {
+	return db.zoneRecordQuery(query.ZoneRecordGetChanges, z.Name, z.ID, z.ID, lim)
+} // func (db *Database) ZoneRecordGetChanges(z *model.Zone, lim int) ([]*model.ZoneRecord, error)
+
+// ZoneRecordGetByType returns the current records of the zone z that have
+// the type typ, e.g. "TXT", sorted by owner.
+func (db *Database) ZoneRecordGetByType(z *model.Zone, typ string) ([]*model.ZoneRecord, error) {
+	return db.zoneRecordQuery(query.ZoneRecordGetByType, z.Name, z.ID, typ)
+} // func (db *Database) ZoneRecordGetByType(z *model.Zone, typ string) ([]*model.ZoneRecord, error)
+
+// ZoneRecordGetByOwner returns the current records named owner that have
+// the type typ, from all zones we hold.
+func (db *Database) ZoneRecordGetByOwner(owner, typ string) ([]*model.ZoneRecord, error) {
+	return db.zoneRecordQuery(query.ZoneRecordGetByOwner, owner, owner, typ)
+} // func (db *Database) ZoneRecordGetByOwner(owner, typ string) ([]*model.ZoneRecord, error)
+
+// zoneRecordQuery runs one of the queries for records, name is what we
+// looked for, for the error message.
+func (db *Database) zoneRecordQuery(qid query.ID, name string, args ...any) ([]*model.ZoneRecord, error) {
+	var (
+		err  error
+		stmt *sql.Stmt
+		rows *sql.Rows
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(args...); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query records of %s: %s\n",
+			name,
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var list = make([]*model.ZoneRecord, 0, 32)
+
+	for rows.Next() {
+		var (
+			added, removed int64
+			r              = new(model.ZoneRecord)
+		)
+
+		if err = rows.Scan(&r.ID, &r.ZoneID, &r.Owner, &r.Type, &r.TTL, &r.RData, &added, &removed); err != nil {
+			var ex = fmt.Errorf("failed to scan row: %w", err)
+			db.log.Printf("[ERROR] %s\n", ex.Error())
+			return nil, ex
+		}
+
+		r.Added = time.Unix(added, 0)
+		if removed != -1 {
+			r.Removed = time.Unix(removed, 0)
+		}
+
+		list = append(list, r)
+	}
+
+	return list, rows.Err()
+} // func (db *Database) zoneRecordQuery(qid query.ID, name string, args ...any) ([]*model.ZoneRecord, error)
+
+// ZoneRecordSetTTL updates the TTL of a record.
+func (db *Database) ZoneRecordSetTTL(r *model.ZoneRecord, ttl uint32) error {
+	const qid query.ID = query.ZoneRecordSetTTL
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(ttl, r.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		err = fmt.Errorf("cannot update TTL of record %s: %w",
+			r.Key(),
+			err)
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return err
+	}
+
+	r.TTL = ttl
+	return nil
+} // func (db *Database) ZoneRecordSetTTL(r *model.ZoneRecord, ttl uint32) error
+
+// ZoneRecordRemove marks a record as missing from the latest transfer of
+// its zone.
+func (db *Database) ZoneRecordRemove(r *model.ZoneRecord) error {
+	const qid query.ID = query.ZoneRecordRemove
+	var (
+		err  error
+		stmt *sql.Stmt
+		now  = time.Now()
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(now.Unix(), r.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		err = fmt.Errorf("cannot remove record %s: %w",
+			r.Key(),
+			err)
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return err
+	}
+
+	r.Removed = now
+	return nil
+} // func (db *Database) ZoneRecordRemove(r *model.ZoneRecord) error
@@ -0,0 +1,138 @@
+// /home/krylon/go/src/github.com/blicero/guangng/events/events.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 22:38:05 krylon>
+
+// Package events passes notifications about what the subsystems are doing
+// to whoever is interested, most notably the web frontend.
+//
+// Publishing an Event never blocks. If a subscriber does not keep up, the
+// Events it has no room for are dropped, the subsystems have more important
+// things to do than wait for a browser.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/subsystem"
+)
+
+// Event is a notification about something that happened in one of the
+// subsystems. Which of the fields are set depends on the Kind.
+type Event struct {
+	Kind      Kind         `json:"-"`
+	Timestamp time.Time    `json:"timestamp"`
+	Subsystem subsystem.ID `json:"-"`
+	SubName   string       `json:"subsystem,omitempty"`
+	Count     int          `json:"count,omitempty"`
+	Active    bool         `json:"active,omitempty"`
+	Addr      string       `json:"addr,omitempty"`
+	Name      string       `json:"name,omitempty"`
+	Source    string       `json:"source,omitempty"`
+	Port      uint16       `json:"port,omitempty"`
+	Response  string       `json:"response,omitempty"`
+	Zone      string       `json:"zone,omitempty"`
+	Success   bool         `json:"success,omitempty"`
+}
+
+type bus struct {
+	lock  sync.RWMutex
+	idCnt int64
+	subs  map[int64]chan *Event
+}
+
+var b = &bus{subs: make(map[int64]chan *Event)}
+
+// Subscribe registers a new subscriber. It returns an ID to unsubscribe
+// with and the channel Events are delivered to. bufSize is the number of
+// Events that can be queued before further Events are dropped.
+func Subscribe(bufSize int) (int64, <-chan *Event) {
+	var q = make(chan *Event, max(bufSize, 1))
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.idCnt++
+	b.subs[b.idCnt] = q
+
+	return b.idCnt, q
+} // func Subscribe(bufSize int) (int64, <-chan *Event)
+
+// Unsubscribe removes a subscriber and closes its channel.
+func Unsubscribe(id int64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if q, ok := b.subs[id]; ok {
+		delete(b.subs, id)
+		close(q)
+	}
+} // func Unsubscribe(id int64)
+
+// Publish delivers an Event to all subscribers.
+func Publish(ev *Event) {
+	if ev.Timestamp.IsZero() {
+		ev.Timestamp = time.Now()
+	}
+
+	if ev.Subsystem != subsystem.None {
+		ev.SubName = ev.Subsystem.String()
+	}
+
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	for _, q := range b.subs {
+		select {
+		case q <- ev:
+		default:
+		}
+	}
+} // func Publish(ev *Event)
+
+// Workers publishes the number of workers in a subsystem.
+func Workers(sub subsystem.ID, cnt int) {
+	Publish(&Event{Kind: WorkerCount, Subsystem: sub, Count: cnt})
+} // func Workers(sub subsystem.ID, cnt int)
+
+// State publishes a subsystem being started or stopped.
+func State(sub subsystem.ID, active bool) {
+	Publish(&Event{Kind: SubsystemState, Subsystem: sub, Active: active})
+} // func State(sub subsystem.ID, active bool)
+
+// Host publishes a Host that was added to the Database.
+func Host(h *model.Host) {
+	Publish(&Event{
+		Kind:   HostAdded,
+		Addr:   h.AStr(),
+		Name:   h.Name,
+		Source: h.Source.String(),
+	})
+} // func Host(h *model.Host)
+
+// Probe publishes a port that responded to the Scanner.
+func Probe(h *model.Host, svc *model.Service) {
+	Publish(&Event{
+		Kind:      ProbeSuccess,
+		Subsystem: subsystem.Scanner,
+		Addr:      h.AStr(),
+		Name:      h.Name,
+		Port:      svc.Port,
+		Response:  svc.Response,
+		Success:   svc.Success,
+	})
+} // func Probe(h *model.Host, svc *model.Service)
+
+// XFR publishes the outcome of a zone transfer.
+func XFR(z *model.Zone, success bool, cnt int64) {
+	Publish(&Event{
+		Kind:      XFRFinished,
+		Subsystem: subsystem.XFR,
+		Zone:      z.Name,
+		Success:   success,
+		Count:     int(cnt),
+	})
+} // func XFR(z *model.Zone, success bool, cnt int64)
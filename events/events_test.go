@@ -0,0 +1,45 @@
+// /home/krylon/go/src/github.com/blicero/guangng/events/events_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 23:12:48 krylon>
+
+package events
+
+import (
+	"testing"
+
+	"github.com/blicero/guangng/model/subsystem"
+)
+
+func TestPublish(t *testing.T) {
+	var (
+		id1, q1 = Subscribe(4)
+		id2, q2 = Subscribe(1)
+	)
+
+	defer Unsubscribe(id1)
+
+	Workers(subsystem.Scanner, 3)
+	State(subsystem.XFR, true) // q2 is full, this one is dropped for it.
+
+	for i, kind := range []Kind{WorkerCount, SubsystemState} {
+		var ev = <-q1
+
+		if ev.Kind != kind {
+			t.Errorf("Event #%d has Kind %s (expected %s)", i, ev.Kind, kind)
+		} else if ev.Timestamp.IsZero() {
+			t.Errorf("Event #%d has no timestamp", i)
+		}
+	}
+
+	if ev := <-q2; ev.SubName != "Scanner" || ev.Count != 3 {
+		t.Errorf("Unexpected Event: %#v", ev)
+	}
+
+	Unsubscribe(id2)
+
+	if _, ok := <-q2; ok {
+		t.Error("Channel should be closed after unsubscribing")
+	}
+} // func TestPublish(t *testing.T)
@@ -0,0 +1,36 @@
+// /home/krylon/go/src/github.com/blicero/guangng/events/kind.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 22:21:40 krylon>
+
+package events
+
+//go:generate stringer -type=Kind
+
+// Kind identifies what an Event is about.
+type Kind uint8
+
+// WorkerCount is sent when a worker starts or stops.
+// HostAdded is sent when a new Host has been stored in the Database.
+// ProbeSuccess is sent when the Scanner got a response from a port.
+// XFRFinished is sent when a zone transfer has been attempted.
+// SubsystemState is sent when a subsystem is started or stopped.
+const (
+	WorkerCount Kind = iota
+	HostAdded
+	ProbeSuccess
+	XFRFinished
+	SubsystemState
+)
+
+// AllKinds returns a slice of all Kinds of Events.
+func AllKinds() []Kind {
+	return []Kind{
+		WorkerCount,
+		HostAdded,
+		ProbeSuccess,
+		XFRFinished,
+		SubsystemState,
+	}
+} // func AllKinds() []Kind
@@ -0,0 +1,273 @@
+// /home/krylon/go/src/github.com/blicero/guangng/export/export.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:25:10 krylon>
+
+// Package export writes the Hosts and Services we have collected in formats
+// other tools can digest.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/logdomain"
+	"github.com/blicero/guangng/model"
+)
+
+// writer is implemented by the various output formats.
+type writer interface {
+	begin() error
+	host(h *model.Host, svc []*model.Service) error
+	end() error
+}
+
+// Exporter streams Hosts along with their Services from the Database.
+type Exporter struct {
+	log *log.Logger
+	db  *database.Database
+}
+
+// New creates an Exporter that reads from the given Database.
+func New(db *database.Database) (*Exporter, error) {
+	var (
+		err error
+		ex  = &Exporter{db: db}
+	)
+
+	if ex.log, err = common.GetLogger(logdomain.Export); err != nil {
+		return nil, err
+	}
+
+	return ex, nil
+} // func New(db *database.Database) (*Exporter, error)
+
+// Export writes all Hosts matched by the filter to w, using the given Format.
+// It returns the number of Hosts written.
+func (ex *Exporter) Export(w io.Writer, f Format, filter *database.HostFilter) (int64, error) {
+	var (
+		err error
+		cnt int64
+		out writer
+	)
+
+	switch f {
+	case NmapXML:
+		out = newNmapWriter(w)
+	case JSONL:
+		out = newJSONWriter(w)
+	case CSV:
+		out = newCSVWriter(w)
+	default:
+		err = fmt.Errorf("unsupported export format %s", f)
+		ex.log.Printf("[ERROR] %s\n", err.Error())
+		return 0, err
+	}
+
+	if err = out.begin(); err != nil {
+		ex.log.Printf("[ERROR] Failed to write %s preamble: %s\n",
+			f,
+			err.Error())
+		return 0, err
+	}
+
+	err = ex.db.HostWalk(filter, func(h *model.Host) error {
+		var (
+			ierr  error
+			ports map[uint16]*model.Service
+		)
+
+		if ports, ierr = ex.db.ServiceGetByHost(h); ierr != nil {
+			return fmt.Errorf("cannot load Services of %s (%s): %w",
+				h.Name,
+				h.AStr(),
+				ierr)
+		} else if ierr = out.host(h, sortServices(ports)); ierr != nil {
+			return ierr
+		}
+
+		cnt++
+		return nil
+	})
+
+	if err != nil {
+		ex.log.Printf("[ERROR] %s export aborted after %d Hosts: %s\n",
+			f,
+			cnt,
+			err.Error())
+		return cnt, err
+	} else if err = out.end(); err != nil {
+		ex.log.Printf("[ERROR] Failed to finish %s export: %s\n",
+			f,
+			err.Error())
+		return cnt, err
+	}
+
+	ex.log.Printf("[DEBUG] Exported %d Hosts as %s\n", cnt, f)
+	return cnt, nil
+} // func (ex *Exporter) Export(w io.Writer, f Format, filter *database.HostFilter) (int64, error)
+
+func sortServices(ports map[uint16]*model.Service) []*model.Service {
+	var list = make([]*model.Service, 0, len(ports))
+
+	for _, s := range ports {
+		list = append(list, s)
+	}
+
+	slices.SortFunc(list, func(a, b *model.Service) int {
+		return int(a.Port) - int(b.Port)
+	})
+
+	return list
+} // func sortServices(ports map[uint16]*model.Service) []*model.Service
+
+//////////////////////////////////////////////////////////////////////////////
+/// JSON Lines ///////////////////////////////////////////////////////////////
+//////////////////////////////////////////////////////////////////////////////
+
+type jsonService struct {
+	Port      uint16    `json:"port"`
+	Proto     string    `json:"proto"`
+	Success   bool      `json:"success"`
+	Response  string    `json:"response,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type jsonHost struct {
+	ID           int64         `json:"id"`
+	Addr         string        `json:"addr"`
+	Name         string        `json:"name"`
+	Confirmed    bool          `json:"confirmed"`
+	Added        time.Time     `json:"added"`
+	LastContact  time.Time     `json:"last_contact"`
+	Sysname      string        `json:"sysname,omitempty"`
+	OSConfidence float64       `json:"os_confidence,omitempty"`
+	ASN          uint32        `json:"asn,omitempty"`
+	ASOrg        string        `json:"as_org,omitempty"`
+	Location     string        `json:"location,omitempty"`
+	Source       string        `json:"source"`
+	Services     []jsonService `json:"services"`
+}
+
+type jsonWriter struct {
+	enc *json.Encoder
+}
+
+func newJSONWriter(w io.Writer) *jsonWriter {
+	return &jsonWriter{enc: json.NewEncoder(w)}
+} // func newJSONWriter(w io.Writer) *jsonWriter
+
+func (j *jsonWriter) begin() error { return nil }
+func (j *jsonWriter) end() error   { return nil }
+
+func (j *jsonWriter) host(h *model.Host, svc []*model.Service) error {
+	var rec = jsonHost{
+		ID:           h.ID,
+		Addr:         h.AStr(),
+		Name:         h.Name,
+		Confirmed:    h.Confirmed,
+		Added:        h.Added,
+		LastContact:  h.LastContact,
+		Sysname:      h.Sysname,
+		OSConfidence: h.OSConfidence,
+		ASN:          h.ASN,
+		ASOrg:        h.ASOrg,
+		Location:     h.Location,
+		Source:       h.Source.String(),
+		Services:     make([]jsonService, len(svc)),
+	}
+
+	for i, s := range svc {
+		rec.Services[i] = jsonService{
+			Port:      s.Port,
+			Proto:     portProto(s.Port),
+			Success:   s.Success,
+			Response:  s.Response,
+			Timestamp: s.Timestamp,
+		}
+	}
+
+	// json.Encoder terminates each value with a newline, which is
+	// all JSON Lines asks for.
+	return j.enc.Encode(&rec)
+} // func (j *jsonWriter) host(h *model.Host, svc []*model.Service) error
+
+//////////////////////////////////////////////////////////////////////////////
+/// CSV //////////////////////////////////////////////////////////////////////
+//////////////////////////////////////////////////////////////////////////////
+
+var csvHeader = []string{
+	"addr",
+	"name",
+	"source",
+	"location",
+	"sysname",
+	"added",
+	"port",
+	"proto",
+	"success",
+	"response",
+	"timestamp",
+}
+
+// csvWriter emits one row per Service. Hosts without any Services get a
+// single row with the service columns left empty.
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+} // func newCSVWriter(w io.Writer) *csvWriter
+
+func (c *csvWriter) begin() error {
+	return c.w.Write(csvHeader)
+} // func (c *csvWriter) begin() error
+
+func (c *csvWriter) end() error {
+	c.w.Flush()
+	return c.w.Error()
+} // func (c *csvWriter) end() error
+
+func (c *csvWriter) host(h *model.Host, svc []*model.Service) error {
+	var row = []string{
+		h.AStr(),
+		h.Name,
+		h.Source.String(),
+		h.Location,
+		h.Sysname,
+		h.Added.Format(common.TimestampFormat),
+		"",
+		"",
+		"",
+		"",
+		"",
+	}
+
+	if len(svc) == 0 {
+		return c.w.Write(row)
+	}
+
+	for _, s := range svc {
+		row[6] = strconv.Itoa(int(s.Port))
+		row[7] = portProto(s.Port)
+		row[8] = strconv.FormatBool(s.Success)
+		row[9] = s.Response
+		row[10] = s.Timestamp.Format(common.TimestampFormat)
+
+		if err := c.w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+} // func (c *csvWriter) host(h *model.Host, svc []*model.Service) error
@@ -0,0 +1,166 @@
+// /home/krylon/go/src/github.com/blicero/guangng/export/export_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:02:25 krylon>
+
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/hsrc"
+	"github.com/blicero/guangng/model/weakness"
+)
+
+var tHost = &model.Host{
+	ID:     1,
+	Addr:   net.ParseIP("192.0.2.23"),
+	Name:   "neuromancer.example.com.",
+	Added:  time.Now(),
+	Source: hsrc.XFR,
+}
+
+var tMail = []*model.MailPolicy{
+	{
+		Zone:        "example.com",
+		HasMX:       true,
+		SPF:         "v=spf1 mx +all",
+		SPFAll:      "+",
+		SPFLookups:  1,
+		DMARC:       "v=DMARC1; p=none",
+		DMARCPolicy: "none",
+		Weaknesses:  []weakness.Weakness{weakness.SPFPassAll, weakness.DMARCNone},
+		Checked:     time.Now(),
+	},
+	{
+		Zone:    "example.net",
+		SPF:     "v=spf1 -all",
+		SPFAll:  "-",
+		DMARC:   "v=DMARC1; p=reject",
+		Checked: time.Now(),
+	},
+}
+
+var tServices = []*model.Service{
+	{HostID: 1, Port: 22, Success: true, Response: "SSH-2.0-OpenSSH_9.6", Timestamp: time.Now()},
+	{HostID: 1, Port: 23, Timestamp: time.Now()},
+	{HostID: 1, Port: 161, Success: true, Response: "Linux neuromancer 6.1.0", Timestamp: time.Now()},
+}
+
+func writeAll(t *testing.T, out writer) {
+	if err := out.begin(); err != nil {
+		t.Fatalf("begin failed: %s", err.Error())
+	} else if err = out.host(tHost, tServices); err != nil {
+		t.Fatalf("host failed: %s", err.Error())
+	} else if err = out.end(); err != nil {
+		t.Fatalf("end failed: %s", err.Error())
+	}
+} // func writeAll(t *testing.T, out writer)
+
+func TestExportNmap(t *testing.T) {
+	var (
+		err error
+		buf bytes.Buffer
+		res struct {
+			Hosts []nmapHost `xml:"host"`
+		}
+	)
+
+	writeAll(t, newNmapWriter(&buf))
+
+	if err = xml.Unmarshal(buf.Bytes(), &res); err != nil {
+		t.Fatalf("Cannot parse XML output: %s\n%s", err.Error(), buf.String())
+	} else if len(res.Hosts) != 1 {
+		t.Fatalf("Unexpected number of hosts: %d (expected 1)", len(res.Hosts))
+	}
+
+	var h = res.Hosts[0]
+
+	if h.Address.Addr != tHost.AStr() || h.Address.AddrType != "ipv4" {
+		t.Errorf("Unexpected address: %#v", h.Address)
+	} else if len(h.Ports) != 2 {
+		t.Errorf("Unexpected number of open ports: %d (expected 2)", len(h.Ports))
+	} else if h.Ports[1].Protocol != "udp" || h.Ports[1].Service.Name != "snmp" {
+		t.Errorf("Unexpected port entry: %#v", h.Ports[1])
+	}
+} // func TestExportNmap(t *testing.T)
+
+func TestExportJSONL(t *testing.T) {
+	var (
+		err error
+		buf bytes.Buffer
+		rec jsonHost
+	)
+
+	writeAll(t, newJSONWriter(&buf))
+
+	var lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	if len(lines) != 1 {
+		t.Fatalf("Unexpected number of lines: %d (expected 1)", len(lines))
+	} else if err = json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatalf("Cannot parse JSON output: %s", err.Error())
+	} else if rec.Name != tHost.Name || len(rec.Services) != len(tServices) {
+		t.Errorf("Unexpected record: %#v", rec)
+	}
+} // func TestExportJSONL(t *testing.T)
+
+func TestExportCSV(t *testing.T) {
+	var (
+		err  error
+		buf  bytes.Buffer
+		rows [][]string
+	)
+
+	writeAll(t, newCSVWriter(&buf))
+
+	if rows, err = csv.NewReader(&buf).ReadAll(); err != nil {
+		t.Fatalf("Cannot parse CSV output: %s", err.Error())
+	} else if len(rows) != len(tServices)+1 {
+		t.Fatalf("Unexpected number of rows: %d (expected %d)",
+			len(rows),
+			len(tServices)+1)
+	} else if rows[1][6] != "22" || rows[1][9] != tServices[0].Response {
+		t.Errorf("Unexpected row: %v", rows[1])
+	}
+} // func TestExportCSV(t *testing.T)
+
+func TestExportMail(t *testing.T) {
+	var (
+		err  error
+		buf  bytes.Buffer
+		rec  jsonMailPolicy
+		rows [][]string
+	)
+
+	if err = writeMailJSON(&buf, tMail); err != nil {
+		t.Fatalf("Cannot write mail policies as JSON: %s", err.Error())
+	} else if err = json.NewDecoder(&buf).Decode(&rec); err != nil {
+		t.Fatalf("Cannot parse JSON output: %s", err.Error())
+	} else if rec.Zone != "example.com" || len(rec.Weaknesses) != 2 || rec.Weaknesses[0] != "SPFPassAll" {
+		t.Errorf("Unexpected record: %#v", rec)
+	}
+
+	buf.Reset()
+
+	if err = writeMailCSV(&buf, tMail); err != nil {
+		t.Fatalf("Cannot write mail policies as CSV: %s", err.Error())
+	} else if rows, err = csv.NewReader(&buf).ReadAll(); err != nil {
+		t.Fatalf("Cannot parse CSV output: %s", err.Error())
+	} else if len(rows) != len(tMail)+1 {
+		t.Fatalf("Unexpected number of rows: %d (expected %d)",
+			len(rows),
+			len(tMail)+1)
+	} else if rows[1][9] != "SPFPassAll DMARCNone" || rows[2][9] != "" {
+		t.Errorf("Unexpected weaknesses: %q, %q", rows[1][9], rows[2][9])
+	}
+} // func TestExportMail(t *testing.T)
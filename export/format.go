@@ -0,0 +1,75 @@
+// /home/krylon/go/src/github.com/blicero/guangng/export/format.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:02:11 krylon>
+
+package export
+
+import (
+	"fmt"
+	"strings"
+)
+
+//go:generate stringer -type=Format
+
+// Format identifies one of the output formats the Exporter supports.
+type Format uint8
+
+const (
+	NmapXML Format = iota
+	JSONL
+	CSV
+)
+
+// AllFormats returns a slice of all supported Formats.
+func AllFormats() []Format {
+	return []Format{
+		NmapXML,
+		JSONL,
+		CSV,
+	}
+} // func AllFormats() []Format
+
+// ParseFormat returns the Format matching the given name, which is
+// the same one used by Extension.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "xml", "nmap":
+		return NmapXML, nil
+	case "jsonl", "json":
+		return JSONL, nil
+	case "csv":
+		return CSV, nil
+	default:
+		return 0, fmt.Errorf("unknown export format %q", name)
+	}
+} // func ParseFormat(name string) (Format, error)
+
+// Extension returns the file name extension commonly used for the Format.
+func (f Format) Extension() string {
+	switch f {
+	case NmapXML:
+		return "xml"
+	case JSONL:
+		return "jsonl"
+	case CSV:
+		return "csv"
+	default:
+		return "txt"
+	}
+} // func (f Format) Extension() string
+
+// MimeType returns the MIME type to use when delivering the Format over HTTP.
+func (f Format) MimeType() string {
+	switch f {
+	case NmapXML:
+		return "application/xml"
+	case JSONL:
+		return "application/jsonl"
+	case CSV:
+		return "text/csv"
+	default:
+		return "text/plain"
+	}
+} // func (f Format) MimeType() string
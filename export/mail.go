@@ -0,0 +1,155 @@
+// /home/krylon/go/src/github.com/blicero/guangng/export/mail.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:02:25 krylon>
+
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/model"
+)
+
+// ExportMail writes the e-mail security policies of all zones we have
+// analyzed to w. Nmap XML has no place for them, so only JSON Lines and CSV
+// are supported. It returns the number of zones written.
+func (ex *Exporter) ExportMail(w io.Writer, f Format) (int64, error) {
+	var (
+		err   error
+		list  []*model.MailPolicy
+		write func(io.Writer, []*model.MailPolicy) error
+	)
+
+	switch f {
+	case JSONL:
+		write = writeMailJSON
+	case CSV:
+		write = writeMailCSV
+	default:
+		err = fmt.Errorf("mail policies cannot be exported as %s", f)
+		ex.log.Printf("[ERROR] %s\n", err.Error())
+		return 0, err
+	}
+
+	if list, err = ex.db.MailPolicyGetAll(); err != nil {
+		ex.log.Printf("[ERROR] Cannot load mail policies: %s\n",
+			err.Error())
+		return 0, err
+	} else if err = write(w, list); err != nil {
+		ex.log.Printf("[ERROR] %s export of mail policies failed: %s\n",
+			f,
+			err.Error())
+		return 0, err
+	}
+
+	ex.log.Printf("[DEBUG] Exported mail policies of %d zones as %s\n",
+		len(list),
+		f)
+	return int64(len(list)), nil
+} // func (ex *Exporter) ExportMail(w io.Writer, f Format) (int64, error)
+
+type jsonMailPolicy struct {
+	Zone        string    `json:"zone"`
+	HasMX       bool      `json:"has_mx"`
+	SPF         string    `json:"spf,omitempty"`
+	SPFAll      string    `json:"spf_all,omitempty"`
+	SPFLookups  int       `json:"spf_lookups"`
+	DMARC       string    `json:"dmarc,omitempty"`
+	DMARCPolicy string    `json:"dmarc_policy,omitempty"`
+	MTASTS      string    `json:"mta_sts,omitempty"`
+	TLSRPT      string    `json:"tls_rpt,omitempty"`
+	Weaknesses  []string  `json:"weaknesses"`
+	Checked     time.Time `json:"checked"`
+}
+
+func writeMailJSON(w io.Writer, list []*model.MailPolicy) error {
+	var enc = json.NewEncoder(w)
+
+	for _, p := range list {
+		var rec = jsonMailPolicy{
+			Zone:        p.Zone,
+			HasMX:       p.HasMX,
+			SPF:         p.SPF,
+			SPFAll:      p.SPFAll,
+			SPFLookups:  p.SPFLookups,
+			DMARC:       p.DMARC,
+			DMARCPolicy: p.DMARCPolicy,
+			MTASTS:      p.MTASTS,
+			TLSRPT:      p.TLSRPT,
+			Weaknesses:  weaknessNames(p),
+			Checked:     p.Checked,
+		}
+
+		if err := enc.Encode(&rec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+} // func writeMailJSON(w io.Writer, list []*model.MailPolicy) error
+
+var csvMailHeader = []string{
+	"zone",
+	"has_mx",
+	"spf",
+	"spf_all",
+	"spf_lookups",
+	"dmarc",
+	"dmarc_policy",
+	"mta_sts",
+	"tls_rpt",
+	"weaknesses",
+	"checked",
+}
+
+// writeMailCSV emits one row per zone, the weaknesses are separated by
+// spaces.
+func writeMailCSV(w io.Writer, list []*model.MailPolicy) error {
+	var c = csv.NewWriter(w)
+
+	if err := c.Write(csvMailHeader); err != nil {
+		return err
+	}
+
+	for _, p := range list {
+		var row = []string{
+			p.Zone,
+			strconv.FormatBool(p.HasMX),
+			p.SPF,
+			p.SPFAll,
+			strconv.Itoa(p.SPFLookups),
+			p.DMARC,
+			p.DMARCPolicy,
+			p.MTASTS,
+			p.TLSRPT,
+			strings.Join(weaknessNames(p), " "),
+			p.Checked.Format(common.TimestampFormat),
+		}
+
+		if err := c.Write(row); err != nil {
+			return err
+		}
+	}
+
+	c.Flush()
+	return c.Error()
+} // func writeMailCSV(w io.Writer, list []*model.MailPolicy) error
+
+func weaknessNames(p *model.MailPolicy) []string {
+	var names = make([]string, len(p.Weaknesses))
+
+	for i, w := range p.Weaknesses {
+		names[i] = w.String()
+	}
+
+	return names
+} // func weaknessNames(p *model.MailPolicy) []string
@@ -0,0 +1,237 @@
+// /home/krylon/go/src/github.com/blicero/guangng/export/nmap.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:21:09 krylon>
+
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/model"
+)
+
+// The XML we produce mimics what "nmap -oX" writes, at least the parts
+// that tools consuming Nmap output commonly look at.
+
+const nmapXMLVersion = "1.05"
+
+// serviceNames maps the ports we scan to the service names Nmap uses.
+var serviceNames = map[uint16]string{
+	21:   "ftp",
+	22:   "ssh",
+	23:   "telnet",
+	25:   "smtp",
+	53:   "domain",
+	79:   "finger",
+	80:   "http",
+	110:  "pop3",
+	143:  "imap",
+	161:  "snmp",
+	443:  "https",
+	587:  "submission",
+	631:  "ipp",
+	1024: "kdm",
+	2525: "ms-v-worlds",
+	4444: "krb524",
+	5353: "mdns",
+	5800: "vnc-http",
+	5900: "vnc",
+	8000: "http-alt",
+	8080: "http-proxy",
+	8081: "blackice-icecap",
+}
+
+// portProto returns the transport protocol the Scanner uses to probe a port.
+func portProto(port uint16) string {
+	switch port {
+	case 53, 161, 5353:
+		return "udp"
+	default:
+		return "tcp"
+	}
+} // func portProto(port uint16) string
+
+type nmapAddress struct {
+	Addr     string `xml:"addr,attr"`
+	AddrType string `xml:"addrtype,attr"`
+}
+
+type nmapHostname struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type nmapState struct {
+	State  string `xml:"state,attr"`
+	Reason string `xml:"reason,attr"`
+}
+
+type nmapService struct {
+	Name   string `xml:"name,attr"`
+	Method string `xml:"method,attr"`
+	Conf   int    `xml:"conf,attr"`
+}
+
+type nmapScript struct {
+	ID     string `xml:"id,attr"`
+	Output string `xml:"output,attr"`
+}
+
+type nmapPort struct {
+	Protocol string      `xml:"protocol,attr"`
+	PortID   uint16      `xml:"portid,attr"`
+	State    nmapState   `xml:"state"`
+	Service  nmapService `xml:"service"`
+	Script   *nmapScript `xml:"script,omitempty"`
+}
+
+type nmapHost struct {
+	XMLName   xml.Name       `xml:"host"`
+	StartTime int64          `xml:"starttime,attr"`
+	EndTime   int64          `xml:"endtime,attr"`
+	Status    nmapState      `xml:"status"`
+	Address   nmapAddress    `xml:"address"`
+	Hostnames []nmapHostname `xml:"hostnames>hostname"`
+	Ports     []nmapPort     `xml:"ports>port"`
+}
+
+type nmapWriter struct {
+	w     io.Writer
+	enc   *xml.Encoder
+	start time.Time
+	cnt   int64
+}
+
+func newNmapWriter(w io.Writer) *nmapWriter {
+	return &nmapWriter{
+		w:   w,
+		enc: xml.NewEncoder(w),
+	}
+} // func newNmapWriter(w io.Writer) *nmapWriter
+
+func (n *nmapWriter) begin() error {
+	var err error
+
+	n.start = time.Now()
+
+	if _, err = io.WriteString(n.w, xml.Header+"<!DOCTYPE nmaprun>\n"); err != nil {
+		return err
+	}
+
+	n.enc.Indent("", "  ")
+
+	return n.enc.EncodeToken(xml.StartElement{
+		Name: xml.Name{Local: "nmaprun"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "scanner"}, Value: "guangng"},
+			{Name: xml.Name{Local: "args"}, Value: "guangng export"},
+			{Name: xml.Name{Local: "start"}, Value: strconv.FormatInt(n.start.Unix(), 10)},
+			{Name: xml.Name{Local: "startstr"}, Value: n.start.Format(time.ANSIC)},
+			{Name: xml.Name{Local: "version"}, Value: common.Version},
+			{Name: xml.Name{Local: "xmloutputversion"}, Value: nmapXMLVersion},
+		},
+	})
+} // func (n *nmapWriter) begin() error
+
+func (n *nmapWriter) host(h *model.Host, svc []*model.Service) error {
+	var (
+		addrType = "ipv4"
+		rec      = nmapHost{
+			StartTime: h.Added.Unix(),
+			EndTime:   h.LastContact.Unix(),
+			Status:    nmapState{State: "up", Reason: "user-set"},
+			Ports:     make([]nmapPort, 0, len(svc)),
+		}
+	)
+
+	if h.Addr.To4() == nil {
+		addrType = "ipv6"
+	}
+
+	rec.Address = nmapAddress{Addr: h.AStr(), AddrType: addrType}
+
+	if h.Name != "" {
+		rec.Hostnames = []nmapHostname{{Name: h.Name, Type: "PTR"}}
+	}
+
+	// We only know for sure a port is open if our probe got an answer.
+	// A failed probe may just as well mean a timeout, so we leave those out
+	// rather than claim they are closed.
+	for _, s := range svc {
+		if !s.Success {
+			continue
+		}
+
+		var port = nmapPort{
+			Protocol: portProto(s.Port),
+			PortID:   s.Port,
+			State:    nmapState{State: "open", Reason: "response"},
+			Service: nmapService{
+				Name:   serviceNames[s.Port],
+				Method: "table",
+				Conf:   3,
+			},
+		}
+
+		if s.Response != "" {
+			port.Script = &nmapScript{ID: "banner", Output: s.Response}
+		}
+
+		rec.Ports = append(rec.Ports, port)
+	}
+
+	n.cnt++
+	return n.enc.Encode(&rec)
+} // func (n *nmapWriter) host(h *model.Host, svc []*model.Service) error
+
+func (n *nmapWriter) end() error {
+	var (
+		err error
+		now = time.Now()
+		cnt = strconv.FormatInt(n.cnt, 10)
+	)
+
+	var stats = []xml.Token{
+		xml.StartElement{Name: xml.Name{Local: "runstats"}},
+		xml.StartElement{
+			Name: xml.Name{Local: "finished"},
+			Attr: []xml.Attr{
+				{Name: xml.Name{Local: "time"}, Value: strconv.FormatInt(now.Unix(), 10)},
+				{Name: xml.Name{Local: "timestr"}, Value: now.Format(time.ANSIC)},
+				{Name: xml.Name{Local: "elapsed"}, Value: strconv.FormatFloat(now.Sub(n.start).Seconds(), 'f', 2, 64)},
+				{Name: xml.Name{Local: "exit"}, Value: "success"},
+			},
+		},
+		xml.EndElement{Name: xml.Name{Local: "finished"}},
+		xml.StartElement{
+			Name: xml.Name{Local: "hosts"},
+			Attr: []xml.Attr{
+				{Name: xml.Name{Local: "up"}, Value: cnt},
+				{Name: xml.Name{Local: "down"}, Value: "0"},
+				{Name: xml.Name{Local: "total"}, Value: cnt},
+			},
+		},
+		xml.EndElement{Name: xml.Name{Local: "hosts"}},
+		xml.EndElement{Name: xml.Name{Local: "runstats"}},
+		xml.EndElement{Name: xml.Name{Local: "nmaprun"}},
+	}
+
+	for _, tok := range stats {
+		if err = n.enc.EncodeToken(tok); err != nil {
+			return err
+		}
+	}
+
+	if err = n.enc.Flush(); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(n.w, "\n")
+	return err
+} // func (n *nmapWriter) end() error
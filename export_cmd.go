@@ -0,0 +1,126 @@
+// /home/krylon/go/src/github.com/blicero/guangng/export_cmd.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:02:25 krylon>
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/export"
+	"github.com/blicero/guangng/model/hsrc"
+)
+
+// runExport implements the "export" subcommand. It returns the exit code
+// for the process.
+func runExport(args []string) int {
+	var (
+		err                         error
+		db                          *database.Database
+		ex                          *export.Exporter
+		fh                          *os.File
+		format                      export.Format
+		cnt                         int64
+		fmtName, out, name, country string
+		source, port, asn           uint
+		limit                       int
+		mail                        bool
+		flags                       = flag.NewFlagSet("export", flag.ExitOnError)
+	)
+
+	flags.StringVar(&fmtName, "format", "jsonl", "Output format (xml, jsonl, csv)")
+	flags.StringVar(&out, "out", "", "File to write to (default: guangng_export_<timestamp>.<format>)")
+	flags.StringVar(&name, "name", "", "Only export Hosts whose name contains this string")
+	flags.UintVar(&source, "source", 0, "Only export Hosts from this source (0 for all)")
+	flags.UintVar(&port, "port", 0, "Only export Hosts with a successful response on this port")
+	flags.UintVar(&asn, "asn", 0, "Only export Hosts in this autonomous system")
+	flags.StringVar(&country, "country", "", "Only export Hosts in this country (ISO 3166 code)")
+	flags.IntVar(&limit, "limit", -1, "Export at most this many Hosts (-1 for all)")
+	flags.BoolVar(&mail, "mail", false, "Export the e-mail security policies of zones instead of Hosts (jsonl, csv)")
+
+	flags.Parse(args) // nolint: errcheck
+
+	if format, err = export.ParseFormat(fmtName); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		return 1
+	} else if port > 65535 {
+		fmt.Fprintf(os.Stderr, "Invalid port number %d\n", port)
+		return 1
+	} else if asn > math.MaxUint32 {
+		fmt.Fprintf(os.Stderr, "Invalid AS number %d\n", asn)
+		return 1
+	}
+
+	var filter = database.HostFilter{
+		Source:  hsrc.HostSource(source),
+		Name:    name,
+		Port:    uint16(port),
+		ASN:     uint32(asn),
+		Country: strings.ToUpper(country),
+		Limit:   limit,
+	}
+
+	if out == "" {
+		out = fmt.Sprintf("%s_export_%s.%s",
+			strings.ToLower(common.AppName),
+			time.Now().Format("20060102_150405"),
+			format.Extension())
+	}
+
+	if db, err = database.Open(common.DbPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot open database %s: %s\n",
+			common.DbPath,
+			err.Error())
+		return 1
+	}
+
+	defer db.Close() // nolint: errcheck
+
+	if ex, err = export.New(db); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot create Exporter: %s\n",
+			err.Error())
+		return 1
+	} else if fh, err = os.Create(out); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot create %s: %s\n",
+			out,
+			err.Error())
+		return 1
+	}
+
+	defer fh.Close() // nolint: errcheck
+
+	var (
+		buf  = bufio.NewWriter(fh)
+		what = "Hosts"
+	)
+
+	if mail {
+		what = "mail policies"
+		cnt, err = ex.ExportMail(buf, format)
+	} else {
+		cnt, err = ex.Export(buf, format, &filter)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Export failed: %s\n", err.Error())
+		return 1
+	} else if err = buf.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot write %s: %s\n",
+			out,
+			err.Error())
+		return 1
+	}
+
+	fmt.Printf("Exported %d %s to %s\n", cnt, what, out)
+	return 0
+} // func runExport(args []string) int
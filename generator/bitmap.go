@@ -0,0 +1,378 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/bitmap.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:30:45 krylon>
+
+package generator
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/bits"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/blicero/guangng/metrics"
+	"github.com/blicero/guangng/model"
+)
+
+// The bitmap is split into one shard per /16, which is only allocated once
+// we look at an address in that network. With all shards allocated, the
+// bitmap takes up 512 MiB.
+const (
+	bitmapShardCnt   = 1 << 16
+	bitmapShardWords = (1 << 16) / 64
+	bitmapShardSize  = bitmapShardWords * 8
+	bitmapMagic      = "GNGBMP01"
+)
+
+var errNotIPv4 = errors.New("not an IPv4 address")
+
+type bitmapShard [bitmapShardWords]atomic.Uint64
+
+// bitmapCache remembers IPv4 addresses with one bit per address. Lookups
+// take constant time and need no locking.
+//
+// The bitmap is saved to a file periodically and when the Generator stops.
+// Individual addresses cannot expire, so if the cache has a TTL, it forgets
+// all addresses at once when it has become older than that.
+type bitmapCache struct {
+	log      *log.Logger
+	path     string
+	ttl      time.Duration
+	fresh    bool // There was no saved bitmap to load
+	shards   [bitmapShardCnt]atomic.Pointer[bitmapShard]
+	created  atomic.Int64
+	lookups  atomic.Int64
+	hits     atomic.Int64
+	entries  atomic.Int64
+	lastGC   atomic.Int64
+	saveLock sync.Mutex
+}
+
+func openBitmapCache(l *log.Logger, path string, ttl time.Duration) (*bitmapCache, error) {
+	var (
+		err error
+		c   = &bitmapCache{
+			log:  l,
+			path: path,
+			ttl:  ttl,
+		}
+	)
+
+	if err = c.load(); errors.Is(err, os.ErrNotExist) {
+		c.log.Printf("[INFO] No saved IPv4 cache at %s, starting with an empty one.\n",
+			path)
+		c.fresh = true
+		c.created.Store(time.Now().Unix())
+	} else if err != nil {
+		c.log.Printf("[ERROR] Failed to load IPv4 cache from %s: %s\n",
+			path,
+			err.Error())
+		return nil, err
+	}
+
+	return c, nil
+} // func openBitmapCache(l *log.Logger, path string, ttl time.Duration) (*bitmapCache, error)
+
+// shard returns the shard for the /16 with the given prefix, allocating it
+// if needed.
+func (c *bitmapCache) shard(prefix uint32) *bitmapShard {
+	var s = c.shards[prefix].Load()
+
+	if s == nil {
+		s = new(bitmapShard)
+		if !c.shards[prefix].CompareAndSwap(nil, s) {
+			s = c.shards[prefix].Load()
+		}
+	}
+
+	return s
+} // func (c *bitmapCache) shard(prefix uint32) *bitmapShard
+
+// set sets the bit for addr and returns true if it was set already.
+func (c *bitmapCache) set(addr net.IP) (bool, error) {
+	var ip4 = addr.To4()
+
+	if ip4 == nil {
+		return false, errNotIPv4
+	}
+
+	var (
+		val  = binary.BigEndian.Uint32(ip4)
+		idx  = val & 0xffff
+		mask = uint64(1) << (idx & 63)
+		old  = c.shard(val >> 16)[idx>>6].Or(mask)
+	)
+
+	if old&mask != 0 {
+		return true, nil
+	}
+
+	c.entries.Add(1)
+	return false, nil
+} // func (c *bitmapCache) set(addr net.IP) (bool, error)
+
+func (c *bitmapCache) check(addr net.IP) (bool, error) {
+	var present, err = c.set(addr)
+
+	if err != nil {
+		return false, fmt.Errorf("cannot check %s: %w", addr, err)
+	}
+
+	c.lookups.Add(1)
+
+	if present {
+		c.hits.Add(1)
+		metrics.CacheHits.Inc()
+	}
+
+	return present, nil
+} // func (c *bitmapCache) check(addr net.IP) (bool, error)
+
+// maintain clears the bitmap if it has expired, then saves it.
+func (c *bitmapCache) maintain() error {
+	var created = time.Unix(c.created.Load(), 0)
+
+	if c.ttl > 0 && time.Since(created) > c.ttl {
+		c.log.Printf("[INFO] IPv4 cache was started on %s, forgetting all addresses.\n",
+			created.Format(time.DateOnly))
+		c.clear()
+	}
+
+	c.lastGC.Store(time.Now().Unix())
+	return c.save()
+} // func (c *bitmapCache) maintain() error
+
+func (c *bitmapCache) clear() {
+	for i := range c.shards {
+		c.shards[i].Store(nil)
+	}
+
+	c.entries.Store(0)
+	c.created.Store(time.Now().Unix())
+} // func (c *bitmapCache) clear()
+
+// memSize returns the amount of memory taken up by the bitmap's shards.
+func (c *bitmapCache) memSize() int64 {
+	var cnt int64
+
+	for i := range c.shards {
+		if c.shards[i].Load() != nil {
+			cnt++
+		}
+	}
+
+	return cnt * bitmapShardSize
+} // func (c *bitmapCache) memSize() int64
+
+func (c *bitmapCache) stats() model.CacheStats {
+	var s = model.CacheStats{
+		Backend: "bitmap",
+		Entries: c.entries.Load(),
+		MemSize: c.memSize(),
+		TTL:     c.ttl,
+		Lookups: c.lookups.Load(),
+		Hits:    c.hits.Load(),
+	}
+
+	if info, err := os.Stat(c.path); err == nil {
+		s.DiskSize = info.Size()
+	}
+
+	if ts := c.lastGC.Load(); ts != 0 {
+		s.LastGC = time.Unix(ts, 0)
+	}
+
+	return s
+} // func (c *bitmapCache) stats() model.CacheStats
+
+func (c *bitmapCache) reset() error {
+	c.clear()
+	c.lookups.Store(0)
+	c.hits.Store(0)
+	c.log.Println("[INFO] IPv4 cache was cleared.")
+	return c.save()
+} // func (c *bitmapCache) reset() error
+
+func (c *bitmapCache) close() error {
+	return c.save()
+} // func (c *bitmapCache) close() error
+
+// save writes the bitmap to a file. To keep the file small, it only
+// contains the shards that have been allocated, each prefixed with its
+// index. The file is replaced atomically, so a crash while saving does not
+// cost us the previous copy.
+func (c *bitmapCache) save() error {
+	c.saveLock.Lock()
+	defer c.saveLock.Unlock()
+
+	var (
+		err  error
+		fh   *os.File
+		w    *bufio.Writer
+		buf  = make([]byte, 0, bitmapShardSize)
+		tmp  = c.path + ".tmp"
+		head = binary.BigEndian.AppendUint64([]byte(bitmapMagic), uint64(c.created.Load()))
+	)
+
+	if fh, err = os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600); err != nil {
+		c.log.Printf("[ERROR] Cannot open %s: %s\n",
+			tmp,
+			err.Error())
+		return err
+	}
+
+	defer fh.Close() // nolint: errcheck
+
+	w = bufio.NewWriter(fh)
+
+	if _, err = w.Write(head); err != nil {
+		goto FAIL
+	}
+
+	for i := range c.shards {
+		var s = c.shards[i].Load()
+
+		if s == nil {
+			continue
+		}
+
+		buf = binary.BigEndian.AppendUint16(buf[:0], uint16(i))
+		for j := range s {
+			buf = binary.LittleEndian.AppendUint64(buf, s[j].Load())
+		}
+
+		if _, err = w.Write(buf); err != nil {
+			goto FAIL
+		}
+	}
+
+	if err = w.Flush(); err != nil {
+		goto FAIL
+	} else if err = fh.Close(); err != nil {
+		goto FAIL
+	} else if err = os.Rename(tmp, c.path); err != nil {
+		goto FAIL
+	}
+
+	return nil
+
+FAIL:
+	c.log.Printf("[ERROR] Failed to save IPv4 cache to %s: %s\n",
+		c.path,
+		err.Error())
+	os.Remove(tmp) // nolint: errcheck,gosec
+	return err
+} // func (c *bitmapCache) save() error
+
+// load reads a bitmap saved by save.
+func (c *bitmapCache) load() error {
+	var (
+		err  error
+		fh   *os.File
+		r    *bufio.Reader
+		cnt  int64
+		head = make([]byte, len(bitmapMagic)+8)
+		buf  = make([]byte, 2+bitmapShardSize)
+	)
+
+	if fh, err = os.Open(c.path); err != nil {
+		return err
+	}
+
+	defer fh.Close() // nolint: errcheck
+
+	r = bufio.NewReader(fh)
+
+	if _, err = io.ReadFull(r, head); err != nil {
+		return fmt.Errorf("cannot read header: %w", err)
+	} else if string(head[:len(bitmapMagic)]) != bitmapMagic {
+		return fmt.Errorf("%s is not a saved IPv4 cache", c.path)
+	}
+
+	c.created.Store(int64(binary.BigEndian.Uint64(head[len(bitmapMagic):])))
+
+	for {
+		if _, err = io.ReadFull(r, buf); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("cannot read shard: %w", err)
+		}
+
+		var s = c.shard(uint32(binary.BigEndian.Uint16(buf)))
+
+		for j := range s {
+			var w = binary.LittleEndian.Uint64(buf[2+j*8:])
+			s[j].Store(w)
+			cnt += int64(bits.OnesCount64(w))
+		}
+	}
+
+	c.entries.Store(cnt)
+	return nil
+} // func (c *bitmapCache) load() error
+
+// splitCache keeps IPv4 addresses in a bitmapCache and all others in a
+// second cache.
+type splitCache struct {
+	v4    *bitmapCache
+	other addrCache
+}
+
+func (c *splitCache) check(addr net.IP) (bool, error) {
+	if addr.To4() != nil {
+		return c.v4.check(addr)
+	}
+
+	return c.other.check(addr)
+} // func (c *splitCache) check(addr net.IP) (bool, error)
+
+func (c *splitCache) maintain() error {
+	var err = c.v4.maintain()
+
+	return errors.Join(err, c.other.maintain())
+} // func (c *splitCache) maintain() error
+
+func (c *splitCache) stats() model.CacheStats {
+	var s, o = c.v4.stats(), c.other.stats()
+
+	s.Backend += "/" + o.Backend
+	s.Entries += o.Entries
+	s.DiskSize += o.DiskSize
+	s.MemSize += o.MemSize
+	s.Lookups += o.Lookups
+	s.Hits += o.Hits
+
+	if o.LastGC.After(s.LastGC) {
+		s.LastGC = o.LastGC
+	}
+
+	return s
+} // func (c *splitCache) stats() model.CacheStats
+
+func (c *splitCache) reset() error {
+	var err = c.v4.reset()
+
+	return errors.Join(err, c.other.reset())
+} // func (c *splitCache) reset() error
+
+func (c *splitCache) save() error {
+	var err = c.v4.save()
+
+	return errors.Join(err, c.other.save())
+} // func (c *splitCache) save() error
+
+func (c *splitCache) close() error {
+	var err = c.v4.close()
+
+	return errors.Join(err, c.other.close())
+} // func (c *splitCache) close() error
@@ -0,0 +1,185 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/bitmap_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:30:45 krylon>
+
+package generator
+
+import (
+	"encoding/binary"
+	"log"
+	"math/rand/v2"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestBitmap(t testing.TB, path string, ttl time.Duration) *bitmapCache {
+	var (
+		err error
+		c   *bitmapCache
+	)
+
+	if c, err = openBitmapCache(log.New(os.Stderr, "", 0), path, ttl); err != nil {
+		t.Fatalf("Cannot open bitmap: %s", err.Error())
+	}
+
+	return c
+} // func openTestBitmap(t testing.TB, path string, ttl time.Duration) *bitmapCache
+
+func TestBitmapCache(t *testing.T) {
+	var (
+		err   error
+		known bool
+		path  = filepath.Join(t.TempDir(), "cache.bitmap")
+		c     = openTestBitmap(t, path, 0)
+		addrs = []net.IP{
+			net.ParseIP("192.0.2.1"),
+			net.ParseIP("192.0.2.64"),
+			net.ParseIP("198.51.100.255"),
+			net.ParseIP("255.255.255.255"),
+			net.ParseIP("0.0.0.0"),
+		}
+	)
+
+	if !c.fresh {
+		t.Error("New bitmap is not flagged as fresh")
+	}
+
+	for _, a := range addrs {
+		if known, err = c.check(a); err != nil {
+			t.Fatalf("Cannot check %s: %s", a, err.Error())
+		} else if known {
+			t.Errorf("New address %s was found in the bitmap", a)
+		}
+	}
+
+	if known, err = c.check(net.ParseIP("192.0.2.2")); err != nil {
+		t.Fatalf("Cannot check address: %s", err.Error())
+	} else if known {
+		t.Error("Neighbour of a known address was found in the bitmap")
+	} else if _, err = c.check(net.ParseIP("2001:db8::1")); err == nil {
+		t.Error("Bitmap accepted an IPv6 address")
+	} else if err = c.close(); err != nil {
+		t.Fatalf("Cannot save bitmap: %s", err.Error())
+	}
+
+	c = openTestBitmap(t, path, 0)
+
+	if c.fresh {
+		t.Error("Loaded bitmap is flagged as fresh")
+	} else if cnt := c.entries.Load(); cnt != int64(len(addrs)+1) {
+		t.Errorf("Expected %d entries after loading, got %d", len(addrs)+1, cnt)
+	} else if mem := c.memSize(); mem != 4*bitmapShardSize {
+		t.Errorf("Expected 4 shards, got %d bytes", mem)
+	}
+
+	for _, a := range addrs {
+		if known, err = c.check(a); err != nil {
+			t.Fatalf("Cannot check %s: %s", a, err.Error())
+		} else if !known {
+			t.Errorf("Address %s was lost when saving the bitmap", a)
+		}
+	}
+} // func TestBitmapCache(t *testing.T)
+
+func TestBitmapCacheTTL(t *testing.T) {
+	var (
+		err   error
+		known bool
+		addr  = net.ParseIP("192.0.2.1")
+		c     = openTestBitmap(t, filepath.Join(t.TempDir(), "cache.bitmap"), time.Hour)
+	)
+
+	if _, err = c.check(addr); err != nil {
+		t.Fatalf("Cannot check %s: %s", addr, err.Error())
+	} else if err = c.maintain(); err != nil {
+		t.Fatalf("Maintenance failed: %s", err.Error())
+	} else if known, _ = c.check(addr); !known {
+		t.Fatalf("Address %s expired too early", addr)
+	}
+
+	c.created.Add(-7200)
+
+	if err = c.maintain(); err != nil {
+		t.Fatalf("Maintenance failed: %s", err.Error())
+	} else if known, _ = c.check(addr); known {
+		t.Errorf("Address %s did not expire", addr)
+	}
+} // func TestBitmapCacheTTL(t *testing.T)
+
+func TestSplitCache(t *testing.T) {
+	var (
+		err   error
+		known bool
+		dir   = t.TempDir()
+		other = openTestCache(t, 0)
+		v4    = net.ParseIP("192.0.2.1")
+		v6    = net.ParseIP("2001:db8::1")
+		c     = &splitCache{
+			v4:    openTestBitmap(t, filepath.Join(dir, "cache.bitmap"), 0),
+			other: other,
+		}
+	)
+
+	for _, a := range []net.IP{v4, v6, v4, v6} {
+		if _, err = c.check(a); err != nil {
+			t.Fatalf("Cannot check %s: %s", a, err.Error())
+		}
+	}
+
+	if s := c.v4.stats(); s.Lookups != 2 || s.Hits != 1 {
+		t.Errorf("Bitmap saw %d lookups and %d hits, expected 2 and 1", s.Lookups, s.Hits)
+	} else if s = other.stats(); s.Lookups != 2 || s.Hits != 1 {
+		t.Errorf("Badger saw %d lookups and %d hits, expected 2 and 1", s.Lookups, s.Hits)
+	} else if s = c.stats(); s.Backend != "bitmap/badger" || s.Lookups != 4 {
+		t.Errorf("Unexpected statistics: %#v", s)
+	}
+
+	if err = c.reset(); err != nil {
+		t.Fatalf("Cannot reset cache: %s", err.Error())
+	} else if known, _ = c.check(v4); known {
+		t.Errorf("%s is still known after a reset", v4)
+	} else if known, _ = c.check(v6); known {
+		t.Errorf("%s is still known after a reset", v6)
+	}
+} // func TestSplitCache(t *testing.T)
+
+// benchAddrs returns n random IPv4 addresses.
+func benchAddrs(n int) []net.IP {
+	var (
+		rng   = rand.New(rand.NewPCG(23, 42))
+		addrs = make([]net.IP, n)
+	)
+
+	for i := range addrs {
+		var a = make(net.IP, 4)
+		binary.BigEndian.PutUint32(a, rng.Uint32())
+		addrs[i] = a.To16()
+	}
+
+	return addrs
+} // func benchAddrs(n int) []net.IP
+
+func benchmarkCache(b *testing.B, c addrCache) {
+	var addrs = benchAddrs(1 << 16)
+
+	b.ResetTimer()
+
+	for i := range b.N {
+		if _, err := c.check(addrs[i%len(addrs)]); err != nil {
+			b.Fatalf("Cannot check address: %s", err.Error())
+		}
+	}
+} // func benchmarkCache(b *testing.B, c addrCache)
+
+func BenchmarkCacheBadger(b *testing.B) {
+	benchmarkCache(b, openTestCache(b, 0))
+} // func BenchmarkCacheBadger(b *testing.B)
+
+func BenchmarkCacheBitmap(b *testing.B) {
+	benchmarkCache(b, openTestBitmap(b, filepath.Join(b.TempDir(), "cache.bitmap"), 0))
+} // func BenchmarkCacheBitmap(b *testing.B)
@@ -0,0 +1,296 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/cache.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:30:45 krylon>
+
+package generator
+
+import (
+	"errors"
+	"log"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/logdomain"
+	"github.com/blicero/guangng/metrics"
+	"github.com/blicero/guangng/model"
+	"github.com/dgraph-io/badger"
+	"github.com/dgraph-io/badger/options"
+)
+
+// cacheMaintenanceInterval is how often the Generator performs housekeeping
+// on its address cache.
+const cacheMaintenanceInterval = time.Minute * 10
+
+// addrCache remembers which addresses the Generator has looked at already.
+// To add another backend, implement this interface and have openCache
+// return it.
+type addrCache interface {
+	// check returns true if addr is in the cache. If it is not, check
+	// adds it.
+	check(addr net.IP) (bool, error)
+	// maintain performs whatever housekeeping the backend needs, e.g.
+	// garbage collection.
+	maintain() error
+	stats() model.CacheStats
+	// reset removes all addresses from the cache.
+	reset() error
+	// save makes sure all addresses are written to disk.
+	save() error
+	close() error
+}
+
+// openCache opens the address cache. IPv4 addresses go into a bitmap, all
+// others into a Badger database. If ttl is not 0, addresses are forgotten
+// after that time, so the Generator will try them again.
+func openCache(ttl time.Duration) (addrCache, error) {
+	var (
+		err error
+		l   *log.Logger
+		c   splitCache
+		bc  *badgerCache
+	)
+
+	if l, err = common.GetLogger(logdomain.IPCache); err != nil {
+		return nil, err
+	} else if c.v4, err = openBitmapCache(l, common.BitmapPath, ttl); err != nil {
+		return nil, err
+	} else if bc, err = openBadgerCache(l, common.CachePath, ttl); err != nil {
+		return nil, err
+	}
+
+	c.other = bc
+
+	// Before we had the bitmap, IPv4 addresses went into Badger, too.
+	if c.v4.fresh {
+		var cnt int64
+
+		err = bc.each(func(addr net.IP) {
+			if known, _ := c.v4.set(addr); !known && addr.To4() != nil {
+				cnt++
+			}
+		})
+
+		if err != nil {
+			l.Printf("[ERROR] Failed to copy IPv4 addresses from Badger: %s\n",
+				err.Error())
+		} else if cnt > 0 {
+			l.Printf("[INFO] Copied %d IPv4 addresses from Badger to the bitmap.\n",
+				cnt)
+			if err = c.v4.save(); err != nil {
+				return nil, err
+			} else if err = bc.dropIPv4(); err != nil {
+				l.Printf("[ERROR] Failed to remove IPv4 addresses from Badger: %s\n",
+					err.Error())
+			}
+		}
+	}
+
+	return &c, nil
+} // func openCache(ttl time.Duration) (addrCache, error)
+
+// badgerCache is an address cache that stores addresses in a Badger
+// database.
+type badgerCache struct {
+	log     *log.Logger
+	db      *badger.DB
+	ttl     time.Duration
+	lookups atomic.Int64
+	hits    atomic.Int64
+	entries atomic.Int64
+	lastGC  atomic.Int64
+}
+
+func openBadgerCache(l *log.Logger, path string, ttl time.Duration) (*badgerCache, error) {
+	var (
+		err     error
+		opt     = badger.DefaultOptions(path)
+		ipcache = &badgerCache{log: l, ttl: ttl}
+	)
+
+	// By default, Badger mmaps value log files of up to 1 GiB, which
+	// exhausts the address space of 32-bit systems in short order.
+	if strconv.IntSize == 32 {
+		opt = opt.WithTableLoadingMode(options.FileIO).
+			WithValueLogLoadingMode(options.FileIO).
+			WithValueLogFileSize(64 << 20)
+	}
+
+	if ipcache.db, err = badger.Open(opt); err != nil {
+		ipcache.log.Printf("[ERROR] Failed to open IP cache at %s: %s\n",
+			path,
+			err.Error())
+		return nil, err
+	}
+
+	return ipcache, nil
+} // func openBadgerCache(l *log.Logger, path string, ttl time.Duration) (*badgerCache, error)
+
+func (c *badgerCache) check(addr net.IP) (bool, error) {
+	var (
+		err     error
+		present bool
+	)
+
+	c.lookups.Add(1)
+
+	err = c.db.Update(func(tx *badger.Txn) error {
+		if _, err := tx.Get(addr); err == badger.ErrKeyNotFound {
+			var e = badger.NewEntry(addr, []byte{0x1})
+
+			if c.ttl > 0 {
+				e = e.WithTTL(c.ttl)
+			}
+
+			if err = tx.SetEntry(e); err != nil {
+				c.log.Printf("[ERROR] Failed to add address %s to cache: %s\n",
+					addr,
+					err.Error())
+				return err
+			}
+
+		} else if err != nil {
+			c.log.Printf("[ERROR] Failed to lookup %s in cache: %s\n",
+				addr,
+				err.Error())
+			return err
+		} else {
+			present = true
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return false, err
+	} else if present {
+		c.hits.Add(1)
+		metrics.CacheHits.Inc()
+	} else {
+		c.entries.Add(1)
+	}
+
+	return present, nil
+} // func (c *badgerCache) check(addr net.IP) (bool, error)
+
+// maintain runs the garbage collection on Badger's value log until there is
+// nothing left to reclaim, and counts the entries in the cache. Badger does
+// not do either on its own.
+func (c *badgerCache) maintain() error {
+	const discardRatio = 0.5
+	var (
+		err error
+		cnt int64
+	)
+
+	// Each run rewrites at most one file of the value log.
+	for err == nil {
+		err = c.db.RunValueLogGC(discardRatio)
+	}
+
+	if !errors.Is(err, badger.ErrNoRewrite) {
+		c.log.Printf("[ERROR] Garbage collection on IP cache failed: %s\n",
+			err.Error())
+		return err
+	}
+
+	c.lastGC.Store(time.Now().Unix())
+
+	err = c.db.View(func(tx *badger.Txn) error {
+		var opt = badger.DefaultIteratorOptions
+		opt.PrefetchValues = false
+
+		var iter = tx.NewIterator(opt)
+		defer iter.Close()
+
+		for iter.Rewind(); iter.Valid(); iter.Next() {
+			cnt++
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		c.log.Printf("[ERROR] Failed to count entries in IP cache: %s\n",
+			err.Error())
+		return err
+	}
+
+	c.entries.Store(cnt)
+	return nil
+} // func (c *badgerCache) maintain() error
+
+func (c *badgerCache) stats() model.CacheStats {
+	var (
+		lsm, vlog = c.db.Size()
+		s         = model.CacheStats{
+			Backend:  "badger",
+			Entries:  c.entries.Load(),
+			DiskSize: lsm + vlog,
+			TTL:      c.ttl,
+			Lookups:  c.lookups.Load(),
+			Hits:     c.hits.Load(),
+		}
+	)
+
+	if ts := c.lastGC.Load(); ts != 0 {
+		s.LastGC = time.Unix(ts, 0)
+	}
+
+	return s
+} // func (c *badgerCache) stats() model.CacheStats
+
+func (c *badgerCache) reset() error {
+	if err := c.db.DropAll(); err != nil {
+		c.log.Printf("[ERROR] Failed to clear IP cache: %s\n",
+			err.Error())
+		return err
+	}
+
+	c.entries.Store(0)
+	c.lookups.Store(0)
+	c.hits.Store(0)
+	c.log.Println("[INFO] IP cache was cleared.")
+	return nil
+} // func (c *badgerCache) reset() error
+
+// each calls fn for every address in the cache.
+func (c *badgerCache) each(fn func(addr net.IP)) error {
+	return c.db.View(func(tx *badger.Txn) error {
+		var opt = badger.DefaultIteratorOptions
+		opt.PrefetchValues = false
+
+		var iter = tx.NewIterator(opt)
+		defer iter.Close()
+
+		for iter.Rewind(); iter.Valid(); iter.Next() {
+			fn(net.IP(iter.Item().KeyCopy(nil)))
+		}
+
+		return nil
+	})
+} // func (c *badgerCache) each(fn func(addr net.IP)) error
+
+// dropIPv4 removes all IPv4 addresses from the cache.
+func (c *badgerCache) dropIPv4() error {
+	// net.IP stores IPv4 addresses with the IPv4-in-IPv6 prefix.
+	var prefix = net.IPv4zero.To16()[:12]
+
+	if err := c.db.DropPrefix(prefix); err != nil {
+		return err
+	}
+
+	return c.maintain()
+} // func (c *badgerCache) dropIPv4() error
+
+func (c *badgerCache) save() error {
+	return c.db.Sync()
+} // func (c *badgerCache) save() error
+
+func (c *badgerCache) close() error {
+	return c.db.Close()
+} // func (c *badgerCache) close() error
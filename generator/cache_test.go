@@ -0,0 +1,103 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/cache_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:30:45 krylon>
+
+package generator
+
+import (
+	"log"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func openTestCache(t testing.TB, ttl time.Duration) *badgerCache {
+	var (
+		err error
+		c   *badgerCache
+	)
+
+	if c, err = openBadgerCache(log.New(os.Stderr, "", 0), t.TempDir(), ttl); err != nil {
+		t.Fatalf("Cannot open cache: %s", err.Error())
+	}
+
+	t.Cleanup(func() { c.close() }) // nolint: errcheck
+
+	return c
+} // func openTestCache(t testing.TB, ttl time.Duration) *badgerCache
+
+func TestBadgerCache(t *testing.T) {
+	var (
+		err   error
+		known bool
+		c     = openTestCache(t, 0)
+		addrs = []net.IP{
+			net.ParseIP("192.0.2.1"),
+			net.ParseIP("192.0.2.2"),
+			net.ParseIP("2001:db8::1"),
+		}
+	)
+
+	for _, a := range addrs {
+		if known, err = c.check(a); err != nil {
+			t.Fatalf("Cannot check %s: %s", a, err.Error())
+		} else if known {
+			t.Errorf("New address %s was found in the cache", a)
+		}
+	}
+
+	if known, err = c.check(addrs[0]); err != nil {
+		t.Fatalf("Cannot check %s: %s", addrs[0], err.Error())
+	} else if !known {
+		t.Errorf("Address %s was not found in the cache", addrs[0])
+	} else if err = c.maintain(); err != nil {
+		t.Fatalf("Cache maintenance failed: %s", err.Error())
+	}
+
+	var s = c.stats()
+
+	if s.Entries != int64(len(addrs)) {
+		t.Errorf("Expected %d entries, got %d", len(addrs), s.Entries)
+	} else if s.Lookups != 4 || s.Hits != 1 {
+		t.Errorf("Expected 4 lookups and 1 hit, got %d and %d", s.Lookups, s.Hits)
+	} else if s.LastGC.IsZero() {
+		t.Error("Time of last maintenance was not recorded")
+	}
+
+	if err = c.reset(); err != nil {
+		t.Fatalf("Cannot reset cache: %s", err.Error())
+	} else if known, err = c.check(addrs[0]); err != nil {
+		t.Fatalf("Cannot check %s: %s", addrs[0], err.Error())
+	} else if known {
+		t.Errorf("Address %s is still in the cache after a reset", addrs[0])
+	}
+} // func TestBadgerCache(t *testing.T)
+
+func TestBadgerCacheTTL(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	var (
+		err   error
+		known bool
+		c     = openTestCache(t, time.Second)
+		addr  = net.ParseIP("192.0.2.1")
+	)
+
+	if _, err = c.check(addr); err != nil {
+		t.Fatalf("Cannot check %s: %s", addr, err.Error())
+	}
+
+	// Badger stores expiry times with a resolution of one second.
+	time.Sleep(time.Second * 2)
+
+	if known, err = c.check(addr); err != nil {
+		t.Fatalf("Cannot check %s: %s", addr, err.Error())
+	} else if known {
+		t.Errorf("Address %s did not expire", addr)
+	}
+} // func TestBadgerCacheTTL(t *testing.T)
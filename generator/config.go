@@ -0,0 +1,73 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/config.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:40:01 krylon>
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blicero/guangng/model/genmode"
+)
+
+// Config holds the settings of a Generator.
+type Config struct {
+	// AddrWorkers is the number of goroutines generating IP addresses.
+	AddrWorkers int
+	// NameWorkers is the number of goroutines resolving addresses and
+	// checking the names we get.
+	NameWorkers int
+	// Mode determines how addresses are generated.
+	Mode genmode.Mode
+	// Seed selects the permutation to walk in Permutation mode. If it is
+	// 0, the Generator resumes the one it worked on last or picks a
+	// random seed.
+	Seed int64
+	// Explore is the share of addresses drawn uniformly from the whole
+	// address space in Random mode, the rest is drawn from networks that
+	// have yielded Hosts before. An Explore of 1 disables the bias.
+	Explore float64
+	// Expand is the prefix length of the network around each Host we find
+	// in Random mode, which we look at before we draw new addresses. 0
+	// disables the expansion.
+	Expand int
+	// CacheTTL is how long we remember having looked at an address in
+	// Random mode. 0 means forever.
+	CacheTTL time.Duration
+	// AsyncPTR enables the asynchronous resolver, which keeps up to
+	// PTRInflight queries in flight.
+	AsyncPTR    bool
+	PTRInflight int
+}
+
+// validate returns an error if the settings in cfg do not make sense.
+func (cfg *Config) validate() error {
+	switch {
+	case cfg.AddrWorkers < 0 || cfg.NameWorkers < 0:
+		return fmt.Errorf("worker counts must not be negative, not %d and %d",
+			cfg.AddrWorkers,
+			cfg.NameWorkers)
+	case (cfg.AddrWorkers == 0) != (cfg.NameWorkers == 0 && !cfg.AsyncPTR):
+		return errors.New("there must be workers to generate addresses and to resolve them, or neither")
+	case cfg.Seed < 0:
+		return fmt.Errorf("seed must not be negative, not %d", cfg.Seed)
+	case !(cfg.Explore >= 0 && cfg.Explore <= 1):
+		return fmt.Errorf("exploration ratio must be between 0 and 1, not %f", cfg.Explore)
+	case cfg.Expand != 0 && (cfg.Expand < expandMinBits || cfg.Expand > expandMaxBits):
+		return fmt.Errorf("prefix length for expansion must be between %d and %d, not %d",
+			expandMinBits,
+			expandMaxBits,
+			cfg.Expand)
+	case cfg.CacheTTL < 0:
+		return fmt.Errorf("cache TTL must not be negative, not %s", cfg.CacheTTL)
+	case cfg.AsyncPTR && cfg.PTRInflight < 1:
+		return fmt.Errorf("number of PTR queries in flight must be at least 1, not %d",
+			cfg.PTRInflight)
+	}
+
+	return nil
+} // func (cfg *Config) validate() error
@@ -0,0 +1,61 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/config_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:40:01 krylon>
+
+package generator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/blicero/guangng/model/genmode"
+)
+
+func TestConfigValidate(t *testing.T) {
+	var base = Config{
+		AddrWorkers: 2,
+		NameWorkers: 8,
+		Mode:        genmode.Random,
+		Explore:     0.25,
+	}
+
+	for _, c := range []struct {
+		name string
+		edit func(cfg *Config)
+		ok   bool
+	}{
+		{"default", func(cfg *Config) {}, true},
+		{"no workers", func(cfg *Config) { cfg.AddrWorkers, cfg.NameWorkers = 0, 0 }, true},
+		{"async only", func(cfg *Config) { cfg.NameWorkers, cfg.AsyncPTR, cfg.PTRInflight = 0, true, 64 }, true},
+		{"no resolvers", func(cfg *Config) { cfg.NameWorkers = 0 }, false},
+		{"no address workers", func(cfg *Config) { cfg.AddrWorkers = 0 }, false},
+		{"negative workers", func(cfg *Config) { cfg.NameWorkers = -1 }, false},
+		{"negative seed", func(cfg *Config) { cfg.Seed = -1 }, false},
+		{"explore 0", func(cfg *Config) { cfg.Explore = 0 }, true},
+		{"explore 1", func(cfg *Config) { cfg.Explore = 1 }, true},
+		{"explore too small", func(cfg *Config) { cfg.Explore = -0.1 }, false},
+		{"explore too big", func(cfg *Config) { cfg.Explore = 1.5 }, false},
+		{"explore NaN", func(cfg *Config) { cfg.Explore = math.NaN() }, false},
+		{"expand", func(cfg *Config) { cfg.Expand = 24 }, true},
+		{"expand too small", func(cfg *Config) { cfg.Expand = expandMinBits - 1 }, false},
+		{"expand too big", func(cfg *Config) { cfg.Expand = expandMaxBits + 1 }, false},
+		{"cache TTL", func(cfg *Config) { cfg.CacheTTL = time.Hour }, true},
+		{"negative cache TTL", func(cfg *Config) { cfg.CacheTTL = -time.Hour }, false},
+		{"async", func(cfg *Config) { cfg.AsyncPTR, cfg.PTRInflight = true, 1 }, true},
+		{"async without queries", func(cfg *Config) { cfg.AsyncPTR = true }, false},
+		{"async negative", func(cfg *Config) { cfg.AsyncPTR, cfg.PTRInflight = true, -4 }, false},
+	} {
+		var cfg = base
+
+		c.edit(&cfg)
+
+		if err := cfg.validate(); c.ok && err != nil {
+			t.Errorf("Config %q was rejected: %s", c.name, err.Error())
+		} else if !c.ok && err == nil {
+			t.Errorf("Config %q was accepted", c.name)
+		}
+	}
+} // func TestConfigValidate(t *testing.T)
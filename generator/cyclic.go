@@ -0,0 +1,129 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/cyclic.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 23:27:05 krylon>
+
+package generator
+
+import (
+	"math/bits"
+	"math/rand/v2"
+)
+
+// To walk the IPv4 address space in a pseudo-random order without
+// remembering where we have been, we do what zmap does: We iterate over
+// the multiplicative group of integers modulo a prime p slightly larger
+// than 2^32. Starting at some element x, we keep multiplying by a generator
+// g of the group, which visits every element 1..p-1 exactly once before
+// coming back to x. Element n maps to the address n-1, elements that are
+// too large for an IPv4 address are skipped.
+const (
+	// groupPrime is the smallest prime larger than 2^32.
+	groupPrime uint64 = 1<<32 + 15
+	// groupOrder is the number of elements in the group.
+	groupOrder = groupPrime - 1
+	// groupRoot is a primitive root modulo groupPrime, i.e. a generator
+	// of the group.
+	groupRoot uint64 = 3
+)
+
+// groupFactors are the distinct prime factors of groupOrder.
+var groupFactors = []uint64{2, 3, 5, 131, 364289}
+
+// cyclicGroup is a walk through the group, determined by the generator we
+// multiply by and the element we started with.
+type cyclicGroup struct {
+	root  uint64
+	cur   uint64
+	count uint64
+}
+
+// newCyclicGroup derives a walk from a seed. The same seed always yields the
+// same walk.
+func newCyclicGroup(seed int64) *cyclicGroup {
+	var (
+		exp uint64
+		rng = rand.New(rand.NewPCG(uint64(seed), uint64(seed)^0x9e3779b97f4a7c15)) // nolint: gosec
+	)
+
+	// groupRoot^k is a generator as well iff k is coprime to the order of
+	// the group.
+	for {
+		exp = rng.Uint64N(groupOrder-1) + 1
+		if gcd(exp, groupOrder) == 1 {
+			break
+		}
+	}
+
+	return &cyclicGroup{
+		root: powMod(groupRoot, exp, groupPrime),
+		cur:  rng.Uint64N(groupOrder) + 1,
+	}
+} // func newCyclicGroup(seed int64) *cyclicGroup
+
+// next returns the next IPv4 address in the walk, as an integer. Once all
+// addresses have been visited, ok is false.
+func (c *cyclicGroup) next() (addr uint32, ok bool) {
+	for c.count < groupOrder {
+		var n = c.cur
+
+		c.cur = mulMod(c.cur, c.root, groupPrime)
+		c.count++
+
+		if n-1 <= 0xffffffff {
+			return uint32(n - 1), true
+		}
+	}
+
+	return 0, false
+} // func (c *cyclicGroup) next() (addr uint32, ok bool)
+
+// done returns the share of the group we have visited, from 0 to 1.
+func (c *cyclicGroup) done() float64 {
+	return float64(c.count) / float64(groupOrder)
+} // func (c *cyclicGroup) done() float64
+
+// isGenerator returns true if g generates the whole group.
+func isGenerator(g uint64) bool {
+	if g == 0 || g >= groupPrime {
+		return false
+	}
+
+	for _, q := range groupFactors {
+		if powMod(g, groupOrder/q, groupPrime) == 1 {
+			return false
+		}
+	}
+
+	return true
+} // func isGenerator(g uint64) bool
+
+func mulMod(a, b, m uint64) uint64 {
+	var hi, lo = bits.Mul64(a, b)
+	return bits.Rem64(hi, lo, m)
+} // func mulMod(a, b, m uint64) uint64
+
+func powMod(base, exp, m uint64) uint64 {
+	var res uint64 = 1
+
+	base %= m
+
+	for exp > 0 {
+		if exp&1 == 1 {
+			res = mulMod(res, base, m)
+		}
+		base = mulMod(base, base, m)
+		exp >>= 1
+	}
+
+	return res
+} // func powMod(base, exp, m uint64) uint64
+
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+} // func gcd(a, b uint64) uint64
@@ -0,0 +1,90 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/cyclic_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 23:58:12 krylon>
+
+package generator
+
+import "testing"
+
+func TestGroupRoot(t *testing.T) {
+	if !isGenerator(groupRoot) {
+		t.Fatalf("%d is not a generator of the group", groupRoot)
+	} else if isGenerator(1) || isGenerator(groupPrime-1) {
+		t.Error("isGenerator accepts elements of small order")
+	}
+
+	for seed := range int64(32) {
+		var grp = newCyclicGroup(seed)
+
+		if !isGenerator(grp.root) {
+			t.Errorf("Seed %d yields %d, which is not a generator", seed, grp.root)
+		} else if grp.cur == 0 || grp.cur >= groupPrime {
+			t.Errorf("Seed %d yields invalid start element %d", seed, grp.cur)
+		}
+	}
+} // func TestGroupRoot(t *testing.T)
+
+func TestCyclicWalk(t *testing.T) {
+	const steps = 1 << 18
+	var (
+		a    = newCyclicGroup(42)
+		b    = newCyclicGroup(42)
+		c    = newCyclicGroup(43)
+		seen = make(map[uint32]bool, steps)
+		same = 0
+	)
+
+	for i := range steps {
+		var (
+			x, okA = a.next()
+			y, okB = b.next()
+			z, _   = c.next()
+		)
+
+		if !okA || !okB {
+			t.Fatalf("Walk ended after %d steps", i)
+		} else if x != y {
+			t.Fatalf("Walks with the same seed differ at step %d: %d != %d", i, x, y)
+		} else if seen[x] {
+			t.Fatalf("Address %d was generated twice within %d steps", x, i)
+		} else if x == z {
+			same++
+		}
+
+		seen[x] = true
+	}
+
+	if same > steps/1000 {
+		t.Errorf("Walks with different seeds agree on %d of %d addresses", same, steps)
+	}
+} // func TestCyclicWalk(t *testing.T)
+
+func TestCyclicResume(t *testing.T) {
+	var grp = newCyclicGroup(1337)
+
+	for range 1000 {
+		grp.next()
+	}
+
+	// This is what we would load from the Database after a restart.
+	var resumed = &cyclicGroup{root: grp.root, cur: grp.cur, count: grp.count}
+
+	for i := range 1000 {
+		var x, _ = grp.next()
+		var y, _ = resumed.next()
+
+		if x != y {
+			t.Fatalf("Resumed walk differs at step %d: %d != %d", i, x, y)
+		}
+	}
+
+	// Near the end of the group, the walk has to stop.
+	resumed.count = groupOrder - 1
+	resumed.next()
+
+	if _, ok := resumed.next(); ok {
+		t.Error("Walk did not end after visiting every element")
+	}
+} // func TestCyclicResume(t *testing.T)
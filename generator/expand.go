@@ -0,0 +1,176 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/expand.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-21 12:58:10 krylon>
+
+package generator
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+)
+
+// When we find a Host with a proper name, its neighbours are likely to have
+// proper names, too. The expander keeps a queue of the neighbourhoods of
+// such Hosts and hands out their addresses before the address workers
+// generate new ones. Once a neighbourhood has not given us a proper name
+// for a while, we leave it alone.
+const (
+	// expandPatience is the number of lookups in a row that may fail or
+	// yield generic or blacklisted names before we give up on a
+	// neighbourhood.
+	expandPatience = 16
+	// expandMaxQueued is the number of neighbourhoods we queue at most.
+	expandMaxQueued = 64
+	// expandMaxSeen is the number of neighbourhoods we remember having
+	// expanded before we start over.
+	expandMaxSeen = 1 << 16
+	// expandMinBits and expandMaxBits limit the prefix length of the
+	// neighbourhoods.
+	expandMinBits = 16
+	expandMaxBits = 30
+)
+
+type neighbourhood struct {
+	base      uint32
+	next      uint32 // Offset of the next address to hand out
+	sinceGood int
+	abandoned bool
+}
+
+type expander struct {
+	lock   sync.Mutex
+	log    *log.Logger
+	mask   uint32
+	size   uint32
+	queue  []*neighbourhood
+	active map[uint32]*neighbourhood
+	seen   map[uint32]bool
+}
+
+// newExpander creates an expander for neighbourhoods of the given prefix
+// length.
+func newExpander(l *log.Logger, bits int) (*expander, error) {
+	if bits < expandMinBits || bits > expandMaxBits {
+		return nil, fmt.Errorf("prefix length for expansion must be between %d and %d, not %d",
+			expandMinBits,
+			expandMaxBits,
+			bits)
+	}
+
+	var e = &expander{
+		log:    l,
+		mask:   ^uint32(0) << (32 - bits),
+		size:   1 << (32 - bits),
+		queue:  make([]*neighbourhood, 0, expandMaxQueued),
+		active: make(map[uint32]*neighbourhood),
+		seen:   make(map[uint32]bool),
+	}
+
+	return e, nil
+} // func newExpander(l *log.Logger, bits int) (*expander, error)
+
+func addrInt(addr net.IP) (uint32, bool) {
+	var ip4 = addr.To4()
+
+	if ip4 == nil {
+		return 0, false
+	}
+
+	return binary.BigEndian.Uint32(ip4), true
+} // func addrInt(addr net.IP) (uint32, bool)
+
+// add queues the neighbourhood of addr, unless we have done so before.
+func (e *expander) add(addr net.IP) {
+	var v, ok = addrInt(addr)
+
+	if !ok {
+		return
+	}
+
+	var base = v & e.mask
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.seen[base] {
+		return
+	} else if len(e.queue) >= expandMaxQueued {
+		e.log.Printf("[TRACE] Expansion queue is full, not expanding around %s\n",
+			addr)
+		return
+	} else if len(e.seen) >= expandMaxSeen {
+		clear(e.seen)
+	}
+
+	var n = &neighbourhood{base: base}
+
+	e.seen[base] = true
+	e.active[base] = n
+	e.queue = append(e.queue, n)
+} // func (e *expander) add(addr net.IP)
+
+// next returns the next address of the neighbourhoods we are expanding. If
+// there are none, ok is false.
+func (e *expander) next() (addr net.IP, ok bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	for len(e.queue) > 0 {
+		var n = e.queue[0]
+
+		if n.abandoned || n.next >= e.size {
+			e.queue = e.queue[1:]
+			delete(e.active, n.base)
+			continue
+		}
+
+		addr = make(net.IP, 4)
+		binary.BigEndian.PutUint32(addr, n.base+n.next)
+		n.next++
+
+		return addr.To16(), true
+	}
+
+	return nil, false
+} // func (e *expander) next() (addr net.IP, ok bool)
+
+// report tells the expander whether looking up an address gave us a Host
+// with a proper name. If the address belongs to a neighbourhood we expand,
+// and that neighbourhood has not given us a proper name for a while, we
+// abandon it.
+func (e *expander) report(addr net.IP, good bool) {
+	var v, ok = addrInt(addr)
+
+	if !ok {
+		return
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	var n = e.active[v&e.mask]
+
+	if n == nil || n.abandoned {
+		return
+	} else if good {
+		n.sinceGood = 0
+	} else if n.sinceGood++; n.sinceGood >= expandPatience {
+		n.abandoned = true
+		e.log.Printf("[DEBUG] Abandoning neighbourhood of %s after %d lookups without a proper name\n",
+			addr,
+			n.sinceGood)
+	}
+} // func (e *expander) report(addr net.IP, good bool)
+
+// queued returns the number of neighbourhoods waiting to be expanded.
+func (e *expander) queued() int {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return len(e.queue)
+} // func (e *expander) queued() int
@@ -0,0 +1,100 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/expand_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-21 13:24:50 krylon>
+
+package generator
+
+import (
+	"log"
+	"net"
+	"os"
+	"testing"
+)
+
+func TestGenericName(t *testing.T) {
+	for _, c := range []struct {
+		addr, name string
+		generic    bool
+	}{
+		{"10.1.2.3", "10-1-2-3.example.net", true},
+		{"10.1.2.3", "static.3.2.1.10.clients.example.com", true},
+		{"10.1.2.3", "host-010-001-002-003.example.org", true},
+		{"10.1.2.3", "0a010203.example.org", true},
+		{"10.1.2.3", "pool-77.dynamic.example.net", true},
+		{"10.1.2.3", "mail.example.com", false},
+		{"10.1.2.3", "www2.example.com", false},
+		{"10.1.2.3", "server1.ns.example.org", false},
+	} {
+		if g := isGenericName(net.ParseIP(c.addr), c.name); g != c.generic {
+			t.Errorf("isGenericName(%s, %s) = %t, expected %t",
+				c.addr,
+				c.name,
+				g,
+				c.generic)
+		}
+	}
+} // func TestGenericName(t *testing.T)
+
+func TestExpander(t *testing.T) {
+	var (
+		err  error
+		e    *expander
+		addr net.IP
+		ok   bool
+		hit  = net.ParseIP("10.1.2.99")
+	)
+
+	if _, err = newExpander(nil, 8); err == nil {
+		t.Error("Expander accepted prefix length 8")
+	} else if e, err = newExpander(log.New(os.Stderr, "", 0), 28); err != nil {
+		t.Fatalf("Cannot create expander: %s", err.Error())
+	}
+
+	e.add(hit)
+	e.add(hit)
+
+	if n := e.queued(); n != 1 {
+		t.Fatalf("Expected 1 queued neighbourhood, got %d", n)
+	}
+
+	for i := range 16 {
+		if addr, ok = e.next(); !ok {
+			t.Fatalf("Neighbourhood ran out after %d addresses", i)
+		} else if !addr.Equal(net.IPv4(10, 1, 2, byte(96+i))) {
+			t.Errorf("Address #%d of the neighbourhood is %s", i, addr)
+		}
+	}
+
+	if addr, ok = e.next(); ok {
+		t.Errorf("Neighbourhood of a /28 yielded a 17th address: %s", addr)
+	}
+
+	// We have been there already.
+	e.add(hit)
+
+	if _, ok = e.next(); ok {
+		t.Error("Neighbourhood was expanded twice")
+	}
+
+	// A /24 does not run out of addresses before we lose patience.
+	if e, err = newExpander(log.New(os.Stderr, "", 0), 24); err != nil {
+		t.Fatalf("Cannot create expander: %s", err.Error())
+	}
+
+	e.add(net.ParseIP("10.1.3.1"))
+
+	for i := range expandPatience {
+		if addr, ok = e.next(); !ok {
+			t.Fatalf("Neighbourhood ran out after %d addresses", i)
+		}
+		e.report(addr, false)
+	}
+
+	if addr, ok = e.next(); ok {
+		t.Errorf("Neighbourhood was not abandoned after %d bad names, got %s",
+			expandPatience,
+			addr)
+	}
+} // func TestExpander(t *testing.T)
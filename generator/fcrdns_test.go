@@ -0,0 +1,71 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/fcrdns_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:25:10 krylon>
+
+package generator
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/blicero/guangng/blacklist"
+)
+
+func TestMkHost(t *testing.T) {
+	var (
+		gen   = &Generator{blName: blacklist.NewBlacklistName()}
+		addr  = net.ParseIP("192.0.2.17")
+		other = net.ParseIP("192.0.2.99")
+		fwd   = map[string][]net.IP{
+			"dsl-17.wintermute.test.": {addr},
+			"www.straylight.test.":    {other},
+			"mail.straylight.test.":   {other, addr},
+		}
+	)
+
+	defer func(f func(string) ([]net.IP, error)) { lookupIP = f }(lookupIP)
+
+	lookupIP = func(name string) ([]net.IP, error) {
+		if addrs, ok := fwd[name]; ok {
+			return addrs, nil
+		}
+
+		return nil, errors.New("no such host")
+	}
+
+	var host = gen.mkHost(addr, []string{
+		"dsl-17.wintermute.test.",
+		"www.straylight.test.",
+		"nowhere.straylight.test.",
+		"mail.straylight.test.",
+	})
+
+	if host == nil {
+		t.Fatal("mkHost returned nil")
+	} else if len(host.Names) != 3 {
+		t.Fatalf("Expected 3 names, got %d", len(host.Names))
+	} else if host.Name != "mail.straylight.test." || !host.Confirmed {
+		t.Errorf("Expected the confirmed name to be the primary one, got %s (%t)",
+			host.Name,
+			host.Confirmed)
+	} else if host.Names[0].Confirmed || host.Names[1].Confirmed || !host.Names[2].Confirmed {
+		t.Error("Names were not flagged correctly")
+	}
+
+	if zones := host.Zones(); len(zones) != 1 || zones[0] != "straylight.test" {
+		t.Errorf("Unexpected zones: %v", zones)
+	}
+
+	if host = gen.mkHost(addr, []string{"nowhere.straylight.test."}); host == nil {
+		t.Fatal("mkHost returned nil")
+	} else if host.Confirmed || host.Name != "nowhere.straylight.test." {
+		t.Errorf("Unexpected name %s (%t)", host.Name, host.Confirmed)
+	}
+
+	if host = gen.mkHost(addr, []string{"dsl-17.wintermute.test."}); host != nil {
+		t.Errorf("Expected nil for a blacklisted name, got %s", host.Name)
+	}
+} // func TestMkHost(t *testing.T)
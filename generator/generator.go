@@ -2,16 +2,18 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 12. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-02-03 20:11:18 krylon>
+// Time-stamp: <2026-10-19 15:40:01 krylon>
 
 package generator
 
 import (
-	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"log"
 	"net"
-	"regexp"
-	"strings"
+	"os"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -19,74 +21,32 @@ import (
 	"github.com/blicero/guangng/blacklist"
 	"github.com/blicero/guangng/common"
 	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/events"
 	"github.com/blicero/guangng/logdomain"
+	"github.com/blicero/guangng/metrics"
 	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/genmode"
 	"github.com/blicero/guangng/model/hsrc"
+	"github.com/blicero/guangng/model/outcome"
 	"github.com/blicero/guangng/model/subsystem"
-	"github.com/dgraph-io/badger"
+	"github.com/blicero/guangng/psl"
 )
 
-type cache struct {
-	log *log.Logger
-	db  *badger.DB
-}
-
-func openCache() (*cache, error) {
-	var (
-		err     error
-		ipcache = new(cache)
-	)
-
-	if ipcache.log, err = common.GetLogger(logdomain.IPCache); err != nil {
-		return nil, err
-	} else if ipcache.db, err = badger.Open(badger.DefaultOptions(common.CachePath)); err != nil {
-		ipcache.log.Printf("[ERROR] Failed to open IP cache at %s: %s\n",
-			common.CachePath,
-			err.Error())
-		return nil, err
-	}
-
-	return ipcache, nil
-} // func openCache() (*cache, error)
-
-func (c *cache) check(addr net.IP) (bool, error) {
-	var (
-		err     error
-		present bool
-	)
-
-	err = c.db.Update(func(tx *badger.Txn) error {
-		if _, err := tx.Get(addr); err == badger.ErrKeyNotFound {
-			var val = []byte{0x1}
-			if err = tx.Set(addr, val); err != nil {
-				c.log.Printf("[ERROR] Failed to add address %s to cache: %s\n",
-					addr,
-					err.Error())
-				return err
-			}
-
-		} else if err != nil {
-			c.log.Printf("[ERROR] Failed to lookup %s in cache: %s\n",
-				addr,
-				err.Error())
-			return err
-		} else {
-			present = true
-		}
-
-		return nil
-	})
-
-	return present, err
-} // func (c *cache) check(addr net.IP) bool
-
 // Generator generates random Hosts, checking them against blacklists
 // and ensuring that the IP address resolves to a valid PTR (i.e. that the
 // generated Host is likely to exist on the Internet).
 type Generator struct {
 	log                      *log.Logger
 	lock                     sync.RWMutex
-	cache                    *cache
+	mode                     genmode.Mode
+	cache                    addrCache
+	perm                     *permutation
+	yield                    *yieldTracker
+	expand                   *expander
+	res                      *resolver
+	resServers               []string
+	resInflight              int
+	outcomes                 *outcomeStats
 	blAddr                   *blacklist.BlacklistAddr
 	blName                   *blacklist.BlacklistName
 	ipQ                      chan net.IP
@@ -99,37 +59,94 @@ type Generator struct {
 	ctlQName                 chan bool
 }
 
-// New creates a new Generator.
-// iCnt is the number of goroutines to spawn for generating IP addresses
-// wCnt is the number of goroutines to spawn for resolving and checking hostnames.
-func New(icnt, ncnt int) (*Generator, error) {
+// New creates a new Generator with the settings in cfg.
+func New(cfg Config) (*Generator, error) {
 	var (
 		err error
 		gen = &Generator{
-			// iCnt: icnt,
-			// nCnt: ncnt,
+			mode:     cfg.Mode,
+			outcomes: newOutcomeStats(),
 		}
 	)
 
-	if (icnt == 0) != (ncnt == 0) {
-		panic("Worker counts must both be non-zero or both be zero")
-	}
-
-	gen.addrGenGoal.Store(int64(icnt))
-	gen.nameGenGoal.Store(int64(ncnt))
+	gen.addrGenGoal.Store(int64(cfg.AddrWorkers))
+	gen.nameGenGoal.Store(int64(cfg.NameWorkers))
 
 	if gen.log, err = common.GetLogger(logdomain.Generator); err != nil {
 		return nil, err
-	} else if gen.cache, err = openCache(); err != nil {
-		gen.log.Printf("[ERROR] Failed to open cache: %s\n",
+	} else if err = cfg.validate(); err != nil {
+		gen.log.Printf("[ERROR] Invalid Generator configuration: %s\n",
 			err.Error())
 		return nil, err
+	} else if gen.yield, err = newYieldTracker(gen.log, cfg.Explore); err != nil {
+		gen.log.Printf("[ERROR] Failed to load yield statistics: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	if err = psl.Load(common.PSLPath); errors.Is(err, os.ErrNotExist) {
+		gen.log.Printf("[DEBUG] Using the built-in Public Suffix List, there is none at %s\n",
+			common.PSLPath)
+	} else if err != nil {
+		gen.log.Printf("[ERROR] Failed to load Public Suffix List, using the built-in one: %s\n",
+			err.Error())
+	} else {
+		gen.log.Printf("[INFO] Loaded Public Suffix List from %s\n",
+			common.PSLPath)
+	}
+
+	switch cfg.Mode {
+	case genmode.Random:
+		if gen.cache, err = openCache(cfg.CacheTTL); err != nil {
+			gen.log.Printf("[ERROR] Failed to open cache: %s\n",
+				err.Error())
+			return nil, err
+		} else if cfg.Expand == 0 {
+			break
+		} else if gen.expand, err = newExpander(gen.log, cfg.Expand); err != nil {
+			gen.log.Printf("[ERROR] %s\n", err.Error())
+			return nil, err
+		}
+	case genmode.Permutation:
+		// Without the cache, we cannot tell if we have looked at an
+		// address already, and the permutation gets to the neighbours
+		// eventually anyway.
+		if cfg.Expand != 0 {
+			gen.log.Println("[INFO] Neighbourhood expansion is not available in permutation mode.")
+		}
+
+		if gen.perm, err = openPermutation(gen.log, cfg.Seed); err != nil {
+			gen.log.Printf("[ERROR] Failed to open permutation: %s\n",
+				err.Error())
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid generator mode %s", cfg.Mode)
+	}
+
+	if cfg.AsyncPTR {
+		var servers []string
+
+		if servers, err = resolverServers(); err != nil {
+			gen.log.Printf("[ERROR] Cannot read name servers from %s: %s\n",
+				resolvConf,
+				err.Error())
+			return nil, err
+		} else if gen.res, err = newResolver(gen.log, servers, cfg.PTRInflight, resolverTimeout); err != nil {
+			gen.log.Printf("[ERROR] Cannot create resolver: %s\n",
+				err.Error())
+			return nil, err
+		}
+
+		// Stop closes the resolver, Start needs these to open a new one.
+		gen.resServers = servers
+		gen.resInflight = cfg.PTRInflight
 	}
 
 	var aqcnt, nqcnt int
 
-	aqcnt = max(icnt, 2)
-	nqcnt = max(ncnt, 2)
+	aqcnt = max(cfg.AddrWorkers, 2)
+	nqcnt = max(cfg.NameWorkers, 2)
 
 	gen.blAddr = blacklist.NewBlacklistAddr()
 	gen.blName = blacklist.NewBlacklistName()
@@ -139,11 +156,12 @@ func New(icnt, ncnt int) (*Generator, error) {
 	gen.ctlQName = make(chan bool, nqcnt)
 
 	return gen, nil
-} // func New() (*Generator, error)
+} // func New(cfg Config) (*Generator, error)
 
 // Start sets the Generator's active flag and spawns the worker goroutines.
 func (gen *Generator) Start() {
 	gen.active.Store(true)
+	events.State(subsystem.Generator, true)
 
 	for range gen.addrGenGoal.Load() {
 		go gen.addrWorker(gen.getID())
@@ -153,9 +171,40 @@ func (gen *Generator) Start() {
 		go gen.nameWorker(gen.getID())
 	}
 
+	if r := gen.openResolver(); r != nil {
+		go gen.ptrFeeder(r)
+		for range ptrCollectors {
+			go gen.ptrCollector(r)
+		}
+	}
+
 	go gen.hostWorker()
+	go gen.yieldWorker()
+
+	if gen.cache != nil {
+		go gen.cacheWorker()
+	}
 } // func (gen *Generator) Start()
 
+// openResolver returns the asynchronous resolver, if the Generator uses
+// one, and opens a new one if the last one was closed by Stop.
+func (gen *Generator) openResolver() *resolver {
+	var err error
+
+	gen.lock.Lock()
+	defer gen.lock.Unlock()
+
+	if gen.res != nil || gen.resInflight == 0 {
+		return gen.res
+	} else if gen.res, err = newResolver(gen.log, gen.resServers, gen.resInflight, resolverTimeout); err != nil {
+		gen.log.Printf("[ERROR] Cannot create resolver, only name workers will resolve addresses: %s\n",
+			err.Error())
+		gen.res = nil
+	}
+
+	return gen.res
+} // func (gen *Generator) openResolver() *resolver
+
 func (gen *Generator) getID() int {
 	var val = gen.idCounter.Add(1)
 	return int(val)
@@ -176,6 +225,30 @@ func (gen *Generator) StartNameWorker() {
 // Stop clears the Generator's active flag.
 func (gen *Generator) Stop() {
 	gen.active.Store(false)
+	events.State(subsystem.Generator, false)
+
+	gen.lock.Lock()
+	if gen.res != nil {
+		gen.res.close()
+		gen.res = nil
+	}
+	gen.lock.Unlock()
+
+	if gen.perm != nil {
+		gen.perm.save()
+	}
+
+	if err := gen.yield.flush(); err != nil {
+		gen.log.Printf("[ERROR] Failed to save yield statistics: %s\n",
+			err.Error())
+	}
+
+	if gen.cache != nil {
+		if err := gen.cache.save(); err != nil {
+			gen.log.Printf("[ERROR] Failed to save address cache: %s\n",
+				err.Error())
+		}
+	}
 } // func (gen *Generator) Stop()
 
 // StopAddrWorker stops one address generation worker.
@@ -188,6 +261,47 @@ func (gen *Generator) StopNameWorker() {
 	gen.ctlQName <- true
 } // func (gen *Generator) StopNameWorker()
 
+// Mode returns the strategy the Generator uses to come up with addresses.
+func (gen *Generator) Mode() genmode.Mode {
+	return gen.mode
+} // func (gen *Generator) Mode() genmode.Mode
+
+// Seed returns the seed of the permutation the Generator walks, or 0 if it
+// does not walk one.
+func (gen *Generator) Seed() int64 {
+	if gen.perm == nil {
+		return 0
+	}
+
+	return gen.perm.seed()
+} // func (gen *Generator) Seed() int64
+
+// CacheStats returns the state of the Generator's address cache. If the
+// Generator does not use a cache, the second return value is false.
+func (gen *Generator) CacheStats() (model.CacheStats, bool) {
+	if gen.cache == nil {
+		return model.CacheStats{}, false
+	}
+
+	return gen.cache.stats(), true
+} // func (gen *Generator) CacheStats() (model.CacheStats, bool)
+
+// CacheReset removes all addresses from the Generator's address cache, so
+// it will try all of them again.
+func (gen *Generator) CacheReset() error {
+	if gen.cache == nil {
+		return errors.New("the Generator does not use a cache")
+	}
+
+	return gen.cache.reset()
+} // func (gen *Generator) CacheReset() error
+
+// ResolverStats returns the outcomes of the PTR lookups so far, per name
+// server.
+func (gen *Generator) ResolverStats() []model.ResolverStats {
+	return gen.outcomes.snapshot()
+} // func (gen *Generator) ResolverStats() []model.ResolverStats
+
 // IsActive returns the Generator's active flag.
 func (gen *Generator) IsActive() bool {
 	return gen.active.Load()
@@ -207,6 +321,26 @@ func (gen *Generator) NameWorkerCount() int {
 	return int(gen.nameGenCnt.Load())
 } // func (gen *Generator) NameWorkerCount() int
 
+// QueueDepths returns the number of items waiting in the Generator's queues.
+func (gen *Generator) QueueDepths() map[string]int {
+	var depths = map[string]int{
+		"ipQ":   len(gen.ipQ),
+		"hostQ": len(gen.hostQ),
+	}
+
+	if gen.expand != nil {
+		depths["expandQ"] = gen.expand.queued()
+	}
+
+	gen.lock.RLock()
+	if gen.res != nil {
+		depths["ptrInflight"] = gen.res.inflight()
+	}
+	gen.lock.RUnlock()
+
+	return depths
+} // func (gen *Generator) QueueDepths() map[string]int
+
 func (gen *Generator) System() subsystem.ID {
 	return subsystem.Generator
 } // func (gen *Generator) System() subsystem.ID
@@ -214,25 +348,34 @@ func (gen *Generator) System() subsystem.ID {
 func (gen *Generator) addrWorker(id int) {
 	const maxErr = 5
 
-	gen.addrGenCnt.Add(1)
-	defer gen.addrGenCnt.Add(-1)
+	events.Workers(subsystem.GeneratorAddress, int(gen.addrGenCnt.Add(1)))
+	defer func() {
+		events.Workers(subsystem.GeneratorAddress, int(gen.addrGenCnt.Add(-1)))
+	}()
 
 	gen.log.Printf("[DEBUG] addrWorker#%d starting up, total worker count is %d...\n",
 		id,
 		gen.addrGenCnt.Load())
 	defer gen.log.Printf("[DEBUG] addrWorker#%d is quitting.", id)
 
-	var ticker = time.NewTicker(common.ActiveTimeout)
+	var (
+		errCnt int
+		ticker = time.NewTicker(common.ActiveTimeout)
+	)
 	defer ticker.Stop()
 
 	for gen.active.Load() {
 		var (
-			err    error
-			addr   net.IP
-			errCnt int
+			err  error
+			addr net.IP
 		)
 
-		if addr, err = gen.mkIP(); err != nil {
+		if addr, err = gen.mkIP(); errors.Is(err, errExhausted) {
+			gen.log.Printf("[INFO] addrWorker#%d is done: %s\n",
+				id,
+				err.Error())
+			return
+		} else if err != nil {
 			gen.log.Printf("[ERROR] addrWorker#%d failed to generate IP address: %s\n",
 				id,
 				err.Error())
@@ -243,6 +386,7 @@ func (gen *Generator) addrWorker(id int) {
 					errCnt)
 				return
 			}
+			continue
 		}
 
 	SEND_ADDR:
@@ -260,27 +404,74 @@ func (gen *Generator) addrWorker(id int) {
 } // func (gen *Generator) addrWorker(id int)
 
 func (gen *Generator) mkIP() (net.IP, error) {
-	const maxErr = 5
-	var (
-		err               error
-		octets            [4]byte
-		bytesRead, errCnt int
-	)
+	if gen.mode == genmode.Permutation {
+		return gen.mkIPPermutation()
+	}
+
+	return gen.mkIPRandom()
+} // func (gen *Generator) mkIP() (net.IP, error)
 
+// mkIPPermutation returns the next address from the permutation that is not
+// blacklisted.
+func (gen *Generator) mkIPPermutation() (net.IP, error) {
 	for {
-		if bytesRead, err = rand.Read(octets[:]); err != nil {
-			gen.log.Printf("[ERROR] Failed to read random bytes: %s\n",
-				err.Error())
+		var addr, err = gen.perm.next()
+
+		if err != nil {
 			return nil, err
-		} else if bytesRead != 4 {
+		} else if gen.blAddr.Match(addr) {
 			continue
 		}
 
+		metrics.AddrGenerated.Inc()
+		return addr, nil
+	}
+} // func (gen *Generator) mkIPPermutation() (net.IP, error)
+
+func (gen *Generator) mkIPRandom() (net.IP, error) {
+	const (
+		maxErr = 5
+		// maxBiased is the number of addresses we draw from productive
+		// networks in a row before we give up and draw one from the
+		// whole address space, in case the networks we like best are
+		// exhausted.
+		maxBiased = 16
+	)
+	var (
+		err            error
+		errCnt, biased int
+	)
+
+	for {
 		var (
-			known bool
-			addr  = net.IPv4(octets[0], octets[1], octets[2], octets[3])
+			known, ok bool
+			strategy  string
+			addr      net.IP
 		)
 
+		// The neighbours of Hosts we found go first.
+		if gen.expand != nil {
+			addr, ok = gen.expand.next()
+		}
+
+		if ok {
+			strategy = "expand"
+		} else {
+			var v, bsd = gen.yield.pick(biased >= maxBiased)
+
+			addr = make(net.IP, 4)
+			binary.BigEndian.PutUint32(addr, v)
+			addr = addr.To16()
+
+			if bsd {
+				biased++
+				strategy = "exploit"
+			} else {
+				biased = 0
+				strategy = "explore"
+			}
+		}
+
 		if known, err = gen.cache.check(addr); err != nil {
 			gen.log.Printf("[ERROR] Failed to look up IP %s in cache: %s\n",
 				addr,
@@ -293,9 +484,11 @@ func (gen *Generator) mkIP() (net.IP, error) {
 			continue
 		}
 
+		metrics.AddrGenerated.Inc()
+		metrics.AddrStrategy.With(strategy).Inc()
 		return addr, nil
 	}
-} // func (gen *Generator) mkIP() (net.IP, error)
+} // func (gen *Generator) mkIPRandom() (net.IP, error)
 
 func (gen *Generator) nameWorker(id int) {
 	var (
@@ -305,8 +498,10 @@ func (gen *Generator) nameWorker(id int) {
 		ticker *time.Ticker
 	)
 
-	gen.nameGenCnt.Add(1)
-	defer gen.nameGenCnt.Add(-1)
+	events.Workers(subsystem.GeneratorName, int(gen.nameGenCnt.Add(1)))
+	defer func() {
+		events.Workers(subsystem.GeneratorName, int(gen.nameGenCnt.Add(-1)))
+	}()
 
 	gen.log.Printf("[DEBUG] nameWorker#%d starting up, total worker count is %d...\n",
 		id,
@@ -323,13 +518,8 @@ func (gen *Generator) nameWorker(id int) {
 		case <-gen.ctlQName:
 			return
 		case addr = <-gen.ipQ:
+			// handlePTR has already logged the error, if it was worth it.
 			if host, err = gen.processAddr(addr); err != nil {
-				if !ignoreErr(err) {
-					gen.log.Printf("[ERROR] nameWorker#%d failed to process IP address %s: %s\n",
-						id,
-						addr,
-						err.Error())
-				}
 				continue
 			} else if host != nil {
 				gen.hostQ <- host
@@ -338,30 +528,58 @@ func (gen *Generator) nameWorker(id int) {
 	}
 } // func (gen *Generator) nameWorker(id int)
 
-func ignoreErr(err error) bool {
-	var (
-		res bool
-		msg = err.Error()
-	)
+// ptrCollectors is the number of goroutines handling the results of the
+// asynchronous resolver. For Hosts it finds, they look up their names, so
+// one goroutine is not enough.
+const ptrCollectors = 8
+
+// ptrFeeder passes the addresses from ipQ to the asynchronous resolver.
+func (gen *Generator) ptrFeeder(r *resolver) {
+	var ticker = time.NewTicker(common.ActiveTimeout)
+	defer ticker.Stop()
+
+	gen.log.Println("[DEBUG] ptrFeeder starting up...")
+	defer gen.log.Println("[DEBUG] ptrFeeder is quitting.")
 
-	if strings.HasSuffix(msg, "no such host") {
-		res = true
-	} else if strings.HasSuffix(msg, "Temporary failure in name resolution") {
-		res = true
+	for gen.active.Load() {
+		select {
+		case <-ticker.C:
+			continue
+		case addr := <-gen.ipQ:
+			if err := r.lookup(addr); errors.Is(err, errResolverClosed) {
+				return
+			} else if err != nil {
+				gen.log.Printf("[ERROR] Failed to look up %s: %s\n",
+					addr,
+					err.Error())
+			}
+		}
 	}
+} // func (gen *Generator) ptrFeeder(r *resolver)
+
+// ptrCollector handles the results of the asynchronous resolver.
+func (gen *Generator) ptrCollector(r *resolver) {
+	var ticker = time.NewTicker(common.ActiveTimeout)
+	defer ticker.Stop()
 
-	return res
-} // func ignoreErr(err error) bool
+	for gen.active.Load() {
+		select {
+		case <-ticker.C:
+			continue
+		case res := <-r.results():
+			if host, _ := gen.handlePTR(res.addr, res.server, res.names, res.err); host != nil {
+				gen.hostQ <- host
+			}
+		}
+	}
+} // func (gen *Generator) ptrCollector(r *resolver)
 
 func isTransient(err error) bool {
-	return strings.HasSuffix(err.Error(), "Temporary failure in name resolution")
+	return classifyErr(err).Transient()
 } // func isTransient(err error) bool
 
 func (gen *Generator) processAddr(addr net.IP) (*model.Host, error) {
-	const (
-		maxErr     = 5
-		retryDelay = time.Millisecond * 250
-	)
+	const maxErr = 5
 	var (
 		err    error
 		errCnt int
@@ -369,35 +587,137 @@ func (gen *Generator) processAddr(addr net.IP) (*model.Host, error) {
 	)
 
 RESOLVE:
-	if names, err = net.LookupAddr(addr.String()); err != nil {
-		if isTransient(err) {
-			if errCnt < maxErr {
-				errCnt++
-				time.Sleep(retryDelay)
-				goto RESOLVE
-			}
+	if names, err = net.LookupAddr(addr.String()); err != nil && isTransient(err) {
+		if errCnt < maxErr {
+			errCnt++
+			time.Sleep(backoff(errCnt))
+			goto RESOLVE
 		}
-		if !ignoreErr(err) {
+	}
+
+	return gen.handlePTR(addr, serverOf(err, serverSystem), names, err)
+} // func (gen *Generator) processAddr(addr net.IP) (*model.Host, error)
+
+// handlePTR deals with the outcome of a PTR lookup for addr, answered by
+// server. If it gave us a name worth keeping, it returns a Host.
+func (gen *Generator) handlePTR(addr net.IP, server string, names []string, err error) (*model.Host, error) {
+	if err != nil {
+		var o = classifyErr(err)
+
+		if o == outcome.Error {
 			gen.log.Printf("[ERROR] Failed to resolve address %s to name: %s\n",
 				addr,
 				err.Error())
 		}
+		// If our resolver is having trouble, that does not tell us
+		// anything about the network.
+		if !o.Transient() {
+			gen.record(addr, yieldMiss, "")
+		}
+		gen.outcomes.add(server, o)
 		return nil, err
 	} else if len(names) == 0 {
-		return nil, nil
-	} else if gen.blName.Match(names[0]) {
+		gen.record(addr, yieldMiss, "")
+		gen.outcomes.add(server, outcome.Empty)
 		return nil, nil
 	}
 
-	var host = &model.Host{
-		Addr:   addr,
-		Name:   names[0],
-		Added:  time.Now(),
-		Source: hsrc.Generator,
+	var host = gen.mkHost(addr, names)
+
+	if host == nil {
+		gen.record(addr, yieldBlocked, names[0])
+		gen.outcomes.add(server, outcome.Blacklisted)
+		return nil, nil
 	}
 
+	gen.record(addr, yieldHit, host.Name)
+	gen.outcomes.add(server, outcome.Success)
+
 	return host, nil
-} // func (gen *Generator) processAddr(addr net.IP) (*model.Host, error)
+} // func (gen *Generator) handlePTR(addr net.IP, server string, names []string, err error) (*model.Host, error)
+
+// lookupIP resolves names to addresses. Tests replace it, so they do not
+// depend on the DNS.
+var lookupIP = net.LookupIP
+
+// mkHost creates a Host from an address and the names a PTR lookup returned
+// for it. Blacklisted names are dropped, the others are checked for whether
+// they resolve back to the address. The Host's name is the first confirmed
+// name, or the first name if none is confirmed.
+// If all names are blacklisted, mkHost returns nil.
+func (gen *Generator) mkHost(addr net.IP, names []string) *model.Host {
+	const maxNames = 8
+	var (
+		now  = time.Now()
+		host = &model.Host{
+			Addr:   addr,
+			Added:  now,
+			Source: hsrc.Generator,
+			Names:  make([]*model.HostName, 0, len(names)),
+		}
+	)
+
+	for _, name := range names[:min(len(names), maxNames)] {
+		if gen.blName.Match(name) {
+			continue
+		}
+
+		var n = &model.HostName{
+			Name:      name,
+			Confirmed: confirmName(addr, name),
+			Checked:   now,
+		}
+
+		if n.Confirmed {
+			metrics.FCrDNS.With("confirmed").Inc()
+		} else {
+			metrics.FCrDNS.With("unconfirmed").Inc()
+		}
+
+		host.Names = append(host.Names, n)
+
+		if host.Name == "" || (n.Confirmed && !host.Confirmed) {
+			host.Name = n.Name
+			host.Confirmed = n.Confirmed
+		}
+	}
+
+	if len(host.Names) == 0 {
+		return nil
+	}
+
+	return host
+} // func (gen *Generator) mkHost(addr net.IP, names []string) *model.Host
+
+// confirmName returns true if name resolves back to addr.
+func confirmName(addr net.IP, name string) bool {
+	var addrs, err = lookupIP(name)
+
+	if err != nil {
+		return false
+	}
+
+	return slices.ContainsFunc(addrs, addr.Equal)
+} // func confirmName(addr net.IP, name string) bool
+
+// record tells the yield statistics and the expander what looking up an
+// address has given us. If it is a Host with a proper name, we expand its
+// neighbourhood.
+func (gen *Generator) record(addr net.IP, o yieldOutcome, name string) {
+	gen.yield.record(addr, o)
+
+	if gen.expand == nil {
+		return
+	}
+
+	var good = o == yieldHit && !isGenericName(addr, name)
+
+	gen.expand.report(addr, good)
+
+	if good {
+		gen.expand.add(addr)
+	}
+} // func (gen *Generator) record(addr net.IP, o yieldOutcome, name string)
 
 func (gen *Generator) hostWorker() {
 	var (
@@ -428,23 +748,81 @@ func (gen *Generator) hostWorker() {
 				gen.log.Printf("[ERROR] Failed to add Host to Database: %s\n",
 					err.Error())
 			} else {
+				for _, n := range host.Names {
+					if err = db.HostNameAdd(host, n); err != nil {
+						gen.log.Printf("[ERROR] Failed to add name %s of Host %s: %s\n",
+							n.Name,
+							host.AStr(),
+							err.Error())
+					}
+				}
+
+				events.Host(host)
 				gen.checkXFR(host, db)
 			}
 		}
 	}
 } // func (gen *Generator) hostWorker()
 
-var tldPat = regexp.MustCompile("^[^.]+[.]?$")
+// yieldWorker periodically saves the yield statistics. Stop saves them one
+// last time.
+func (gen *Generator) yieldWorker() {
+	var ticker = time.NewTicker(yieldFlushInterval)
+	defer ticker.Stop()
+
+	for gen.active.Load() {
+		<-ticker.C
+		if err := gen.yield.flush(); err != nil {
+			gen.log.Printf("[ERROR] Failed to save yield statistics: %s\n",
+				err.Error())
+		}
+	}
+} // func (gen *Generator) yieldWorker()
+
+// cacheWorker performs housekeeping on the address cache, once right away
+// and then periodically.
+func (gen *Generator) cacheWorker() {
+	var ticker = time.NewTicker(cacheMaintenanceInterval)
+	defer ticker.Stop()
+
+	for gen.active.Load() {
+		var (
+			err   error
+			begin = time.Now()
+		)
+
+		if err = gen.cache.maintain(); err != nil {
+			gen.log.Printf("[ERROR] Cache maintenance failed: %s\n",
+				err.Error())
+		} else {
+			var s = gen.cache.stats()
+			gen.log.Printf("[DEBUG] Cache maintenance took %s, cache has %d entries, %d bytes on disk.\n",
+				time.Since(begin),
+				s.Entries,
+				s.DiskSize)
+		}
 
+		<-ticker.C
+	}
+} // func (gen *Generator) cacheWorker()
+
+// checkXFR queues the zones of the Host's confirmed names for a zone
+// transfer, from each name's parent down to its registrable domain. If none
+// of its names is confirmed, we go with the zones of the Host's name.
 func (gen *Generator) checkXFR(host *model.Host, db *database.Database) {
+	for _, dns := range host.Zones() {
+		gen.checkZone(dns, db)
+	}
+} // func (gen *Generator) checkXFR(host *model.Host, db *database.Database)
+
+func (gen *Generator) checkZone(dns string, db *database.Database) {
 	var (
 		err error
 		xfr *model.Zone
-		dns = host.Zone()
 	)
 
-	if tldPat.MatchString(dns) {
-		gen.log.Printf("[DEBUG] Zone %s looks like a top-level domain, so we skip it.\n",
+	if psl.IsPublicSuffix(dns) {
+		gen.log.Printf("[DEBUG] Zone %s is a public suffix, so we skip it.\n",
 			dns)
 		return
 	} else if xfr, err = db.XFRGetByName(dns); err != nil {
@@ -466,4 +844,4 @@ func (gen *Generator) checkXFR(host *model.Host, db *database.Database) {
 			dns,
 			err.Error())
 	}
-} // func (gen *Generator) checkXFR(host *model.Host)
+} // func (gen *Generator) checkZone(dns string, db *database.Database)
@@ -0,0 +1,72 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/generic.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-21 12:31:44 krylon>
+
+package generator
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// genericPat matches the words ISPs like to put in the names they generate
+// for the addresses of their customers.
+var genericPat = regexp.MustCompile(`(?i)\b(?:dyn|dynamic|dhcp|pool|dsl|adsl|vdsl|xdsl|cable|dial|dialup|ppp|pppoe|broadband|customer|client|clients|subscriber|unassigned|cpe|ftth|fttx)\b`)
+
+var digitPat = regexp.MustCompile(`[0-9]+`)
+
+// isGenericName returns true if name looks like it was generated from the
+// address rather than given to a machine by someone, e.g.
+// 10-1-2-3.dynamic.example.net or static.3.2.1.10.example.com.
+func isGenericName(addr net.IP, name string) bool {
+	var ip4 = addr.To4()
+
+	if ip4 == nil {
+		return false
+	} else if genericPat.MatchString(name) {
+		return true
+	}
+
+	var hex = fmt.Sprintf("%08x", binary.BigEndian.Uint32(ip4))
+
+	if strings.Contains(strings.ToLower(name), hex) {
+		return true
+	}
+
+	var (
+		nums = digitPat.FindAllString(name, -1)
+		fwd  = []string{
+			strconv.Itoa(int(ip4[0])),
+			strconv.Itoa(int(ip4[1])),
+			strconv.Itoa(int(ip4[2])),
+			strconv.Itoa(int(ip4[3])),
+		}
+		rev = []string{fwd[3], fwd[2], fwd[1], fwd[0]}
+	)
+
+	for i := 0; i+4 <= len(nums); i++ {
+		if matchOctets(nums[i:i+4], fwd) || matchOctets(nums[i:i+4], rev) {
+			return true
+		}
+	}
+
+	return false
+} // func isGenericName(addr net.IP, name string) bool
+
+// matchOctets compares numbers found in a name to the octets of an address,
+// ignoring leading zeros, as in 010-001-002-003.
+func matchOctets(nums, octets []string) bool {
+	for i, n := range nums {
+		if t := strings.TrimLeft(n, "0"); t != octets[i] && !(t == "" && octets[i] == "0") {
+			return false
+		}
+	}
+
+	return true
+} // func matchOctets(nums, octets []string) bool
@@ -0,0 +1,167 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/outcome.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:42:16 krylon>
+
+package generator
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blicero/guangng/metrics"
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/outcome"
+	dns "github.com/tonnerre/golang-dns"
+)
+
+// serverSystem is what we count lookups under if we do not know which
+// server answered them, i.e. if they went through the system's resolver.
+const serverSystem = "system"
+
+// Transient failures are retried after a delay that starts at retryBase
+// and doubles with every attempt, up to retryMax.
+const (
+	retryBase = time.Millisecond * 100
+	retryMax  = time.Second * 5
+)
+
+// backoff returns how long to wait before the given retry. To keep
+// retries from bunching up, the delay is picked at random from the upper
+// half of the interval.
+func backoff(try int) time.Duration {
+	var d = retryMax
+
+	if try < 1 {
+		try = 1
+	}
+
+	if try <= 16 {
+		d = min(retryBase<<(try-1), retryMax)
+	}
+
+	return d/2 + rand.N(d/2+1)
+} // func backoff(try int) time.Duration
+
+// rcodeError is the response code of a failed query. The asynchronous
+// resolver wraps it in a net.DNSError, so we can tell REFUSED from
+// SERVFAIL.
+type rcodeError int
+
+func (e rcodeError) Error() string {
+	if s, ok := dns.RcodeToString[int(e)]; ok {
+		return "server returned " + s
+	}
+
+	return "server returned unknown response code"
+} // func (e rcodeError) Error() string
+
+// classifyErr returns the Outcome of a lookup that failed with err.
+func classifyErr(err error) outcome.Outcome {
+	var (
+		rc     rcodeError
+		dnsErr *net.DNSError
+	)
+
+	switch {
+	case err == nil:
+		return outcome.Success
+	case errors.As(err, &rc):
+		switch int(rc) {
+		case dns.RcodeNameError:
+			return outcome.NXDomain
+		case dns.RcodeServerFailure:
+			return outcome.ServFail
+		case dns.RcodeRefused:
+			return outcome.Refused
+		}
+	case errors.As(err, &dnsErr):
+		switch {
+		case dnsErr.IsNotFound:
+			return outcome.NXDomain
+		case dnsErr.IsTimeout:
+			return outcome.Timeout
+		case dnsErr.IsTemporary:
+			// Go's resolver reports SERVFAIL as a temporary error,
+			// the system's resolver does the same when it cannot
+			// reach any server.
+			return outcome.ServFail
+		}
+	case errors.Is(err, os.ErrDeadlineExceeded), errors.Is(err, context.DeadlineExceeded):
+		return outcome.Timeout
+	}
+
+	return outcome.Error
+} // func classifyErr(err error) outcome.Outcome
+
+// serverOf returns the server that produced err, if we can tell, or def.
+func serverOf(err error, def string) string {
+	var dnsErr *net.DNSError
+
+	if errors.As(err, &dnsErr) && dnsErr.Server != "" {
+		return dnsErr.Server
+	}
+
+	return def
+} // func serverOf(err error, def string) string
+
+// outcomeStats counts the outcomes of PTR lookups per server.
+type outcomeStats struct {
+	lock   sync.Mutex
+	counts map[string]map[outcome.Outcome]int64
+}
+
+func newOutcomeStats() *outcomeStats {
+	return &outcomeStats{counts: make(map[string]map[outcome.Outcome]int64)}
+} // func newOutcomeStats() *outcomeStats
+
+func (s *outcomeStats) add(server string, o outcome.Outcome) {
+	metrics.PTRLookups.With(o.String(), server).Inc()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var c, ok = s.counts[server]
+
+	if !ok {
+		c = make(map[outcome.Outcome]int64, len(outcome.AllOutcomes()))
+		s.counts[server] = c
+	}
+
+	c[o]++
+} // func (s *outcomeStats) add(server string, o outcome.Outcome)
+
+// snapshot returns a copy of the counters, ordered by server.
+func (s *outcomeStats) snapshot() []model.ResolverStats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var list = make([]model.ResolverStats, 0, len(s.counts))
+
+	for server, c := range s.counts {
+		var r = model.ResolverStats{
+			Server: server,
+			Counts: make(map[outcome.Outcome]int64, len(c)),
+		}
+
+		for o, n := range c {
+			r.Counts[o] = n
+		}
+
+		list = append(list, r)
+	}
+
+	slices.SortFunc(list, func(a, b model.ResolverStats) int {
+		return strings.Compare(a.Server, b.Server)
+	})
+
+	return list
+} // func (s *outcomeStats) snapshot() []model.ResolverStats
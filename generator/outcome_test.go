@@ -0,0 +1,81 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/outcome_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:42:16 krylon>
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/blicero/guangng/model/outcome"
+	dns "github.com/tonnerre/golang-dns"
+)
+
+func TestClassifyErr(t *testing.T) {
+	for _, c := range []struct {
+		err error
+		o   outcome.Outcome
+	}{
+		{nil, outcome.Success},
+		{&net.DNSError{Err: "no such host", IsNotFound: true}, outcome.NXDomain},
+		{&net.DNSError{Err: "i/o timeout", IsTimeout: true}, outcome.Timeout},
+		{&net.DNSError{Err: "server misbehaving", IsTemporary: true}, outcome.ServFail},
+		{&net.DNSError{Err: "server misbehaving"}, outcome.Error},
+		{&net.DNSError{UnwrapErr: rcodeError(dns.RcodeRefused), Err: "refused"}, outcome.Refused},
+		{&net.DNSError{UnwrapErr: rcodeError(dns.RcodeServerFailure), IsTemporary: true}, outcome.ServFail},
+		{fmt.Errorf("lookup failed: %w", os.ErrDeadlineExceeded), outcome.Timeout},
+		{errors.New("Temporary failure in name resolution"), outcome.Error},
+	} {
+		if o := classifyErr(c.err); o != c.o {
+			t.Errorf("classifyErr(%v) = %s, expected %s", c.err, o, c.o)
+		}
+	}
+} // func TestClassifyErr(t *testing.T)
+
+func TestBackoff(t *testing.T) {
+	for try := 1; try <= 20; try++ {
+		var (
+			d   = backoff(try)
+			max = min(retryBase<<(try-1), retryMax)
+		)
+
+		if try > 16 {
+			max = retryMax
+		}
+
+		if d < max/2 || d > max {
+			t.Errorf("Delay for try #%d is %s, expected between %s and %s",
+				try,
+				d,
+				max/2,
+				max)
+		}
+	}
+} // func TestBackoff(t *testing.T)
+
+func TestOutcomeStats(t *testing.T) {
+	var s = newOutcomeStats()
+
+	s.add("192.0.2.53:53", outcome.Success)
+	s.add("192.0.2.53:53", outcome.NXDomain)
+	s.add("192.0.2.53:53", outcome.NXDomain)
+	s.add(serverSystem, outcome.Timeout)
+
+	var list = s.snapshot()
+
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 servers, got %d", len(list))
+	} else if list[0].Server != "192.0.2.53:53" || list[0].Total() != 3 {
+		t.Errorf("Unexpected statistics for %s: %v", list[0].Server, list[0].Counts)
+	} else if sh := list[0].Share(outcome.NXDomain); sh < 0.66 || sh > 0.67 {
+		t.Errorf("Unexpected share of NXDOMAIN: %f", sh)
+	} else if list[1].Counts[outcome.Timeout] != 1 {
+		t.Errorf("Unexpected statistics for %s: %v", list[1].Server, list[1].Counts)
+	}
+} // func TestOutcomeStats(t *testing.T)
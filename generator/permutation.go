@@ -0,0 +1,177 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/permutation.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 23:48:31 krylon>
+
+package generator
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"sync"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/model"
+)
+
+// checkpointInterval is the number of addresses after which we save our
+// position in the permutation to the Database.
+const checkpointInterval = 4096
+
+// errExhausted is returned once we have walked the entire address space.
+var errExhausted = errors.New("all IPv4 addresses have been generated")
+
+// permutation hands out the addresses of a cyclicGroup to the address
+// workers and keeps track of our progress in the Database, so that after
+// a restart we can pick up where we left off.
+type permutation struct {
+	lock  sync.Mutex
+	log   *log.Logger
+	db    *database.Database
+	state *model.Permutation
+	grp   *cyclicGroup
+	since int
+}
+
+// openPermutation resumes the permutation with the given seed, or starts it
+// if we have not seen that seed before. If seed is 0, we resume the
+// permutation we worked on last, or start one with a random seed if there
+// is none we have not finished.
+func openPermutation(l *log.Logger, seed int64) (*permutation, error) {
+	var (
+		err error
+		p   = &permutation{log: l}
+	)
+
+	if p.db, err = database.Open(common.DbPath); err != nil {
+		return nil, fmt.Errorf("cannot open database: %w", err)
+	}
+
+	if seed == 0 {
+		if p.state, err = p.db.PermutationGetLatest(); err != nil {
+			p.db.Close() // nolint: errcheck
+			return nil, err
+		} else if p.state != nil && p.state.Count >= groupOrder {
+			p.state = nil
+		}
+
+		if p.state == nil {
+			if seed, err = randomSeed(); err != nil {
+				p.db.Close() // nolint: errcheck
+				return nil, err
+			}
+		}
+	} else if p.state, err = p.db.PermutationGet(seed); err != nil {
+		p.db.Close() // nolint: errcheck
+		return nil, err
+	}
+
+	if p.state == nil {
+		p.grp = newCyclicGroup(seed)
+		p.state = &model.Permutation{
+			Seed:     seed,
+			Root:     p.grp.root,
+			Position: p.grp.cur,
+		}
+
+		if err = p.db.PermutationSave(p.state); err != nil {
+			p.db.Close() // nolint: errcheck
+			return nil, err
+		}
+
+		p.log.Printf("[INFO] Starting new permutation of the address space with seed %d\n",
+			seed)
+		return p, nil
+	} else if !isGenerator(p.state.Root) || p.state.Position == 0 || p.state.Position >= groupPrime {
+		p.db.Close() // nolint: errcheck
+		return nil, fmt.Errorf("stored state of permutation %d is invalid", p.state.Seed)
+	}
+
+	p.grp = &cyclicGroup{
+		root:  p.state.Root,
+		cur:   p.state.Position,
+		count: p.state.Count,
+	}
+
+	p.log.Printf("[INFO] Resuming permutation of the address space with seed %d, %.2f%% done\n",
+		p.state.Seed,
+		p.grp.done()*100)
+
+	return p, nil
+} // func openPermutation(l *log.Logger, seed int64) (*permutation, error)
+
+// randomSeed returns a random positive seed.
+func randomSeed() (int64, error) {
+	var buf [8]byte
+
+	for {
+		if _, err := rand.Read(buf[:]); err != nil {
+			return 0, fmt.Errorf("cannot read random bytes: %w", err)
+		}
+
+		if seed := int64(binary.BigEndian.Uint64(buf[:]) & math.MaxInt64); seed != 0 {
+			return seed, nil
+		}
+	}
+} // func randomSeed() (int64, error)
+
+// next returns the next address of the permutation.
+func (p *permutation) next() (net.IP, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var addr, ok = p.grp.next()
+
+	if !ok {
+		if p.since > 0 {
+			p.checkpoint()
+		}
+		return nil, errExhausted
+	}
+
+	if p.since++; p.since >= checkpointInterval {
+		p.checkpoint()
+	}
+
+	var ip = make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, addr)
+
+	return ip.To16(), nil
+} // func (p *permutation) next() (net.IP, error)
+
+// checkpoint saves our position to the Database. The caller must hold the
+// lock. If it fails, we simply try again at the next checkpoint.
+func (p *permutation) checkpoint() {
+	p.state.Position = p.grp.cur
+	p.state.Count = p.grp.count
+
+	if err := p.db.PermutationSave(p.state); err != nil {
+		p.log.Printf("[ERROR] Failed to save position in permutation %d: %s\n",
+			p.state.Seed,
+			err.Error())
+		return
+	}
+
+	p.since = 0
+} // func (p *permutation) checkpoint()
+
+// save saves our position to the Database. Addresses that have been handed
+// out but not processed yet will be skipped after a restart.
+func (p *permutation) save() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.checkpoint()
+} // func (p *permutation) save()
+
+// seed returns the seed of the permutation.
+func (p *permutation) seed() int64 {
+	return p.state.Seed
+} // func (p *permutation) seed() int64
@@ -0,0 +1,444 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/resolver.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:23:32 krylon>
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	dns "github.com/tonnerre/golang-dns"
+)
+
+// Tunables for the asynchronous resolver.
+const (
+	resolvConf      = "/etc/resolv.conf"
+	resolverSockets = 4
+	resolverTimeout = time.Second * 2
+	resolverTries   = 3
+	// The largest response we accept. Without EDNS, servers do not send
+	// more than 512 bytes over UDP, but some do anyway.
+	resolverBufSize = 4096
+)
+
+var errResolverClosed = errors.New("resolver is closed")
+
+// ptrResult is the outcome of a PTR query. If the query succeeded, but
+// there were no PTR records, names is empty and err is nil.
+type ptrResult struct {
+	addr   net.IP
+	server string
+	names  []string
+	err    error
+}
+
+// queryKey identifies a query in flight. A response has to arrive on the
+// same socket and carry the same ID.
+type queryKey struct {
+	sock int
+	id   uint16
+}
+
+type ptrQuery struct {
+	addr    net.IP
+	qname   string
+	server  *net.UDPAddr
+	sent    time.Time
+	retryAt time.Time
+	tries   int
+}
+
+// resolver looks up the names of addresses by sending PTR queries to the
+// recursive resolvers from resolv.conf. Unlike net.LookupAddr, it does not
+// wait for one query to be answered before it sends the next one, so a
+// single goroutine feeding it addresses can keep many queries in flight.
+//
+// Queries are spread over a few UDP sockets and servers. A query that
+// times out is sent to the next server, up to resolverTries times. A query
+// that fails with SERVFAIL is sent again after a backoff delay.
+// Results are delivered on the channel returned by results.
+type resolver struct {
+	log     *log.Logger
+	servers []*net.UDPAddr
+	socks   []*net.UDPConn
+	timeout time.Duration
+	lock    sync.Mutex
+	pending map[queryKey]*ptrQuery
+	retry   []*ptrQuery
+	next    int
+	slots   chan struct{}
+	resQ    chan ptrResult
+	done    chan struct{}
+	closed  bool
+}
+
+// resolverServers returns the addresses of the name servers listed in
+// resolv.conf.
+func resolverServers() ([]string, error) {
+	var (
+		err     error
+		cfg     *dns.ClientConfig
+		servers []string
+	)
+
+	if cfg, err = dns.ClientConfigFromFile(resolvConf); err != nil {
+		return nil, err
+	}
+
+	servers = make([]string, len(cfg.Servers))
+	for i, s := range cfg.Servers {
+		servers[i] = net.JoinHostPort(s, cfg.Port)
+	}
+
+	return servers, nil
+} // func resolverServers() ([]string, error)
+
+// newResolver creates a resolver that sends its queries to servers and
+// keeps at most inflight queries outstanding.
+func newResolver(l *log.Logger, servers []string, inflight int, timeout time.Duration) (*resolver, error) {
+	var (
+		err error
+		r   = &resolver{
+			log:     l,
+			servers: make([]*net.UDPAddr, len(servers)),
+			socks:   make([]*net.UDPConn, resolverSockets),
+			timeout: timeout,
+			pending: make(map[queryKey]*ptrQuery, inflight),
+			slots:   make(chan struct{}, inflight),
+			resQ:    make(chan ptrResult, inflight),
+			done:    make(chan struct{}),
+		}
+	)
+
+	if len(servers) == 0 {
+		return nil, errors.New("no name servers to send queries to")
+	} else if inflight < 1 {
+		return nil, fmt.Errorf("invalid number of queries in flight: %d", inflight)
+	}
+
+	for i, s := range servers {
+		if r.servers[i], err = net.ResolveUDPAddr("udp", s); err != nil {
+			return nil, fmt.Errorf("invalid name server address %q: %w", s, err)
+		}
+	}
+
+	for i := range r.socks {
+		if r.socks[i], err = net.ListenUDP("udp", nil); err != nil {
+			r.close()
+			return nil, fmt.Errorf("cannot open UDP socket: %w", err)
+		}
+	}
+
+	for i := range r.socks {
+		go r.receive(i)
+	}
+
+	go r.expire()
+
+	return r, nil
+} // func newResolver(l *log.Logger, servers []string, inflight int, timeout time.Duration) (*resolver, error)
+
+// results returns the channel the resolver delivers its results on.
+func (r *resolver) results() <-chan ptrResult {
+	return r.resQ
+} // func (r *resolver) results() <-chan ptrResult
+
+// lookup sends a PTR query for addr. If there are too many queries in
+// flight already, it blocks until one of them is finished.
+func (r *resolver) lookup(addr net.IP) error {
+	var (
+		err   error
+		qname string
+		q     *ptrQuery
+	)
+
+	if qname, err = dns.ReverseAddr(addr.String()); err != nil {
+		return err
+	}
+
+	select {
+	case r.slots <- struct{}{}:
+	case <-r.done:
+		return errResolverClosed
+	}
+
+	q = &ptrQuery{
+		addr:  addr,
+		qname: qname,
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err = r.send(q); err != nil {
+		<-r.slots
+	}
+
+	return err
+} // func (r *resolver) lookup(addr net.IP) error
+
+// send sends q to the next server, on the next socket. The caller must hold
+// the lock.
+func (r *resolver) send(q *ptrQuery) error {
+	var (
+		err error
+		buf []byte
+		key queryKey
+		msg = new(dns.Msg)
+	)
+
+	if r.closed {
+		return errResolverClosed
+	}
+
+	key.sock = r.next % len(r.socks)
+	q.server = r.servers[r.next%len(r.servers)]
+	r.next++
+
+	// Find an ID that is not taken on this socket.
+	for {
+		key.id = uint16(rand.N(1 << 16))
+		if _, taken := r.pending[key]; !taken {
+			break
+		}
+	}
+
+	msg.SetQuestion(q.qname, dns.TypePTR)
+	msg.Id = key.id
+
+	if buf, err = msg.Pack(); err != nil {
+		return fmt.Errorf("cannot pack query for %s: %w", q.addr, err)
+	} else if _, err = r.socks[key.sock].WriteToUDP(buf, q.server); err != nil {
+		return fmt.Errorf("cannot send query for %s to %s: %w",
+			q.addr,
+			q.server,
+			err)
+	}
+
+	q.sent = time.Now()
+	q.tries++
+	r.pending[key] = q
+	return nil
+} // func (r *resolver) send(q *ptrQuery) error
+
+// finish delivers the result of a query and frees its slot. Once the
+// resolver is closed, nobody may be reading results anymore, so they are
+// dropped.
+func (r *resolver) finish(res ptrResult) {
+	<-r.slots
+	select {
+	case r.resQ <- res:
+	case <-r.done:
+	}
+} // func (r *resolver) finish(res ptrResult)
+
+// receive reads responses from one socket and matches them to the queries
+// we sent.
+func (r *resolver) receive(idx int) {
+	var (
+		buf  = make([]byte, resolverBufSize)
+		sock = r.socks[idx]
+	)
+
+	for {
+		var (
+			err  error
+			n    int
+			from *net.UDPAddr
+			q    *ptrQuery
+			ok   bool
+			msg  = new(dns.Msg)
+		)
+
+		if n, from, err = sock.ReadFromUDP(buf); err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+
+			r.log.Printf("[ERROR] Failed to read from resolver socket #%d: %s\n",
+				idx,
+				err.Error())
+			continue
+		} else if err = msg.Unpack(buf[:n]); err != nil {
+			r.log.Printf("[DEBUG] Cannot parse response from %s: %s\n",
+				from,
+				err.Error())
+			continue
+		}
+
+		var key = queryKey{sock: idx, id: msg.Id}
+
+		r.lock.Lock()
+		if q, ok = r.pending[key]; ok && r.matches(q, from, msg) {
+			delete(r.pending, key)
+		} else {
+			ok = false
+		}
+		r.lock.Unlock()
+
+		if !ok {
+			// Late, duplicate or forged.
+			continue
+		}
+
+		var res = parsePTR(q, msg)
+
+		if res.err != nil && isTransient(res.err) && q.tries < resolverTries {
+			q.retryAt = time.Now().Add(backoff(q.tries))
+			r.lock.Lock()
+			r.retry = append(r.retry, q)
+			r.lock.Unlock()
+			continue
+		}
+
+		r.finish(res)
+	}
+} // func (r *resolver) receive(idx int)
+
+// matches returns true if msg is the response to q.
+func (r *resolver) matches(q *ptrQuery, from *net.UDPAddr, msg *dns.Msg) bool {
+	return msg.Response &&
+		from.IP.Equal(q.server.IP) &&
+		from.Port == q.server.Port &&
+		len(msg.Question) == 1 &&
+		strings.EqualFold(msg.Question[0].Name, q.qname)
+} // func (r *resolver) matches(q *ptrQuery, from *net.UDPAddr, msg *dns.Msg) bool
+
+// parsePTR extracts the result of a query from the response.
+func parsePTR(q *ptrQuery, msg *dns.Msg) ptrResult {
+	var res = ptrResult{
+		addr:   q.addr,
+		server: q.server.String(),
+	}
+
+	switch msg.Rcode {
+	case dns.RcodeSuccess:
+		for _, rr := range msg.Answer {
+			if ptr, ok := rr.(*dns.PTR); ok {
+				res.names = append(res.names, ptr.Ptr)
+			}
+		}
+	case dns.RcodeNameError:
+		res.err = &net.DNSError{
+			UnwrapErr:  rcodeError(msg.Rcode),
+			Err:        "no such host",
+			Name:       q.addr.String(),
+			Server:     res.server,
+			IsNotFound: true,
+		}
+	default:
+		res.err = &net.DNSError{
+			UnwrapErr:   rcodeError(msg.Rcode),
+			Err:         rcodeError(msg.Rcode).Error(),
+			Name:        q.addr.String(),
+			Server:      res.server,
+			IsTemporary: msg.Rcode == dns.RcodeServerFailure,
+		}
+	}
+
+	return res
+} // func parsePTR(q *ptrQuery, msg *dns.Msg) ptrResult
+
+// expire periodically looks for queries that have not been answered in
+// time. They are sent again or, if they have been tried too often, reported
+// as timed out. It also sends the queries that are due for a retry.
+func (r *resolver) expire() {
+	var ticker = time.NewTicker(r.timeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		}
+
+		var (
+			now    = time.Now()
+			failed = make([]ptrResult, 0)
+		)
+
+		r.lock.Lock()
+		r.retry = slices.DeleteFunc(r.retry, func(q *ptrQuery) bool {
+			if now.Before(q.retryAt) {
+				return false
+			} else if err := r.send(q); err != nil {
+				failed = append(failed, ptrResult{
+					addr:   q.addr,
+					server: q.server.String(),
+					err:    err,
+				})
+			}
+
+			return true
+		})
+
+		for key, q := range r.pending {
+			if now.Sub(q.sent) < r.timeout {
+				continue
+			}
+
+			delete(r.pending, key)
+
+			var err error
+
+			if q.tries < resolverTries {
+				if err = r.send(q); err == nil {
+					continue
+				}
+			} else {
+				err = &net.DNSError{
+					Err:       "i/o timeout",
+					Name:      q.addr.String(),
+					Server:    q.server.String(),
+					IsTimeout: true,
+				}
+			}
+
+			failed = append(failed, ptrResult{
+				addr:   q.addr,
+				server: q.server.String(),
+				err:    err,
+			})
+		}
+		r.lock.Unlock()
+
+		for _, res := range failed {
+			r.finish(res)
+		}
+	}
+} // func (r *resolver) expire()
+
+// inflight returns the number of queries waiting for a response.
+func (r *resolver) inflight() int {
+	return len(r.slots)
+} // func (r *resolver) inflight() int
+
+// close closes the resolver's sockets. Queries in flight are lost.
+func (r *resolver) close() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	r.closed = true
+	close(r.done)
+
+	for _, s := range r.socks {
+		if s != nil {
+			s.Close() // nolint: errcheck,gosec
+		}
+	}
+} // func (r *resolver) close()
@@ -0,0 +1,201 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/resolver_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:23:32 krylon>
+
+package generator
+
+import (
+	"errors"
+	"log"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/blicero/guangng/model/outcome"
+	dns "github.com/tonnerre/golang-dns"
+)
+
+// fakeDNS answers PTR queries on a local UDP socket. Addresses in names get
+// a PTR record, or REFUSED if the name is "REFUSED". Addresses in drop get
+// no answer at all, all others get an NXDOMAIN.
+func fakeDNS(t *testing.T, names map[string]string, drop map[string]bool) string {
+	var (
+		err  error
+		conn *net.UDPConn
+	)
+
+	if conn, err = net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}); err != nil {
+		t.Fatalf("Cannot open UDP socket: %s", err.Error())
+	}
+
+	t.Cleanup(func() { conn.Close() }) // nolint: errcheck
+
+	go func() {
+		var buf = make([]byte, 512)
+
+		for {
+			var (
+				n    int
+				from *net.UDPAddr
+				req  = new(dns.Msg)
+				res  = new(dns.Msg)
+				out  []byte
+			)
+
+			if n, from, err = conn.ReadFromUDP(buf); err != nil {
+				return
+			} else if req.Unpack(buf[:n]) != nil {
+				continue
+			}
+
+			var qname = req.Question[0].Name
+
+			if drop[qname] {
+				continue
+			} else if name, ok := names[qname]; ok && name == "REFUSED" {
+				res.SetRcode(req, dns.RcodeRefused)
+			} else if ok {
+				res.SetReply(req)
+				res.Answer = []dns.RR{&dns.PTR{
+					Hdr: dns.RR_Header{
+						Name:   qname,
+						Rrtype: dns.TypePTR,
+						Class:  dns.ClassINET,
+						Ttl:    3600,
+					},
+					Ptr: name,
+				}}
+			} else {
+				res.SetRcode(req, dns.RcodeNameError)
+			}
+
+			if out, err = res.Pack(); err == nil {
+				conn.WriteToUDP(out, from) // nolint: errcheck
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+} // func fakeDNS(t *testing.T, names map[string]string, drop map[string]bool) string
+
+func TestResolver(t *testing.T) {
+	var (
+		err    error
+		r      *resolver
+		server = fakeDNS(t,
+			map[string]string{
+				"1.2.0.192.in-addr.arpa.": "wintermute.straylight.test.",
+				"2.2.0.192.in-addr.arpa.": "neuromancer.straylight.test.",
+				"5.2.0.192.in-addr.arpa.": "REFUSED",
+			},
+			map[string]bool{
+				"4.2.0.192.in-addr.arpa.": true,
+			})
+		expect = map[string]string{
+			"192.0.2.1": "wintermute.straylight.test.",
+			"192.0.2.2": "neuromancer.straylight.test.",
+			"192.0.2.3": "NXDOMAIN",
+			"192.0.2.4": "timeout",
+			"192.0.2.5": "REFUSED",
+		}
+	)
+
+	if r, err = newResolver(log.New(os.Stderr, "", 0), []string{server}, 2, time.Millisecond*200); err != nil {
+		t.Fatalf("Cannot create resolver: %s", err.Error())
+	}
+
+	defer r.close()
+
+	// Only two queries may be in flight, so lookup blocks unless someone
+	// collects the results.
+	go func() {
+		for addr := range expect {
+			if err := r.lookup(net.ParseIP(addr)); err != nil {
+				t.Errorf("Cannot look up %s: %s", addr, err.Error())
+			}
+		}
+	}()
+
+	for range expect {
+		var (
+			res    ptrResult
+			dnsErr *net.DNSError
+		)
+
+		select {
+		case res = <-r.results():
+		case <-time.After(time.Second * 5):
+			t.Fatal("Timed out waiting for results")
+		}
+
+		var exp = expect[res.addr.String()]
+
+		if res.server != server {
+			t.Errorf("Result for %s came from %q, expected %q", res.addr, res.server, server)
+		}
+
+		switch {
+		case exp == "NXDOMAIN":
+			if !errors.As(res.err, &dnsErr) || !dnsErr.IsNotFound {
+				t.Errorf("Expected NXDOMAIN for %s, got %v", res.addr, res.err)
+			} else if o := classifyErr(res.err); o != outcome.NXDomain {
+				t.Errorf("Expected outcome NXDomain for %s, got %s", res.addr, o)
+			}
+		case exp == "REFUSED":
+			if o := classifyErr(res.err); o != outcome.Refused {
+				t.Errorf("Expected outcome Refused for %s, got %s (%v)", res.addr, o, res.err)
+			}
+		case exp == "timeout":
+			if !errors.As(res.err, &dnsErr) || !dnsErr.IsTimeout {
+				t.Errorf("Expected a timeout for %s, got %v", res.addr, res.err)
+			}
+		case res.err != nil:
+			t.Errorf("Failed to look up %s: %s", res.addr, res.err.Error())
+		case len(res.names) != 1 || res.names[0] != exp:
+			t.Errorf("Expected %s for %s, got %v", exp, res.addr, res.names)
+		}
+	}
+
+	if n := r.inflight(); n != 0 {
+		t.Errorf("%d queries are still in flight", n)
+	}
+} // func TestResolver(t *testing.T)
+
+func TestResolverClose(t *testing.T) {
+	var (
+		err    error
+		r      *resolver
+		server = fakeDNS(t, map[string]string{}, nil)
+		done   = make(chan struct{})
+	)
+
+	if r, err = newResolver(log.New(os.Stderr, "", 0), []string{server}, 1, time.Millisecond*200); err != nil {
+		t.Fatalf("Cannot create resolver: %s", err.Error())
+	}
+
+	// Nobody collects results, and there is one waiting already, so
+	// delivering another one blocks until the resolver is closed.
+	r.resQ <- ptrResult{addr: net.ParseIP("192.0.2.1")}
+	r.slots <- struct{}{}
+
+	go func() {
+		defer close(done)
+		r.finish(ptrResult{addr: net.ParseIP("192.0.2.2")})
+	}()
+
+	time.Sleep(time.Millisecond * 100)
+	r.close()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("Delivering a result still blocks after the resolver was closed")
+	}
+
+	if err = r.lookup(net.ParseIP("192.0.2.3")); !errors.Is(err, errResolverClosed) {
+		t.Errorf("Expected lookup on closed resolver to fail, got %v", err)
+	}
+} // func TestResolverClose(t *testing.T)
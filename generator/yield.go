@@ -0,0 +1,327 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/yield.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-21 10:52:08 krylon>
+
+package generator
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/model"
+)
+
+// Most random addresses have no PTR record. To waste less time on them, we
+// keep track of how many addresses in each /16 (and in each /24 that has
+// produced at least one Host) we have tried and how many of them turned out
+// to be Hosts, and prefer the networks that do better than average.
+// A share of the addresses, the exploration ratio, is still drawn at
+// random, so we keep learning about the rest of the address space.
+const (
+	// yieldFlushInterval is how often we save the statistics.
+	yieldFlushInterval = time.Minute
+	// yieldRebuildInterval is how often we recompute the weights of the
+	// networks at most.
+	yieldRebuildInterval = time.Second * 10
+	// yieldPriorWeight is how many attempts the overall hit rate is worth
+	// when we estimate the hit rate of a network. The smaller it is, the
+	// quicker we jump to conclusions.
+	yieldPriorWeight = 8
+	// yieldDefaultRate is the hit rate we assume before we know better.
+	yieldDefaultRate = 0.1
+	// yieldSubnetShare is the probability that, once we have picked a /16
+	// with productive /24s in it, we pick one of those.
+	yieldSubnetShare = 0.5
+)
+
+type yieldOutcome uint8
+
+const (
+	yieldHit     yieldOutcome = iota // The address has a usable name
+	yieldMiss                        // The address has no name
+	yieldBlocked                     // The name of the address is blacklisted
+)
+
+type yieldCount struct {
+	attempts int64
+	hits     int64
+	blocked  int64
+	dirty    bool
+}
+
+func (c *yieldCount) add(o yieldOutcome) {
+	c.attempts++
+	c.dirty = true
+
+	switch o {
+	case yieldHit:
+		c.hits++
+	case yieldBlocked:
+		c.blocked++
+	}
+} // func (c *yieldCount) add(o yieldOutcome)
+
+// weight estimates the hit rate of the network, assuming it is close to
+// the overall rate as long as we know little about it.
+func (c *yieldCount) weight(rate float64) float64 {
+	return (float64(c.hits) + yieldPriorWeight*rate) /
+		(float64(c.attempts) + yieldPriorWeight)
+} // func (c *yieldCount) weight(rate float64) float64
+
+// yieldTracker keeps the yield statistics and picks addresses based on them.
+type yieldTracker struct {
+	lock    sync.RWMutex
+	dbLock  sync.Mutex // Serializes flushes, the Database is not safe for concurrent use
+	log     *log.Logger
+	db      *database.Database
+	explore float64
+	net16   []yieldCount
+	net24   map[uint32]*yieldCount
+	prod24  map[uint16][]uint32
+	cumul   []float64
+	rate    float64
+	stale   bool
+	built   time.Time
+}
+
+// newYieldTracker creates a yieldTracker and loads the statistics we saved
+// earlier. explore is the share of addresses to draw at random.
+func newYieldTracker(l *log.Logger, explore float64) (*yieldTracker, error) {
+	var (
+		err  error
+		list []*model.NetYield
+		y    = &yieldTracker{
+			log:     l,
+			explore: explore,
+			net16:   make([]yieldCount, 1<<16),
+			net24:   make(map[uint32]*yieldCount),
+			prod24:  make(map[uint16][]uint32),
+			cumul:   make([]float64, 1<<16),
+		}
+	)
+
+	if explore < 0 || explore > 1 {
+		return nil, fmt.Errorf("exploration ratio must be between 0 and 1, not %f", explore)
+	} else if y.db, err = database.Open(common.DbPath); err != nil {
+		return nil, fmt.Errorf("cannot open database: %w", err)
+	} else if list, err = y.db.NetYieldGetAll(); err != nil {
+		y.db.Close() // nolint: errcheck
+		return nil, err
+	}
+
+	for _, n := range list {
+		var c = yieldCount{attempts: n.Attempts, hits: n.Hits, blocked: n.Blocked}
+
+		switch n.Bits {
+		case 16:
+			y.net16[n.Prefix>>16] = c
+		case 24:
+			y.net24[n.Prefix>>8] = &c
+			y.prod24[uint16(n.Prefix>>16)] = append(y.prod24[uint16(n.Prefix>>16)], n.Prefix>>8)
+		}
+	}
+
+	y.rebuild()
+	y.log.Printf("[INFO] Loaded yield statistics for %d networks, overall hit rate is %.2f%%\n",
+		len(list),
+		y.rate*100)
+
+	return y, nil
+} // func newYieldTracker(l *log.Logger, explore float64) (*yieldTracker, error)
+
+// record adds the outcome of looking at an address to the statistics.
+func (y *yieldTracker) record(addr net.IP, o yieldOutcome) {
+	var ip4 = addr.To4()
+
+	if ip4 == nil {
+		return
+	}
+
+	var (
+		c   *yieldCount
+		v   = binary.BigEndian.Uint32(ip4)
+		k16 = uint16(v >> 16)
+		k24 = v >> 8
+	)
+
+	y.lock.Lock()
+	defer y.lock.Unlock()
+
+	y.net16[k16].add(o)
+	y.stale = true
+
+	// We only keep track of /24s once they have given us a Host, or we
+	// would end up with an entry for every /24 we ever looked at.
+	if c = y.net24[k24]; c != nil {
+		c.add(o)
+	} else if o == yieldHit {
+		c = new(yieldCount)
+		c.add(o)
+		y.net24[k24] = c
+		y.prod24[k16] = append(y.prod24[k16], k24)
+	}
+} // func (y *yieldTracker) record(addr net.IP, o yieldOutcome)
+
+// rebuild recomputes the overall hit rate and the cumulative weights of the
+// /16s. The caller must hold the write lock, unless it is the constructor.
+func (y *yieldTracker) rebuild() {
+	var (
+		attempts, hits int64
+		sum            float64
+	)
+
+	for i := range y.net16 {
+		attempts += y.net16[i].attempts
+		hits += y.net16[i].hits
+	}
+
+	if y.rate = yieldDefaultRate; attempts > 0 && hits > 0 {
+		y.rate = float64(hits) / float64(attempts)
+	}
+
+	for i := range y.net16 {
+		sum += y.net16[i].weight(y.rate)
+		y.cumul[i] = sum
+	}
+
+	y.stale = false
+	y.built = time.Now()
+} // func (y *yieldTracker) rebuild()
+
+// pick returns an address as an integer. If explore is true, or if we
+// decide to explore anyway, the address is drawn uniformly from the whole
+// address space, otherwise from a network picked by its weight.
+// biased is true if the address was not drawn uniformly.
+func (y *yieldTracker) pick(explore bool) (addr uint32, biased bool) {
+	if explore || y.explore >= 1 || rand.Float64() < y.explore { // nolint: gosec
+		return rand.Uint32(), false // nolint: gosec
+	}
+
+	y.lock.RLock()
+	if y.stale && time.Since(y.built) >= yieldRebuildInterval {
+		y.lock.RUnlock()
+		y.lock.Lock()
+		if y.stale && time.Since(y.built) >= yieldRebuildInterval {
+			y.rebuild()
+		}
+		y.lock.Unlock()
+		y.lock.RLock()
+	}
+	defer y.lock.RUnlock()
+
+	var (
+		r    = rand.Float64() * y.cumul[len(y.cumul)-1] // nolint: gosec
+		k16  = min(sort.SearchFloat64s(y.cumul, r), len(y.cumul)-1)
+		subs = y.prod24[uint16(k16)]
+	)
+
+	if len(subs) == 0 || rand.Float64() >= yieldSubnetShare { // nolint: gosec
+		return uint32(k16)<<16 | uint32(rand.N(1<<16)), true // nolint: gosec
+	}
+
+	// There are usually only a few productive /24s in a /16, so we can
+	// afford to pick one the simple way.
+	var total float64
+
+	for _, k := range subs {
+		total += y.net24[k].weight(y.rate)
+	}
+
+	r = rand.Float64() * total // nolint: gosec
+
+	for _, k := range subs {
+		if r -= y.net24[k].weight(y.rate); r <= 0 {
+			return k<<8 | uint32(rand.N(256)), true // nolint: gosec
+		}
+	}
+
+	return subs[len(subs)-1]<<8 | uint32(rand.N(256)), true // nolint: gosec
+} // func (y *yieldTracker) pick(explore bool) (addr uint32, biased bool)
+
+// flush saves the statistics of all networks that have changed since the
+// last flush.
+func (y *yieldTracker) flush() error {
+	var (
+		err   error
+		dirty = make([]*model.NetYield, 0, 256)
+	)
+
+	y.dbLock.Lock()
+	defer y.dbLock.Unlock()
+
+	y.lock.Lock()
+	for i := range y.net16 {
+		if c := &y.net16[i]; c.dirty {
+			c.dirty = false
+			dirty = append(dirty, &model.NetYield{
+				Prefix:   uint32(i) << 16,
+				Bits:     16,
+				Attempts: c.attempts,
+				Hits:     c.hits,
+				Blocked:  c.blocked,
+			})
+		}
+	}
+
+	for k, c := range y.net24 {
+		if c.dirty {
+			c.dirty = false
+			dirty = append(dirty, &model.NetYield{
+				Prefix:   k << 8,
+				Bits:     24,
+				Attempts: c.attempts,
+				Hits:     c.hits,
+				Blocked:  c.blocked,
+			})
+		}
+	}
+	y.lock.Unlock()
+
+	if len(dirty) == 0 {
+		return nil
+	} else if err = y.db.Begin(); err != nil {
+		y.markDirty(dirty)
+		return fmt.Errorf("cannot start transaction: %w", err)
+	}
+
+	for _, n := range dirty {
+		if err = y.db.NetYieldSave(n); err != nil {
+			y.db.Rollback() // nolint: errcheck
+			y.markDirty(dirty)
+			return err
+		}
+	}
+
+	if err = y.db.Commit(); err != nil {
+		y.markDirty(dirty)
+		return fmt.Errorf("cannot commit transaction: %w", err)
+	}
+
+	y.log.Printf("[DEBUG] Saved yield statistics of %d networks\n", len(dirty))
+	return nil
+} // func (y *yieldTracker) flush() error
+
+// markDirty marks the given networks as changed again after we failed to
+// save them, so the next flush tries again.
+func (y *yieldTracker) markDirty(list []*model.NetYield) {
+	y.lock.Lock()
+	defer y.lock.Unlock()
+
+	for _, n := range list {
+		if n.Bits == 16 {
+			y.net16[n.Prefix>>16].dirty = true
+		} else if c := y.net24[n.Prefix>>8]; c != nil {
+			c.dirty = true
+		}
+	}
+} // func (y *yieldTracker) markDirty(list []*model.NetYield)
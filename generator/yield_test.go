@@ -0,0 +1,107 @@
+// /home/krylon/go/src/github.com/blicero/guangng/generator/yield_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-21 11:40:19 krylon>
+
+package generator
+
+import (
+	"net"
+	"testing"
+)
+
+// testTracker returns a yieldTracker that does not talk to a Database.
+func testTracker(explore float64) *yieldTracker {
+	var y = &yieldTracker{
+		explore: explore,
+		net16:   make([]yieldCount, 1<<16),
+		net24:   make(map[uint32]*yieldCount),
+		prod24:  make(map[uint16][]uint32),
+		cumul:   make([]float64, 1<<16),
+	}
+
+	y.rebuild()
+	return y
+} // func testTracker(explore float64) *yieldTracker
+
+func TestYieldRecord(t *testing.T) {
+	var y = testTracker(0)
+
+	y.record(net.ParseIP("10.1.2.3"), yieldMiss)
+	y.record(net.ParseIP("10.1.3.3"), yieldBlocked)
+
+	if len(y.net24) != 0 {
+		t.Errorf("Misses created %d /24 entries", len(y.net24))
+	}
+
+	y.record(net.ParseIP("10.1.2.4"), yieldHit)
+	y.record(net.ParseIP("10.1.2.5"), yieldMiss)
+
+	var c16, c24 = y.net16[10<<8|1], y.net24[10<<16|1<<8|2]
+
+	if c16.attempts != 4 || c16.hits != 1 || c16.blocked != 1 {
+		t.Errorf("Unexpected counts for 10.1.0.0/16: %#v", c16)
+	} else if c24 == nil {
+		t.Fatal("Hit did not create an entry for 10.1.2.0/24")
+	} else if c24.attempts != 2 || c24.hits != 1 {
+		t.Errorf("Unexpected counts for 10.1.2.0/24: %#v", c24)
+	} else if len(y.prod24[10<<8|1]) != 1 {
+		t.Errorf("Expected 1 productive /24 in 10.1.0.0/16, got %d", len(y.prod24[10<<8|1]))
+	}
+} // func TestYieldRecord(t *testing.T)
+
+func TestYieldPick(t *testing.T) {
+	const picks = 10000
+	var (
+		y              = testTracker(0)
+		hit            = net.ParseIP("10.1.2.3")
+		in16, in24, bs int
+	)
+
+	// Pretend we have looked at every /16 a lot and found nothing but
+	// in 10.1.0.0/16.
+	for i := range y.net16 {
+		y.net16[i].attempts = 1000
+	}
+
+	for range 50 {
+		y.record(hit, yieldHit)
+	}
+
+	y.rebuild()
+
+	for range picks {
+		var addr, biased = y.pick(false)
+
+		if biased {
+			bs++
+		}
+
+		if addr>>16 == 10<<8|1 {
+			in16++
+		}
+
+		if addr>>8 == 10<<16|1<<8|2 {
+			in24++
+		}
+	}
+
+	if bs != picks {
+		t.Errorf("Only %d of %d addresses were biased with an exploration ratio of 0", bs, picks)
+	} else if in16 < picks*9/10 {
+		t.Errorf("Only %d of %d addresses are in the productive /16", in16, picks)
+	} else if in24 < picks/4 {
+		t.Errorf("Only %d of %d addresses are in the productive /24", in24, picks)
+	}
+
+	if _, biased := y.pick(true); biased {
+		t.Error("Address was biased, although we asked for exploration")
+	}
+
+	y.explore = 1
+
+	if _, biased := y.pick(false); biased {
+		t.Error("Address was biased with an exploration ratio of 1")
+	}
+} // func TestYieldPick(t *testing.T)
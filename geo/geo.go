@@ -0,0 +1,321 @@
+// /home/krylon/go/src/github.com/blicero/guangng/geo/geo.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:36:33 krylon>
+
+// Package geo looks up the locations of Hosts in the background.
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/events"
+	"github.com/blicero/guangng/logdomain"
+	"github.com/blicero/guangng/metrics"
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/meta"
+	"github.com/blicero/guangng/model/subsystem"
+	"github.com/blicero/krylib"
+)
+
+const (
+	// batchSize is the number of Hosts the feeder fetches at once.
+	batchSize = 256
+	// recheckAfter is how long we wait before looking up a Host's
+	// location again, in case the GeoIP database has been updated.
+	recheckAfter = time.Hour * 24 * 30
+)
+
+// Geo looks up the locations of Hosts that have not been located yet.
+type Geo struct {
+	log       *log.Logger
+	active    atomic.Bool
+	gcnt      atomic.Int32
+	idCounter atomic.Int64
+	goalCnt   int
+	cmdQ      chan bool
+	hostQ     chan *model.Host
+	pending   atomic.Int64
+	pool      *database.Pool
+	eng       *meta.MetaEngine
+}
+
+// New returns a new Geo instance that returns place names in the given
+// language. If the GeoIP databases are missing, the Geo is disabled, i.e.
+// Start does nothing.
+func New(cnt int, lang string) (*Geo, error) {
+	var (
+		err  error
+		gcnt = max(cnt, 2)
+		g    = &Geo{
+			goalCnt: cnt,
+		}
+	)
+
+	if g.log, err = common.GetLogger(logdomain.Geo); err != nil {
+		return nil, err
+	} else if !slices.Contains(meta.Languages, lang) {
+		// Check this even if we end up disabled, so a typo does not go
+		// unnoticed until the GeoIP databases are installed.
+		err = fmt.Errorf("unsupported language %q, must be one of %s",
+			lang,
+			strings.Join(meta.Languages, ", "))
+		g.log.Printf("[ERROR] %s\n", err.Error())
+		return nil, err
+	} else if g.eng, err = meta.OpenMetaEngine(""); err != nil {
+		if errors.Is(err, meta.ErrNoDatabase) {
+			g.log.Printf("[WARN] Geolocation is disabled: %s\n",
+				err.Error())
+			g.eng = nil
+			return g, nil
+		}
+
+		g.log.Printf("[ERROR] Failed to open MetaEngine: %s\n",
+			err.Error())
+		return nil, err
+	} else if err = g.eng.SetLanguage(lang); err != nil {
+		g.log.Printf("[ERROR] %s\n", err.Error())
+		g.eng.Close()
+		return nil, err
+	} else if g.pool, err = database.NewPool(2); err != nil {
+		g.log.Printf("[ERROR] Failed to create DB pool: %s\n",
+			err.Error())
+		g.eng.Close()
+		return nil, err
+	}
+
+	g.cmdQ = make(chan bool, gcnt)
+	g.hostQ = make(chan *model.Host, batchSize)
+
+	return g, nil
+} // func New(cnt int, lang string) (*Geo, error)
+
+func (g *Geo) getID() int {
+	var val = g.idCounter.Add(1)
+	return int(val)
+} // func (g *Geo) getID() int
+
+// IsEnabled returns true if the GeoIP databases are available.
+func (g *Geo) IsEnabled() bool {
+	return g.eng != nil
+} // func (g *Geo) IsEnabled() bool
+
+// IsActive returns the Geo's active flag.
+func (g *Geo) IsActive() bool {
+	return g.active.Load()
+} // func (g *Geo) IsActive() bool
+
+// Start sets the Geo's active flag and starts the set number of workers.
+func (g *Geo) Start() {
+	if !g.IsEnabled() {
+		g.log.Println("[INFO] Geolocation is disabled, not starting.")
+		return
+	}
+
+	g.active.Store(true)
+	events.State(subsystem.Geo, true)
+
+	go g.feeder()
+
+	for range g.goalCnt {
+		go g.worker(g.getID())
+	}
+} // func (g *Geo) Start()
+
+// Stop clears the Geo's active flag.
+func (g *Geo) Stop() {
+	if !g.active.Swap(false) {
+		return
+	}
+
+	events.State(subsystem.Geo, false)
+} // func (g *Geo) Stop()
+
+// StartOne starts an additional worker.
+func (g *Geo) StartOne() {
+	if !g.active.Load() {
+		g.log.Println("[INFO] Geolocation is not active, not starting a worker.")
+		return
+	}
+
+	go g.worker(g.getID())
+} // func (g *Geo) StartOne()
+
+// StopOne stops one worker.
+func (g *Geo) StopOne() {
+	if g.WorkerCount() == 0 {
+		return
+	}
+
+	g.cmdQ <- true
+} // func (g *Geo) StopOne()
+
+// WorkerCount returns the number of active workers.
+func (g *Geo) WorkerCount() int {
+	return int(g.gcnt.Load())
+} // func (g *Geo) WorkerCount() int
+
+func (g *Geo) System() subsystem.ID {
+	return subsystem.Geo
+} // func (g *Geo) System() subsystem.ID
+
+// QueueDepths returns the number of items waiting in the Geo's queues.
+func (g *Geo) QueueDepths() map[string]int {
+	return map[string]int{
+		"hostQ": len(g.hostQ),
+	}
+} // func (g *Geo) QueueDepths() map[string]int
+
+// feeder fetches Hosts that need to be located from the Database and hands
+// them to the workers.
+func (g *Geo) feeder() {
+	g.log.Println("[DEBUG] Geo feeder starting up...")
+	defer g.log.Println("[DEBUG] Geo feeder quitting...")
+
+	var (
+		err    error
+		db     *database.Database
+		ticker *time.Ticker
+		delay  = 1
+	)
+
+	db = g.pool.Get()
+	defer g.pool.Put(db)
+
+	ticker = time.NewTicker(common.ActiveTimeout)
+	defer ticker.Stop()
+
+	for g.active.Load() {
+		var hosts []*model.Host
+
+		if hosts, err = db.HostGetGeoPending(batchSize, time.Now().Add(-recheckAfter)); err != nil {
+			g.log.Printf("[ERROR] Failed to get Hosts to locate: %s\n",
+				err.Error())
+			g.Stop()
+			return
+		} else if len(hosts) == 0 {
+			delay = min(delay+1, 10)
+			g.log.Println("[TRACE] No Hosts need to be located, maybe next time...")
+			time.Sleep(common.ActiveTimeout * time.Duration(krylib.Fibonacci(delay)))
+			continue
+		}
+
+		delay = 1
+
+		for _, h := range hosts {
+			if !g.send(h, ticker.C) {
+				return
+			}
+		}
+
+		// The workers have to be done with the batch before we ask
+		// the Database again, or we would fetch the same Hosts twice.
+		// An empty queue is not enough, a worker may have taken a
+		// Host without having stored its location yet, so we count
+		// the Hosts that are not done.
+		g.waitBatch()
+	}
+} // func (g *Geo) feeder()
+
+// send hands h to the workers and counts it as pending. h is counted
+// before it is sent, so a worker cannot be done with it before it counts.
+// If the Geo is stopped before a worker takes h, send returns false and h
+// does not count.
+func (g *Geo) send(h *model.Host, tick <-chan time.Time) bool {
+	g.pending.Add(1)
+
+SEND:
+	select {
+	case <-tick:
+		if !g.active.Load() {
+			g.pending.Add(-1)
+			return false
+		}
+		goto SEND
+	case g.hostQ <- h:
+		return true
+	}
+} // func (g *Geo) send(h *model.Host, tick <-chan time.Time) bool
+
+// waitBatch waits until the workers are done with all pending Hosts or
+// the Geo is stopped.
+func (g *Geo) waitBatch() {
+	for g.pending.Load() > 0 && g.active.Load() {
+		time.Sleep(time.Millisecond * 250)
+	}
+} // func (g *Geo) waitBatch()
+
+func (g *Geo) worker(id int) {
+	g.log.Printf("[DEBUG] geoWorker#%02d starting up...\n", id)
+	defer g.log.Printf("[DEBUG] geoWorker#%02d quitting...\n", id)
+
+	var (
+		db     *database.Database
+		ticker *time.Ticker
+	)
+
+	events.Workers(subsystem.Geo, int(g.gcnt.Add(1)))
+	defer func() {
+		events.Workers(subsystem.Geo, int(g.gcnt.Add(-1)))
+	}()
+
+	db = g.pool.Get()
+	defer g.pool.Put(db)
+
+	ticker = time.NewTicker(common.ActiveTimeout)
+	defer ticker.Stop()
+
+	for g.active.Load() {
+		select {
+		case <-ticker.C:
+			continue
+		case <-g.cmdQ:
+			return
+		case h := <-g.hostQ:
+			g.locate(db, h)
+			g.pending.Add(-1)
+		}
+	}
+} // func (g *Geo) worker(id int)
+
+// locate looks up the location of a Host and stores it in the Database.
+func (g *Geo) locate(db *database.Database, h *model.Host) {
+	var (
+		err error
+		loc *model.GeoLocation
+	)
+
+	if loc, err = g.eng.LookupLocation(h); err != nil {
+		metrics.GeoLookups.With("error").Inc()
+		g.log.Printf("[ERROR] Cannot look up location of %s: %s\n",
+			h.AStr(),
+			err.Error())
+		// We still record the attempt, so we don't try again right
+		// away.
+		loc = nil
+	} else if loc == nil {
+		metrics.GeoLookups.With("unknown").Inc()
+	} else {
+		metrics.GeoLookups.With("success").Inc()
+	}
+
+	if err = db.HostSetLocation(h, loc); err != nil {
+		g.log.Printf("[ERROR] Failed to store location of %s: %s\n",
+			h.AStr(),
+			err.Error())
+	} else if loc != nil {
+		g.log.Printf("[TRACE] %s (%s) is located in %s\n",
+			h.Name,
+			h.AStr(),
+			h.Location)
+	}
+} // func (g *Geo) locate(db *database.Database, h *model.Host)
@@ -0,0 +1,133 @@
+// /home/krylon/go/src/github.com/blicero/guangng/geo/geo_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:36:33 krylon>
+
+package geo
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/model"
+)
+
+func TestMain(m *testing.M) {
+	var (
+		err     error
+		result  int
+		baseDir = time.Now().Format("/tmp/guangng_geo_test_20060102_150405")
+	)
+
+	if err = common.SetBaseDir(baseDir); err != nil {
+		fmt.Printf("Cannot set base directory to %s: %s\n",
+			baseDir,
+			err.Error())
+		os.Exit(1)
+	} else if result = m.Run(); result == 0 {
+		fmt.Printf("Removing BaseDir %s\n",
+			baseDir)
+		_ = os.RemoveAll(baseDir)
+	} else {
+		fmt.Printf(">>> TEST DIRECTORY: %s\n", baseDir)
+	}
+
+	os.Exit(result)
+} // func TestMain(m *testing.M)
+
+// The test environment has no GeoIP databases, so the Geo should disable
+// itself instead of failing.
+func TestDisabled(t *testing.T) {
+	var (
+		err error
+		g   *Geo
+	)
+
+	if g, err = New(2, "de"); err != nil {
+		t.Fatalf("Failed to create Geo without GeoIP databases: %s",
+			err.Error())
+	} else if g.IsEnabled() {
+		t.Fatal("Geo claims to be enabled without GeoIP databases")
+	}
+
+	g.Start()
+
+	if g.IsActive() {
+		t.Error("Disabled Geo became active")
+	}
+
+	g.StartOne()
+	g.StopOne()
+
+	if cnt := g.WorkerCount(); cnt != 0 {
+		t.Errorf("Disabled Geo has %d workers", cnt)
+	}
+
+	g.Stop()
+} // func TestDisabled(t *testing.T)
+
+func TestBadLanguage(t *testing.T) {
+	if _, err := New(2, "tlh"); err == nil {
+		t.Error("New accepted an unsupported language")
+	}
+} // func TestBadLanguage(t *testing.T)
+
+// If the Geo is stopped while the feeder is waiting for a worker to take a
+// Host, that Host must not count as pending, or the feeder would wait for
+// it forever after the next Start.
+func TestStopWhileSending(t *testing.T) {
+	var (
+		g = &Geo{
+			log:   log.New(io.Discard, "", 0),
+			hostQ: make(chan *model.Host),
+		}
+		ticker = time.NewTicker(time.Millisecond * 10)
+		sent   = make(chan bool)
+		waited = make(chan struct{})
+	)
+
+	defer ticker.Stop()
+
+	g.active.Store(true)
+
+	go func() {
+		sent <- g.send(&model.Host{Addr: net.ParseIP("192.0.2.1")}, ticker.C)
+	}()
+
+	time.Sleep(time.Millisecond * 50)
+	g.Stop()
+
+	select {
+	case ok := <-sent:
+		if ok {
+			t.Fatal("send claims to have sent a Host nobody took")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("send did not give up after Stop")
+	}
+
+	if n := g.pending.Load(); n != 0 {
+		t.Errorf("%d Hosts are pending after Stop, expected 0", n)
+	}
+
+	g.active.Store(true)
+	defer g.active.Store(false)
+
+	go func() {
+		g.waitBatch()
+		close(waited)
+	}()
+
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Error("Feeder is still waiting for a Host that was never sent")
+	}
+} // func TestStopWhileSending(t *testing.T)
@@ -1,4 +1,4 @@
-// Time-stamp: <2026-01-26 14:06:47 krylon>
+// Time-stamp: <2026-10-19 14:44:56 krylon>
 module github.com/blicero/guangng
 
 go 1.25.1
@@ -15,7 +15,10 @@ require (
 	github.com/gorilla/mux v1.8.1
 	github.com/mattn/go-sqlite3 v1.14.33
 	github.com/mborgerson/GoTruncateHtml v0.0.0-20150507032438-125d9154cd1e
+	github.com/oschwald/geoip2-golang/v2 v2.1.0
 	github.com/tonnerre/golang-dns v0.0.0-20130925195549-c07f3c3cc475
+	golang.org/x/crypto v0.41.0
+	golang.org/x/net v0.43.0
 )
 
 require (
@@ -27,11 +30,10 @@ require (
 	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/golang/protobuf v1.5.0 // indirect
 	github.com/google/go-cmp v0.7.0 // indirect
-	github.com/oschwald/geoip2-golang/v2 v2.1.0 // indirect
 	github.com/oschwald/maxminddb-golang/v2 v2.1.1 // indirect
 	github.com/pkg/errors v0.8.1 // indirect
 	github.com/stretchr/testify v1.11.1 // indirect
-	golang.org/x/net v0.43.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
+	golang.org/x/text v0.28.0 // indirect
 	google.golang.org/protobuf v1.36.7 // indirect
 )
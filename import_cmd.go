@@ -0,0 +1,97 @@
+// /home/krylon/go/src/github.com/blicero/guangng/import_cmd.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 20:52:19 krylon>
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/importer"
+)
+
+// runImport implements the "import" subcommand. It returns the exit code
+// for the process.
+func runImport(args []string) int {
+	var (
+		err     error
+		db      *database.Database
+		imp     *importer.Importer
+		fmtName string
+		load    func(io.Reader) (importer.Stats, error)
+		flags   = flag.NewFlagSet("import", flag.ExitOnError)
+	)
+
+	flags.StringVar(&fmtName, "format", "nmap", "Input format (nmap, masscan)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s import [-format nmap|masscan] FILE...\n",
+			os.Args[0])
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(args) // nolint: errcheck
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 1
+	} else if db, err = database.Open(common.DbPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot open database %s: %s\n",
+			common.DbPath,
+			err.Error())
+		return 1
+	}
+
+	defer db.Close() // nolint: errcheck
+
+	if imp, err = importer.New(db); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot create Importer: %s\n",
+			err.Error())
+		return 1
+	}
+
+	switch fmtName {
+	case "nmap", "xml":
+		load = imp.ImportNmap
+	case "masscan", "json":
+		load = imp.ImportMasscan
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown import format %q\n", fmtName)
+		return 1
+	}
+
+	for _, path := range flags.Args() {
+		var (
+			fh    *os.File
+			stats importer.Stats
+		)
+
+		if fh, err = os.Open(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot open %s: %s\n",
+				path,
+				err.Error())
+			return 1
+		}
+
+		stats, err = load(bufio.NewReader(fh))
+		fh.Close() // nolint: errcheck
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to import %s: %s\n",
+				path,
+				err.Error())
+			return 1
+		}
+
+		fmt.Printf("%s: %s\n", path, stats)
+	}
+
+	return 0
+} // func runImport(args []string) int
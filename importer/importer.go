@@ -0,0 +1,193 @@
+// /home/krylon/go/src/github.com/blicero/guangng/importer/importer.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:37:46 krylon>
+
+// Package importer reads the results of scans performed by other tools and
+// feeds the Hosts and open ports into our Database, so the Scanner can
+// probe them.
+package importer
+
+import (
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/blicero/guangng/blacklist"
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/logdomain"
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/hsrc"
+	"github.com/blicero/guangng/model/probe"
+)
+
+// batchSize is the number of Hosts we process per database transaction.
+const batchSize = 256
+
+// Stats summarizes the outcome of an import.
+type Stats struct {
+	HostsAdded   int64
+	HostsKnown   int64
+	HostsSkipped int64
+	PortsQueued  int64
+	PortsSkipped int64
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%d Hosts added, %d already known, %d skipped, %d ports queued, %d ports skipped",
+		s.HostsAdded,
+		s.HostsKnown,
+		s.HostsSkipped,
+		s.PortsQueued,
+		s.PortsSkipped)
+} // func (s Stats) String() string
+
+// openPort is a port another scanner reported as open.
+type openPort struct {
+	port  uint16
+	proto string
+}
+
+// record is a Host as reported by another scanner.
+type record struct {
+	addr  net.IP
+	name  string
+	ports []openPort
+}
+
+// Importer adds Hosts and ports found by other scanners to the Database.
+type Importer struct {
+	log    *log.Logger
+	db     *database.Database
+	blAddr *blacklist.BlacklistAddr
+	stats  Stats
+	txCnt  int
+	seen   map[string]bool
+}
+
+// New creates an Importer that writes to the given Database.
+func New(db *database.Database) (*Importer, error) {
+	var (
+		err error
+		imp = &Importer{
+			db:     db,
+			blAddr: blacklist.NewBlacklistAddr(),
+		}
+	)
+
+	if imp.log, err = common.GetLogger(logdomain.Import); err != nil {
+		return nil, err
+	}
+
+	return imp, nil
+} // func New(db *database.Database) (*Importer, error)
+
+// begin resets the statistics before an import.
+func (imp *Importer) begin() {
+	imp.stats = Stats{}
+	imp.txCnt = 0
+	imp.seen = make(map[string]bool)
+} // func (imp *Importer) begin()
+
+// add stores a single record, starting a new transaction every batchSize
+// records.
+func (imp *Importer) add(rec *record) error {
+	var (
+		err  error
+		host *model.Host
+	)
+
+	if rec.addr == nil || len(rec.ports) == 0 {
+		imp.stats.HostsSkipped++
+		return nil
+	} else if imp.blAddr.Match(rec.addr) {
+		imp.log.Printf("[DEBUG] Skip blacklisted address %s\n",
+			rec.addr)
+		imp.stats.HostsSkipped++
+		return nil
+	}
+
+	if imp.txCnt == 0 {
+		if err = imp.db.Begin(); err != nil {
+			imp.log.Printf("[ERROR] Cannot start transaction: %s\n",
+				err.Error())
+			return err
+		}
+	}
+
+	if host, err = imp.db.HostGetByAddr(rec.addr); err != nil {
+		goto FAIL
+	} else if host != nil {
+		// masscan reports each port as a separate record, we only
+		// want to count each Host once.
+		if !imp.seen[host.AStr()] {
+			imp.stats.HostsKnown++
+		}
+	} else {
+		host = &model.Host{
+			Addr:   rec.addr,
+			Name:   rec.name,
+			Source: hsrc.Import,
+		}
+
+		if err = imp.db.HostAdd(host); err != nil {
+			goto FAIL
+		}
+
+		imp.stats.HostsAdded++
+	}
+
+	imp.seen[host.AStr()] = true
+
+	for _, p := range rec.ports {
+		// The Scanner can only probe a few services via UDP, there is
+		// no point in queueing a UDP port it cannot probe.
+		if p.proto == "udp" && !probe.ForPort(p.port).UDP() {
+			imp.stats.PortsSkipped++
+			continue
+		} else if err = imp.db.PortQueueAdd(host, p.port, p.proto, probe.Auto); err != nil {
+			goto FAIL
+		}
+		imp.stats.PortsQueued++
+	}
+
+	if imp.txCnt++; imp.txCnt >= batchSize {
+		return imp.commit()
+	}
+
+	return nil
+
+FAIL:
+	imp.db.Rollback() // nolint: errcheck
+	imp.txCnt = 0
+	return err
+} // func (imp *Importer) add(rec *record) error
+
+// commit commits the pending transaction, if there is one.
+func (imp *Importer) commit() error {
+	if imp.txCnt == 0 {
+		return nil
+	}
+
+	imp.txCnt = 0
+
+	if err := imp.db.Commit(); err != nil {
+		imp.log.Printf("[ERROR] Cannot commit transaction: %s\n",
+			err.Error())
+		return err
+	}
+
+	return nil
+} // func (imp *Importer) commit() error
+
+// abort rolls back the pending transaction, if there is one.
+func (imp *Importer) abort() {
+	if imp.txCnt == 0 {
+		return
+	}
+
+	imp.txCnt = 0
+	imp.db.Rollback() // nolint: errcheck
+} // func (imp *Importer) abort()
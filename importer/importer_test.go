@@ -0,0 +1,184 @@
+// /home/krylon/go/src/github.com/blicero/guangng/importer/importer_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:37:46 krylon>
+
+package importer
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/hsrc"
+)
+
+var tdb *database.Database
+
+func TestMain(m *testing.M) {
+	var (
+		err     error
+		result  int
+		baseDir = time.Now().Format("/tmp/guangng_import_test_20060102_150405")
+	)
+
+	if err = common.SetBaseDir(baseDir); err != nil {
+		fmt.Printf("Cannot set base directory to %s: %s\n",
+			baseDir,
+			err.Error())
+		os.Exit(1)
+	} else if tdb, err = database.Open(common.DbPath); err != nil {
+		fmt.Printf("Cannot open database: %s\n", err.Error())
+		os.Exit(1)
+	} else if result = m.Run(); result == 0 {
+		fmt.Printf("Removing BaseDir %s\n",
+			baseDir)
+		tdb.Close() // nolint: errcheck
+		_ = os.RemoveAll(baseDir)
+	} else {
+		fmt.Printf(">>> TEST DIRECTORY: %s\n", baseDir)
+	}
+
+	os.Exit(result)
+} // func TestMain(m *testing.M)
+
+const nmapSample = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE nmaprun>
+<nmaprun scanner="nmap" args="nmap -oX - 93.184.215.0/30" start="1760000000" version="7.94">
+<host starttime="1760000001" endtime="1760000002"><status state="up" reason="syn-ack"/>
+<address addr="93.184.215.1" addrtype="ipv4"/>
+<hostnames>
+<hostname name="alpha.example.net" type="user"/>
+<hostname name="gw.example.net" type="PTR"/>
+</hostnames>
+<ports><extraports state="closed" count="997"/>
+<port protocol="tcp" portid="22"><state state="open" reason="syn-ack"/><service name="ssh"/></port>
+<port protocol="tcp" portid="25"><state state="filtered" reason="no-response"/></port>
+<port protocol="udp" portid="161"><state state="open" reason="udp-response"/></port>
+<port protocol="udp" portid="123"><state state="open" reason="udp-response"/></port>
+</ports>
+</host>
+<host><status state="down" reason="no-response"/>
+<address addr="93.184.215.2" addrtype="ipv4"/>
+</host>
+<host><status state="up" reason="echo-reply"/>
+<address addr="10.1.2.3" addrtype="ipv4"/>
+<ports><port protocol="tcp" portid="80"><state state="open" reason="syn-ack"/></port></ports>
+</host>
+<runstats><finished time="1760000003" exit="success"/></runstats>
+</nmaprun>
+`
+
+const masscanSampleJSON = `[
+{   "ip": "93.184.215.1",   "timestamp": "1760000000", "ports": [ {"port": 22, "proto": "tcp", "status": "open", "reason": "syn-ack", "ttl": 54} ] }
+,
+{   "ip": "93.184.215.9",   "timestamp": "1760000000", "ports": [ {"port": 443, "proto": "tcp", "status": "open", "reason": "syn-ack", "ttl": 54} ] }
+,
+{   "ip": "93.184.215.9",   "timestamp": "1760000001", "ports": [ {"port": 443, "proto": "tcp", "service": {"name": "http", "banner": "nginx"} } ] }
+,
+{finished: 1}
+]
+`
+
+const masscanSampleNDJSON = `{"ip":"93.184.215.10","timestamp":"1760000000","port":8080,"proto":"tcp","rec_type":"status","data":{"status":"open","reason":"syn-ack","ttl":54}}
+{"ip":"93.184.215.10","timestamp":"1760000000","port":8081,"proto":"tcp","rec_type":"status","data":{"status":"open","reason":"syn-ack","ttl":54}}
+`
+
+func TestImportNmap(t *testing.T) {
+	var (
+		err   error
+		imp   *Importer
+		stats Stats
+		host  *model.Host
+	)
+
+	if imp, err = New(tdb); err != nil {
+		t.Fatalf("Cannot create Importer: %s", err.Error())
+	} else if stats, err = imp.ImportNmap(strings.NewReader(nmapSample)); err != nil {
+		t.Fatalf("Failed to import Nmap XML: %s", err.Error())
+	}
+
+	// 93.184.215.2 is down, 10.1.2.3 is blacklisted, and we have no UDP
+	// Probe for NTP.
+	if stats.HostsAdded != 1 || stats.HostsSkipped != 2 || stats.PortsQueued != 2 || stats.PortsSkipped != 1 {
+		t.Errorf("Unexpected import statistics: %s", stats)
+	}
+
+	if host, err = tdb.HostGetByAddr(net.ParseIP("93.184.215.1")); err != nil {
+		t.Fatalf("Cannot look up imported Host: %s", err.Error())
+	} else if host == nil {
+		t.Fatal("Imported Host was not found")
+	} else if host.Name != "gw.example.net" {
+		t.Errorf("Imported Host has name %q (expected %q)",
+			host.Name,
+			"gw.example.net")
+	} else if host.Source != hsrc.Import {
+		t.Errorf("Imported Host has source %s (expected %s)",
+			host.Source,
+			hsrc.Import)
+	}
+} // func TestImportNmap(t *testing.T)
+
+func TestImportMasscan(t *testing.T) {
+	type masscanTest struct {
+		input string
+		stats Stats
+	}
+
+	var testCases = []masscanTest{
+		{
+			input: masscanSampleJSON,
+			// 93.184.215.1 was added by the Nmap import, and port 22
+			// is queued already, but we count it anyway.
+			stats: Stats{HostsAdded: 1, HostsKnown: 1, HostsSkipped: 1, PortsQueued: 2},
+		},
+		{
+			input: masscanSampleNDJSON,
+			stats: Stats{HostsAdded: 1, PortsQueued: 2},
+		},
+	}
+
+	var (
+		err error
+		imp *Importer
+	)
+
+	if imp, err = New(tdb); err != nil {
+		t.Fatalf("Cannot create Importer: %s", err.Error())
+	}
+
+	for i, c := range testCases {
+		var stats Stats
+
+		if stats, err = imp.ImportMasscan(strings.NewReader(c.input)); err != nil {
+			t.Errorf("Failed to import masscan sample #%d: %s",
+				i,
+				err.Error())
+		} else if stats != c.stats {
+			t.Errorf("Unexpected statistics for masscan sample #%d: %s (expected %s)",
+				i,
+				stats,
+				c.stats)
+		}
+	}
+
+	var (
+		pending []*model.PendingPort
+		total   = 2 + 1 + 2 // Nmap + masscan JSON + masscan NDJSON
+	)
+
+	if pending, err = tdb.PortQueueGetPending(100); err != nil {
+		t.Errorf("Cannot get pending ports: %s", err.Error())
+	} else if len(pending) != total {
+		t.Errorf("Unexpected number of pending ports: %d (expected %d)",
+			len(pending),
+			total)
+	}
+} // func TestImportMasscan(t *testing.T)
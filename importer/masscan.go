@@ -0,0 +1,122 @@
+// /home/krylon/go/src/github.com/blicero/guangng/importer/masscan.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 20:14:03 krylon>
+
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// masscan writes JSON in two flavours: "-oJ" produces an array with one
+// record per line, "-oD" produces one record per line without the array
+// around it, and in a slightly different shape.
+// Older versions of masscan terminate the array with "{finished: 1}",
+// which is not valid JSON, so we read the input line by line rather than
+// feeding the whole thing to the JSON decoder.
+
+var masscanFinishedPat = regexp.MustCompile(`^\{\s*"?finished"?\s*:`)
+
+// maxLineLength is the longest line we accept. Banners can get long.
+const maxLineLength = 1 << 20
+
+type masscanPort struct {
+	Port   uint16 `json:"port"`
+	Proto  string `json:"proto"`
+	Status string `json:"status"`
+}
+
+type masscanRecord struct {
+	IP    string        `json:"ip"`
+	Ports []masscanPort `json:"ports"`
+	// The following fields are used by the "-oD" format.
+	Port    uint16 `json:"port"`
+	Proto   string `json:"proto"`
+	RecType string `json:"rec_type"`
+	Data    struct {
+		Status string `json:"status"`
+	} `json:"data"`
+}
+
+func (m *masscanRecord) record() *record {
+	var rec = &record{addr: net.ParseIP(m.IP)}
+
+	if m.RecType != "" {
+		m.Ports = []masscanPort{
+			{Port: m.Port, Proto: m.Proto, Status: m.Data.Status},
+		}
+	}
+
+	for _, p := range m.Ports {
+		// Banner records carry a "service" instead of a status, we
+		// do our own banner grabbing.
+		if p.Status != "open" || p.Port == 0 {
+			continue
+		} else if p.Proto != "tcp" && p.Proto != "udp" {
+			continue
+		}
+
+		rec.ports = append(rec.ports, openPort{port: p.Port, proto: p.Proto})
+	}
+
+	return rec
+} // func (m *masscanRecord) record() *record
+
+// ImportMasscan reads the JSON output of masscan ("-oJ" or "-oD") from r
+// and adds all Hosts with open ports.
+func (imp *Importer) ImportMasscan(r io.Reader) (Stats, error) {
+	var (
+		err    error
+		lineNo int
+		scn    = bufio.NewScanner(r)
+	)
+
+	scn.Buffer(make([]byte, 0, 4096), maxLineLength)
+	imp.begin()
+
+	for scn.Scan() {
+		var (
+			m    masscanRecord
+			line = strings.TrimSpace(scn.Text())
+		)
+
+		lineNo++
+		line = strings.TrimLeft(line, "[,")
+		line = strings.TrimRight(line, "],")
+		line = strings.TrimSpace(line)
+
+		if line == "" || masscanFinishedPat.MatchString(line) {
+			continue
+		} else if err = json.Unmarshal([]byte(line), &m); err != nil {
+			err = fmt.Errorf("cannot parse masscan record in line %d: %w",
+				lineNo,
+				err)
+			goto FAIL
+		} else if err = imp.add(m.record()); err != nil {
+			goto FAIL
+		}
+	}
+
+	if err = scn.Err(); err != nil {
+		err = fmt.Errorf("cannot read masscan output: %w", err)
+		goto FAIL
+	} else if err = imp.commit(); err != nil {
+		return imp.stats, err
+	}
+
+	imp.log.Printf("[INFO] Imported masscan JSON: %s\n", imp.stats)
+	return imp.stats, nil
+
+FAIL:
+	imp.log.Printf("[ERROR] %s\n", err.Error())
+	imp.abort()
+	return imp.stats, err
+} // func (imp *Importer) ImportMasscan(r io.Reader) (Stats, error)
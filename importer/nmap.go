@@ -0,0 +1,135 @@
+// /home/krylon/go/src/github.com/blicero/guangng/importer/nmap.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 19:58:40 krylon>
+
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+)
+
+// We only look at the parts of "nmap -oX" output we can use, the decoder
+// skips everything else.
+
+type nmapAddress struct {
+	Addr     string `xml:"addr,attr"`
+	AddrType string `xml:"addrtype,attr"`
+}
+
+type nmapHostname struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type nmapState struct {
+	State string `xml:"state,attr"`
+}
+
+type nmapPort struct {
+	Protocol string    `xml:"protocol,attr"`
+	PortID   uint16    `xml:"portid,attr"`
+	State    nmapState `xml:"state"`
+}
+
+type nmapHost struct {
+	Status    nmapState      `xml:"status"`
+	Addresses []nmapAddress  `xml:"address"`
+	Hostnames []nmapHostname `xml:"hostnames>hostname"`
+	Ports     []nmapPort     `xml:"ports>port"`
+}
+
+// record converts the Host into something we can store. Hosts that are
+// down or have no open ports yield a record without ports.
+func (h *nmapHost) record() *record {
+	var rec = new(record)
+
+	if h.Status.State != "" && h.Status.State != "up" {
+		return rec
+	}
+
+	for _, a := range h.Addresses {
+		if a.AddrType == "ipv4" || a.AddrType == "ipv6" {
+			rec.addr = net.ParseIP(a.Addr)
+			break
+		}
+	}
+
+	// Prefer the name from the reverse lookup, since that is what we
+	// would have found ourselves.
+	for _, n := range h.Hostnames {
+		if n.Type == "PTR" {
+			rec.name = n.Name
+			break
+		} else if rec.name == "" {
+			rec.name = n.Name
+		}
+	}
+
+	for _, p := range h.Ports {
+		if p.State.State != "open" || p.PortID == 0 {
+			continue
+		} else if p.Protocol != "tcp" && p.Protocol != "udp" {
+			continue
+		}
+
+		rec.ports = append(rec.ports, openPort{port: p.PortID, proto: p.Protocol})
+	}
+
+	return rec
+} // func (h *nmapHost) record() *record
+
+// ImportNmap reads the XML output of Nmap ("nmap -oX") from r and adds all
+// Hosts that were up and had open ports.
+func (imp *Importer) ImportNmap(r io.Reader) (Stats, error) {
+	var (
+		err error
+		tok xml.Token
+		dec = xml.NewDecoder(r)
+	)
+
+	imp.begin()
+
+	for {
+		if tok, err = dec.Token(); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			err = fmt.Errorf("cannot parse Nmap XML: %w", err)
+			goto FAIL
+		}
+
+		var start, ok = tok.(xml.StartElement)
+
+		if !ok || start.Name.Local != "host" {
+			continue
+		}
+
+		var h nmapHost
+
+		if err = dec.DecodeElement(&h, &start); err != nil {
+			err = fmt.Errorf("cannot parse Nmap XML host element: %w", err)
+			goto FAIL
+		} else if err = imp.add(h.record()); err != nil {
+			goto FAIL
+		}
+	}
+
+	if err = imp.commit(); err != nil {
+		return imp.stats, err
+	}
+
+	imp.log.Printf("[INFO] Imported Nmap XML: %s\n", imp.stats)
+	return imp.stats, nil
+
+FAIL:
+	imp.log.Printf("[ERROR] %s\n", err.Error())
+	imp.abort()
+	return imp.stats, err
+} // func (imp *Importer) ImportNmap(r io.Reader) (Stats, error)
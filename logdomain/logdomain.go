@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 06. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-02-10 20:01:21 krylon>
+// Time-stamp: <2026-10-20 17:20:02 krylon>
 
 package logdomain
 
@@ -23,6 +23,9 @@ const (
 	Scanner
 	Nexus
 	MetaEngine
+	Export
+	Import
+	Geo
 )
 
 // AllDomains returns a slice of all valid values for logdomain.ID
@@ -39,5 +42,8 @@ func AllDomains() []ID {
 		Scanner,
 		Nexus,
 		MetaEngine,
+		Export,
+		Import,
+		Geo,
 	}
 } // func AllDomains() []ID
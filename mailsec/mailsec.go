@@ -0,0 +1,326 @@
+// /home/krylon/go/src/github.com/blicero/guangng/mailsec/mailsec.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:02:25 krylon>
+
+// Package mailsec analyzes the e-mail security policies zones publish in
+// the DNS: SPF, DMARC, MTA-STS and TLS-RPT. It works on the records we
+// received in zone transfers only and never queries the DNS itself, so
+// SPF includes pointing to zones we do not hold cannot be followed.
+package mailsec
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/weakness"
+)
+
+// Lookup returns the TXT records named name from zones other than the one
+// being analyzed, or nil if we have none.
+type Lookup func(name string) []*model.ZoneRecord
+
+// maxDepth limits how deep we follow includes and redirects. Anything
+// beyond it exceeds MaxLookups anyway.
+const maxDepth = MaxLookups + 1
+
+// TXTString returns the text of a TXT record, given its data in master file
+// format, i.e. as one or more quoted strings. The strings are joined without
+// any separator, as RFC 7208 asks us to do for SPF.
+func TXTString(rdata string) string {
+	var (
+		b strings.Builder
+		s = strings.TrimSpace(rdata)
+	)
+
+	for s != "" {
+		var end int
+
+		if s[0] != '"' {
+			if end = strings.IndexAny(s, " \t"); end == -1 {
+				end = len(s)
+			}
+			b.WriteString(s[:end])
+		} else {
+			for end = 1; end < len(s) && s[end] != '"'; end++ {
+				if s[end] == '\\' {
+					end++
+				}
+			}
+
+			if end = min(end+1, len(s)); end < 2 {
+				break
+			} else if str, err := strconv.Unquote(s[:end]); err == nil {
+				b.WriteString(str)
+			} else {
+				b.WriteString(strings.Trim(s[:end], `"`))
+			}
+		}
+
+		s = strings.TrimLeft(s[end:], " \t")
+	}
+
+	return b.String()
+} // func TXTString(rdata string) string
+
+// fqdn returns name in lower case, with a trailing dot.
+func fqdn(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, ".")) + "."
+} // func fqdn(name string) string
+
+// analyzer holds the TXT records of a zone while we look at them.
+type analyzer struct {
+	zone   string
+	txt    map[string][]string
+	lookup Lookup
+}
+
+// texts returns the strings of all TXT records named name. For names in
+// the zone, the zone's records are all there is, anything else we ask
+// lookup for. The second return value is false if we know nothing about
+// name.
+func (a *analyzer) texts(name string) ([]string, bool) {
+	name = fqdn(name)
+
+	if name == a.zone || strings.HasSuffix(name, "."+a.zone) {
+		return a.txt[name], true
+	} else if a.lookup == nil || strings.Contains(name, "%") {
+		return nil, false
+	}
+
+	var records = a.lookup(name)
+
+	if records == nil {
+		return nil, false
+	}
+
+	var list = make([]string, 0, len(records))
+
+	for _, r := range records {
+		list = append(list, TXTString(r.RData))
+	}
+
+	return list, true
+} // func (a *analyzer) texts(name string) ([]string, bool)
+
+// find returns the strings of the TXT records named name that match is.
+func (a *analyzer) find(name string, is func(string) bool) ([]string, bool) {
+	var (
+		list, ok = a.texts(name)
+		matches  []string
+	)
+
+	for _, s := range list {
+		if is(s) {
+			matches = append(matches, s)
+		}
+	}
+
+	return matches, ok
+} // func (a *analyzer) find(name string, is func(string) bool) ([]string, bool)
+
+// spfResult is what we learn from following an SPF record through its
+// includes and redirects.
+type spfResult struct {
+	open    bool // Anyone passes
+	all     byte // Qualifier of the all mechanism that applies in the end
+	lookups int
+}
+
+// evalSPF follows the includes and redirect of s. Includes and redirects we
+// have no data for are counted as lookups, but otherwise ignored.
+func (a *analyzer) evalSPF(s *SPF, depth int, seen map[string]bool) spfResult {
+	var res = spfResult{lookups: s.Lookups()}
+
+	if depth >= maxDepth {
+		return res
+	}
+
+	for i := range s.Terms {
+		var t = &s.Terms[i]
+
+		if t.Mechanism != "include" {
+			continue
+		}
+
+		var sub = a.resolveSPF(t.Domain(), depth, seen)
+
+		res.lookups += sub.lookups
+		// An include matches if the included record passes, so an
+		// included record that passes everyone makes us pass everyone
+		// if the include itself is qualified with +.
+		if sub.open && t.Qualifier == '+' {
+			res.open = true
+		}
+	}
+
+	if q, ok := s.All(); ok {
+		res.all = q
+		res.open = res.open || q == '+'
+	} else if s.Redirect != "" {
+		var sub = a.resolveSPF(s.Redirect, depth, seen)
+
+		res.lookups += sub.lookups
+		res.all = sub.all
+		res.open = res.open || sub.open
+	}
+
+	return res
+} // func (a *analyzer) evalSPF(s *SPF, depth int, seen map[string]bool) spfResult
+
+// resolveSPF looks up the SPF record named name and evaluates it.
+func (a *analyzer) resolveSPF(name string, depth int, seen map[string]bool) spfResult {
+	var (
+		err  error
+		spf  *SPF
+		list []string
+		ok   bool
+	)
+
+	if name = fqdn(name); seen[name] {
+		return spfResult{}
+	}
+
+	seen[name] = true
+
+	if list, ok = a.find(name, IsSPF); !ok || len(list) != 1 {
+		return spfResult{}
+	} else if spf, err = ParseSPF(list[0]); err != nil {
+		return spfResult{}
+	}
+
+	return a.evalSPF(spf, depth+1, seen)
+} // func (a *analyzer) resolveSPF(name string, depth int, seen map[string]bool) spfResult
+
+// Analyze looks at the e-mail security policy the apex of zone publishes,
+// given the records of the zone. If the zone neither receives mail nor has
+// any policy, it returns nil.
+func Analyze(zone string, records []*model.ZoneRecord, lookup Lookup) *model.MailPolicy {
+	var (
+		a = &analyzer{
+			zone:   fqdn(zone),
+			txt:    make(map[string][]string),
+			lookup: lookup,
+		}
+		p = &model.MailPolicy{
+			Zone:    strings.TrimSuffix(fqdn(zone), "."),
+			Checked: time.Now(),
+		}
+		list []string
+	)
+
+	for _, r := range records {
+		if r.IsRemoved() {
+			continue
+		}
+
+		switch r.Type {
+		case "TXT":
+			var owner = fqdn(r.Owner)
+			a.txt[owner] = append(a.txt[owner], TXTString(r.RData))
+		case "MX":
+			// A null MX (RFC 7505) says the zone takes no mail.
+			if fqdn(r.Owner) == a.zone && !strings.HasSuffix(strings.TrimSpace(r.RData), " .") {
+				p.HasMX = true
+			}
+		}
+	}
+
+	// SPF
+	list, _ = a.find(a.zone, IsSPF)
+	switch len(list) {
+	case 0:
+		if p.HasMX {
+			p.Weaknesses = append(p.Weaknesses, weakness.NoSPF)
+		}
+	case 1:
+		var spf, err = ParseSPF(list[0])
+
+		if err != nil {
+			p.SPF = list[0]
+			p.Weaknesses = append(p.Weaknesses, weakness.InvalidSPF)
+			break
+		}
+
+		var res = a.evalSPF(spf, 0, map[string]bool{a.zone: true})
+
+		p.SPF = spf.String()
+		p.SPFLookups = res.lookups
+		if res.all != 0 {
+			p.SPFAll = string(res.all)
+		}
+
+		switch {
+		case res.open:
+			p.Weaknesses = append(p.Weaknesses, weakness.SPFPassAll)
+		case res.all == '?':
+			p.Weaknesses = append(p.Weaknesses, weakness.SPFNeutralAll)
+		case res.all == 0:
+			p.Weaknesses = append(p.Weaknesses, weakness.SPFNoAll)
+		}
+
+		if res.lookups > MaxLookups {
+			p.Weaknesses = append(p.Weaknesses, weakness.SPFTooManyLookups)
+		}
+	default:
+		p.SPF = strings.Join(list, " | ")
+		p.Weaknesses = append(p.Weaknesses, weakness.InvalidSPF)
+	}
+
+	// DMARC
+	list, _ = a.find("_dmarc."+a.zone, IsDMARC)
+	switch len(list) {
+	case 0:
+		if p.HasMX || p.SPF != "" {
+			p.Weaknesses = append(p.Weaknesses, weakness.NoDMARC)
+		}
+	case 1:
+		var d, err = ParseDMARC(list[0])
+
+		if err != nil {
+			p.DMARC = list[0]
+			p.Weaknesses = append(p.Weaknesses, weakness.InvalidDMARC)
+			break
+		}
+
+		p.DMARC = d.String()
+		p.DMARCPolicy = d.Policy
+
+		if d.Policy == "none" {
+			p.Weaknesses = append(p.Weaknesses, weakness.DMARCNone)
+		}
+
+		if d.Pct < 100 {
+			p.Weaknesses = append(p.Weaknesses, weakness.DMARCPartial)
+		}
+	default:
+		p.DMARC = strings.Join(list, " | ")
+		p.Weaknesses = append(p.Weaknesses, weakness.InvalidDMARC)
+	}
+
+	// MTA-STS and TLS-RPT only matter for zones that receive mail.
+	if list, _ = a.find("_mta-sts."+a.zone, IsMTASTS); len(list) == 1 {
+		p.MTASTS, _ = ParseMTASTS(list[0])
+	}
+
+	if list, _ = a.find("_smtp._tls."+a.zone, IsTLSRPT); len(list) == 1 {
+		p.TLSRPT, _ = ParseTLSRPT(list[0])
+	}
+
+	if p.HasMX {
+		if p.MTASTS == "" {
+			p.Weaknesses = append(p.Weaknesses, weakness.NoMTASTS)
+		}
+
+		if p.TLSRPT == "" {
+			p.Weaknesses = append(p.Weaknesses, weakness.NoTLSRPT)
+		}
+	} else if p.SPF == "" && p.DMARC == "" && p.MTASTS == "" && p.TLSRPT == "" {
+		return nil
+	}
+
+	return p
+} // func Analyze(zone string, records []*model.ZoneRecord, lookup Lookup) *model.MailPolicy
@@ -0,0 +1,197 @@
+// /home/krylon/go/src/github.com/blicero/guangng/mailsec/mailsec_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:02:25 krylon>
+
+package mailsec
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/weakness"
+)
+
+func TestTXTString(t *testing.T) {
+	for _, c := range []struct {
+		rdata, expected string
+	}{
+		{`"v=spf1 -all"`, "v=spf1 -all"},
+		{`"v=spf1 ip4:192.0.2.0/24 " "include:_spf.straylight.test ~all"`, "v=spf1 ip4:192.0.2.0/24 include:_spf.straylight.test ~all"},
+		{`"say \"hello\""`, `say "hello"`},
+		{`"unterminated`, "unterminated"},
+		{``, ""},
+	} {
+		if s := TXTString(c.rdata); s != c.expected {
+			t.Errorf("TXTString(%q) = %q, expected %q", c.rdata, s, c.expected)
+		}
+	}
+} // func TestTXTString(t *testing.T)
+
+func TestParseSPF(t *testing.T) {
+	for _, c := range []struct {
+		txt, normalized string
+		lookups         int
+		valid           bool
+	}{
+		{"v=spf1 -all", "v=spf1 -all", 0, true},
+		{"V=SPF1  +MX  IP4:192.0.2.1 ~ALL", "v=spf1 mx ip4:192.0.2.1 ~all", 1, true},
+		{"v=spf1 a:mail.straylight.test/28 include:_spf.test redirect=_spf.straylight.test", "v=spf1 a:mail.straylight.test/28 include:_spf.test redirect=_spf.straylight.test", 3, true},
+		{"v=spf1 include:_spf.test -all redirect=_spf.straylight.test", "v=spf1 include:_spf.test -all redirect=_spf.straylight.test", 1, true},
+		{"v=spf1 exists:%{i}._spf.test foo=bar ?all", "v=spf1 exists:%{i}._spf.test ?all", 1, true},
+		{"v=spf10 -all", "", 0, false},
+		{"v=spf1 ip4:192.0.2.300 -all", "", 0, false},
+		{"v=spf1 include -all", "", 0, false},
+		{"v=spf1 allow -all", "", 0, false},
+		{"v=spf1 redirect=a.test redirect=b.test", "", 0, false},
+	} {
+		var s, err = ParseSPF(c.txt)
+
+		if !c.valid {
+			if err == nil {
+				t.Errorf("ParseSPF(%q) should have failed", c.txt)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("ParseSPF(%q) failed: %s", c.txt, err.Error())
+		} else if s.String() != c.normalized {
+			t.Errorf("ParseSPF(%q) = %q, expected %q", c.txt, s.String(), c.normalized)
+		} else if s.Lookups() != c.lookups {
+			t.Errorf("%q needs %d lookups, expected %d", c.txt, s.Lookups(), c.lookups)
+		}
+	}
+} // func TestParseSPF(t *testing.T)
+
+func TestParseDMARC(t *testing.T) {
+	for _, c := range []struct {
+		txt, normalized string
+		valid           bool
+	}{
+		{"v=DMARC1; p=reject", "v=DMARC1; p=reject", true},
+		{"v=DMARC1;p=Quarantine;sp=quarantine;pct=50;rua=mailto:dmarc@straylight.test;",
+			"v=DMARC1; p=quarantine; pct=50; rua=mailto:dmarc@straylight.test", true},
+		{"v=DMARC1; p=none; adkim=s; aspf=r", "v=DMARC1; p=none; adkim=s", true},
+		{"v=DMARC1; rua=mailto:dmarc@straylight.test", "", false},
+		{"v=DMARC1; rua=mailto:dmarc@straylight.test; p=reject", "", false},
+		{"v=DMARC1; p=maybe", "", false},
+		{"v=DMARC1; p=reject; pct=120", "", false},
+		{"p=reject; v=DMARC1", "", false},
+	} {
+		var d, err = ParseDMARC(c.txt)
+
+		if !c.valid {
+			if err == nil {
+				t.Errorf("ParseDMARC(%q) should have failed", c.txt)
+			}
+		} else if err != nil {
+			t.Errorf("ParseDMARC(%q) failed: %s", c.txt, err.Error())
+		} else if d.String() != c.normalized {
+			t.Errorf("ParseDMARC(%q) = %q, expected %q", c.txt, d.String(), c.normalized)
+		}
+	}
+} // func TestParseDMARC(t *testing.T)
+
+func txt(owner, text string) *model.ZoneRecord {
+	return &model.ZoneRecord{Owner: owner, Type: "TXT", RData: `"` + text + `"`}
+} // func txt(owner, text string) *model.ZoneRecord
+
+func TestAnalyze(t *testing.T) {
+	var (
+		mx     = &model.ZoneRecord{Owner: "straylight.test.", Type: "MX", RData: "10 mail.straylight.test."}
+		others = map[string][]*model.ZoneRecord{
+			"_spf.tessier.test.": {txt("_spf.tessier.test.", "v=spf1 ip4:192.0.2.0/24 -all")},
+			"_spf.freeside.test.": {
+				txt("_spf.freeside.test.", "v=spf1 include:_spf.tessier.test +all"),
+			},
+		}
+		lookup = func(name string) []*model.ZoneRecord { return others[name] }
+	)
+
+	for _, c := range []struct {
+		name       string
+		records    []*model.ZoneRecord
+		all        string
+		lookups    int
+		weaknesses []weakness.Weakness
+	}{
+		{
+			name: "strict",
+			records: []*model.ZoneRecord{
+				mx,
+				txt("straylight.test.", "v=spf1 mx include:_spf.tessier.test -all"),
+				txt("straylight.test.", "google-site-verification=abc"),
+				txt("_dmarc.straylight.test.", "v=DMARC1; p=reject"),
+				txt("_mta-sts.straylight.test.", "v=STSv1; id=20261019"),
+				txt("_smtp._tls.straylight.test.", "v=TLSRPTv1; rua=mailto:tls@straylight.test"),
+			},
+			all:     "-",
+			lookups: 2,
+		},
+		{
+			name: "open through include",
+			records: []*model.ZoneRecord{
+				mx,
+				txt("straylight.test.", "v=spf1 include:_spf.freeside.test -all"),
+				txt("_dmarc.straylight.test.", "v=DMARC1; p=none"),
+			},
+			all:        "-",
+			lookups:    2,
+			weaknesses: []weakness.Weakness{weakness.SPFPassAll, weakness.DMARCNone, weakness.NoMTASTS, weakness.NoTLSRPT},
+		},
+		{
+			name: "redirect within the zone",
+			records: []*model.ZoneRecord{
+				txt("straylight.test.", "v=spf1 redirect=_spf.straylight.test"),
+				txt("_spf.straylight.test.", "v=spf1 a ?all"),
+			},
+			all:        "?",
+			lookups:    2,
+			weaknesses: []weakness.Weakness{weakness.SPFNeutralAll, weakness.NoDMARC},
+		},
+		{
+			name: "include loop",
+			records: []*model.ZoneRecord{
+				mx,
+				txt("straylight.test.", "v=spf1 include:straylight.test mx"),
+				txt("_dmarc.straylight.test.", "v=DMARC1; p=quarantine; pct=10"),
+			},
+			lookups:    2,
+			weaknesses: []weakness.Weakness{weakness.SPFNoAll, weakness.DMARCPartial, weakness.NoMTASTS, weakness.NoTLSRPT},
+		},
+		{
+			name: "duplicate SPF, no DMARC",
+			records: []*model.ZoneRecord{
+				mx,
+				txt("straylight.test.", "v=spf1 -all"),
+				txt("straylight.test.", "v=spf1 mx -all"),
+			},
+			weaknesses: []weakness.Weakness{weakness.InvalidSPF, weakness.NoDMARC, weakness.NoMTASTS, weakness.NoTLSRPT},
+		},
+		{
+			name:       "mail without policy",
+			records:    []*model.ZoneRecord{mx},
+			weaknesses: []weakness.Weakness{weakness.NoSPF, weakness.NoDMARC, weakness.NoMTASTS, weakness.NoTLSRPT},
+		},
+	} {
+		var p = Analyze("Straylight.test", c.records, lookup)
+
+		if p == nil {
+			t.Errorf("%s: Analyze returned nil", c.name)
+			continue
+		} else if p.SPFAll != c.all {
+			t.Errorf("%s: SPFAll = %q, expected %q", c.name, p.SPFAll, c.all)
+		} else if p.SPFLookups != c.lookups {
+			t.Errorf("%s: SPFLookups = %d, expected %d", c.name, p.SPFLookups, c.lookups)
+		} else if !slices.Equal(p.Weaknesses, c.weaknesses) {
+			t.Errorf("%s: Weaknesses = %v, expected %v", c.name, p.Weaknesses, c.weaknesses)
+		}
+	}
+
+	if p := Analyze("straylight.test", []*model.ZoneRecord{
+		txt("www.straylight.test.", "v=spf1 -all"),
+	}, nil); p != nil {
+		t.Errorf("Zone without mail got a policy: %#v", p)
+	}
+} // func TestAnalyze(t *testing.T)
@@ -0,0 +1,215 @@
+// /home/krylon/go/src/github.com/blicero/guangng/mailsec/spf.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:02:25 krylon>
+
+package mailsec
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// MaxLookups is the number of DNS lookups RFC 7208 allows evaluating an
+// SPF record to cause.
+const MaxLookups = 10
+
+const spfVersion = "v=spf1"
+
+// Term is a mechanism in an SPF record. Arg is whatever follows the name
+// of the mechanism, including the leading colon or slash, e.g.
+// ":_spf.example.com" or ":192.0.2.0/24".
+type Term struct {
+	Qualifier byte
+	Mechanism string
+	Arg       string
+}
+
+// Domain returns the domain name a mechanism refers to, if it refers to
+// one.
+func (t *Term) Domain() string {
+	if !strings.HasPrefix(t.Arg, ":") {
+		return ""
+	}
+
+	var domain = t.Arg[1:]
+
+	if idx := strings.IndexByte(domain, '/'); idx != -1 {
+		domain = domain[:idx]
+	}
+
+	return domain
+} // func (t *Term) Domain() string
+
+// String returns the Term the way it appears in an SPF record, omitting the
+// default qualifier.
+func (t *Term) String() string {
+	if t.Qualifier == '+' {
+		return t.Mechanism + t.Arg
+	}
+
+	return string(t.Qualifier) + t.Mechanism + t.Arg
+} // func (t *Term) String() string
+
+// SPF is a parsed SPF record, as described in RFC 7208.
+type SPF struct {
+	Terms    []Term
+	Redirect string
+	Exp      string
+}
+
+// IsSPF returns true if txt looks like it is meant to be an SPF record.
+func IsSPF(txt string) bool {
+	return len(txt) >= len(spfVersion) &&
+		strings.EqualFold(txt[:len(spfVersion)], spfVersion) &&
+		(len(txt) == len(spfVersion) || txt[len(spfVersion)] == ' ')
+} // func IsSPF(txt string) bool
+
+// ParseSPF parses the SPF record txt.
+func ParseSPF(txt string) (*SPF, error) {
+	if !IsSPF(txt) {
+		return nil, fmt.Errorf("not an SPF record: %q", txt)
+	}
+
+	var s = new(SPF)
+
+	for _, f := range strings.Fields(txt[len(spfVersion):]) {
+		var (
+			name, value string
+			isModifier  bool
+		)
+
+		// A modifier is name=value, but a mechanism's argument may
+		// contain an equals sign, too, e.g. in macros.
+		if idx := strings.IndexAny(f, "=:/"); idx > 0 && f[idx] == '=' {
+			name, value, isModifier = strings.ToLower(f[:idx]), f[idx+1:], true
+		}
+
+		if isModifier {
+			switch name {
+			case "redirect":
+				if s.Redirect != "" || value == "" {
+					return nil, fmt.Errorf("invalid redirect modifier %q", f)
+				}
+				s.Redirect = value
+			case "exp":
+				if s.Exp != "" || value == "" {
+					return nil, fmt.Errorf("invalid exp modifier %q", f)
+				}
+				s.Exp = value
+			}
+			// Unknown modifiers must be ignored.
+			continue
+		}
+
+		var t = Term{Qualifier: '+'}
+
+		if strings.IndexByte("+-~?", f[0]) != -1 {
+			t.Qualifier = f[0]
+			f = f[1:]
+		}
+
+		if idx := strings.IndexAny(f, ":/"); idx != -1 {
+			t.Mechanism, t.Arg = strings.ToLower(f[:idx]), f[idx:]
+		} else {
+			t.Mechanism = strings.ToLower(f)
+		}
+
+		if err := t.validate(); err != nil {
+			return nil, err
+		}
+
+		s.Terms = append(s.Terms, t)
+	}
+
+	return s, nil
+} // func ParseSPF(txt string) (*SPF, error)
+
+func (t *Term) validate() error {
+	switch t.Mechanism {
+	case "all":
+		if t.Arg != "" {
+			return fmt.Errorf("all takes no argument: %q", t.String())
+		}
+	case "include", "exists":
+		if t.Domain() == "" || strings.Contains(t.Arg[1:], "/") {
+			return fmt.Errorf("%s needs a domain: %q", t.Mechanism, t.String())
+		}
+	case "a", "mx", "ptr":
+		if t.Arg == ":" {
+			return fmt.Errorf("empty domain in %q", t.String())
+		}
+	case "ip4", "ip6":
+		var arg = strings.TrimPrefix(t.Arg, ":")
+
+		if arg == t.Arg || arg == "" {
+			return fmt.Errorf("%s needs an address: %q", t.Mechanism, t.String())
+		} else if !strings.Contains(arg, "/") {
+			if net.ParseIP(arg) == nil {
+				return fmt.Errorf("invalid address in %q", t.String())
+			}
+		} else if _, _, err := net.ParseCIDR(arg); err != nil {
+			return fmt.Errorf("invalid network in %q", t.String())
+		}
+	default:
+		return fmt.Errorf("unknown mechanism %q", t.Mechanism)
+	}
+
+	return nil
+} // func (t *Term) validate() error
+
+// String returns the SPF record in normalized form: mechanism names in
+// lower case, without the default qualifier, separated by single spaces,
+// followed by the modifiers.
+func (s *SPF) String() string {
+	var parts = make([]string, 1, len(s.Terms)+3)
+
+	parts[0] = spfVersion
+	for i := range s.Terms {
+		parts = append(parts, s.Terms[i].String())
+	}
+
+	if s.Redirect != "" {
+		parts = append(parts, "redirect="+s.Redirect)
+	}
+
+	if s.Exp != "" {
+		parts = append(parts, "exp="+s.Exp)
+	}
+
+	return strings.Join(parts, " ")
+} // func (s *SPF) String() string
+
+// All returns the qualifier of the all mechanism, if there is one.
+// Mechanisms after all are never looked at, so the first one counts.
+func (s *SPF) All() (byte, bool) {
+	for _, t := range s.Terms {
+		if t.Mechanism == "all" {
+			return t.Qualifier, true
+		}
+	}
+
+	return 0, false
+} // func (s *SPF) All() (byte, bool)
+
+// Lookups returns the number of DNS lookups the record itself causes, not
+// counting those of the records it includes or redirects to.
+func (s *SPF) Lookups() int {
+	var cnt int
+
+	for _, t := range s.Terms {
+		switch t.Mechanism {
+		case "include", "a", "mx", "ptr", "exists":
+			cnt++
+		}
+	}
+
+	// A redirect only takes effect if there is no all mechanism.
+	if _, ok := s.All(); !ok && s.Redirect != "" {
+		cnt++
+	}
+
+	return cnt
+} // func (s *SPF) Lookups() int
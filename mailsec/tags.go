@@ -0,0 +1,247 @@
+// /home/krylon/go/src/github.com/blicero/guangng/mailsec/tags.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:02:25 krylon>
+
+package mailsec
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DMARC, MTA-STS and TLS-RPT records all are lists of tag=value pairs,
+// separated by semicolons, that start with a version tag.
+
+type tag struct {
+	name, value string
+}
+
+// parseTags splits txt into its tags and checks that the first one is
+// v=<version>. Tag names are returned in lower case.
+func parseTags(txt, version string) ([]tag, error) {
+	var list []tag
+
+	for _, part := range strings.Split(txt, ";") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+
+		var name, value, ok = strings.Cut(part, "=")
+
+		if !ok {
+			return nil, fmt.Errorf("invalid tag %q", part)
+		}
+
+		list = append(list, tag{
+			name:  strings.ToLower(strings.TrimSpace(name)),
+			value: strings.TrimSpace(value),
+		})
+	}
+
+	if len(list) == 0 || list[0].name != "v" || list[0].value != version {
+		return nil, fmt.Errorf("record does not start with v=%s", version)
+	}
+
+	return list, nil
+} // func parseTags(txt, version string) ([]tag, error)
+
+func isTagRecord(txt, version string) bool {
+	var v, _, _ = strings.Cut(txt, ";")
+
+	v = strings.Join(strings.Fields(v), "")
+	return strings.EqualFold(v, "v="+version)
+} // func isTagRecord(txt, version string) bool
+
+const (
+	dmarcVersion  = "DMARC1"
+	mtastsVersion = "STSv1"
+	tlsrptVersion = "TLSRPTv1"
+)
+
+// DMARC is a parsed DMARC record, as described in RFC 7489.
+type DMARC struct {
+	Policy    string
+	SubPolicy string
+	Pct       int
+	ADKIM     string
+	ASPF      string
+	RUA       string
+	RUF       string
+}
+
+// IsDMARC returns true if txt looks like it is meant to be a DMARC record.
+func IsDMARC(txt string) bool {
+	return isTagRecord(txt, dmarcVersion)
+} // func IsDMARC(txt string) bool
+
+var errNoPolicy = errors.New("DMARC record has no policy")
+
+// ParseDMARC parses the DMARC record txt.
+func ParseDMARC(txt string) (*DMARC, error) {
+	var (
+		err  error
+		tags []tag
+		d    = &DMARC{Pct: 100}
+	)
+
+	if tags, err = parseTags(txt, dmarcVersion); err != nil {
+		return nil, err
+	}
+
+	for i, t := range tags[1:] {
+		switch t.name {
+		case "p":
+			// RFC 7489 wants the policy right after the version.
+			if i != 0 {
+				return nil, fmt.Errorf("p is tag #%d in DMARC record", i+2)
+			} else if d.Policy, err = dmarcPolicy(t.value); err != nil {
+				return nil, err
+			}
+		case "sp":
+			if d.SubPolicy, err = dmarcPolicy(t.value); err != nil {
+				return nil, err
+			}
+		case "pct":
+			if d.Pct, err = strconv.Atoi(t.value); err != nil || d.Pct < 0 || d.Pct > 100 {
+				return nil, fmt.Errorf("invalid pct %q in DMARC record", t.value)
+			}
+		case "adkim":
+			if d.ADKIM, err = dmarcAlignment(t.value); err != nil {
+				return nil, err
+			}
+		case "aspf":
+			if d.ASPF, err = dmarcAlignment(t.value); err != nil {
+				return nil, err
+			}
+		case "rua":
+			d.RUA = t.value
+		case "ruf":
+			d.RUF = t.value
+		}
+	}
+
+	if d.Policy == "" {
+		return nil, errNoPolicy
+	}
+
+	return d, nil
+} // func ParseDMARC(txt string) (*DMARC, error)
+
+func dmarcPolicy(p string) (string, error) {
+	switch p = strings.ToLower(p); p {
+	case "none", "quarantine", "reject":
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid DMARC policy %q", p)
+	}
+} // func dmarcPolicy(p string) (string, error)
+
+func dmarcAlignment(a string) (string, error) {
+	switch a = strings.ToLower(a); a {
+	case "r", "s":
+		return a, nil
+	default:
+		return "", fmt.Errorf("invalid DMARC alignment mode %q", a)
+	}
+} // func dmarcAlignment(a string) (string, error)
+
+// String returns the DMARC record in normalized form, with the tags in a
+// fixed order and without the ones that have their default value.
+func (d *DMARC) String() string {
+	var parts = []string{"v=" + dmarcVersion, "p=" + d.Policy}
+
+	if d.SubPolicy != "" && d.SubPolicy != d.Policy {
+		parts = append(parts, "sp="+d.SubPolicy)
+	}
+
+	if d.Pct != 100 {
+		parts = append(parts, "pct="+strconv.Itoa(d.Pct))
+	}
+
+	if d.ADKIM == "s" {
+		parts = append(parts, "adkim=s")
+	}
+
+	if d.ASPF == "s" {
+		parts = append(parts, "aspf=s")
+	}
+
+	if d.RUA != "" {
+		parts = append(parts, "rua="+d.RUA)
+	}
+
+	if d.RUF != "" {
+		parts = append(parts, "ruf="+d.RUF)
+	}
+
+	return strings.Join(parts, "; ")
+} // func (d *DMARC) String() string
+
+var stsID = regexp.MustCompile(`^[[:alnum:]]{1,32}$`)
+
+// IsMTASTS returns true if txt looks like it is meant to be an MTA-STS
+// record.
+func IsMTASTS(txt string) bool {
+	return isTagRecord(txt, mtastsVersion)
+} // func IsMTASTS(txt string) bool
+
+// ParseMTASTS parses the MTA-STS record txt, as described in RFC 8461,
+// and returns it in normalized form.
+func ParseMTASTS(txt string) (string, error) {
+	var (
+		err  error
+		tags []tag
+	)
+
+	if tags, err = parseTags(txt, mtastsVersion); err != nil {
+		return "", err
+	}
+
+	for _, t := range tags[1:] {
+		if t.name == "id" {
+			if !stsID.MatchString(t.value) {
+				return "", fmt.Errorf("invalid MTA-STS id %q", t.value)
+			}
+
+			return fmt.Sprintf("v=%s; id=%s", mtastsVersion, t.value), nil
+		}
+	}
+
+	return "", errors.New("MTA-STS record has no id")
+} // func ParseMTASTS(txt string) (string, error)
+
+// IsTLSRPT returns true if txt looks like it is meant to be a TLS-RPT
+// record.
+func IsTLSRPT(txt string) bool {
+	return isTagRecord(txt, tlsrptVersion)
+} // func IsTLSRPT(txt string) bool
+
+// ParseTLSRPT parses the TLS-RPT record txt, as described in RFC 8460,
+// and returns it in normalized form.
+func ParseTLSRPT(txt string) (string, error) {
+	var (
+		err  error
+		tags []tag
+	)
+
+	if tags, err = parseTags(txt, tlsrptVersion); err != nil {
+		return "", err
+	}
+
+	for _, t := range tags[1:] {
+		if t.name == "rua" {
+			if t.value == "" {
+				break
+			}
+
+			return fmt.Sprintf("v=%s; rua=%s", tlsrptVersion, t.value), nil
+		}
+	}
+
+	return "", errors.New("TLS-RPT record has no rua")
+} // func ParseTLSRPT(txt string) (string, error)
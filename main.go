@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 12. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-01-30 15:16:15 krylon>
+// Time-stamp: <2026-10-19 15:40:58 krylon>
 
 package main
 
@@ -15,6 +15,9 @@ import (
 	"time"
 
 	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/generator"
+	"github.com/blicero/guangng/model/genmode"
+	"github.com/blicero/guangng/model/meta"
 	"github.com/blicero/guangng/nexus"
 	"github.com/blicero/guangng/web"
 )
@@ -24,6 +27,12 @@ const (
 	defaultNCnt = 8
 	defaultXCnt = 2
 	defaultScnt = 4
+	defaultGcnt = 2
+	// defaultExplore is the share of addresses the Generator draws from
+	// the whole address space in random mode.
+	defaultExplore = 0.3
+	// month is the unit of the cache TTL.
+	month = time.Hour * 24 * 30
 )
 
 func printVer() {
@@ -34,16 +43,39 @@ func printVer() {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			os.Exit(runExport(os.Args[2:]))
+		case "import":
+			os.Exit(runImport(os.Args[2:]))
+		case "user":
+			os.Exit(runUser(os.Args[2:]))
+		case "classify":
+			os.Exit(runClassify(os.Args[2:]))
+		case "psl":
+			os.Exit(runPSL(os.Args[2:]))
+		}
+	}
+
 	printVer()
 
 	var (
-		err                    error
-		nx                     *nexus.Nexus
-		srv                    *web.Server
-		aCnt, nCnt, xCnt, sCnt int
-		version                bool
-		addr, defaultAddr      string
-		delay                  int
+		err                          error
+		nx                           *nexus.Nexus
+		cfg                          nexus.Config
+		srv                          *web.Server
+		aCnt, nCnt, xCnt, sCnt, gCnt int
+		version, useTLS, nsecWalk    bool
+		addr, defaultAddr            string
+		certFile, keyFile            string
+		redirectAddr, geoLang        string
+		modeName                     string
+		mode                         genmode.Mode
+		seed                         int64
+		explore                      float64
+		delay, expand, cacheTTL      int
+		ptrInflight                  int
 	)
 
 	defaultAddr = fmt.Sprintf("[::1]:%d", common.WebPort)
@@ -52,9 +84,22 @@ func main() {
 	flag.IntVar(&nCnt, "ncnt", defaultNCnt, "Number of name resolution workers")
 	flag.IntVar(&xCnt, "xcnt", defaultXCnt, "Number of AXFR workers")
 	flag.IntVar(&sCnt, "scnt", defaultScnt, "Number of scan workers")
+	flag.IntVar(&gCnt, "gcnt", defaultGcnt, "Number of geolocation workers")
+	flag.StringVar(&modeName, "genmode", "random", "How to generate addresses (random, permutation)")
+	flag.Int64Var(&seed, "seed", 0, "Seed of the permutation to walk in permutation mode (0: resume the last one or pick one at random)")
+	flag.Float64Var(&explore, "explore", defaultExplore, "Share of random addresses drawn from the whole address space rather than from productive networks (1 disables the bias)")
+	flag.IntVar(&expand, "expand", 0, "Prefix length of the network around each Host found to look at next in random mode (0 disables expansion)")
+	flag.IntVar(&cacheTTL, "cachettl", 0, "Number of months after which the Generator tries an address again in random mode (0: never)")
+	flag.IntVar(&ptrInflight, "ptrasync", 0, "Number of PTR queries to keep in flight with the asynchronous resolver (0: only use name workers)")
+	flag.BoolVar(&nsecWalk, "nsecwalk", false, "Enumerate NSEC-signed zones that refuse AXFR by walking their NSEC chain")
+	flag.StringVar(&geoLang, "geolang", meta.DefaultLanguage, "Language for country and city names")
 	flag.BoolVar(&version, "version", false, "Display the version number and exit")
 	flag.StringVar(&addr, "addr", defaultAddr, "Address for the web UI to listen on")
 	flag.IntVar(&delay, "delay", 5, "Delay before starting all the moving parts")
+	flag.BoolVar(&useTLS, "tls", false, "Serve the web UI via HTTPS")
+	flag.StringVar(&certFile, "cert", "", "TLS certificate (default: generate a self-signed one)")
+	flag.StringVar(&keyFile, "key", "", "TLS private key (default: generate one with the certificate)")
+	flag.StringVar(&redirectAddr, "redirect", "", "Address to redirect plain HTTP requests to HTTPS from")
 
 	flag.Parse()
 
@@ -62,7 +107,32 @@ func main() {
 		os.Exit(0)
 	}
 
-	if nx, err = nexus.New(aCnt, nCnt, xCnt, sCnt); err != nil {
+	if mode, err = genmode.Parse(modeName); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	// The Generator checks its settings itself.
+	cfg = nexus.Config{
+		Generator: generator.Config{
+			AddrWorkers: aCnt,
+			NameWorkers: nCnt,
+			Mode:        mode,
+			Seed:        seed,
+			Explore:     explore,
+			Expand:      expand,
+			CacheTTL:    time.Duration(cacheTTL) * month,
+			AsyncPTR:    ptrInflight != 0,
+			PTRInflight: ptrInflight,
+		},
+		XFRWorkers:  xCnt,
+		NSECWalk:    nsecWalk,
+		ScanWorkers: sCnt,
+		GeoWorkers:  gCnt,
+		GeoLang:     geoLang,
+	}
+
+	if nx, err = nexus.New(cfg); err != nil {
 		fmt.Fprintf(
 			os.Stderr,
 			"Failed to create Nexus: %s\n",
@@ -74,6 +144,14 @@ func main() {
 			"Failed to create web server: %s\n",
 			err.Error())
 		os.Exit(1)
+	} else if useTLS || certFile != "" {
+		if err = srv.UseTLS(certFile, keyFile, redirectAddr); err != nil {
+			fmt.Fprintf(
+				os.Stderr,
+				"Failed to set up TLS: %s\n",
+				err.Error())
+			os.Exit(1)
+		}
 	}
 
 	fmt.Printf("WebUI is running on %s\n", addr)
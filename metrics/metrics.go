@@ -0,0 +1,415 @@
+// /home/krylon/go/src/github.com/blicero/guangng/metrics/metrics.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:10:30 krylon>
+
+// Package metrics keeps counters on what the various subsystems are doing
+// and renders them in the Prometheus text exposition format.
+//
+// The counters are package-level variables, so any part of the application
+// can update them without having to pass anything around. Values that are
+// only interesting at the moment someone looks, like the number of workers,
+// are collected as Gauges when the metrics are written.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Prefix is prepended to the names of all metrics.
+const Prefix = "guangng_"
+
+// The metrics we keep track of.
+var (
+	AddrGenerated = NewCounter("generator_addresses_total",
+		"Number of IP addresses generated")
+	CacheHits = NewCounter("generator_cache_hits_total",
+		"Number of generated IP addresses that were found in the cache")
+	BlacklistHits = NewCounterVec("blacklist_hits_total",
+		"Number of addresses and names matched by a blacklist",
+		"list")
+	AddrStrategy = NewCounterVec("generator_address_strategy_total",
+		"Number of IP addresses drawn from the whole address space (explore), from productive networks (exploit) or from the neighbourhood of Hosts found (expand)",
+		"strategy")
+	PTRLookups = NewCounterVec("generator_ptr_lookups_total",
+		"Number of reverse lookups by outcome and the name server that answered them",
+		"outcome",
+		"server")
+	FCrDNS = NewCounterVec("generator_fcrdns_total",
+		"Number of PTR names by whether they resolve back to the address",
+		"result")
+	GeoLookups = NewCounterVec("geo_lookups_total",
+		"Number of geolocation lookups by result",
+		"result")
+	XFRAttempts = NewCounter("xfr_attempts_total",
+		"Number of attempted zone transfers")
+	XFRSuccesses = NewCounter("xfr_successes_total",
+		"Number of successful zone transfers")
+	XFRServerAttempts = NewCounterVec("xfr_server_attempts_total",
+		"Number of zone transfers attempted against a single name server address by outcome",
+		"outcome")
+	XFRDNSSEC = NewCounterVec("xfr_dnssec_total",
+		"Number of zones checked for DNSSEC by how they are signed",
+		"mode")
+	NSECNames = NewCounter("xfr_nsec_names_total",
+		"Number of names found by walking NSEC chains")
+	Probes = NewCounterVec("scanner_probes_total",
+		"Number of ports probed by port and outcome",
+		"port",
+		"outcome")
+	DBRetries = NewCounter("db_retries_total",
+		"Number of database operations retried because the database was busy")
+	PoolWaits = NewCounter("db_pool_waits_total",
+		"Number of times a connection pool was empty when a connection was requested")
+	PoolWaitTime = NewDurationCounter("db_pool_wait_seconds_total",
+		"Total time spent waiting for a connection pool")
+)
+
+// metric is implemented by everything that can be written out.
+type metric interface {
+	name() string
+	write(w io.Writer) error
+}
+
+var (
+	regLock  sync.Mutex
+	registry []metric
+)
+
+func register(m metric) {
+	regLock.Lock()
+	registry = append(registry, m)
+	regLock.Unlock()
+} // func register(m metric)
+
+// Write renders all registered counters, followed by the given Gauges, in
+// the Prometheus text format.
+func Write(w io.Writer, gauges ...*Gauge) error {
+	var (
+		err  error
+		list []metric
+	)
+
+	regLock.Lock()
+	list = slices.Clone(registry)
+	regLock.Unlock()
+
+	for _, g := range gauges {
+		list = append(list, g)
+	}
+
+	slices.SortStableFunc(list, func(a, b metric) int {
+		return strings.Compare(a.name(), b.name())
+	})
+
+	for _, m := range list {
+		if err = m.write(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+} // func Write(w io.Writer, gauges ...*Gauge) error
+
+func writeHeader(w io.Writer, name, help, kind string) error {
+	_, err := fmt.Fprintf(w, "# HELP %s%s %s\n# TYPE %s%s %s\n",
+		Prefix,
+		name,
+		strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help),
+		Prefix,
+		name,
+		kind)
+	return err
+} // func writeHeader(w io.Writer, name, help, kind string) error
+
+var labelEscape = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels renders a label set as {a="x",b="y"}.
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	sb.WriteByte('{')
+	for i, n := range names {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(n)
+		sb.WriteString(`="`)
+		sb.WriteString(labelEscape.Replace(values[i]))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+
+	return sb.String()
+} // func formatLabels(names, values []string) string
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+} // func formatFloat(f float64) string
+
+//////////////////////////////////////////////////////////////////////////////
+/// Counter //////////////////////////////////////////////////////////////////
+//////////////////////////////////////////////////////////////////////////////
+
+// Counter is a monotonically increasing number.
+type Counter struct {
+	n, help string
+	val     atomic.Uint64
+}
+
+// NewCounter creates and registers a Counter.
+func NewCounter(name, help string) *Counter {
+	var c = &Counter{n: name, help: help}
+	register(c)
+	return c
+} // func NewCounter(name, help string) *Counter
+
+// Inc increments the Counter by one.
+func (c *Counter) Inc() {
+	c.val.Add(1)
+} // func (c *Counter) Inc()
+
+// Add increments the Counter by n.
+func (c *Counter) Add(n uint64) {
+	c.val.Add(n)
+} // func (c *Counter) Add(n uint64)
+
+// Value returns the Counter's current value.
+func (c *Counter) Value() uint64 {
+	return c.val.Load()
+} // func (c *Counter) Value() uint64
+
+func (c *Counter) name() string { return c.n }
+
+func (c *Counter) write(w io.Writer) error {
+	if err := writeHeader(w, c.n, c.help, "counter"); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "%s%s %d\n", Prefix, c.n, c.val.Load())
+	return err
+} // func (c *Counter) write(w io.Writer) error
+
+//////////////////////////////////////////////////////////////////////////////
+/// DurationCounter //////////////////////////////////////////////////////////
+//////////////////////////////////////////////////////////////////////////////
+
+// DurationCounter accumulates time, it is written out in seconds.
+type DurationCounter struct {
+	n, help string
+	val     atomic.Int64
+}
+
+// NewDurationCounter creates and registers a DurationCounter.
+func NewDurationCounter(name, help string) *DurationCounter {
+	var c = &DurationCounter{n: name, help: help}
+	register(c)
+	return c
+} // func NewDurationCounter(name, help string) *DurationCounter
+
+// Add adds d to the DurationCounter. Negative durations are ignored.
+func (c *DurationCounter) Add(d time.Duration) {
+	if d > 0 {
+		c.val.Add(int64(d))
+	}
+} // func (c *DurationCounter) Add(d time.Duration)
+
+// Value returns the accumulated time.
+func (c *DurationCounter) Value() time.Duration {
+	return time.Duration(c.val.Load())
+} // func (c *DurationCounter) Value() time.Duration
+
+func (c *DurationCounter) name() string { return c.n }
+
+func (c *DurationCounter) write(w io.Writer) error {
+	if err := writeHeader(w, c.n, c.help, "counter"); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "%s%s %s\n",
+		Prefix,
+		c.n,
+		formatFloat(c.Value().Seconds()))
+	return err
+} // func (c *DurationCounter) write(w io.Writer) error
+
+//////////////////////////////////////////////////////////////////////////////
+/// CounterVec ///////////////////////////////////////////////////////////////
+//////////////////////////////////////////////////////////////////////////////
+
+// CounterVec is a family of Counters that share a name and differ by the
+// values of their labels.
+type CounterVec struct {
+	n, help string
+	labels  []string
+	lock    sync.RWMutex
+	vals    map[string]*labeledCounter
+}
+
+type labeledCounter struct {
+	values []string
+	Counter
+}
+
+// NewCounterVec creates and registers a CounterVec with the given label names.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	var v = &CounterVec{
+		n:      name,
+		help:   help,
+		labels: labels,
+		vals:   make(map[string]*labeledCounter),
+	}
+
+	register(v)
+	return v
+} // func NewCounterVec(name, help string, labels ...string) *CounterVec
+
+// With returns the Counter for the given label values, creating it if
+// necessary. The number of values must match the number of labels.
+func (v *CounterVec) With(values ...string) *Counter {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d",
+			v.n,
+			len(v.labels),
+			len(values)))
+	}
+
+	var (
+		c     *labeledCounter
+		found bool
+		key   = strings.Join(values, "\xff")
+	)
+
+	v.lock.RLock()
+	c, found = v.vals[key]
+	v.lock.RUnlock()
+
+	if found {
+		return &c.Counter
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if c, found = v.vals[key]; !found {
+		c = &labeledCounter{values: slices.Clone(values)}
+		v.vals[key] = c
+	}
+
+	return &c.Counter
+} // func (v *CounterVec) With(values ...string) *Counter
+
+func (v *CounterVec) name() string { return v.n }
+
+func (v *CounterVec) write(w io.Writer) error {
+	var (
+		err  error
+		keys []string
+	)
+
+	if err = writeHeader(w, v.n, v.help, "counter"); err != nil {
+		return err
+	}
+
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+
+	for k := range v.vals {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		var c = v.vals[k]
+
+		if _, err = fmt.Fprintf(w, "%s%s%s %d\n",
+			Prefix,
+			v.n,
+			formatLabels(v.labels, c.values),
+			c.val.Load()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+} // func (v *CounterVec) write(w io.Writer) error
+
+//////////////////////////////////////////////////////////////////////////////
+/// Gauge ////////////////////////////////////////////////////////////////////
+//////////////////////////////////////////////////////////////////////////////
+
+// Gauge is a snapshot of values that may go up and down. Unlike the
+// Counters, Gauges are not registered, they are filled in right before the
+// metrics are written.
+type Gauge struct {
+	n, help string
+	labels  []string
+	samples []gaugeSample
+}
+
+type gaugeSample struct {
+	values []string
+	val    float64
+}
+
+// NewGauge creates a Gauge with the given label names.
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{n: name, help: help, labels: labels}
+} // func NewGauge(name, help string, labels ...string) *Gauge
+
+// Set records a value for the given label values.
+func (g *Gauge) Set(val float64, values ...string) {
+	if len(values) != len(g.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d",
+			g.n,
+			len(g.labels),
+			len(values)))
+	}
+
+	g.samples = append(g.samples, gaugeSample{values: values, val: val})
+} // func (g *Gauge) Set(val float64, values ...string)
+
+func (g *Gauge) name() string { return g.n }
+
+func (g *Gauge) write(w io.Writer) error {
+	var err error
+
+	if err = writeHeader(w, g.n, g.help, "gauge"); err != nil {
+		return err
+	}
+
+	for _, s := range g.samples {
+		if _, err = fmt.Fprintf(w, "%s%s%s %s\n",
+			Prefix,
+			g.n,
+			formatLabels(g.labels, s.values),
+			formatFloat(s.val)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+} // func (g *Gauge) write(w io.Writer) error
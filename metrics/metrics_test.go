@@ -0,0 +1,50 @@
+// /home/krylon/go/src/github.com/blicero/guangng/metrics/metrics_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 21:58:02 krylon>
+
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	var (
+		err   error
+		buf   bytes.Buffer
+		gauge = NewGauge("test_queue_depth", "Queue depth", "queue")
+		vec   = NewCounterVec("test_probes_total", "Probes", "port", "outcome")
+		dur   = NewDurationCounter("test_wait_seconds_total", "Wait time")
+	)
+
+	vec.With("22", "success").Inc()
+	vec.With("22", "success").Inc()
+	vec.With("80", "fail\"ure").Add(3)
+	dur.Add(1500 * time.Millisecond)
+	gauge.Set(4, "ipQ")
+
+	if err = Write(&buf, gauge); err != nil {
+		t.Fatalf("Failed to write metrics: %s", err.Error())
+	}
+
+	var out = buf.String()
+
+	for _, line := range []string{
+		"# TYPE guangng_test_probes_total counter",
+		`guangng_test_probes_total{port="22",outcome="success"} 2`,
+		`guangng_test_probes_total{port="80",outcome="fail\"ure"} 3`,
+		"guangng_test_wait_seconds_total 1.5",
+		"# TYPE guangng_test_queue_depth gauge",
+		`guangng_test_queue_depth{queue="ipQ"} 4`,
+		"guangng_db_retries_total 0",
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Output does not contain %q:\n%s", line, out)
+		}
+	}
+} // func TestWrite(t *testing.T)
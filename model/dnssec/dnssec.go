@@ -0,0 +1,35 @@
+// /home/krylon/go/src/github.com/blicero/guangng/model/dnssec/dnssec.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:10:30 krylon>
+
+// Package dnssec defines the ways a zone can prove that a name does not
+// exist, which decides whether we can enumerate its names.
+package dnssec
+
+//go:generate stringer -type=Mode
+
+// Mode is how a zone is signed. The zero value means we have not checked.
+type Mode uint8
+
+const (
+	_ = iota
+	// Unsigned means the zone is not signed at all.
+	Unsigned Mode = iota
+	// NSEC means the zone uses NSEC records, which link each name to the
+	// next one, so following the chain gives us all the names in the zone.
+	NSEC
+	// NSEC3 means the zone uses hashed NSEC3 records, so walking the
+	// chain only gives us hashes.
+	NSEC3
+)
+
+// AllModes returns a slice of all valid Mode values.
+func AllModes() []Mode {
+	return []Mode{
+		Unsigned,
+		NSEC,
+		NSEC3,
+	}
+} // func AllModes() []Mode
@@ -0,0 +1,49 @@
+// /home/krylon/go/src/github.com/blicero/guangng/model/genmode/genmode.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 23:02:18 krylon>
+
+// Package genmode defines the strategies the Generator can use to come up
+// with IP addresses.
+package genmode
+
+import (
+	"fmt"
+	"strings"
+)
+
+//go:generate stringer -type=Mode
+
+// Mode determines how the Generator picks the next IP address.
+type Mode uint8
+
+const (
+	_ = iota
+	// Random draws addresses at random and uses the IP cache to avoid
+	// looking at the same address twice.
+	Random Mode = iota
+	// Permutation walks a pseudo-random permutation of the IPv4 address
+	// space, so it never repeats itself and needs no cache.
+	Permutation
+)
+
+// AllModes returns a slice of all valid Mode values.
+func AllModes() []Mode {
+	return []Mode{
+		Random,
+		Permutation,
+	}
+} // func AllModes() []Mode
+
+// Parse returns the Mode with the given name.
+func Parse(name string) (Mode, error) {
+	switch strings.ToLower(name) {
+	case "random":
+		return Random, nil
+	case "permutation", "perm":
+		return Permutation, nil
+	default:
+		return 0, fmt.Errorf("unknown generator mode %q", name)
+	}
+} // func Parse(name string) (Mode, error)
@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 15. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-01-23 15:43:59 krylon>
+// Time-stamp: <2026-10-19 15:10:30 krylon>
 
 package hsrc
 
@@ -18,4 +18,19 @@ const (
 	MX
 	NS
 	User
+	Import
+	NSEC
 )
+
+// AllSources returns a slice of all valid HostSource values.
+func AllSources() []HostSource {
+	return []HostSource{
+		Generator,
+		XFR,
+		MX,
+		NS,
+		User,
+		Import,
+		NSEC,
+	}
+} // func AllSources() []HostSource
@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 10. 02. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-02-10 20:23:31 krylon>
+// Time-stamp: <2026-10-20 20:44:02 krylon>
 
 // Package meta provides facilities to guesstimate the locations and operating
 // systems of Hosts.
@@ -11,10 +11,13 @@ package meta
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"net/netip"
+	"os"
 	"path/filepath"
-	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/blicero/guangng/common"
 	"github.com/blicero/guangng/logdomain"
@@ -25,105 +28,59 @@ import (
 const (
 	geoIPCityPath    = "GeoLite2-City.mmdb"
 	geoIPCountryPath = "GeoLite2-Country.mmdb"
+	geoIPASNPath     = "GeoLite2-ASN.mmdb"
 )
 
-var osList = []string{ // nolint: unused
-	"Windows",
-	"Ubuntu",
-	"Debian",
-	"CentOS",
-	"Red Hat",
-	"Fedora",
-	"Yocto",
-	"FreeBSD",
-	"NetBSD",
-	"OpenBSD",
-	"DragonflyBSD",
-	"RouterOS",
-	"Linux",
-	"JUNOS",
-	"Cisco IOS",
-	"SonicOS",
-}
-
-var osPatterns = map[string][]*regexp.Regexp{ // nolint: unused
-	"Windows": {
-		regexp.MustCompile("Microsoft"),
-		regexp.MustCompile("Windows"),
-	},
-	"Debian": {
-		regexp.MustCompile("(?i)Debian"),
-		regexp.MustCompile("(?i)[.]deb"),
-	},
-	"Ubuntu": {
-		regexp.MustCompile("(?i)ubuntu"),
-	},
-	"CentOS": {
-		regexp.MustCompile("(?i)CentOS"),
-	},
-	"Red Hat": {
-		regexp.MustCompile(`(?i)rhel\d+`),
-		regexp.MustCompile("(?i)Red ?Hat"),
-		regexp.MustCompile(`(?i)[.]el\d+[.]`),
-	},
-	"Fedora": {
-		regexp.MustCompile("(?i)fedora"),
-	},
-	"Yocto Linux": {
-		regexp.MustCompile("(?i)yocto"),
-	},
-	"FreeBSD": {
-		regexp.MustCompile("(?i)FreeBSD"),
-	},
-	"OpenBSD": {
-		regexp.MustCompile("(?i)OpenBSD"),
-	},
-	"DragonflyBSD": {
-		regexp.MustCompile("Dragonfly"),
-	},
-	"NetBSD": {
-		regexp.MustCompile("(?i)NetBSD"),
-	},
-	"RouterOS": {
-		regexp.MustCompile("(?i)RouterOS"),
-	},
-	"Linux": {
-		regexp.MustCompile("(?i)Linux"),
-	},
-	"JUNOS": {
-		regexp.MustCompile("(?i:JUNOS|Juniper)"),
-	},
-	"Cisco IOS": {
-		regexp.MustCompile("(?i)Cisco IOS Software"),
-		regexp.MustCompile("(?i)Cisco Systems"),
-	},
-	"SonicOS": {
-		regexp.MustCompile("(?i)SonicOS"),
-		regexp.MustCompile("(?i)SonicWALL"),
-	},
-}
+// ErrNoDatabase is returned by OpenMetaEngine if the GeoIP database files
+// cannot be found.
+var ErrNoDatabase = errors.New("GeoIP database not found")
+
+// DefaultLanguage is the language place names are returned in, unless
+// configured otherwise.
+const DefaultLanguage = "en"
+
+// Languages lists the languages the GeoIP databases offer place names in.
+var Languages = []string{"de", "en", "es", "fr", "ja", "pt-BR", "ru", "zh-CN"}
 
 // MetaEngine processes metadata on Hosts.
 type MetaEngine struct {
 	citydb    *geoip2.Reader
 	countrydb *geoip2.Reader
+	asndb     *geoip2.Reader // Optional, nil if the database is missing
 	log       *log.Logger
+	lang      string
 } // type MetaEngine struct
 
-// OpenMetaEngine creates a new MetaEngine.
+// OpenMetaEngine creates a new MetaEngine. The GeoIP databases are looked
+// for in the folder prefix, or in common.BaseDir if prefix is empty.
+// If they do not exist, the error wraps ErrNoDatabase. The ASN database is
+// optional, if it is missing, Hosts are located without their networks.
 func OpenMetaEngine(prefix string) (*MetaEngine, error) {
 	var (
 		err                            error
 		msg, countrydbPath, citydbPath string
-		eng                            = new(MetaEngine)
+		eng                            = &MetaEngine{lang: DefaultLanguage}
 	)
 
-	countrydbPath = filepath.Join(common.BaseDir, geoIPCountryPath)
-	citydbPath = filepath.Join(common.BaseDir, geoIPCityPath)
+	if prefix == "" {
+		prefix = common.BaseDir
+	}
+
+	countrydbPath = filepath.Join(prefix, geoIPCountryPath)
+	citydbPath = filepath.Join(prefix, geoIPCityPath)
 
 	if eng.log, err = common.GetLogger(logdomain.MetaEngine); err != nil {
 		return nil, err
-	} else if eng.countrydb, err = geoip2.Open(countrydbPath); err != nil {
+	}
+
+	for _, path := range []string{countrydbPath, citydbPath} {
+		if _, err = os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			eng.log.Printf("[WARN] GeoIP database %s does not exist\n", path)
+			return nil, fmt.Errorf("%w: %s", ErrNoDatabase, path)
+		}
+	}
+
+	if eng.countrydb, err = geoip2.Open(countrydbPath); err != nil {
 		msg = fmt.Sprintf("Error opening GeoIP database %s: %s",
 			countrydbPath,
 			err.Error())
@@ -134,39 +91,113 @@ func OpenMetaEngine(prefix string) (*MetaEngine, error) {
 		eng.log.Println(msg)
 		return nil, errors.New(msg)
 	} else if eng.citydb, err = geoip2.Open(citydbPath); err != nil {
+		eng.countrydb.Close() // nolint: errcheck
 		msg = fmt.Sprintf("cannot open GeoIP database %s: %s",
 			citydbPath,
 			err.Error())
 		eng.log.Printf("[ERROR] %s\n", msg)
 		return nil, errors.New(msg)
-	} else {
-		return eng, nil
 	}
+
+	var asndbPath = filepath.Join(prefix, geoIPASNPath)
+
+	if _, err = os.Stat(asndbPath); errors.Is(err, fs.ErrNotExist) {
+		eng.log.Printf("[INFO] ASN database %s does not exist, not looking up networks\n",
+			asndbPath)
+	} else if eng.asndb, err = geoip2.Open(asndbPath); err != nil {
+		eng.Close()
+		msg = fmt.Sprintf("cannot open ASN database %s: %s",
+			asndbPath,
+			err.Error())
+		eng.log.Printf("[ERROR] %s\n", msg)
+		return nil, errors.New(msg)
+	}
+
+	return eng, nil
 } // func OpenMetaEngine() (*MetaEngine, error)
 
 // Close closes the MetaEngine.
 func (m *MetaEngine) Close() {
 	m.countrydb.Close() // nolint: errcheck
+	m.citydb.Close()    // nolint: errcheck
+	if m.asndb != nil {
+		m.asndb.Close() // nolint: errcheck
+	}
 } // func (m *MetaEngine) Close()
 
+// HasASN returns true if the MetaEngine can look up autonomous systems.
+func (m *MetaEngine) HasASN() bool {
+	return m.asndb != nil
+} // func (m *MetaEngine) HasASN() bool
+
+// SetLanguage sets the language place names are returned in.
+func (m *MetaEngine) SetLanguage(lang string) error {
+	if !slices.Contains(Languages, lang) {
+		return fmt.Errorf("unsupported language %q, must be one of %s",
+			lang,
+			strings.Join(Languages, ", "))
+	}
+
+	m.lang = lang
+	return nil
+} // func (m *MetaEngine) SetLanguage(lang string) error
+
+// localName returns the name of a place in the MetaEngine's language,
+// falling back to English if there is no translation.
+func localName(names *geoip2.Names, lang string) string {
+	var name string
+
+	switch lang {
+	case "de":
+		name = names.German
+	case "es":
+		name = names.Spanish
+	case "fr":
+		name = names.French
+	case "ja":
+		name = names.Japanese
+	case "pt-BR":
+		name = names.BrazilianPortuguese
+	case "ru":
+		name = names.Russian
+	case "zh-CN":
+		name = names.SimplifiedChinese
+	}
+
+	if name == "" {
+		name = names.English
+	}
+
+	return name
+} // func localName(names *geoip2.Names, lang string) string
+
+func hostAddr(h *model.Host) (netip.Addr, error) {
+	var addr, ok = netip.AddrFromSlice(h.Addr)
+
+	if !ok {
+		return addr, fmt.Errorf("cannot process IP address of Host %s/%s",
+			h.Name,
+			h.AStr())
+	}
+
+	return addr.Unmap(), nil
+} // func hostAddr(h *model.Host) (netip.Addr, error)
+
 // LookupCountry attempts to determine what county a Host is located in.
 func (m *MetaEngine) LookupCountry(h *model.Host) (string, error) {
 	var (
 		err     error
 		country *geoip2.Country
 		addr    netip.Addr
-		ok      bool
 	)
 
-	if addr, ok = netip.AddrFromSlice(h.Addr); !ok {
-		return "", fmt.Errorf("cannot process IP address of Host %s/%s",
-			h.Name,
-			h.AStr())
+	if addr, err = hostAddr(h); err != nil {
+		return "", err
 	} else if country, err = m.countrydb.Country(addr); err != nil {
 		return "", err
 	}
 
-	return country.Country.Names.German, nil
+	return localName(&country.Country.Names, m.lang), nil
 } // func (m *MetaEngine) LookupCountry(h *Host) (string, error)
 
 // LookupCity attempts to determine what city a Host is located in.
@@ -175,52 +206,69 @@ func (m *MetaEngine) LookupCity(h *model.Host) (string, error) {
 		err  error
 		city *geoip2.City
 		addr netip.Addr
-		ok   bool
 	)
 
-	if addr, ok = netip.AddrFromSlice(h.Addr); !ok {
-		return "", fmt.Errorf("cannot process IP address of Host %s/%s",
-			h.Name,
-			h.AStr())
+	if addr, err = hostAddr(h); err != nil {
+		return "", err
 	} else if city, err = m.citydb.City(addr); err != nil {
 		return "", err
 	}
 
-	return city.City.Names.German, nil
+	return localName(&city.City.Names, m.lang), nil
 } // func (m *MetaEngine) LookupCity(h *Host) (string, error)
 
-// LookupOperatingSystem attempts to determine what OS a Host is running.
-// func (m *MetaEngine) LookupOperatingSystem(h *data.HostWithPorts) string {
-// 	var results map[string]int = make(map[string]int)
-
-// PORT:
-// 	for _, port := range h.Ports {
-// 		//for os, patterns := range osPatterns {
-// 		for _, osname := range osList {
-// 			patterns := osPatterns[osname]
-// 			for _, pattern := range patterns {
-// 				if port.Reply != nil && pattern.MatchString(*port.Reply) {
-// 					results[osname]++
-// 					continue PORT
-// 				}
-// 			}
-// 		}
-// 	}
+// LookupLocation determines the country and, if possible, the city a Host is
+// located in, as well as the autonomous system it belongs to, if the ASN
+// database is available. If the GeoIP databases know nothing about the
+// Host's address, it returns nil without an error.
+func (m *MetaEngine) LookupLocation(h *model.Host) (*model.GeoLocation, error) {
+	var (
+		err  error
+		city *geoip2.City
+		addr netip.Addr
+		loc  model.GeoLocation
+	)
 
-// 	var (
-// 		os     = "Unknown"
-// 		hitCnt int
-// 	)
+	if addr, err = hostAddr(h); err != nil {
+		return nil, err
+	} else if city, err = m.citydb.City(addr); err != nil {
+		return nil, err
+	}
 
-// 	for system, cnt := range results {
-// 		if cnt > hitCnt {
-// 			os = system
-// 			hitCnt = cnt
-// 		}
-// 	}
+	if city.HasData() {
+		loc.CountryCode = city.Country.ISOCode
+		loc.Country = localName(&city.Country.Names, m.lang)
+		loc.City = localName(&city.City.Names, m.lang)
+	} else {
+		// The city database does not cover everything the country
+		// database does.
+		var country *geoip2.Country
+
+		if country, err = m.countrydb.Country(addr); err != nil {
+			return nil, err
+		} else if country.HasData() {
+			loc.CountryCode = country.Country.ISOCode
+			loc.Country = localName(&country.Country.Names, m.lang)
+		}
+	}
+
+	if m.asndb != nil {
+		var asn *geoip2.ASN
+
+		if asn, err = m.asndb.ASN(addr); err != nil {
+			return nil, err
+		} else if asn.HasData() {
+			loc.ASN = uint32(asn.AutonomousSystemNumber)
+			loc.ASOrg = asn.AutonomousSystemOrganization
+		}
+	}
+
+	if loc.Country == "" && loc.ASN == 0 {
+		return nil, nil
+	}
 
-// 	return os
-// } // func (m *MetaEngine) LookupOperatingSystem(h *HostWithPorts) string
+	return &loc, nil
+} // func (m *MetaEngine) LookupLocation(h *model.Host) (*model.GeoLocation, error)
 
 // // UpdateMetadata refreshes the location and OS metadata for all hosts.
 // func (m *MetaEngine) UpdateMetadata() error {
@@ -0,0 +1,51 @@
+// /home/krylon/go/src/github.com/blicero/guangng/model/meta/meta_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 19:05:12 krylon>
+
+package meta
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/blicero/guangng/common"
+	"github.com/oschwald/geoip2-golang/v2"
+)
+
+func TestOpenMissing(t *testing.T) {
+	var err error
+
+	// Keep the log file out of the user's real base directory.
+	if err = common.SetBaseDir(t.TempDir()); err != nil {
+		t.Fatalf("Cannot set base directory: %s", err.Error())
+	} else if _, err = OpenMetaEngine(""); err == nil {
+		t.Fatal("OpenMetaEngine succeeded without GeoIP databases")
+	} else if !errors.Is(err, ErrNoDatabase) {
+		t.Errorf("Unexpected error from OpenMetaEngine: %s", err.Error())
+	}
+} // func TestOpenMissing(t *testing.T)
+
+func TestLocalName(t *testing.T) {
+	var names = &geoip2.Names{
+		English: "Germany",
+		German:  "Deutschland",
+		French:  "Allemagne",
+	}
+
+	for lang, expect := range map[string]string{
+		"de":    "Deutschland",
+		"en":    "Germany",
+		"fr":    "Allemagne",
+		"ja":    "Germany",
+		"zh-CN": "Germany",
+	} {
+		if name := localName(names, lang); name != expect {
+			t.Errorf("localName(%q) = %q, expected %q",
+				lang,
+				name,
+				expect)
+		}
+	}
+} // func TestLocalName(t *testing.T)
@@ -0,0 +1,253 @@
+// /home/krylon/go/src/github.com/blicero/guangng/model/meta/osguess.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 19:58:12 krylon>
+
+package meta
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/model"
+)
+
+// confidenceSaturation is the score at which we consider the evidence for
+// a Host's OS to be sufficient. Below that, the confidence is scaled down,
+// so a single weak hint does not yield a confident guess.
+const confidenceSaturation = 6
+
+// sourceWeight says how much we trust the responses of the services on
+// various ports. SNMP's sysDescr usually names the OS outright, SSH and
+// Telnet banners often do, everything else counts once.
+var sourceWeight = map[uint16]int{
+	22:  2,
+	23:  2,
+	161: 3,
+}
+
+// OSRule is a piece of evidence for an operating system: If Pattern matches
+// the response of a service on one of Ports (or on any port, if Ports is
+// empty), OS gets Weight points.
+type OSRule struct {
+	OS      string   `json:"os"`
+	Pattern string   `json:"pattern"`
+	Ports   []uint16 `json:"ports,omitempty"`
+	Weight  int      `json:"weight"`
+	re      *regexp.Regexp
+}
+
+// osRuleFile is the format of the file additional rules are loaded from.
+// Families maps an OS to a more generic one, e.g. a Linux distribution to
+// "Linux".
+type osRuleFile struct {
+	Families map[string]string `json:"families"`
+	Rules    []OSRule          `json:"rules"`
+}
+
+var builtinFamilies = map[string]string{
+	"Debian":      "Linux",
+	"Ubuntu":      "Linux",
+	"CentOS":      "Linux",
+	"Red Hat":     "Linux",
+	"Fedora":      "Linux",
+	"Yocto Linux": "Linux",
+}
+
+var builtinRules = []OSRule{
+	{OS: "Windows", Pattern: "Microsoft-IIS", Weight: 3},
+	{OS: "Windows", Pattern: "(?i)Windows", Weight: 2},
+	{OS: "Windows", Pattern: "Microsoft", Weight: 2},
+	{OS: "Debian", Pattern: "(?i)Debian", Weight: 3},
+	{OS: "Debian", Pattern: `(?i)[+~]deb\d+`, Weight: 3},
+	{OS: "Ubuntu", Pattern: "(?i)ubuntu", Weight: 3},
+	{OS: "CentOS", Pattern: "(?i)CentOS", Weight: 3},
+	{OS: "Red Hat", Pattern: `(?i)rhel\d+`, Weight: 3},
+	{OS: "Red Hat", Pattern: "(?i)Red ?Hat", Weight: 3},
+	{OS: "Red Hat", Pattern: `(?i)[.]el\d+[._]`, Weight: 2},
+	{OS: "Fedora", Pattern: "(?i)fedora", Weight: 3},
+	{OS: "Yocto Linux", Pattern: "(?i)yocto", Weight: 3},
+	{OS: "FreeBSD", Pattern: "(?i)FreeBSD", Weight: 3},
+	{OS: "OpenBSD", Pattern: "(?i)OpenBSD", Weight: 3},
+	{OS: "NetBSD", Pattern: "(?i)NetBSD", Weight: 3},
+	{OS: "DragonflyBSD", Pattern: "(?i)DragonFly", Weight: 3},
+	{OS: "RouterOS", Pattern: "(?i)RouterOS|MikroTik", Weight: 3},
+	{OS: "Linux", Pattern: `(?i)\bLinux\b`, Weight: 2},
+	{OS: "JUNOS", Pattern: "(?i:JUNOS|Juniper)", Weight: 3},
+	{OS: "Cisco IOS", Pattern: "(?i)Cisco IOS Software", Weight: 3},
+	{OS: "Cisco IOS", Pattern: "(?i)Cisco Systems", Weight: 2},
+	{OS: "Cisco IOS", Pattern: "User Access Verification", Ports: []uint16{23}, Weight: 1},
+	{OS: "SonicOS", Pattern: "(?i)SonicOS|SonicWALL", Weight: 3},
+}
+
+// OSGuess is the operating system we think a Host runs.
+// Confidence ranges from 0 to 1.
+type OSGuess struct {
+	Name       string
+	Confidence float64
+	Score      int
+}
+
+// OSGuesser guesses the operating systems of Hosts from the responses of
+// their services.
+type OSGuesser struct {
+	rules    []OSRule
+	families map[string]string
+}
+
+// NewOSGuesser creates an OSGuesser with the built-in rules, plus the ones
+// from the given file. If path is empty, common.OSPatternPath is used.
+// It is not an error if the file does not exist.
+func NewOSGuesser(path string) (*OSGuesser, error) {
+	var (
+		err   error
+		raw   []byte
+		extra osRuleFile
+		g     = &OSGuesser{
+			rules:    slices.Clone(builtinRules),
+			families: make(map[string]string, len(builtinFamilies)),
+		}
+	)
+
+	if path == "" {
+		path = common.OSPatternPath
+	}
+
+	for name, fam := range builtinFamilies {
+		g.families[name] = fam
+	}
+
+	if raw, err = os.ReadFile(path); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("cannot read OS patterns from %s: %w",
+				path,
+				err)
+		}
+	} else if err = json.Unmarshal(raw, &extra); err != nil {
+		return nil, fmt.Errorf("cannot parse OS patterns from %s: %w",
+			path,
+			err)
+	}
+
+	for name, fam := range extra.Families {
+		g.families[name] = fam
+	}
+
+	g.rules = append(g.rules, extra.Rules...)
+
+	for i := range g.rules {
+		var r = &g.rules[i]
+
+		if r.OS == "" || r.Weight <= 0 {
+			return nil, fmt.Errorf("invalid OS rule %q: need an OS and a positive weight",
+				r.Pattern)
+		} else if r.re, err = regexp.Compile(r.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid pattern for %s: %w",
+				r.OS,
+				err)
+		}
+	}
+
+	return g, nil
+} // func NewOSGuesser(path string) (*OSGuesser, error)
+
+// RuleCount returns the number of rules the OSGuesser knows.
+func (g *OSGuesser) RuleCount() int {
+	return len(g.rules)
+} // func (g *OSGuesser) RuleCount() int
+
+// Guess determines which OS a Host most likely runs, based on the responses
+// of its services. For each service, every OS gets the weight of the
+// strongest rule that matches, times the weight of the port. The score of a
+// generic OS like Linux goes to its best-scoring specific variant, if there
+// is one. If there is no evidence at all, Guess returns nil.
+func (g *OSGuesser) Guess(svc map[uint16]*model.Service) *OSGuess {
+	var (
+		scores = make(map[string]int)
+		total  int
+		best   *OSGuess
+	)
+
+	for port, s := range svc {
+		if !s.Success || s.Response == "" {
+			continue
+		}
+
+		var (
+			hits   = make(map[string]int)
+			weight = max(sourceWeight[port], 1)
+		)
+
+		for i := range g.rules {
+			var r = &g.rules[i]
+
+			if len(r.Ports) > 0 && !slices.Contains(r.Ports, port) {
+				continue
+			} else if r.Weight > hits[r.OS] && r.re.MatchString(s.Response) {
+				hits[r.OS] = r.Weight
+			}
+		}
+
+		for name, w := range hits {
+			scores[name] += w * weight
+		}
+	}
+
+	g.foldFamilies(scores)
+
+	for name, score := range scores {
+		total += score
+
+		if best == nil || score > best.Score || (score == best.Score && name < best.Name) {
+			best = &OSGuess{Name: name, Score: score}
+		}
+	}
+
+	if best == nil {
+		return nil
+	}
+
+	best.Confidence = float64(best.Score) / float64(total) *
+		min(1, float64(total)/confidenceSaturation)
+
+	return best
+} // func (g *OSGuesser) Guess(svc map[uint16]*model.Service) *OSGuess
+
+// foldFamilies moves the score of every generic OS to its best-scoring
+// variant, so that e.g. "Linux" in one banner supports "Debian" in another
+// instead of competing with it.
+func (g *OSGuesser) foldFamilies(scores map[string]int) {
+	var members = make(map[string][]string)
+
+	for name, fam := range g.families {
+		if scores[name] > 0 {
+			members[fam] = append(members[fam], name)
+		}
+	}
+
+	for fam, variants := range members {
+		if scores[fam] == 0 {
+			continue
+		}
+
+		slices.Sort(variants)
+
+		var top = variants[0]
+
+		for _, v := range variants[1:] {
+			if scores[v] > scores[top] {
+				top = v
+			}
+		}
+
+		scores[top] += scores[fam]
+		delete(scores, fam)
+	}
+} // func (g *OSGuesser) foldFamilies(scores map[string]int)
@@ -0,0 +1,175 @@
+// /home/krylon/go/src/github.com/blicero/guangng/model/meta/osguess_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 20:27:55 krylon>
+
+package meta
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blicero/guangng/model"
+)
+
+func svcMap(responses map[uint16]string) map[uint16]*model.Service {
+	var svc = make(map[uint16]*model.Service, len(responses))
+
+	for port, res := range responses {
+		svc[port] = &model.Service{
+			Port:     port,
+			Success:  true,
+			Response: res,
+		}
+	}
+
+	return svc
+} // func svcMap(responses map[uint16]string) map[uint16]*model.Service
+
+func TestOSGuess(t *testing.T) {
+	var (
+		err error
+		osg *OSGuesser
+	)
+
+	if osg, err = NewOSGuesser(filepath.Join(t.TempDir(), "nonexistent.json")); err != nil {
+		t.Fatalf("Cannot create OSGuesser: %s", err.Error())
+	}
+
+	type testCase struct {
+		svc     map[uint16]string
+		os      string
+		minConf float64
+	}
+
+	var cases = []testCase{
+		{
+			svc: map[uint16]string{
+				22: "SSH-2.0-OpenSSH_8.4p1 Debian-5+deb11u1",
+				80: "Apache/2.4.56 (Debian)",
+			},
+			os:      "Debian",
+			minConf: 0.99,
+		},
+		{
+			// The generic Linux from SNMP supports Ubuntu rather
+			// than competing with it.
+			svc: map[uint16]string{
+				161: "Linux gw 5.15.0-91-generic #101-Ubuntu SMP x86_64",
+				53:  "9.18.18-0ubuntu0.22.04.1-Ubuntu",
+			},
+			os:      "Ubuntu",
+			minConf: 0.99,
+		},
+		{
+			svc: map[uint16]string{
+				80: "Microsoft-IIS/10.0",
+			},
+			os: "Windows",
+		},
+		{
+			svc: map[uint16]string{
+				161: "Cisco IOS Software, C2960 Software (C2960-LANBASEK9-M), Version 15.0(2)SE4",
+				23:  "User Access Verification",
+			},
+			os:      "Cisco IOS",
+			minConf: 0.99,
+		},
+		{
+			svc: map[uint16]string{
+				80: "nginx",
+			},
+		},
+	}
+
+	for i, c := range cases {
+		var guess = osg.Guess(svcMap(c.svc))
+
+		if c.os == "" {
+			if guess != nil {
+				t.Errorf("Case %d: Expected no guess, got %s", i, guess.Name)
+			}
+			continue
+		} else if guess == nil {
+			t.Errorf("Case %d: Expected %s, got no guess", i, c.os)
+			continue
+		} else if guess.Name != c.os {
+			t.Errorf("Case %d: Expected %s, got %s", i, c.os, guess.Name)
+		} else if guess.Confidence < c.minConf || guess.Confidence > 1 {
+			t.Errorf("Case %d: Unexpected confidence %.2f for %s",
+				i,
+				guess.Confidence,
+				guess.Name)
+		}
+	}
+} // func TestOSGuess(t *testing.T)
+
+func TestOSGuessConflict(t *testing.T) {
+	var (
+		err   error
+		osg   *OSGuesser
+		guess *OSGuess
+	)
+
+	if osg, err = NewOSGuesser(filepath.Join(t.TempDir(), "nonexistent.json")); err != nil {
+		t.Fatalf("Cannot create OSGuesser: %s", err.Error())
+	}
+
+	guess = osg.Guess(svcMap(map[uint16]string{
+		22:   "SSH-2.0-OpenSSH_9.3 FreeBSD-20230316",
+		8080: "Apache/2.4.41 (Ubuntu)",
+	}))
+
+	if guess == nil {
+		t.Fatal("Got no guess for conflicting evidence")
+	} else if guess.Name != "FreeBSD" {
+		t.Errorf("Expected the SSH banner to win, got %s", guess.Name)
+	} else if guess.Confidence >= 1 {
+		t.Errorf("Confidence %.2f should reflect the conflicting evidence",
+			guess.Confidence)
+	}
+} // func TestOSGuessConflict(t *testing.T)
+
+func TestOSPatternFile(t *testing.T) {
+	var (
+		err   error
+		osg   *OSGuesser
+		guess *OSGuess
+		path  = filepath.Join(t.TempDir(), "os_patterns.json")
+	)
+
+	const patterns = `{
+    "families": { "Alpine": "Linux" },
+    "rules": [
+        { "os": "Alpine", "pattern": "(?i)alpine", "ports": [22], "weight": 3 }
+    ]
+}`
+
+	if err = os.WriteFile(path, []byte(patterns), 0600); err != nil {
+		t.Fatalf("Cannot write %s: %s", path, err.Error())
+	} else if osg, err = NewOSGuesser(path); err != nil {
+		t.Fatalf("Cannot load %s: %s", path, err.Error())
+	} else if osg.RuleCount() != len(builtinRules)+1 {
+		t.Errorf("Expected %d rules, got %d",
+			len(builtinRules)+1,
+			osg.RuleCount())
+	}
+
+	guess = osg.Guess(svcMap(map[uint16]string{
+		22:  "SSH-2.0-OpenSSH_9.6 alpine-r0",
+		161: "Linux fw 6.6.14-0-lts",
+		80:  "alpine",
+	}))
+
+	if guess == nil || guess.Name != "Alpine" {
+		t.Errorf("Expected Alpine, got %v", guess)
+	}
+
+	if err = os.WriteFile(path, []byte(`{"rules": [{"os": "Bad", "pattern": "(", "weight": 1}]}`), 0600); err != nil {
+		t.Fatalf("Cannot write %s: %s", path, err.Error())
+	} else if _, err = NewOSGuesser(path); err == nil {
+		t.Error("NewOSGuesser accepted an invalid pattern")
+	}
+} // func TestOSPatternFile(t *testing.T)
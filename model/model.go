@@ -2,33 +2,43 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 11. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-01-29 20:52:35 krylon>
+// Time-stamp: <2026-10-19 15:10:30 krylon>
 
 // Package model provides the data types our application deals with.
 package model
 
 import (
+	"fmt"
 	"net"
-	"regexp"
+	"slices"
 	"time"
 
+	"github.com/blicero/guangng/model/dnssec"
 	"github.com/blicero/guangng/model/hsrc"
+	"github.com/blicero/guangng/model/outcome"
+	"github.com/blicero/guangng/model/probe"
+	"github.com/blicero/guangng/model/role"
 	"github.com/blicero/guangng/model/subsystem"
+	"github.com/blicero/guangng/model/weakness"
+	"github.com/blicero/guangng/psl"
 )
 
-var zonePat = regexp.MustCompile("^[^.]+[.](.*?)[.]?$")
-
 // Host is a host on the wide, wide Internet.
 type Host struct {
-	ID          int64
-	Addr        net.IP
-	Name        string
-	Added       time.Time
-	LastContact time.Time
-	Sysname     string
-	Location    string
-	Source      hsrc.HostSource
-	astr        string
+	ID           int64
+	Addr         net.IP
+	Name         string
+	Added        time.Time
+	LastContact  time.Time
+	Sysname      string
+	OSConfidence float64 // How sure we are about Sysname, from 0 to 1
+	ASN          uint32  // Autonomous system the address belongs to, 0 if unknown
+	ASOrg        string  // Organization owning the autonomous system
+	Location     string
+	Source       hsrc.HostSource
+	Confirmed    bool        // Name resolves back to Addr
+	Names        []*HostName // All names the PTR lookup returned, if loaded
+	astr         string
 }
 
 // AStr returns a string representation of the Host's IP address.
@@ -40,26 +50,159 @@ func (h *Host) AStr() string {
 	return h.astr
 } // func (h *Host) AStr() string
 
+// Zone returns the innermost zone the Host's name may belong to.
 func (h *Host) Zone() string {
-	var match = zonePat.FindStringSubmatch(h.Name)
+	return ZoneOf(h.Name)
+} // func (h *Host) Zone() string
 
-	if match == nil {
-		return "" // CANTHAPPEN!
+// Zones returns the zones all of the Host's forward-confirmed names may
+// belong to, from each name's parent down to its registrable domain. If
+// none of the names is confirmed, or the names are not loaded, it returns
+// the zones of the Host's name.
+func (h *Host) Zones() []string {
+	var zones = make([]string, 0, len(h.Names))
+
+	for _, n := range h.Names {
+		if !n.Confirmed {
+			continue
+		}
+
+		for _, z := range psl.Zones(n.Name) {
+			if !slices.Contains(zones, z) {
+				zones = append(zones, z)
+			}
+		}
 	}
 
-	return match[1]
-} // func (h *Host) Zone() string
+	if len(zones) == 0 {
+		zones = append(zones, psl.Zones(h.Name)...)
+	}
+
+	return zones
+} // func (h *Host) Zones() []string
+
+// ZoneOf returns the innermost zone a DNS name may belong to. Usually, that
+// is the name minus its first label, but if that is a public suffix like
+// "co.uk", the name is a registrable domain and thus a zone itself. For a
+// public suffix, ZoneOf returns an empty string.
+func ZoneOf(name string) string {
+	var zones = psl.Zones(name)
+
+	if len(zones) == 0 {
+		return ""
+	}
+
+	return zones[0]
+} // func ZoneOf(name string) string
+
+// HostName is one of the names a PTR lookup returned for a Host's address.
+// Confirmed means that the name resolves back to the address.
+type HostName struct {
+	ID        int64
+	HostID    int64
+	Name      string
+	Confirmed bool
+	Checked   time.Time
+}
 
 // Zone is a DNS zone that we may attempt to perform a zone transfer on.
+// Started, Finished and Status refer to the most recent attempt. Failures
+// is the number of attempts that failed since the last successful one.
 type Zone struct {
-	ID       int64
-	Name     string
-	Added    time.Time
-	Started  time.Time
-	Finished time.Time
-	Status   bool
+	ID          int64
+	Name        string
+	Added       time.Time
+	Started     time.Time
+	Finished    time.Time
+	Status      bool
+	NextAttempt time.Time
+	Failures    int
+	DNSSEC      dnssec.Mode
 }
 
+// XFRResult is the outcome of a zone transfer attempt against one address
+// of one of the zone's name servers.
+type XFRResult struct {
+	ID        int64
+	ZoneID    int64
+	Zone      string // Name of the zone, if loaded
+	NS        string // Name of the name server
+	Addr      net.IP
+	Outcome   outcome.Outcome
+	RRCnt     int64
+	Timestamp time.Time
+}
+
+// Open returns true if the server let us transfer the zone.
+func (r *XFRResult) Open() bool {
+	return r.Outcome == outcome.Success
+} // func (r *XFRResult) Open() bool
+
+// ZoneRecord is a resource record we received in a zone transfer. Records
+// that were missing from a later transfer of the zone are not deleted, but
+// marked as Removed.
+type ZoneRecord struct {
+	ID      int64
+	ZoneID  int64
+	Owner   string
+	Type    string
+	TTL     uint32
+	RData   string
+	Added   time.Time
+	Removed time.Time
+}
+
+// Key identifies the record within its zone. Two records with the same key
+// differ in their TTL at most.
+func (r *ZoneRecord) Key() string {
+	return r.Owner + "\t" + r.Type + "\t" + r.RData
+} // func (r *ZoneRecord) Key() string
+
+// IsRemoved returns true if the record was missing from a later transfer.
+func (r *ZoneRecord) IsRemoved() bool {
+	return !r.Removed.IsZero()
+} // func (r *ZoneRecord) IsRemoved() bool
+
+// String returns the record in master file format.
+func (r *ZoneRecord) String() string {
+	return fmt.Sprintf("%s\t%d\tIN\t%s\t%s",
+		r.Owner,
+		r.TTL,
+		r.Type,
+		r.RData)
+} // func (r *ZoneRecord) String() string
+
+// MailPolicy is the e-mail security policy the apex of a Zone publishes in
+// its SPF, DMARC, MTA-STS and TLS-RPT records. The policies are stored in
+// normalized form, empty strings mean the Zone has no such record.
+// SPFAll is the qualifier of the all mechanism that applies in the end,
+// after following redirects, or an empty string if there is none.
+type MailPolicy struct {
+	ID          int64
+	ZoneID      int64
+	Zone        string
+	HasMX       bool
+	SPF         string
+	SPFAll      string
+	SPFLookups  int
+	DMARC       string
+	DMARCPolicy string
+	MTASTS      string
+	TLSRPT      string
+	Weaknesses  []weakness.Weakness
+	Checked     time.Time
+}
+
+// IsWeak returns true if the MailPolicy has any Weaknesses.
+func (p *MailPolicy) IsWeak() bool {
+	return len(p.Weaknesses) > 0
+} // func (p *MailPolicy) IsWeak() bool
+
+// Has returns true if the MailPolicy has the Weakness w.
+func (p *MailPolicy) Has(w weakness.Weakness) bool {
+	return slices.Contains(p.Weaknesses, w)
+} // func (p *MailPolicy) Has(w weakness.Weakness) bool
+
 // Service represents a scanned port (success or not).
 type Service struct {
 	ID        int64
@@ -70,6 +213,220 @@ type Service struct {
 	Timestamp time.Time
 }
 
+// PendingPort is a port on a Host that was imported from an external
+// scanner or found in an SRV record and is waiting to be probed by us.
+// Probe is the scan to use on it, if we know which service to expect.
+type PendingPort struct {
+	ID         int64
+	Host       *Host
+	Port       uint16
+	Proto      string
+	Probe      probe.Probe
+	Added      time.Time
+	Dispatched time.Time
+}
+
+// GeoLocation is where a Host is located, according to the GeoIP database.
+// Country and City are localized names, CountryCode is the ISO 3166 code.
+// ASN and ASOrg describe the network the Host belongs to, if the ASN
+// database is available.
+type GeoLocation struct {
+	CountryCode string
+	Country     string
+	City        string
+	ASN         uint32
+	ASOrg       string
+}
+
+// String returns a human-readable description of the location.
+func (l *GeoLocation) String() string {
+	if l.City == "" {
+		return l.Country
+	}
+
+	return l.City + ", " + l.Country
+} // func (l *GeoLocation) String() string
+
+// Permutation is the state of a walk through a pseudo-random permutation of
+// the IPv4 address space. The walk is determined by its Seed, Position and
+// Count tell us how far we have come.
+type Permutation struct {
+	Seed     int64
+	Root     uint64 // The generator of the cyclic group we walk
+	Position uint64 // The next element of the group to visit
+	Count    uint64 // The number of elements visited so far
+	Started  time.Time
+	Updated  time.Time
+}
+
+// NetYield records how many addresses the Generator has tried in a network
+// (a /16 or a /24), and how many of them turned out to be usable Hosts.
+// Blocked counts the addresses whose names were blacklisted.
+type NetYield struct {
+	Prefix   uint32 // The network address as an integer
+	Bits     uint8  // The length of the prefix
+	Attempts int64
+	Hits     int64
+	Blocked  int64
+	Updated  time.Time
+}
+
+// Net returns the network the NetYield is about.
+func (y *NetYield) Net() *net.IPNet {
+	var ip = net.IPv4(byte(y.Prefix>>24), byte(y.Prefix>>16), byte(y.Prefix>>8), byte(y.Prefix))
+
+	return &net.IPNet{
+		IP:   ip.To4(),
+		Mask: net.CIDRMask(int(y.Bits), 32),
+	}
+} // func (y *NetYield) Net() *net.IPNet
+
+// Yield returns the share of attempts that produced a Host.
+func (y *NetYield) Yield() float64 {
+	if y.Attempts == 0 {
+		return 0
+	}
+
+	return float64(y.Hits) / float64(y.Attempts)
+} // func (y *NetYield) Yield() float64
+
+// CacheStats describes the state of the cache the Generator uses to remember
+// which addresses it has looked at already.
+type CacheStats struct {
+	Backend  string
+	Entries  int64 // Approximate, some backends only count during maintenance
+	DiskSize int64
+	MemSize  int64
+	TTL      time.Duration // 0 means entries never expire
+	Lookups  int64
+	Hits     int64
+	LastGC   time.Time
+}
+
+// HitRate returns the share of lookups that found the address in the cache.
+func (c *CacheStats) HitRate() float64 {
+	if c.Lookups == 0 {
+		return 0
+	}
+
+	return float64(c.Hits) / float64(c.Lookups)
+} // func (c *CacheStats) HitRate() float64
+
+// ResolverStats counts the outcomes of the PTR lookups answered by one
+// name server.
+type ResolverStats struct {
+	Server string
+	Counts map[outcome.Outcome]int64
+}
+
+// Total returns the number of lookups answered by the server.
+func (r *ResolverStats) Total() int64 {
+	var total int64
+
+	for _, n := range r.Counts {
+		total += n
+	}
+
+	return total
+} // func (r *ResolverStats) Total() int64
+
+// Share returns the share of lookups that had the given Outcome.
+func (r *ResolverStats) Share(o outcome.Outcome) float64 {
+	var total = r.Total()
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(r.Counts[o]) / float64(total)
+} // func (r *ResolverStats) Share(o outcome.Outcome) float64
+
+// ASNInfo summarizes what we know about the Hosts in one autonomous system.
+type ASNInfo struct {
+	ASN     uint32
+	Org     string
+	HostCnt int64
+	SvcCnt  int64 // Number of successfully scanned services
+}
+
+// ProductCount is the number of services that gave the same response on a
+// port, i.e. that presumably run the same software.
+type ProductCount struct {
+	Port    uint16
+	Product string
+	Count   int64
+}
+
+// CountryStats is the number of Hosts and services we know in a country.
+type CountryStats struct {
+	CountryCode string
+	Country     string
+	HostCnt     int64
+	SvcCnt      int64
+}
+
+// CityStats is the number of Hosts and services we know in a city.
+type CityStats struct {
+	CountryCode string
+	City        string
+	HostCnt     int64
+	SvcCnt      int64
+}
+
+// PortStats is the number of successfully scanned services on a port in a
+// country.
+type PortStats struct {
+	CountryCode string
+	Port        uint16
+	Count       int64
+}
+
+// SourceStats is the number of Hosts in a country we found through a given
+// source.
+type SourceStats struct {
+	CountryCode string
+	Source      hsrc.HostSource
+	HostCnt     int64
+}
+
+// User is an account that may log into the web interface.
+type User struct {
+	ID        int64
+	Name      string
+	PwHash    string
+	Role      role.Role
+	Created   time.Time
+	LastLogin time.Time
+}
+
+// Session is a login session of a User in the web interface.
+// We only keep a hash of the session token, the token itself lives in the
+// User's cookie jar.
+type Session struct {
+	ID        int64
+	User      *User
+	TokenHash string
+	CSRF      string
+	Created   time.Time
+	Expires   time.Time
+}
+
+// IsExpired returns true if the Session has expired.
+func (s *Session) IsExpired() bool {
+	return time.Now().After(s.Expires)
+} // func (s *Session) IsExpired() bool
+
+// APIToken allows scripts to access the web interface on behalf of a User
+// without going through the login form.
+type APIToken struct {
+	ID        int64
+	User      *User
+	Name      string
+	TokenHash string
+	Created   time.Time
+	LastUsed  time.Time
+}
+
 type Subsystem interface {
 	IsActive() bool
 	Start()
@@ -2,11 +2,14 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 20. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-01-20 13:32:26 krylon>
+// Time-stamp: <2026-10-19 14:44:56 krylon>
 
 package model
 
-import "testing"
+import (
+	"slices"
+	"testing"
+)
 
 func TestHostZone(t *testing.T) {
 	type testCase struct {
@@ -17,7 +20,10 @@ func TestHostZone(t *testing.T) {
 	var testcases = []testCase{
 		{"www.example.com.", "example.com"},
 		{"www.", ""},
-		{"mail.wtf", "wtf"},
+		{"mail.wtf", "mail.wtf"},
+		{"www.example.co.uk.", "example.co.uk"},
+		{"mx1.mail.example.com", "mail.example.com"},
+		{"co.uk", ""},
 	}
 
 	for _, c := range testcases {
@@ -31,3 +37,33 @@ func TestHostZone(t *testing.T) {
 		}
 	}
 } // func TestHostZone(t *testing.T)
+
+func TestHostZones(t *testing.T) {
+	var (
+		h = Host{
+			Name: "gw.example.net.",
+			Names: []*HostName{
+				{Name: "gw.example.net.", Confirmed: false},
+				{Name: "a.b.example.co.uk.", Confirmed: true},
+				{Name: "c.example.co.uk.", Confirmed: true},
+			},
+		}
+		expected = []string{"b.example.co.uk", "example.co.uk"}
+	)
+
+	if zones := h.Zones(); !slices.Equal(zones, expected) {
+		t.Errorf("Unexpected result from Host.Zones: %v (expected %v)",
+			zones,
+			expected)
+	}
+
+	h.Names[1].Confirmed = false
+	h.Names[2].Confirmed = false
+	expected = []string{"example.net"}
+
+	if zones := h.Zones(); !slices.Equal(zones, expected) {
+		t.Errorf("Unexpected result from Host.Zones without confirmed names: %v (expected %v)",
+			zones,
+			expected)
+	}
+} // func TestHostZones(t *testing.T)
@@ -0,0 +1,54 @@
+// /home/krylon/go/src/github.com/blicero/guangng/model/outcome/outcome.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:42:16 krylon>
+
+// Package outcome defines what can come of looking up the name of an
+// address.
+package outcome
+
+//go:generate stringer -type=Outcome
+
+// Outcome classifies the result of a PTR lookup.
+type Outcome uint8
+
+const (
+	_ = iota
+	// Success means we got a name that is not blacklisted.
+	Success Outcome = iota
+	// Empty means the server answered, but there was no PTR record.
+	Empty
+	// Blacklisted means all the names we got are blacklisted.
+	Blacklisted
+	// NXDomain means the address has no reverse zone entry.
+	NXDomain
+	// ServFail means the server could not answer the query right now.
+	ServFail
+	// Refused means the server did not want to answer the query.
+	Refused
+	// Timeout means we got no answer in time.
+	Timeout
+	// Error is anything else that went wrong.
+	Error
+)
+
+// AllOutcomes returns a slice of all valid Outcome values.
+func AllOutcomes() []Outcome {
+	return []Outcome{
+		Success,
+		Empty,
+		Blacklisted,
+		NXDomain,
+		ServFail,
+		Refused,
+		Timeout,
+		Error,
+	}
+} // func AllOutcomes() []Outcome
+
+// Transient returns true if the Outcome says more about the server than
+// about the address, so it is worth trying again.
+func (o Outcome) Transient() bool {
+	return o == ServFail || o == Timeout
+} // func (o Outcome) Transient() bool
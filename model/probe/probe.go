@@ -0,0 +1,125 @@
+// /home/krylon/go/src/github.com/blicero/guangng/model/probe/probe.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:37:46 krylon>
+
+// Package probe defines the ways the Scanner can talk to a service.
+package probe
+
+import "strings"
+
+//go:generate stringer -type=Probe
+
+// Probe identifies the scan the Scanner runs against a port.
+type Probe uint8
+
+const (
+	// Auto means the Scanner picks a Probe based on the port number.
+	Auto Probe = iota
+	// Plain reads the banner the server sends after connecting.
+	Plain
+	// Telnet talks to telnet servers.
+	Telnet
+	// DNS asks a name server for its version.
+	DNS
+	// Finger queries a finger daemon.
+	Finger
+	// HTTP fetches the front page of a web server.
+	HTTP
+	// SNMP queries an SNMP agent for its system description.
+	SNMP
+	// XMPPClient opens an XMPP stream the way a client does. XMPP servers
+	// say nothing until the client has opened the stream.
+	XMPPClient
+	// XMPPServer opens an XMPP stream the way a peer server does.
+	XMPPServer
+	// SIP sends an OPTIONS request to a SIP server via TCP.
+	SIP
+	// LDAP reads the root DSE of an LDAP server.
+	LDAP
+)
+
+// AllProbes returns a slice of all valid Probe values.
+func AllProbes() []Probe {
+	return []Probe{
+		Auto,
+		Plain,
+		Telnet,
+		DNS,
+		Finger,
+		HTTP,
+		SNMP,
+		XMPPClient,
+		XMPPServer,
+		SIP,
+		LDAP,
+	}
+} // func AllProbes() []Probe
+
+// services maps the service names used in SRV records to the Probe that
+// fits them. Services we have no specific Probe for, but which send a
+// banner, use Plain.
+var services = map[string]Probe{
+	"domain":      DNS,
+	"dns":         DNS,
+	"finger":      Finger,
+	"ftp":         Plain,
+	"http":        HTTP,
+	"imap":        Plain,
+	"jabber":      XMPPServer,
+	"ldap":        LDAP,
+	"nntp":        Plain,
+	"pop3":        Plain,
+	"sip":         SIP,
+	"smtp":        Plain,
+	"snmp":        SNMP,
+	"ssh":         Plain,
+	"submission":  Plain,
+	"telnet":      Telnet,
+	"www":         HTTP,
+	"xmpp-client": XMPPClient,
+	"xmpp-server": XMPPServer,
+}
+
+// ForService returns the Probe for the service called name, as found in
+// the owner of an SRV record, with or without the leading underscore.
+// For services we do not know, it returns Auto.
+func ForService(name string) Probe {
+	return services[strings.ToLower(strings.TrimPrefix(name, "_"))]
+} // func ForService(name string) Probe
+
+// ForPort returns the Probe for a port if nobody told us which service to
+// expect there.
+func ForPort(port uint16) Probe {
+	switch port {
+	case 21, 22, 25, 110, 143, 2525:
+		// simple plaintext scan
+		return Plain
+	case 23, 3270, 9023:
+		return Telnet
+	case 53, 5353:
+		return DNS
+	case 79:
+		return Finger
+	case 80, 443, 8000, 8080:
+		return HTTP
+	case 161:
+		return SNMP
+	case 389:
+		return LDAP
+	case 5060:
+		return SIP
+	case 5222:
+		return XMPPClient
+	case 5269:
+		return XMPPServer
+	default:
+		return Plain
+	}
+} // func ForPort(port uint16) Probe
+
+// UDP returns true if the Probe talks to its service via UDP.
+func (p Probe) UDP() bool {
+	return p == DNS || p == SNMP
+} // func (p Probe) UDP() bool
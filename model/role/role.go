@@ -0,0 +1,33 @@
+// /home/krylon/go/src/github.com/blicero/guangng/model/role/role.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 10:12:41 krylon>
+
+// Package role defines the roles a User of the web interface can have.
+package role
+
+//go:generate stringer -type=Role
+
+// Role determines what a User is allowed to do.
+type Role uint8
+
+const (
+	_             = iota
+	ReadOnly Role = iota
+	Operator
+)
+
+// AllRoles returns a slice of all valid Role values.
+func AllRoles() []Role {
+	return []Role{
+		ReadOnly,
+		Operator,
+	}
+} // func AllRoles() []Role
+
+// CanOperate returns true if the Role is allowed to change the state of the
+// application, e.g. start or stop workers.
+func (r Role) CanOperate() bool {
+	return r == Operator
+} // func (r Role) CanOperate() bool
@@ -0,0 +1,110 @@
+// /home/krylon/go/src/github.com/blicero/guangng/model/weakness/weakness.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:02:25 krylon>
+
+// Package weakness defines the flaws we look for in the e-mail security
+// policies a zone publishes.
+package weakness
+
+//go:generate stringer -type=Weakness
+
+// Weakness is a flaw in the e-mail security policy of a zone.
+type Weakness uint8
+
+const (
+	_ = iota
+	// NoSPF means the zone receives mail, but has no SPF record.
+	NoSPF Weakness = iota
+	// InvalidSPF means the SPF record cannot be parsed, or there are
+	// several of them.
+	InvalidSPF
+	// SPFPassAll means the SPF policy allows anyone to send mail, either
+	// by itself or through one of its includes.
+	SPFPassAll
+	// SPFNeutralAll means the SPF policy ends in "?all".
+	SPFNeutralAll
+	// SPFNoAll means the SPF policy says nothing about hosts it does not
+	// list.
+	SPFNoAll
+	// SPFTooManyLookups means evaluating the SPF policy takes more than
+	// the 10 DNS lookups RFC 7208 allows.
+	SPFTooManyLookups
+	// NoDMARC means the zone has mail, but no DMARC record.
+	NoDMARC
+	// InvalidDMARC means the DMARC record cannot be parsed, or there
+	// are several of them.
+	InvalidDMARC
+	// DMARCNone means the DMARC policy is p=none.
+	DMARCNone
+	// DMARCPartial means the DMARC policy applies to less than 100% of
+	// the mail.
+	DMARCPartial
+	// NoMTASTS means the zone receives mail, but does not announce an
+	// MTA-STS policy.
+	NoMTASTS
+	// NoTLSRPT means the zone receives mail, but asks for no TLS reports.
+	NoTLSRPT
+)
+
+// AllWeaknesses returns a slice of all valid Weakness values.
+func AllWeaknesses() []Weakness {
+	return []Weakness{
+		NoSPF,
+		InvalidSPF,
+		SPFPassAll,
+		SPFNeutralAll,
+		SPFNoAll,
+		SPFTooManyLookups,
+		NoDMARC,
+		InvalidDMARC,
+		DMARCNone,
+		DMARCPartial,
+		NoMTASTS,
+		NoTLSRPT,
+	}
+} // func AllWeaknesses() []Weakness
+
+// Description returns a short explanation of the Weakness for humans.
+func (w Weakness) Description() string {
+	switch w {
+	case NoSPF:
+		return "No SPF record"
+	case InvalidSPF:
+		return "SPF record is invalid"
+	case SPFPassAll:
+		return "SPF allows anyone to send mail (+all)"
+	case SPFNeutralAll:
+		return "SPF is neutral about unlisted senders (?all)"
+	case SPFNoAll:
+		return "SPF has no all mechanism"
+	case SPFTooManyLookups:
+		return "SPF needs more than 10 DNS lookups"
+	case NoDMARC:
+		return "No DMARC record"
+	case InvalidDMARC:
+		return "DMARC record is invalid"
+	case DMARCNone:
+		return "DMARC policy is none"
+	case DMARCPartial:
+		return "DMARC policy applies to part of the mail only"
+	case NoMTASTS:
+		return "No MTA-STS policy"
+	case NoTLSRPT:
+		return "No TLS reporting"
+	default:
+		return w.String()
+	}
+} // func (w Weakness) Description() string
+
+// Critical returns true if the Weakness lets others send mail in the
+// name of the zone.
+func (w Weakness) Critical() bool {
+	switch w {
+	case NoSPF, SPFPassAll, NoDMARC, DMARCNone:
+		return true
+	default:
+		return false
+	}
+} // func (w Weakness) Critical() bool
@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 16. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-02-04 14:24:49 krylon>
+// Time-stamp: <2026-10-19 15:40:58 krylon>
 
 package nexus
 
@@ -13,7 +13,9 @@ import (
 
 	"github.com/blicero/guangng/common"
 	"github.com/blicero/guangng/generator"
+	"github.com/blicero/guangng/geo"
 	"github.com/blicero/guangng/logdomain"
+	"github.com/blicero/guangng/model"
 	"github.com/blicero/guangng/model/subsystem"
 	"github.com/blicero/guangng/scanner"
 	"github.com/blicero/guangng/xfr"
@@ -26,10 +28,27 @@ type Nexus struct {
 	gen    *generator.Generator
 	xfr    *xfr.XFR
 	scn    *scanner.Scanner
+	geo    *geo.Geo
 }
 
-// New returns a new Nexus.
-func New(gaCnt, gnCnt, xcnt, scnt int) (*Nexus, error) {
+// Config holds the settings of the Nexus and the subsystems it runs.
+type Config struct {
+	// Generator holds the settings of the Generator.
+	Generator generator.Config
+	// XFRWorkers is the number of AXFR workers.
+	XFRWorkers int
+	// NSECWalk enables walking the NSEC chains of zones that refuse AXFR.
+	NSECWalk bool
+	// ScanWorkers is the number of scan workers.
+	ScanWorkers int
+	// GeoWorkers is the number of geolocation workers.
+	GeoWorkers int
+	// GeoLang is the language for country and city names.
+	GeoLang string
+}
+
+// New returns a new Nexus with the settings in cfg.
+func New(cfg Config) (*Nexus, error) {
 	var (
 		err error
 		nx  = new(Nexus)
@@ -37,22 +56,26 @@ func New(gaCnt, gnCnt, xcnt, scnt int) (*Nexus, error) {
 
 	if nx.log, err = common.GetLogger(logdomain.Nexus); err != nil {
 		return nil, err
-	} else if nx.gen, err = generator.New(gaCnt, gnCnt); err != nil {
+	} else if nx.gen, err = generator.New(cfg.Generator); err != nil {
 		nx.log.Printf("[CRITICAL] Failed to create Generator: %s\n",
 			err.Error())
 		return nil, err
-	} else if nx.xfr, err = xfr.New(xcnt); err != nil {
+	} else if nx.xfr, err = xfr.New(cfg.XFRWorkers, cfg.NSECWalk); err != nil {
 		nx.log.Printf("[CRITICAL] Failed to create XFR Engine: %s\n",
 			err.Error())
 		return nil, err
-	} else if nx.scn, err = scanner.New(scnt); err != nil {
+	} else if nx.scn, err = scanner.New(cfg.ScanWorkers); err != nil {
 		nx.log.Printf("[CRITICAL] Failed to create Scanner: %s\n",
 			err.Error())
 		return nil, err
+	} else if nx.geo, err = geo.New(cfg.GeoWorkers, cfg.GeoLang); err != nil {
+		nx.log.Printf("[CRITICAL] Failed to create Geo: %s\n",
+			err.Error())
+		return nil, err
 	}
 
 	return nx, nil
-} // func New(gaCnt, gnCnt int) (*Nexus, error)
+} // func New(cfg Config) (*Nexus, error)
 
 // IsActive returns the status of the Nexus' active flag.
 func (nx *Nexus) IsActive() bool {
@@ -66,6 +89,7 @@ func (nx *Nexus) Start() {
 	nx.gen.Start()
 	nx.xfr.Start()
 	nx.scn.Start()
+	nx.geo.Start()
 } // func (nx *Nexus) Start()
 
 // Stop all running subsystems.
@@ -75,6 +99,7 @@ func (nx *Nexus) Stop() {
 	nx.gen.Stop()
 	nx.xfr.Stop()
 	nx.scn.Stop()
+	nx.geo.Stop()
 } // func (nx *Nexus) Stop()
 
 // StartOne starts an additional worker in one subsystem.
@@ -94,6 +119,8 @@ func (nx *Nexus) StartOne(s subsystem.ID) {
 		nx.xfr.StartOne()
 	case subsystem.Scanner:
 		nx.scn.StartOne()
+	case subsystem.Geo:
+		nx.geo.StartOne()
 	default:
 		nx.log.Printf("[ERROR] Don't how to start a %s worker\n",
 			s)
@@ -117,12 +144,20 @@ func (nx *Nexus) StopOne(s subsystem.ID) {
 		nx.xfr.StopOne()
 	case subsystem.Scanner:
 		nx.scn.StopOne()
+	case subsystem.Geo:
+		nx.geo.StopOne()
 	default:
 		nx.log.Printf("[ERROR] Don't how to stop a %s worker\n",
 			s)
 	}
 } // func (nx *Nexus) StopOne(s subsystem.ID)
 
+// GeoEnabled returns true if the GeoIP databases are available, i.e. if
+// the Geo subsystem can be started at all.
+func (nx *Nexus) GeoEnabled() bool {
+	return nx.geo.IsEnabled()
+} // func (nx *Nexus) GeoEnabled() bool
+
 // GetActiveFlag returns the active flag of the specified subsystem.
 func (nx *Nexus) GetActiveFlag(sub subsystem.ID) bool {
 	switch sub {
@@ -132,6 +167,8 @@ func (nx *Nexus) GetActiveFlag(sub subsystem.ID) bool {
 		return nx.xfr.IsActive()
 	case subsystem.Scanner:
 		return nx.scn.IsActive()
+	case subsystem.Geo:
+		return nx.geo.IsActive()
 	default:
 		var err = fmt.Errorf("invalid subsystem ID: %s (%d)",
 			sub,
@@ -154,6 +191,8 @@ func (nx *Nexus) GetWorkerCount(sub subsystem.ID) int {
 		return nx.xfr.WorkerCount()
 	case subsystem.Scanner:
 		return nx.scn.WorkerCnt()
+	case subsystem.Geo:
+		return nx.geo.WorkerCount()
 	default:
 		nx.log.Printf("[ERROR] Invalid Subsystem ID %s (%d)\n",
 			sub,
@@ -161,3 +200,37 @@ func (nx *Nexus) GetWorkerCount(sub subsystem.ID) int {
 		return -1
 	}
 } // func (nx *Nexus) GetWorkerCount(sub subsystem.ID) int
+
+// CacheStats returns the state of the Generator's address cache. If the
+// Generator does not use one, the second return value is false.
+func (nx *Nexus) CacheStats() (model.CacheStats, bool) {
+	return nx.gen.CacheStats()
+} // func (nx *Nexus) CacheStats() (model.CacheStats, bool)
+
+// CacheReset clears the Generator's address cache.
+func (nx *Nexus) CacheReset() error {
+	return nx.gen.CacheReset()
+} // func (nx *Nexus) CacheReset() error
+
+// ResolverStats returns the outcomes of the Generator's PTR lookups, per
+// name server.
+func (nx *Nexus) ResolverStats() []model.ResolverStats {
+	return nx.gen.ResolverStats()
+} // func (nx *Nexus) ResolverStats() []model.ResolverStats
+
+// GetQueueDepths returns the number of items waiting in each of a
+// subsystem's queues, keyed by the name of the queue.
+func (nx *Nexus) GetQueueDepths(sub subsystem.ID) map[string]int {
+	switch sub {
+	case subsystem.Generator:
+		return nx.gen.QueueDepths()
+	case subsystem.XFR:
+		return nx.xfr.QueueDepths()
+	case subsystem.Scanner:
+		return nx.scn.QueueDepths()
+	case subsystem.Geo:
+		return nx.geo.QueueDepths()
+	default:
+		return nil
+	}
+} // func (nx *Nexus) GetQueueDepths(sub subsystem.ID) map[string]int
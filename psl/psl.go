@@ -0,0 +1,328 @@
+// /home/krylon/go/src/github.com/blicero/guangng/psl/psl.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:44:56 krylon>
+
+// Package psl implements the Public Suffix List, which tells us under which
+// names the public can register domains. We use it to figure out which
+// zones a DNS name belongs to.
+//
+// A copy of the list is compiled into the program. Update can fetch a
+// newer one, which Load then uses instead.
+package psl
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/idna"
+)
+
+// DefaultURL is where the maintainers of the Public Suffix List ask us to
+// download it from.
+const DefaultURL = "https://publicsuffix.org/list/public_suffix_list.dat"
+
+// minRules is the number of rules we expect a list to have at the very
+// least. If a download yields fewer, we probably got an error page.
+const minRules = 1000
+
+const fetchTimeout = time.Minute
+
+//go:embed public_suffix_list.dat
+var builtin []byte
+
+type ruleKind uint8
+
+const (
+	ruleNormal ruleKind = 1 << iota
+	ruleWildcard
+	ruleException
+)
+
+// List is a parsed Public Suffix List. Wildcard and exception rules are
+// stored under the name they apply to, i.e. "*.ck" under "ck" and
+// "!www.ck" under "www.ck".
+type List struct {
+	rules map[string]ruleKind
+}
+
+// Parse reads a Public Suffix List in the format publicsuffix.org
+// distributes it in. Internationalized rules are converted to their ASCII
+// form, because that is what we find in the DNS.
+func Parse(r io.Reader) (*List, error) {
+	var (
+		err  error
+		scan = bufio.NewScanner(r)
+		l    = &List{rules: make(map[string]ruleKind)}
+	)
+
+	for scan.Scan() {
+		var (
+			line = strings.TrimSpace(scan.Text())
+			kind = ruleNormal
+		)
+
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		} else if idx := strings.IndexAny(line, " \t"); idx != -1 {
+			line = line[:idx]
+		}
+
+		if strings.HasPrefix(line, "!") {
+			kind = ruleException
+			line = line[1:]
+		} else if strings.HasPrefix(line, "*.") {
+			kind = ruleWildcard
+			line = line[2:]
+		}
+
+		if line, err = idna.Punycode.ToASCII(line); err != nil {
+			return nil, fmt.Errorf("invalid rule %q: %w", scan.Text(), err)
+		}
+
+		line = strings.ToLower(line)
+		l.rules[line] |= kind
+	}
+
+	if err = scan.Err(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+} // func Parse(r io.Reader) (*List, error)
+
+// Len returns the number of names the List has rules for.
+func (l *List) Len() int {
+	return len(l.rules)
+} // func (l *List) Len() int
+
+// normalize returns name in lower case without the trailing dot, split
+// into its labels. It returns nil for the root.
+func normalize(name string) []string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+
+	if name == "" {
+		return nil
+	}
+
+	return strings.Split(name, ".")
+} // func normalize(name string) []string
+
+// suffixLen returns the number of labels the public suffix of the name
+// made up of labels has.
+func (l *List) suffixLen(labels []string) int {
+	var cnt = len(labels)
+
+	// The first rule we find is the longest one, because we start with
+	// the whole name.
+	for i := range labels {
+		var kind = l.rules[strings.Join(labels[i:], ".")]
+
+		switch {
+		case kind&ruleException != 0:
+			return cnt - i - 1
+		case kind&ruleWildcard != 0 && i > 0:
+			return cnt - i + 1
+		case kind&ruleNormal != 0:
+			return cnt - i
+		}
+	}
+
+	// If no rule matches, the last label is the public suffix.
+	return 1
+} // func (l *List) suffixLen(labels []string) int
+
+// PublicSuffix returns the public suffix of name, e.g. "co.uk" for
+// "www.example.co.uk".
+func (l *List) PublicSuffix(name string) string {
+	var labels = normalize(name)
+
+	if labels == nil {
+		return ""
+	}
+
+	return strings.Join(labels[len(labels)-l.suffixLen(labels):], ".")
+} // func (l *List) PublicSuffix(name string) string
+
+// IsPublicSuffix returns true if name is a public suffix, i.e. a name under
+// which the public can register domains.
+func (l *List) IsPublicSuffix(name string) bool {
+	var labels = normalize(name)
+
+	return labels == nil || l.suffixLen(labels) >= len(labels)
+} // func (l *List) IsPublicSuffix(name string) bool
+
+// Registrable returns the registrable domain of name, i.e. its public
+// suffix plus one label. If name is a public suffix, it returns an empty
+// string.
+func (l *List) Registrable(name string) string {
+	var labels = normalize(name)
+
+	if labels == nil {
+		return ""
+	}
+
+	var n = l.suffixLen(labels) + 1
+
+	if n > len(labels) {
+		return ""
+	}
+
+	return strings.Join(labels[len(labels)-n:], ".")
+} // func (l *List) Registrable(name string) string
+
+// Zones returns the zones name may belong to, starting with the innermost
+// one: every parent of name down to and including its registrable domain.
+// If name is a registrable domain itself, that is the only zone. Public
+// suffixes are never returned.
+func (l *List) Zones(name string) []string {
+	var labels = normalize(name)
+
+	if labels == nil {
+		return nil
+	}
+
+	var (
+		n     = l.suffixLen(labels) + 1
+		zones []string
+	)
+
+	if n > len(labels) {
+		return nil
+	} else if n == len(labels) {
+		return []string{strings.Join(labels, ".")}
+	}
+
+	zones = make([]string, 0, len(labels)-n)
+	for i := 1; i <= len(labels)-n; i++ {
+		zones = append(zones, strings.Join(labels[i:], "."))
+	}
+
+	return zones
+} // func (l *List) Zones(name string) []string
+
+var (
+	builtinOnce sync.Once
+	current     atomic.Pointer[List]
+)
+
+// Default returns the List the package level functions use. Unless Load
+// or Set were called, that is the one compiled into the program.
+func Default() *List {
+	builtinOnce.Do(func() {
+		var l, err = Parse(bytes.NewReader(builtin))
+
+		if err != nil {
+			panic(fmt.Sprintf("Cannot parse built-in Public Suffix List: %s", err.Error()))
+		}
+
+		current.CompareAndSwap(nil, l)
+	})
+
+	return current.Load()
+} // func Default() *List
+
+// Set makes l the List used by the package level functions.
+func Set(l *List) {
+	builtinOnce.Do(func() {})
+	current.Store(l)
+} // func Set(l *List)
+
+// Load reads the List from path and uses it from now on. If path does not
+// exist, the error wraps os.ErrNotExist, and we keep using the built-in
+// List.
+func Load(path string) error {
+	var (
+		err error
+		fh  *os.File
+		l   *List
+	)
+
+	if fh, err = os.Open(path); err != nil {
+		return err
+	}
+
+	defer fh.Close() // nolint: errcheck
+
+	if l, err = Parse(fh); err != nil {
+		return fmt.Errorf("cannot parse %s: %w", path, err)
+	} else if l.Len() < minRules {
+		return fmt.Errorf("%s has only %d rules", path, l.Len())
+	}
+
+	Set(l)
+	return nil
+} // func Load(path string) error
+
+// Update downloads the List from url and saves it to path, replacing the
+// previous copy only if the download looks like a proper List. It returns
+// the number of rules in the new List.
+func Update(url, path string) (int, error) {
+	var (
+		err    error
+		res    *http.Response
+		body   []byte
+		l      *List
+		client = &http.Client{Timeout: fetchTimeout}
+		tmp    = path + ".tmp"
+	)
+
+	if res, err = client.Get(url); err != nil {
+		return 0, err
+	}
+
+	defer res.Body.Close() // nolint: errcheck
+
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("cannot fetch %s: %s", url, res.Status)
+	} else if body, err = io.ReadAll(res.Body); err != nil {
+		return 0, fmt.Errorf("cannot read %s: %w", url, err)
+	} else if l, err = Parse(bytes.NewReader(body)); err != nil {
+		return 0, fmt.Errorf("cannot parse %s: %w", url, err)
+	} else if l.Len() < minRules {
+		return 0, fmt.Errorf("%s has only %d rules, that does not look like a Public Suffix List",
+			url,
+			l.Len())
+	} else if err = os.WriteFile(tmp, body, 0600); err != nil {
+		return 0, err
+	} else if err = os.Rename(tmp, path); err != nil {
+		return 0, errors.Join(err, os.Remove(tmp))
+	}
+
+	Set(l)
+	return l.Len(), nil
+} // func Update(url, path string) (int, error)
+
+// PublicSuffix returns the public suffix of name according to the default
+// List.
+func PublicSuffix(name string) string {
+	return Default().PublicSuffix(name)
+} // func PublicSuffix(name string) string
+
+// IsPublicSuffix returns true if name is a public suffix according to the
+// default List.
+func IsPublicSuffix(name string) bool {
+	return Default().IsPublicSuffix(name)
+} // func IsPublicSuffix(name string) bool
+
+// Registrable returns the registrable domain of name according to the
+// default List.
+func Registrable(name string) string {
+	return Default().Registrable(name)
+} // func Registrable(name string) string
+
+// Zones returns the zones name may belong to according to the default List.
+func Zones(name string) []string {
+	return Default().Zones(name)
+} // func Zones(name string) []string
@@ -0,0 +1,131 @@
+// /home/krylon/go/src/github.com/blicero/guangng/psl/psl_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:44:56 krylon>
+
+package psl
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Taken from the test cases publicsuffix.org provides, checkPublicSuffix.
+func TestRegistrable(t *testing.T) {
+	var l = Default()
+
+	for _, c := range []struct {
+		name, expected string
+	}{
+		{"com", ""},
+		{"example.com", "example.com"},
+		{"www.example.com", "example.com"},
+		{"WWW.Example.COM.", "example.com"},
+		{"uk.com", ""},
+		{"example.uk.com", "example.uk.com"},
+		{"b.example.uk.com", "example.uk.com"},
+		{"c.kobe.jp", ""},
+		{"b.c.kobe.jp", "b.c.kobe.jp"},
+		{"a.b.c.kobe.jp", "b.c.kobe.jp"},
+		{"city.kobe.jp", "city.kobe.jp"},
+		{"www.city.kobe.jp", "city.kobe.jp"},
+		{"ck", ""},
+		{"test.ck", ""},
+		{"b.test.ck", "b.test.ck"},
+		{"www.ck", "www.ck"},
+		{"www.www.ck", "www.ck"},
+		{"us", ""},
+		{"k12.ak.us", ""},
+		{"www.test.k12.ak.us", "test.k12.ak.us"},
+		{"xn--85x722f.com.cn", "xn--85x722f.com.cn"},
+		{"www.xn--85x722f.xn--55qx5d.cn", "xn--85x722f.xn--55qx5d.cn"},
+		{"shishi.xn--55qx5d.cn", "shishi.xn--55qx5d.cn"},
+		{"xn--55qx5d.cn", ""},
+		{"example.local", "example.local"},
+		{"", ""},
+	} {
+		if r := l.Registrable(c.name); r != c.expected {
+			t.Errorf("Registrable(%q) = %q, expected %q",
+				c.name,
+				r,
+				c.expected)
+		}
+	}
+} // func TestRegistrable(t *testing.T)
+
+func TestZones(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		expected []string
+	}{
+		{"www.example.com.", []string{"example.com"}},
+		{"host.dept.example.co.uk.", []string{"dept.example.co.uk", "example.co.uk"}},
+		{"example.co.uk", []string{"example.co.uk"}},
+		{"mail.wtf", []string{"mail.wtf"}},
+		{"co.uk", nil},
+		{"www.", nil},
+		{".", nil},
+	} {
+		if zones := Zones(c.name); !slices.Equal(zones, c.expected) {
+			t.Errorf("Zones(%q) = %v, expected %v",
+				c.name,
+				zones,
+				c.expected)
+		}
+	}
+} // func TestZones(t *testing.T)
+
+func TestUpdate(t *testing.T) {
+	var (
+		err  error
+		cnt  int
+		prev = Default()
+		path = filepath.Join(t.TempDir(), "psl.dat")
+		list strings.Builder
+	)
+
+	defer Set(prev)
+
+	list.WriteString("// A made-up list\ntest\n")
+	for i := range minRules {
+		fmt.Fprintf(&list, "sub%d.test\n", i)
+	}
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.Write([]byte("<html>Oops</html>")) // nolint: errcheck
+			return
+		}
+		w.Write([]byte(list.String())) // nolint: errcheck
+	}))
+	defer srv.Close()
+
+	if _, err = Update(srv.URL+"/broken", path); err == nil {
+		t.Error("Update accepted a broken list")
+	} else if _, err = os.Stat(path); err == nil {
+		t.Error("Update saved a broken list")
+	}
+
+	if cnt, err = Update(srv.URL, path); err != nil {
+		t.Fatalf("Update failed: %s", err.Error())
+	} else if cnt != minRules+1 {
+		t.Errorf("Expected %d rules, got %d", minRules+1, cnt)
+	} else if !IsPublicSuffix("sub1.test") || IsPublicSuffix("com.sub1.test") {
+		t.Error("Updated list is not in use")
+	}
+
+	Set(prev)
+
+	if err = Load(path); err != nil {
+		t.Fatalf("Cannot load %s: %s", path, err.Error())
+	} else if r := Registrable("www.example.sub7.test"); r != "example.sub7.test" {
+		t.Errorf("Unexpected registrable domain from loaded list: %q", r)
+	}
+} // func TestUpdate(t *testing.T)
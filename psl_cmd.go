@@ -0,0 +1,47 @@
+// /home/krylon/go/src/github.com/blicero/guangng/psl_cmd.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:44:56 krylon>
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/psl"
+)
+
+// runPSL implements the "psl" subcommand, which downloads the current
+// Public Suffix List to common.PSLPath. The Generator picks it up the next
+// time it starts. It returns the exit code for the process.
+func runPSL(args []string) int {
+	var (
+		err error
+		cnt int
+		url = psl.DefaultURL
+	)
+
+	if len(args) > 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s psl [URL]\n", os.Args[0])
+		return 1
+	} else if len(args) == 1 {
+		url = args[0]
+	}
+
+	if err = common.InitApp(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		return 1
+	} else if cnt, err = psl.Update(url, common.PSLPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to update Public Suffix List: %s\n",
+			err.Error())
+		return 1
+	}
+
+	fmt.Printf("Saved Public Suffix List with %d rules to %s\n",
+		cnt,
+		common.PSLPath)
+	return 0
+} // func runPSL(args []string) int
@@ -0,0 +1,318 @@
+// /home/krylon/go/src/github.com/blicero/guangng/scanner/ldap.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:28:27 krylon>
+
+package scanner
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/model"
+)
+
+// LDAP servers wait for a request, too. Most of them let anyone read the
+// root DSE, an entry with an empty name that describes the server itself,
+// without binding first. LDAP messages are encoded in ASN.1 BER, and we
+// only need a tiny bit of that, so we do it by hand.
+const (
+	berInteger    = 0x02
+	berOctets     = 0x04
+	berBoolean    = 0x01
+	berEnum       = 0x0a
+	berSequence   = 0x30
+	berSet        = 0x31
+	ldapSearchReq = 0x63 // [APPLICATION 3]
+	ldapEntry     = 0x64 // [APPLICATION 4]
+	ldapDone      = 0x65 // [APPLICATION 5]
+	ldapPresent   = 0x87 // [7], the "present" filter
+	ldapMaxMsg    = 1 << 20
+)
+
+// ldapAttrs are the attributes of the root DSE we ask for.
+var ldapAttrs = []string{
+	"vendorName",
+	"vendorVersion",
+	"supportedLDAPVersion",
+	"dnsHostName",
+	"namingContexts",
+}
+
+var errBER = errors.New("invalid BER encoding")
+
+// berTLV encodes content as a BER element with the given tag.
+func berTLV(tag byte, content ...[]byte) []byte {
+	var (
+		body = slices.Concat(content...)
+		b    = []byte{tag}
+		n    = len(body)
+	)
+
+	if n < 0x80 {
+		b = append(b, byte(n))
+	} else {
+		var l []byte
+
+		for ; n > 0; n >>= 8 {
+			l = append([]byte{byte(n)}, l...)
+		}
+
+		b = append(b, 0x80|byte(len(l)))
+		b = append(b, l...)
+	}
+
+	return append(b, body...)
+} // func berTLV(tag byte, content ...[]byte) []byte
+
+// berLength decodes the length of a BER element. next returns the next
+// byte of the encoding.
+func berLength(next func() (byte, error)) (int, error) {
+	var (
+		err error
+		b   byte
+		n   int
+	)
+
+	if b, err = next(); err != nil {
+		return 0, err
+	} else if b < 0x80 {
+		return int(b), nil
+	} else if b == 0x80 || b > 0x83 {
+		// We do not do indefinite lengths, and anything that does not
+		// fit in three bytes is too big for us anyway.
+		return 0, errBER
+	}
+
+	for i := byte(0); i < b&0x7f; i++ {
+		var c byte
+
+		if c, err = next(); err != nil {
+			return 0, err
+		}
+
+		n = n<<8 | int(c)
+	}
+
+	return n, nil
+} // func berLength(next func() (byte, error)) (int, error)
+
+// berParse splits the first BER element off b. It returns the tag, the
+// content of the element, and whatever follows it.
+func berParse(b []byte) (byte, []byte, []byte, error) {
+	var (
+		err error
+		tag byte
+		n   int
+	)
+
+	if len(b) < 2 {
+		return 0, nil, nil, errBER
+	}
+
+	tag, b = b[0], b[1:]
+
+	if n, err = berLength(func() (byte, error) {
+		if len(b) == 0 {
+			return 0, errBER
+		}
+		var c = b[0]
+		b = b[1:]
+		return c, nil
+	}); err != nil {
+		return 0, nil, nil, err
+	} else if n > len(b) {
+		return 0, nil, nil, errBER
+	}
+
+	return tag, b[:n], b[n:], nil
+} // func berParse(b []byte) (byte, []byte, []byte, error)
+
+// berRead reads one BER element from r.
+func berRead(r *bufio.Reader) (byte, []byte, error) {
+	var (
+		err  error
+		tag  byte
+		n    int
+		body []byte
+	)
+
+	if tag, err = r.ReadByte(); err != nil {
+		return 0, nil, err
+	} else if n, err = berLength(r.ReadByte); err != nil {
+		return 0, nil, err
+	} else if n > ldapMaxMsg {
+		return 0, nil, fmt.Errorf("LDAP message is too big (%d bytes)", n)
+	}
+
+	body = make([]byte, n)
+	if _, err = io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+
+	return tag, body, nil
+} // func berRead(r *bufio.Reader) (byte, []byte, error)
+
+// ldapRootDSEQuery returns an LDAP search request for the root DSE.
+func ldapRootDSEQuery() []byte {
+	var attrs [][]byte
+
+	for _, a := range ldapAttrs {
+		attrs = append(attrs, berTLV(berOctets, []byte(a)))
+	}
+
+	return berTLV(berSequence,
+		berTLV(berInteger, []byte{1}), // message ID
+		berTLV(ldapSearchReq,
+			berTLV(berOctets),          // base object: the root DSE
+			berTLV(berEnum, []byte{0}), // scope: base object only
+			berTLV(berEnum, []byte{0}), // never dereference aliases
+			berTLV(berInteger, []byte{0}),
+			berTLV(berInteger, []byte{0}),
+			berTLV(berBoolean, []byte{0}), // we want values, too
+			berTLV(ldapPresent, []byte("objectClass")),
+			berTLV(berSequence, attrs...)))
+} // func ldapRootDSEQuery() []byte
+
+// ldapEntryAttrs returns the attributes of a search result entry as a list
+// of "name=value" pairs, with multiple values separated by commas.
+func ldapEntryAttrs(entry []byte) ([]string, error) {
+	var (
+		err    error
+		tag    byte
+		attrs  []byte
+		result []string
+	)
+
+	// Skip the name of the entry.
+	if _, _, entry, err = berParse(entry); err != nil {
+		return nil, err
+	} else if tag, attrs, _, err = berParse(entry); err != nil {
+		return nil, err
+	} else if tag != berSequence {
+		return nil, errBER
+	}
+
+	for len(attrs) > 0 {
+		var (
+			attr, name, set []byte
+			vals            []string
+		)
+
+		if _, attr, attrs, err = berParse(attrs); err != nil {
+			return nil, err
+		} else if _, name, attr, err = berParse(attr); err != nil {
+			return nil, err
+		} else if _, set, _, err = berParse(attr); err != nil {
+			return nil, err
+		}
+
+		for len(set) > 0 {
+			var val []byte
+
+			if _, val, set, err = berParse(set); err != nil {
+				return nil, err
+			}
+
+			vals = append(vals, string(val))
+		}
+
+		result = append(result, string(name)+"="+strings.Join(vals, ","))
+	}
+
+	return result, nil
+} // func ldapEntryAttrs(entry []byte) ([]string, error)
+
+// scanLDAP reads the root DSE of the LDAP server on host.
+func (scn *Scanner) scanLDAP(host *model.Host, port uint16) (*scanResult, error) {
+	scn.log.Printf("[TRACE] Scanning %s:%d using LDAP scanner.\n", host.AStr(), port)
+
+	var (
+		err    error
+		conn   net.Conn
+		reader *bufio.Reader
+		info   []string
+		srv    = net.JoinHostPort(host.AStr(), strconv.Itoa(int(port)))
+	)
+
+	if conn, err = net.DialTimeout("tcp", srv, common.ActiveTimeout); err != nil {
+		return nil, fmt.Errorf("error connecting to %s: %w", srv, err)
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	conn.SetDeadline(time.Now().Add(common.ActiveTimeout * 2)) // nolint: errcheck
+
+	if _, err = conn.Write(ldapRootDSEQuery()); err != nil {
+		return nil, fmt.Errorf("error sending LDAP search request to %s: %w", srv, err)
+	}
+
+	reader = bufio.NewReader(conn)
+
+	// The server sends the entry, if we may see it, and then tells us the
+	// search is done.
+	for {
+		var (
+			tag, op  byte
+			msg, res []byte
+		)
+
+		if tag, msg, err = berRead(reader); err != nil {
+			return nil, fmt.Errorf("error receiving LDAP message from %s: %w", srv, err)
+		} else if tag != berSequence {
+			return nil, fmt.Errorf("%s did not answer in LDAP", srv)
+		} else if _, _, msg, err = berParse(msg); err != nil { // message ID
+			return nil, fmt.Errorf("invalid LDAP message from %s: %w", srv, err)
+		} else if op, res, _, err = berParse(msg); err != nil {
+			return nil, fmt.Errorf("invalid LDAP message from %s: %w", srv, err)
+		}
+
+		switch op {
+		case ldapEntry:
+			if info, err = ldapEntryAttrs(res); err != nil {
+				return nil, fmt.Errorf("invalid LDAP search result from %s: %w", srv, err)
+			}
+		case ldapDone:
+			var code []byte
+
+			if _, code, _, err = berParse(res); err != nil {
+				return nil, fmt.Errorf("invalid LDAP search result from %s: %w", srv, err)
+			} else if len(info) == 0 && len(code) == 1 && code[0] != 0 {
+				// The server does not let us read the root DSE, but
+				// still, it is an LDAP server.
+				info = append(info, fmt.Sprintf("resultCode=%d", code[0]))
+			}
+
+			goto DONE
+		default:
+			return nil, fmt.Errorf("%s sent unexpected LDAP message 0x%02x", srv, op)
+		}
+	}
+
+DONE:
+	var res = &scanResult{
+		host: host,
+		svc: &model.Service{
+			HostID:    host.ID,
+			Port:      port,
+			Response:  strings.Join(info, " "),
+			Success:   true,
+			Timestamp: time.Now(),
+		},
+	}
+
+	scn.log.Printf("[TRACE] Got root DSE from %s: %s\n",
+		srv,
+		res.svc.Response)
+
+	return res, nil
+} // func (scn *Scanner) scanLDAP(host *model.Host, port uint16) (*scanResult, error)
@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 24. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-01-26 15:47:26 krylon>
+// Time-stamp: <2026-10-19 15:37:46 krylon>
 
 package scanner
 
@@ -20,28 +20,46 @@ import (
 	"github.com/alouca/gosnmp"
 	"github.com/blicero/guangng/common"
 	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/probe"
 	dns "github.com/tonnerre/golang-dns"
 )
 
-func (scn *Scanner) probePort(host *model.Host, port uint16) (*scanResult, error) {
-	switch port {
-	case 21, 22, 25, 110, 143, 2525:
-		// simple plaintext scan
-		return scn.scanPlain(host, port)
-	case 23, 3270, 9023:
+// probePort scans port on host using the Probe p. If p is probe.Auto, we
+// pick one based on the port number. proto is the protocol the port was
+// reported as open for, if any. We only scan UDP ports with a Probe that
+// talks UDP, otherwise we would report on a TCP port nobody asked about.
+func (scn *Scanner) probePort(host *model.Host, port uint16, proto string, p probe.Probe) (*scanResult, error) {
+	if p == probe.Auto {
+		p = probe.ForPort(port)
+	}
+
+	if proto == "udp" && !p.UDP() {
+		return nil, fmt.Errorf("cannot probe UDP port %d using Probe %s", port, p)
+	}
+
+	switch p {
+	case probe.Telnet:
 		return scn.scanTelnet(host, port)
-	case 53, 5353:
+	case probe.DNS:
 		return scn.scanDNS(host, port)
-	case 79:
+	case probe.Finger:
 		return scn.scanFinger(host, port)
-	case 80, 443, 8000, 8080:
+	case probe.HTTP:
 		return scn.scanHTTP(host, port)
-	case 161:
+	case probe.SNMP:
 		return scn.scanSNMP(host, port)
+	case probe.XMPPClient:
+		return scn.scanXMPP(host, port, xmppClientNS)
+	case probe.XMPPServer:
+		return scn.scanXMPP(host, port, xmppServerNS)
+	case probe.SIP:
+		return scn.scanSIP(host, port)
+	case probe.LDAP:
+		return scn.scanLDAP(host, port)
 	default:
 		return scn.scanPlain(host, port)
 	}
-} // func (scn *Scanner) probePort(host *model.Host, port uint16) (*scanResult, error)
+} // func (scn *Scanner) probePort(host *model.Host, port uint16, proto string, p probe.Probe) (*scanResult, error)
 
 func (scn *Scanner) scanPlain(host *model.Host, port uint16) (*scanResult, error) {
 	scn.log.Printf("[TRACE] Scanning %s:%d using plain scanner.\n", host.AStr(), port)
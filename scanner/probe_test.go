@@ -0,0 +1,212 @@
+// /home/krylon/go/src/github.com/blicero/guangng/scanner/probe_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:37:46 krylon>
+
+package scanner
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/probe"
+)
+
+// fakeServer accepts one connection on a local port, passes it to serve,
+// and returns the port.
+func fakeServer(t *testing.T, serve func(conn net.Conn)) uint16 {
+	var (
+		err error
+		l   net.Listener
+	)
+
+	if l, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+		t.Fatalf("Cannot listen on local port: %s", err.Error())
+	}
+
+	t.Cleanup(func() { l.Close() }) // nolint: errcheck
+
+	go func() {
+		var conn, err = l.Accept()
+
+		if err != nil {
+			return
+		}
+
+		defer conn.Close() // nolint: errcheck
+		serve(conn)
+	}()
+
+	return uint16(l.Addr().(*net.TCPAddr).Port)
+} // func fakeServer(t *testing.T, serve func(conn net.Conn)) uint16
+
+func TestProbes(t *testing.T) {
+	var (
+		scn  = &Scanner{log: log.New(io.Discard, "", 0)}
+		host = &model.Host{
+			Name: "straylight.test.",
+			Addr: net.ParseIP("127.0.0.1"),
+		}
+	)
+
+	for _, c := range []struct {
+		name  string
+		p     probe.Probe
+		serve func(conn net.Conn)
+		reply string
+	}{
+		{
+			name: "XMPP",
+			p:    probe.XMPPServer,
+			serve: func(conn net.Conn) {
+				var r = bufio.NewReader(conn)
+
+				// Nothing happens until the client opens its stream.
+				if s, err := r.ReadString('>'); err != nil || !strings.Contains(s, "<?xml") {
+					return
+				} else if s, err = r.ReadString('>'); err != nil || !strings.Contains(s, "'jabber:server'") {
+					return
+				}
+
+				io.WriteString(conn, "<?xml version='1.0'?>"+ // nolint: errcheck
+					"<stream:stream xmlns='jabber:server' xmlns:stream='http://etherx.jabber.org/streams' "+
+					"from='straylight.test' id='0815' version='1.0'>"+
+					"<stream:features><starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'><required/></starttls>"+
+					"<dialback xmlns='urn:xmpp:features:dialback'/></stream:features>")
+			},
+			reply: "from=straylight.test version=1.0 features=starttls,dialback",
+		},
+		{
+			name: "SIP",
+			p:    probe.SIP,
+			serve: func(conn net.Conn) {
+				var r = textproto.NewReader(bufio.NewReader(conn))
+
+				if l, err := r.ReadLine(); err != nil || !strings.HasPrefix(l, "OPTIONS sip:") {
+					return
+				} else if _, err = r.ReadMIMEHeader(); err != nil {
+					return
+				}
+
+				io.WriteString(conn, "SIP/2.0 200 OK\r\n"+ // nolint: errcheck
+					"Server: Asterisk PBX 20.5.0\r\n"+
+					"Content-Length: 0\r\n\r\n")
+			},
+			reply: "Asterisk PBX 20.5.0",
+		},
+		{
+			name: "LDAP",
+			p:    probe.LDAP,
+			serve: func(conn net.Conn) {
+				var (
+					r   = bufio.NewReader(conn)
+					id  = berTLV(berInteger, []byte{1})
+					tag byte
+					msg []byte
+					err error
+				)
+
+				if tag, msg, err = berRead(r); err != nil || tag != berSequence {
+					return
+				} else if _, _, msg, err = berParse(msg); err != nil {
+					return
+				} else if tag, _, _, err = berParse(msg); err != nil || tag != ldapSearchReq {
+					return
+				}
+
+				conn.Write(berTLV(berSequence, id, // nolint: errcheck
+					berTLV(ldapEntry,
+						berTLV(berOctets),
+						berTLV(berSequence,
+							berTLV(berSequence,
+								berTLV(berOctets, []byte("vendorName")),
+								berTLV(berSet, berTLV(berOctets, []byte("OpenLDAP")))),
+							berTLV(berSequence,
+								berTLV(berOctets, []byte("supportedLDAPVersion")),
+								berTLV(berSet,
+									berTLV(berOctets, []byte("2")),
+									berTLV(berOctets, []byte("3"))))))))
+				conn.Write(berTLV(berSequence, id, // nolint: errcheck
+					berTLV(ldapDone,
+						berTLV(berEnum, []byte{0}),
+						berTLV(berOctets),
+						berTLV(berOctets))))
+			},
+			reply: "vendorName=OpenLDAP supportedLDAPVersion=2,3",
+		},
+	} {
+		var (
+			err  error
+			res  *scanResult
+			port = fakeServer(t, c.serve)
+		)
+
+		if res, err = scn.probePort(host, port, "tcp", c.p); err != nil {
+			t.Errorf("%s probe failed: %s", c.name, err.Error())
+		} else if !res.svc.Success {
+			t.Errorf("%s probe was not successful", c.name)
+		} else if res.svc.Response != c.reply {
+			t.Errorf("%s probe got unexpected reply %q (expected %q)",
+				c.name,
+				res.svc.Response,
+				c.reply)
+		}
+	}
+} // func TestProbes(t *testing.T)
+
+// A UDP port we have no UDP Probe for must not be probed via TCP instead.
+func TestProbeUDPWithoutProbe(t *testing.T) {
+	var (
+		err       error
+		connected = make(chan struct{}, 1)
+		scn       = &Scanner{log: log.New(io.Discard, "", 0)}
+		host      = &model.Host{
+			Name: "straylight.test.",
+			Addr: net.ParseIP("127.0.0.1"),
+		}
+		port = fakeServer(t, func(conn net.Conn) {
+			connected <- struct{}{}
+		})
+	)
+
+	if _, err = scn.probePort(host, port, "udp", probe.Auto); err == nil {
+		t.Errorf("UDP port %d was probed with %s", port, probe.ForPort(port))
+	}
+
+	select {
+	case <-connected:
+		t.Error("Scanner connected via TCP to probe a UDP port")
+	case <-time.After(time.Millisecond * 100):
+	}
+} // func TestProbeUDPWithoutProbe(t *testing.T)
+
+func TestBERLength(t *testing.T) {
+	for _, n := range []int{0, 1, 127, 128, 255, 256, 70000} {
+		var (
+			err     error
+			tag     byte
+			content []byte
+			rest    []byte
+			enc     = berTLV(berOctets, make([]byte, n), []byte{42})
+		)
+
+		if tag, content, rest, err = berParse(append(enc, 23)); err != nil {
+			t.Errorf("Cannot parse element of %d bytes: %s", n+1, err.Error())
+		} else if tag != berOctets || len(content) != n+1 || content[n] != 42 {
+			t.Errorf("Element of %d bytes was parsed as tag 0x%02x, %d bytes",
+				n+1,
+				tag,
+				len(content))
+		} else if len(rest) != 1 || rest[0] != 23 {
+			t.Errorf("Unexpected rest after element of %d bytes: %v", n+1, rest)
+		}
+	}
+} // func TestBERLength(t *testing.T)
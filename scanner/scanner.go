@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 22. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-02-02 16:46:30 krylon>
+// Time-stamp: <2026-10-19 15:37:46 krylon>
 
 // Package scanner implements scanning ports. Duh.
 package scanner
@@ -11,14 +11,19 @@ import (
 	"log"
 	"math/rand"
 	"regexp"
+	"strconv"
 	"sync/atomic"
 	"time"
 
 	"github.com/blicero/guangng/common"
 	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/events"
 	"github.com/blicero/guangng/logdomain"
+	"github.com/blicero/guangng/metrics"
 	"github.com/blicero/guangng/model"
 	"github.com/blicero/guangng/model/hsrc"
+	"github.com/blicero/guangng/model/meta"
+	"github.com/blicero/guangng/model/probe"
 	"github.com/blicero/guangng/model/subsystem"
 )
 
@@ -57,6 +62,9 @@ var Ports []uint16 = []uint16{
 type scanProposal struct {
 	host  *model.Host
 	ports map[uint16]*model.Service
+	port  uint16      // If non-zero, scan this port instead of picking one.
+	proto string      // The protocol port was reported as open for.
+	probe probe.Probe // The Probe to scan port with.
 }
 
 type scanResult struct {
@@ -76,6 +84,7 @@ type Scanner struct {
 	hostQ   chan scanProposal
 	resQ    chan *scanResult
 	cmdQ    chan bool
+	osg     *meta.OSGuesser
 }
 
 // New creates and returns a fresh Scanner instance.
@@ -92,6 +101,10 @@ func New(cnt int) (*Scanner, error) {
 		scn.log.Printf("[CRITICAL] Failed to open DB pool: %s\n",
 			err.Error())
 		return nil, err
+	} else if scn.osg, err = meta.NewOSGuesser(""); err != nil {
+		scn.log.Printf("[CRITICAL] Failed to load OS patterns: %s\n",
+			err.Error())
+		return nil, err
 	}
 
 	scn.goalCnt.Store(int32(cnt))
@@ -116,6 +129,14 @@ func (scn *Scanner) System() subsystem.ID {
 	return subsystem.Scanner
 } // func (scn *Scanner) System() subsystem.ID
 
+// QueueDepths returns the number of items waiting in the Scanner's queues.
+func (scn *Scanner) QueueDepths() map[string]int {
+	return map[string]int{
+		"hostQ": len(scn.hostQ),
+		"resQ":  len(scn.resQ),
+	}
+} // func (scn *Scanner) QueueDepths() map[string]int
+
 // IsActive returns state of the Scanner's active flag.
 func (scn *Scanner) IsActive() bool {
 	return scn.active.Load()
@@ -124,6 +145,7 @@ func (scn *Scanner) IsActive() bool {
 // Start spawns the Scanner's workers.
 func (scn *Scanner) Start() {
 	scn.active.Store(true)
+	events.State(subsystem.Scanner, true)
 	// ...
 	go scn.feeder()
 	go scn.collector()
@@ -136,6 +158,7 @@ func (scn *Scanner) Start() {
 // Stop clears the Scanner's active flag.
 func (scn *Scanner) Stop() {
 	scn.active.Store(false)
+	events.State(subsystem.Scanner, false)
 } // func (scn *Scanner) Stop()
 
 // StartOne starts one additional worker.
@@ -172,7 +195,42 @@ func (scn *Scanner) feeder() {
 	defer ticker.Stop()
 
 	for scn.active.Load() {
-		var hosts []*model.Host
+		var (
+			hosts   []*model.Host
+			pending []*model.PendingPort
+			props   []scanProposal
+		)
+
+		// Ports reported as open by other scanners take precedence.
+		if pending, err = db.PortQueueGetPending(int(scn.scnt.Load())); err != nil {
+			scn.log.Printf("[ERROR] Failed to get queued ports: %s\n",
+				err.Error())
+			if errcnt++; errcnt > maxErr {
+				scn.active.Store(false)
+				return
+			}
+		}
+
+		for _, p := range pending {
+			if err = db.PortQueueDispatch(p); err != nil {
+				if errcnt++; errcnt > maxErr {
+					scn.active.Store(false)
+					return
+				}
+				continue
+			}
+
+			props = append(props, scanProposal{
+				host:  p.Host,
+				port:  p.Port,
+				proto: p.Proto,
+				probe: p.Probe,
+			})
+		}
+
+		if len(props) > 0 {
+			goto DISPATCH
+		}
 
 		if hosts, err = db.HostGetRandom(int(scn.scnt.Load())); err != nil {
 			scn.log.Printf("[ERROR] Failed to get random Hosts to scan: %s\n",
@@ -200,6 +258,12 @@ func (scn *Scanner) feeder() {
 				}
 
 			}
+
+			props = append(props, prop)
+		}
+
+	DISPATCH:
+		for _, prop := range props {
 		SEND:
 			select {
 			case <-ticker.C:
@@ -241,28 +305,69 @@ func (scn *Scanner) collector() {
 		case <-ticker.C:
 			continue
 		case res := <-scn.resQ:
+			var outcome = "failure"
+
 			if res.svc.Success {
+				outcome = "success"
 				scn.log.Printf("[DEBUG] Got one: %s:%d -- %s\n",
 					res.host.Addr,
 					res.svc.Port,
 					res.svc.Response)
+				events.Probe(res.host, res.svc)
 			}
+
+			metrics.Probes.With(strconv.Itoa(int(res.svc.Port)), outcome).Inc()
+
 			if err = db.ServiceAdd(res.host, res.svc); err != nil {
 				scn.log.Printf("[ERROR] Failed to add scanned Port %s:%d to database - %s\n",
 					res.host.AStr(),
 					res.svc.Port,
 					err.Error())
+			} else if res.svc.Success {
+				scn.classify(db, res.host)
 			}
 		}
 	}
 } // func (scn *Scanner) collector()
 
+// classify re-evaluates which OS a Host runs, taking into account all
+// of its services.
+func (scn *Scanner) classify(db *database.Database, h *model.Host) {
+	var (
+		err   error
+		svc   map[uint16]*model.Service
+		guess *meta.OSGuess
+	)
+
+	if svc, err = db.ServiceGetByHost(h); err != nil {
+		scn.log.Printf("[ERROR] Failed to get services of %s: %s\n",
+			h.AStr(),
+			err.Error())
+		return
+	} else if guess = scn.osg.Guess(svc); guess == nil {
+		return
+	} else if guess.Name == h.Sysname && guess.Confidence == h.OSConfidence {
+		return
+	} else if err = db.HostSetSysname(h, guess.Name, guess.Confidence); err != nil {
+		scn.log.Printf("[ERROR] %s\n", err.Error())
+		return
+	}
+
+	scn.log.Printf("[DEBUG] %s (%s) runs %s (confidence %.2f)\n",
+		h.Name,
+		h.AStr(),
+		guess.Name,
+		guess.Confidence)
+} // func (scn *Scanner) classify(db *database.Database, h *model.Host)
+
 func (scn *Scanner) scanWorker(id int) {
 	scn.log.Printf("[TRACE] scanWorker#%02d reporting for duty\n", id)
 	defer scn.log.Printf("[TRACE] scanWorker#%02d quitting. Bye.\n", id)
 
-	scn.scnt.Add(1)
-	defer scn.scnt.Add(-1)
+	events.Workers(subsystem.Scanner, int(scn.scnt.Add(1)))
+	defer func() {
+		events.Workers(subsystem.Scanner, int(scn.scnt.Add(-1)))
+	}()
 
 	var ticker = time.NewTicker(common.ActiveTimeout)
 	defer ticker.Stop()
@@ -280,7 +385,12 @@ func (scn *Scanner) scanWorker(id int) {
 				res  *scanResult
 			)
 			// Deal with it!
-			if port = scn.pickPort(prop); port == 0 {
+			if port = prop.port; port != 0 {
+				scn.log.Printf("[TRACE] scanWorker#%02d scans queued port %s:%d\n",
+					id,
+					prop.host.AStr(),
+					port)
+			} else if port = scn.pickPort(prop); port == 0 {
 				continue
 			}
 
@@ -290,7 +400,8 @@ func (scn *Scanner) scanWorker(id int) {
 				port)
 
 			// Let's scan a port!
-			if res, err = scn.probePort(prop.host, port); err != nil {
+			if res, err = scn.probePort(prop.host, port, prop.proto, prop.probe); err != nil {
+				metrics.Probes.With(strconv.Itoa(int(port)), "error").Inc()
 				scn.log.Printf("[ERROR] scanWorker#%02d failed to scan %s:%d - %s\n",
 					id,
 					prop.host.AStr(),
@@ -0,0 +1,95 @@
+// /home/krylon/go/src/github.com/blicero/guangng/scanner/sip.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:28:27 krylon>
+
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/model"
+)
+
+// SIP servers do not greet anyone, either, but they answer an OPTIONS
+// request, which is the SIP way of asking what a server can do. Most of
+// them say what software they run in the Server header of the response.
+const sipOptions = "OPTIONS sip:%[1]s SIP/2.0\r\n" +
+	"Via: SIP/2.0/TCP %[2]s;branch=z9hG4bK%[3]x\r\n" +
+	"Max-Forwards: 70\r\n" +
+	"From: <sip:probe@%[2]s>;tag=%[3]x\r\n" +
+	"To: <sip:%[1]s>\r\n" +
+	"Call-ID: %[3]x@%[2]s\r\n" +
+	"CSeq: 1 OPTIONS\r\n" +
+	"Contact: <sip:probe@%[2]s;transport=tcp>\r\n" +
+	"Accept: application/sdp\r\n" +
+	"Content-Length: 0\r\n" +
+	"\r\n"
+
+// scanSIP sends an OPTIONS request to the SIP server on host via TCP.
+func (scn *Scanner) scanSIP(host *model.Host, port uint16) (*scanResult, error) {
+	scn.log.Printf("[TRACE] Scanning %s:%d using SIP scanner.\n", host.AStr(), port)
+
+	var (
+		err    error
+		conn   net.Conn
+		reader *textproto.Reader
+		status string
+		hdr    textproto.MIMEHeader
+		srv    = net.JoinHostPort(host.AStr(), strconv.Itoa(int(port)))
+	)
+
+	if conn, err = net.DialTimeout("tcp", srv, common.ActiveTimeout); err != nil {
+		return nil, fmt.Errorf("error connecting to %s: %w", srv, err)
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	conn.SetDeadline(time.Now().Add(common.ActiveTimeout * 2)) // nolint: errcheck
+
+	if _, err = fmt.Fprintf(conn, sipOptions, srv, conn.LocalAddr(), rand.Uint32()); err != nil {
+		return nil, fmt.Errorf("error sending OPTIONS request to %s: %w", srv, err)
+	}
+
+	reader = textproto.NewReader(bufio.NewReader(conn))
+
+	if status, err = reader.ReadLine(); err != nil {
+		return nil, fmt.Errorf("error receiving data from %s: %w", srv, err)
+	} else if !strings.HasPrefix(status, "SIP/2.0 ") {
+		return nil, fmt.Errorf("%s did not answer in SIP: %q", srv, status)
+	} else if hdr, err = reader.ReadMIMEHeader(); err != nil {
+		return nil, fmt.Errorf("error reading SIP headers from %s: %w", srv, err)
+	}
+
+	var res = &scanResult{
+		host: host,
+		svc: &model.Service{
+			HostID:    host.ID,
+			Port:      port,
+			Response:  status,
+			Success:   true,
+			Timestamp: time.Now(),
+		},
+	}
+
+	if s := hdr.Get("Server"); s != "" {
+		res.svc.Response = s
+	} else if s = hdr.Get("User-Agent"); s != "" {
+		res.svc.Response = s
+	}
+
+	scn.log.Printf("[TRACE] Got SIP reply from %s: %s\n",
+		srv,
+		res.svc.Response)
+
+	return res, nil
+} // func (scn *Scanner) scanSIP(host *model.Host, port uint16) (*scanResult, error)
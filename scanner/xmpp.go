@@ -0,0 +1,128 @@
+// /home/krylon/go/src/github.com/blicero/guangng/scanner/xmpp.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:28:27 krylon>
+
+package scanner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/model"
+)
+
+// An XMPP server does not send anything until the other side has opened
+// its stream, so unlike most services, we have to speak first. The server
+// answers with its own stream header, followed either by the features it
+// offers or by a stream error, e.g. because it does not serve the domain
+// we asked for. Either way, we know we are talking to an XMPP server.
+const (
+	xmppClientNS = "jabber:client"
+	xmppServerNS = "jabber:server"
+	xmppStreamNS = "http://etherx.jabber.org/streams"
+	xmppOpen     = "<?xml version='1.0'?><stream:stream to='%s' xmlns='%s' xmlns:stream='%s' version='1.0'>"
+)
+
+// scanXMPP opens an XMPP stream to host in the namespace ns, which tells
+// the server if we pretend to be a client or a peer server.
+func (scn *Scanner) scanXMPP(host *model.Host, port uint16, ns string) (*scanResult, error) {
+	scn.log.Printf("[TRACE] Scanning %s:%d using XMPP scanner (%s).\n",
+		host.AStr(),
+		port,
+		ns)
+
+	var (
+		err      error
+		conn     net.Conn
+		dec      *xml.Decoder
+		tok      xml.Token
+		depth    int
+		child    string
+		info     []string
+		features []string
+		srv      = net.JoinHostPort(host.AStr(), strconv.Itoa(int(port)))
+		res      = &scanResult{
+			host: host,
+			svc: &model.Service{
+				HostID:    host.ID,
+				Port:      port,
+				Timestamp: time.Now(),
+			},
+		}
+	)
+
+	if conn, err = net.DialTimeout("tcp", srv, common.ActiveTimeout); err != nil {
+		return nil, fmt.Errorf("error connecting to %s: %w", srv, err)
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	conn.SetDeadline(time.Now().Add(common.ActiveTimeout * 2)) // nolint: errcheck
+
+	if _, err = fmt.Fprintf(conn, xmppOpen, strings.TrimSuffix(host.Name, "."), ns, xmppStreamNS); err != nil {
+		return nil, fmt.Errorf("error opening XMPP stream to %s: %w", srv, err)
+	}
+
+	dec = xml.NewDecoder(conn)
+
+	// The stream stays open, so we stop once we have read the features or
+	// the error that follow the stream header.
+READ:
+	for {
+		if tok, err = dec.Token(); err != nil {
+			break READ
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			switch depth {
+			case 1:
+				if t.Name.Space != xmppStreamNS || t.Name.Local != "stream" {
+					err = fmt.Errorf("%s sent <%s> instead of an XMPP stream header",
+						srv,
+						t.Name.Local)
+					break READ
+				}
+
+				res.svc.Success = true
+				for _, attr := range t.Attr {
+					if attr.Name.Space == "" && (attr.Name.Local == "from" || attr.Name.Local == "version") {
+						info = append(info, attr.Name.Local+"="+attr.Value)
+					}
+				}
+			case 2:
+				child = t.Name.Local
+			case 3:
+				if child == "features" || (child == "error" && t.Name.Local != "text") {
+					features = append(features, t.Name.Local)
+				}
+			}
+		case xml.EndElement:
+			if depth--; depth <= 1 {
+				break READ
+			}
+		}
+	}
+
+	if !res.svc.Success {
+		return nil, fmt.Errorf("error receiving XMPP stream header from %s: %w", srv, err)
+	} else if len(features) > 0 {
+		info = append(info, child+"="+strings.Join(features, ","))
+	}
+
+	res.svc.Response = strings.Join(info, " ")
+
+	scn.log.Printf("[TRACE] Got XMPP stream from %s: %s\n",
+		srv,
+		res.svc.Response)
+
+	return res, nil
+} // func (scn *Scanner) scanXMPP(host *model.Host, port uint16, ns string) (*scanResult, error)
@@ -0,0 +1,247 @@
+// /home/krylon/go/src/github.com/blicero/guangng/user_cmd.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 13:58:21 krylon>
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/blicero/guangng/auth"
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/role"
+)
+
+const userUsage = `Usage: %[1]s user COMMAND [ARGS...]
+
+Commands:
+    add [-role operator|readonly] NAME   Create a User, read password from stdin
+    list                                 List all Users
+    del NAME                             Delete a User
+    passwd NAME                          Set a User's password, read from stdin
+    token add NAME TOKEN                 Create an API token, print it once
+    token list NAME                      List a User's API tokens
+    token del NAME TOKEN                 Revoke an API token
+`
+
+// runUser implements the "user" subcommand for managing the accounts of the
+// web interface. It returns the exit code for the process.
+func runUser(args []string) int {
+	var (
+		err error
+		db  *database.Database
+	)
+
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, userUsage, os.Args[0])
+		return 1
+	} else if db, err = database.Open(common.DbPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot open database %s: %s\n",
+			common.DbPath,
+			err.Error())
+		return 1
+	}
+
+	defer db.Close() // nolint: errcheck
+
+	switch args[0] {
+	case "add":
+		err = userAdd(db, args[1:])
+	case "list":
+		err = userList(db)
+	case "del":
+		err = withUser(db, args[1:], 1, func(u *model.User) error {
+			return db.UserDelete(u)
+		})
+	case "passwd":
+		err = withUser(db, args[1:], 1, func(u *model.User) error {
+			var hash string
+
+			if hash, err = readPassword(); err != nil {
+				return err
+			}
+
+			return db.UserSetPassword(u, hash)
+		})
+	case "token":
+		err = userToken(db, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, userUsage, os.Args[0])
+		return 1
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		return 1
+	}
+
+	return 0
+} // func runUser(args []string) int
+
+func userAdd(db *database.Database, args []string) error {
+	var (
+		err      error
+		roleName string
+		user     = new(model.User)
+		flags    = flag.NewFlagSet("user add", flag.ExitOnError)
+	)
+
+	flags.StringVar(&roleName, "role", "readonly", "Role of the new User (operator, readonly)")
+	flags.Parse(args) // nolint: errcheck
+
+	if flags.NArg() != 1 {
+		return errors.New("user add expects exactly one user name")
+	}
+
+	user.Name = flags.Arg(0)
+
+	for _, r := range role.AllRoles() {
+		if strings.EqualFold(r.String(), roleName) {
+			user.Role = r
+		}
+	}
+
+	if user.Role == 0 {
+		return fmt.Errorf("unknown role %q", roleName)
+	} else if user.PwHash, err = readPassword(); err != nil {
+		return err
+	} else if err = db.UserAdd(user); err != nil {
+		return err
+	}
+
+	fmt.Printf("Created User %s (%s)\n", user.Name, user.Role)
+	return nil
+} // func userAdd(db *database.Database, args []string) error
+
+func userList(db *database.Database) error {
+	var (
+		err   error
+		users []*model.User
+	)
+
+	if users, err = db.UserGetAll(); err != nil {
+		return err
+	}
+
+	for _, u := range users {
+		var login = "never"
+
+		if !u.LastLogin.IsZero() {
+			login = u.LastLogin.Format(common.TimestampFormat)
+		}
+
+		fmt.Printf("%-16s %-10s last login: %s\n",
+			u.Name,
+			u.Role,
+			login)
+	}
+
+	return nil
+} // func userList(db *database.Database) error
+
+func userToken(db *database.Database, args []string) error {
+	if len(args) == 0 {
+		return errors.New("user token expects one of add, list, del")
+	}
+
+	switch args[0] {
+	case "add":
+		return withUser(db, args[1:], 2, func(u *model.User) error {
+			var (
+				err   error
+				token string
+				tok   = &model.APIToken{User: u, Name: args[2]}
+			)
+
+			if token, tok.TokenHash, err = auth.NewToken(); err != nil {
+				return err
+			} else if err = db.TokenAdd(tok); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "API token %s for %s (it will not be shown again):\n",
+				tok.Name,
+				u.Name)
+			fmt.Println(token)
+			return nil
+		})
+	case "list":
+		return withUser(db, args[1:], 1, func(u *model.User) error {
+			var (
+				err    error
+				tokens []*model.APIToken
+			)
+
+			if tokens, err = db.TokenGetByUser(u); err != nil {
+				return err
+			}
+
+			for _, t := range tokens {
+				var used = "never"
+
+				if !t.LastUsed.IsZero() {
+					used = t.LastUsed.Format(common.TimestampFormat)
+				}
+
+				fmt.Printf("%-16s created %s, last used: %s\n",
+					t.Name,
+					t.Created.Format(common.TimestampFormat),
+					used)
+			}
+
+			return nil
+		})
+	case "del":
+		return withUser(db, args[1:], 2, func(u *model.User) error {
+			return db.TokenDelete(u, args[2])
+		})
+	default:
+		return fmt.Errorf("unknown token command %q", args[0])
+	}
+} // func userToken(db *database.Database, args []string) error
+
+// withUser looks up the User named by the first of exactly cnt arguments and
+// calls fn on it.
+func withUser(db *database.Database, args []string, cnt int, fn func(u *model.User) error) error {
+	var (
+		err  error
+		user *model.User
+	)
+
+	if len(args) != cnt {
+		return fmt.Errorf("expected %d arguments, got %d", cnt, len(args))
+	} else if user, err = db.UserGetByName(args[0]); err != nil {
+		return err
+	} else if user == nil {
+		return fmt.Errorf("there is no User named %q", args[0])
+	}
+
+	return fn(user)
+} // func withUser(db *database.Database, args []string, cnt int, fn func(u *model.User) error) error
+
+// readPassword reads a password from the first line of stdin and returns its
+// hash.
+func readPassword() (string, error) {
+	var (
+		err  error
+		line string
+		rdr  = bufio.NewReader(os.Stdin)
+	)
+
+	fmt.Fprint(os.Stderr, "Password: ")
+
+	if line, err = rdr.ReadString('\n'); err != nil && line == "" {
+		return "", fmt.Errorf("cannot read password: %w", err)
+	}
+
+	return auth.HashPassword(strings.TrimRight(line, "\r\n"))
+} // func readPassword() (string, error)
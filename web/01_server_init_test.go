@@ -2,14 +2,29 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 25. 08. 2024 by Benjamin Walkenhorst
 // (c) 2024 Benjamin Walkenhorst
-// Time-stamp: <2026-01-30 15:07:21 krylon>
+// Time-stamp: <2026-10-19 15:02:25 krylon>
 
 package web
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/blicero/guangng/auth"
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/role"
+	"github.com/blicero/guangng/model/weakness"
+)
+
+const (
+	testUser     = "molly"
+	testPassword = "razorgirl"
 )
 
 func TestServerCreate(t *testing.T) {
@@ -26,3 +41,163 @@ func TestServerCreate(t *testing.T) {
 	go srv.Run()
 	time.Sleep(time.Second)
 } // func TestServerCreate(t *testing.T)
+
+func TestServerLogin(t *testing.T) {
+	if srv == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		res  *http.Response
+		db   *database.Database
+		user = &model.User{
+			Name: testUser,
+			Role: role.Operator,
+		}
+		loginURL = fmt.Sprintf("http://%s/login", addr)
+	)
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if user.PwHash, err = auth.HashPassword(testPassword); err != nil {
+		t.Fatalf("Cannot hash password: %s", err.Error())
+	} else if err = db.UserAdd(user); err != nil {
+		t.Fatalf("Cannot add User: %s", err.Error())
+	}
+
+	if res, err = client.PostForm(loginURL, url.Values{
+		"name":     {testUser},
+		"password": {testPassword + "?"},
+	}); err != nil {
+		t.Fatalf("Failed to post login form: %s", err.Error())
+	}
+
+	res.Body.Close() // nolint: errcheck
+
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Login with wrong password returned %s", res.Status)
+	}
+
+	if res, err = client.PostForm(loginURL, url.Values{
+		"name":     {testUser},
+		"password": {testPassword},
+	}); err != nil {
+		t.Fatalf("Failed to post login form: %s", err.Error())
+	}
+
+	res.Body.Close() // nolint: errcheck
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Login returned %s", res.Status)
+	} else if res.Request.URL.Path != "/main" {
+		t.Errorf("Login redirected us to %s", res.Request.URL)
+	}
+} // func TestServerLogin(t *testing.T)
+
+func TestServerPages(t *testing.T) {
+	if srv == nil {
+		t.SkipNow()
+	}
+
+	for path, status := range map[string]int{
+		"/main":                          http.StatusOK,
+		"/hosts?asn=AS1234":              http.StatusOK,
+		"/by_port":                       http.StatusOK,
+		"/asn":                           http.StatusOK,
+		"/asn/64496":                     http.StatusNotFound,
+		"/zones":                         http.StatusOK,
+		"/zone/987654":                   http.StatusNotFound,
+		"/stats/country":                 http.StatusOK,
+		"/stats/asn?sort=org&desc=1":     http.StatusOK,
+		"/stats/yield?sort=yield&desc=1": http.StatusOK,
+		"/stats/city/csv":                http.StatusOK,
+		"/stats/nowhere":                 http.StatusNotFound,
+		"/export/mail/csv":               http.StatusOK,
+	} {
+		var (
+			err error
+			res *http.Response
+		)
+
+		if res, err = client.Get(fmt.Sprintf("http://%s%s", addr, path)); err != nil {
+			t.Errorf("Failed to get %s: %s", path, err.Error())
+			continue
+		}
+
+		res.Body.Close() // nolint: errcheck
+
+		if res.StatusCode != status {
+			t.Errorf("Request for %s returned %s, expected %d",
+				path,
+				res.Status,
+				status)
+		}
+	}
+} // func TestServerPages(t *testing.T)
+
+func TestServerZone(t *testing.T) {
+	if srv == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		res  *http.Response
+		body []byte
+		db   *database.Database
+		zone = &model.Zone{Name: "straylight.test", Added: time.Now()}
+		rec  = &model.ZoneRecord{
+			Owner: "wintermute.straylight.test.",
+			Type:  "A",
+			TTL:   3600,
+			RData: "192.0.2.1",
+		}
+		pol = &model.MailPolicy{
+			HasMX:       true,
+			DMARC:       "v=DMARC1; p=none",
+			DMARCPolicy: "none",
+			Weaknesses:  []weakness.Weakness{weakness.NoSPF, weakness.DMARCNone},
+			Checked:     time.Now(),
+		}
+	)
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if err = db.XFRAdd(zone); err != nil {
+		t.Fatalf("Cannot add zone: %s", err.Error())
+	} else if err = db.ZoneRecordAdd(zone, rec); err != nil {
+		t.Fatalf("Cannot add record: %s", err.Error())
+	} else if err = db.MailPolicySet(zone, pol); err != nil {
+		t.Fatalf("Cannot store mail policy: %s", err.Error())
+	}
+
+	for _, path := range []string{
+		fmt.Sprintf("/zone/%d", zone.ID),
+		fmt.Sprintf("/zone/%d/file", zone.ID),
+	} {
+		if res, err = client.Get(fmt.Sprintf("http://%s%s", addr, path)); err != nil {
+			t.Fatalf("Failed to get %s: %s", path, err.Error())
+		}
+
+		body, err = io.ReadAll(res.Body)
+		res.Body.Close() // nolint: errcheck
+
+		if err != nil {
+			t.Fatalf("Failed to read %s: %s", path, err.Error())
+		} else if res.StatusCode != http.StatusOK {
+			t.Errorf("Request for %s returned %s", path, res.Status)
+		} else if !strings.Contains(string(body), rec.Owner) {
+			t.Errorf("Response to %s does not contain the record", path)
+		} else if !strings.HasSuffix(path, "/file") &&
+			!strings.Contains(string(body), weakness.DMARCNone.Description()) {
+			t.Errorf("Response to %s does not show the mail policy", path)
+		}
+	}
+
+	if !strings.Contains(string(body), "$ORIGIN straylight.test.\n"+rec.String()+"\n") {
+		t.Errorf("Unexpected zone file:\n%s", body)
+	}
+} // func TestServerZone(t *testing.T)
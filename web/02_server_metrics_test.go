@@ -0,0 +1,52 @@
+// /home/krylon/go/src/github.com/blicero/guangng/web/02_server_metrics_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 22:03:17 krylon>
+
+package web
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	if srv == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		res  *http.Response
+		body []byte
+		url  = fmt.Sprintf("http://%s/metrics", addr)
+	)
+
+	if res, err = client.Get(url); err != nil {
+		t.Fatalf("Failed to get %s: %s", url, err.Error())
+	}
+
+	defer res.Body.Close() // nolint: errcheck
+
+	if res.StatusCode != 200 {
+		t.Fatalf("Unexpected status from %s: %s", url, res.Status)
+	} else if body, err = io.ReadAll(res.Body); err != nil {
+		t.Fatalf("Failed to read response body: %s", err.Error())
+	}
+
+	for _, name := range []string{
+		"guangng_generator_addresses_total",
+		"guangng_scanner_probes_total",
+		"guangng_db_pool_wait_seconds_total",
+	} {
+		if !strings.Contains(string(body), "# TYPE "+name+" counter\n") {
+			t.Errorf("Metric %s is missing from response:\n%s",
+				name,
+				body)
+		}
+	}
+} // func TestMetrics(t *testing.T)
@@ -0,0 +1,72 @@
+// /home/krylon/go/src/github.com/blicero/guangng/web/03_server_events_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 23:20:06 krylon>
+
+package web
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blicero/guangng/events"
+	"github.com/blicero/guangng/model"
+)
+
+func TestEvents(t *testing.T) {
+	if srv == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err         error
+		req         *http.Request
+		res         *http.Response
+		line        string
+		ctx, cancel = context.WithTimeout(context.Background(), time.Second*5)
+		url         = fmt.Sprintf("http://%s/events", addr)
+	)
+
+	defer cancel()
+
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil); err != nil {
+		t.Fatalf("Cannot create request: %s", err.Error())
+	} else if res, err = client.Do(req); err != nil {
+		t.Fatalf("Failed to get %s: %s", url, err.Error())
+	}
+
+	defer res.Body.Close() // nolint: errcheck
+
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Unexpected Content-Type: %s", ct)
+	}
+
+	var rd = bufio.NewReader(res.Body)
+
+	// Wait for the server to subscribe before we publish anything.
+	for !strings.HasPrefix(line, ": connected") {
+		if line, err = rd.ReadString('\n'); err != nil {
+			t.Fatalf("Failed to read from event stream: %s", err.Error())
+		}
+	}
+
+	events.Host(&model.Host{Name: "wintermute.example.com."})
+
+	for !strings.HasPrefix(line, "data:") {
+		if line, err = rd.ReadString('\n'); err != nil {
+			t.Fatalf("Failed to read from event stream: %s", err.Error())
+		} else if strings.HasPrefix(line, "event:") && line != "event: HostAdded\n" {
+			t.Errorf("Unexpected event type: %s", line)
+		}
+	}
+
+	if !strings.Contains(line, "wintermute.example.com.") {
+		t.Errorf("Unexpected event data: %s", line)
+	}
+} // func TestEvents(t *testing.T)
@@ -0,0 +1,202 @@
+// /home/krylon/go/src/github.com/blicero/guangng/web/04_server_auth_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 14:36:55 krylon>
+
+package web
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/blicero/guangng/auth"
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/role"
+)
+
+var csrfPat = regexp.MustCompile(`name="csrf-token" content="([^"]+)"`)
+
+// noRedirect is a client without a cookie jar that does not follow
+// redirects.
+var noRedirect = http.Client{
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func TestAuthAnonymous(t *testing.T) {
+	if srv == nil {
+		t.SkipNow()
+	}
+
+	for path, status := range map[string]int{
+		"/metrics":           http.StatusUnauthorized,
+		"/ajax/worker_count": http.StatusUnauthorized,
+		"/hosts":             http.StatusSeeOther,
+		"/login":             http.StatusOK,
+		"/static/style.css":  http.StatusOK,
+	} {
+		var (
+			err error
+			res *http.Response
+		)
+
+		if res, err = noRedirect.Get(fmt.Sprintf("http://%s%s", addr, path)); err != nil {
+			t.Errorf("Failed to get %s: %s", path, err.Error())
+			continue
+		}
+
+		res.Body.Close() // nolint: errcheck
+
+		if res.StatusCode != status {
+			t.Errorf("Anonymous request for %s returned %s, expected %d",
+				path,
+				res.Status,
+				status)
+		}
+	}
+} // func TestAuthAnonymous(t *testing.T)
+
+func TestAuthToken(t *testing.T) {
+	if srv == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err   error
+		token string
+		db    *database.Database
+		user  = &model.User{
+			Name:   "finn",
+			PwHash: "-",
+			Role:   role.ReadOnly,
+		}
+		tok = &model.APIToken{
+			User: user,
+			Name: "test",
+		}
+	)
+
+	db = srv.pool.Get()
+
+	if err = db.UserAdd(user); err != nil {
+		srv.pool.Put(db)
+		t.Fatalf("Cannot add User: %s", err.Error())
+	} else if token, tok.TokenHash, err = auth.NewToken(); err != nil {
+		srv.pool.Put(db)
+		t.Fatalf("Cannot generate token: %s", err.Error())
+	} else if err = db.TokenAdd(tok); err != nil {
+		srv.pool.Put(db)
+		t.Fatalf("Cannot add API token: %s", err.Error())
+	}
+
+	srv.pool.Put(db)
+
+	for _, c := range []struct {
+		method, path, token string
+		status              int
+	}{
+		{http.MethodGet, "/metrics", token, http.StatusOK},
+		{http.MethodGet, "/metrics", token + "x", http.StatusUnauthorized},
+		{http.MethodPost, "/ajax/spawn_worker/1/1", token, http.StatusForbidden},
+	} {
+		var (
+			req *http.Request
+			res *http.Response
+		)
+
+		if req, err = http.NewRequest(c.method, fmt.Sprintf("http://%s%s", addr, c.path), nil); err != nil {
+			t.Fatalf("Cannot create request: %s", err.Error())
+		}
+
+		req.Header.Set("Authorization", "Bearer "+c.token)
+
+		if res, err = noRedirect.Do(req); err != nil {
+			t.Errorf("Failed to %s %s: %s", c.method, c.path, err.Error())
+			continue
+		}
+
+		res.Body.Close() // nolint: errcheck
+
+		if res.StatusCode != c.status {
+			t.Errorf("%s %s with token returned %s, expected %d",
+				c.method,
+				c.path,
+				res.Status,
+				c.status)
+		}
+	}
+} // func TestAuthToken(t *testing.T)
+
+func TestAuthCSRF(t *testing.T) {
+	if srv == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		res  *http.Response
+		body []byte
+		m    []string
+		spwn = fmt.Sprintf("http://%s/ajax/spawn_worker/1/1", addr)
+	)
+
+	if res, err = client.Get(spwn); err != nil {
+		t.Fatalf("Failed to get %s: %s", spwn, err.Error())
+	}
+
+	res.Body.Close() // nolint: errcheck
+
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET %s returned %s", spwn, res.Status)
+	}
+
+	if res, err = client.Post(spwn, "text/plain", nil); err != nil {
+		t.Fatalf("Failed to post %s: %s", spwn, err.Error())
+	}
+
+	res.Body.Close() // nolint: errcheck
+
+	if res.StatusCode != http.StatusForbidden {
+		t.Errorf("POST %s without CSRF token returned %s", spwn, res.Status)
+	}
+
+	if res, err = client.Get(fmt.Sprintf("http://%s/main", addr)); err != nil {
+		t.Fatalf("Failed to get main page: %s", err.Error())
+	}
+
+	body, err = io.ReadAll(res.Body)
+	res.Body.Close() // nolint: errcheck
+
+	if err != nil {
+		t.Fatalf("Cannot read main page: %s", err.Error())
+	} else if m = csrfPat.FindStringSubmatch(string(body)); m == nil {
+		t.Fatal("Main page does not contain a CSRF token")
+	}
+
+	if res, err = client.PostForm(fmt.Sprintf("http://%s/logout", addr), url.Values{
+		csrfField: {m[1]},
+	}); err != nil {
+		t.Fatalf("Failed to log out: %s", err.Error())
+	}
+
+	res.Body.Close() // nolint: errcheck
+
+	if res.Request.URL.Path != "/login" {
+		t.Errorf("Logout sent us to %s", res.Request.URL)
+	} else if res, err = client.Get(fmt.Sprintf("http://%s/metrics", addr)); err != nil {
+		t.Fatalf("Failed to get metrics: %s", err.Error())
+	}
+
+	res.Body.Close() // nolint: errcheck
+
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Request after logout returned %s", res.Status)
+	}
+} // func TestAuthCSRF(t *testing.T)
@@ -0,0 +1,118 @@
+// /home/krylon/go/src/github.com/blicero/guangng/web/05_server_tls_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 16:58:04 krylon>
+
+package web
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blicero/guangng/common"
+)
+
+func TestTLS(t *testing.T) {
+	var (
+		err       error
+		tsrv      *Server
+		res       *http.Response
+		tlsAddr   = fmt.Sprintf("[::1]:%d", testPort+1)
+		redirAddr = fmt.Sprintf("[::1]:%d", testPort+3)
+		tlsClient = http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // nolint: gosec
+			},
+		}
+	)
+
+	if tsrv, err = Create(tlsAddr, nil); err != nil {
+		t.Fatalf("Error creating Server: %s", err.Error())
+	} else if err = tsrv.UseTLS("", "", redirAddr); err != nil {
+		t.Fatalf("Error setting up TLS: %s", err.Error())
+	} else if _, err = os.Stat(common.CertPath); err != nil {
+		t.Errorf("Self-signed certificate was not created: %s", err.Error())
+	}
+
+	go tsrv.Run()
+	time.Sleep(time.Second)
+
+	if res, err = tlsClient.Get(fmt.Sprintf("https://%s/login", tlsAddr)); err != nil {
+		t.Fatalf("Failed to get login page via HTTPS: %s", err.Error())
+	}
+
+	res.Body.Close() // nolint: errcheck
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Login page returned %s", res.Status)
+	} else if res.TLS == nil {
+		t.Error("Response was not sent via TLS")
+	}
+
+	if res, err = noRedirect.Get(fmt.Sprintf("http://%s/hosts?limit=5", redirAddr)); err != nil {
+		t.Fatalf("Failed to get redirect: %s", err.Error())
+	}
+
+	res.Body.Close() // nolint: errcheck
+
+	var expect = fmt.Sprintf("https://%s/hosts?limit=5", tlsAddr)
+
+	if res.StatusCode != http.StatusMovedPermanently {
+		t.Errorf("Redirect listener returned %s", res.Status)
+	} else if loc := res.Header.Get("Location"); loc != expect {
+		t.Errorf("Redirect listener sent us to %s, expected %s", loc, expect)
+	}
+} // func TestTLS(t *testing.T)
+
+func TestCertReload(t *testing.T) {
+	if srv == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err      error
+		cert     *tls.Certificate
+		first    []byte
+		dir      = t.TempDir()
+		certFile = filepath.Join(dir, "cert.pem")
+		keyFile  = filepath.Join(dir, "key.pem")
+		cl       = &certLoader{
+			log:      srv.log,
+			certFile: certFile,
+			keyFile:  keyFile,
+			auto:     true,
+			hosts:    []string{"localhost"},
+		}
+	)
+
+	if err = cl.reload(true); err != nil {
+		t.Fatalf("Cannot create certificate: %s", err.Error())
+	} else if cert, err = cl.getCertificate(nil); err != nil {
+		t.Fatalf("Cannot get certificate: %s", err.Error())
+	}
+
+	first = cert.Certificate[0]
+
+	// Put a new certificate in place, as an operator renewing it would.
+	var later = time.Now().Add(time.Minute)
+
+	if err = cl.generate(); err != nil {
+		t.Fatalf("Cannot create second certificate: %s", err.Error())
+	} else if err = os.Chtimes(certFile, later, later); err != nil {
+		t.Fatalf("Cannot touch %s: %s", certFile, err.Error())
+	}
+
+	cl.checked = time.Time{}
+
+	if cert, err = cl.getCertificate(nil); err != nil {
+		t.Fatalf("Cannot get certificate: %s", err.Error())
+	} else if string(cert.Certificate[0]) == string(first) {
+		t.Error("Certificate was not reloaded after it changed on disk")
+	}
+} // func TestCertReload(t *testing.T)
@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 03. 11. 2022 by Benjamin Walkenhorst
 // (c) 2022 Benjamin Walkenhorst
-// Time-stamp: <2026-02-03 15:30:51 krylon>
+// Time-stamp: <2026-10-20 18:53:12 krylon>
 
 package web
 
@@ -33,4 +33,5 @@ type ajaxWorkerCnt struct {
 	GeneratorName    int
 	XFR              int
 	Scanner          int
+	Geo              int
 }
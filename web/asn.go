@@ -0,0 +1,136 @@
+// /home/krylon/go/src/github.com/blicero/guangng/web/asn.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 21:08:44 krylon>
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"text/template"
+
+	"github.com/blicero/guangng/database"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// asnListLimit is the number of autonomous systems we list at most.
+	asnListLimit = 500
+	// asnHostLimit is the number of Hosts we show on the page of an
+	// autonomous system, the host list has all of them.
+	asnHostLimit = 100
+	// asnProductLimit is the number of distinct service responses we show
+	// on the page of an autonomous system.
+	asnProductLimit = 25
+)
+
+// handleASNList lists the autonomous systems we know Hosts in.
+func (srv *Server) handleASNList(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handling request for %s\n", r.RequestURI)
+	const tmplName = "asn_list"
+
+	var (
+		err  error
+		msg  string
+		db   *database.Database
+		tmpl *template.Template
+		data = tmplDataASNList{
+			tmplDataBase: srv.baseData("Networks", r),
+		}
+	)
+
+	if tmpl = srv.tmpl.Lookup(tmplName); tmpl == nil {
+		msg = fmt.Sprintf("Could not find template %q", tmplName)
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if data.Networks, err = db.ASNGetAll(asnListLimit); err != nil {
+		msg = fmt.Sprintf("Failed to get autonomous systems: %s", err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	w.Header().Set("Cache-Control", noCache)
+	if err = tmpl.Execute(w, &data); err != nil {
+		msg = fmt.Sprintf("Error rendering template %q: %s",
+			tmplName,
+			err.Error())
+		srv.sendErrorMessage(w, msg)
+	}
+} // func (srv *Server) handleASNList(w http.ResponseWriter, r *http.Request)
+
+// handleASNDetails shows the Hosts, services and the most common products
+// in one autonomous system.
+func (srv *Server) handleASNDetails(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handling request for %s\n", r.RequestURI)
+	const tmplName = "asn"
+
+	var (
+		err  error
+		msg  string
+		num  uint64
+		db   *database.Database
+		tmpl *template.Template
+		vars = mux.Vars(r)
+		data = tmplDataASN{
+			tmplDataBase: srv.baseData("Network", r),
+		}
+	)
+
+	if tmpl = srv.tmpl.Lookup(tmplName); tmpl == nil {
+		msg = fmt.Sprintf("Could not find template %q", tmplName)
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if num, err = strconv.ParseUint(vars["asn"], 10, 32); err != nil {
+		msg = fmt.Sprintf("Invalid AS number %q: %s", vars["asn"], err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if data.Info, err = db.ASNGetByNumber(uint32(num)); err != nil {
+		msg = fmt.Sprintf("Failed to get AS%d: %s", num, err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if data.Info == nil {
+		http.NotFound(w, r)
+		return
+	} else if data.Products, err = db.ASNGetProducts(data.Info.ASN, asnProductLimit); err != nil {
+		msg = fmt.Sprintf("Failed to get products in AS%d: %s", num, err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if data.Hosts, err = db.HostGetFiltered(&database.HostFilter{
+		ASN:   data.Info.ASN,
+		Limit: asnHostLimit,
+	}); err != nil {
+		msg = fmt.Sprintf("Failed to get Hosts in AS%d: %s", num, err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	data.Title = fmt.Sprintf("AS%d", data.Info.ASN)
+
+	w.Header().Set("Cache-Control", noCache)
+	if err = tmpl.Execute(w, &data); err != nil {
+		msg = fmt.Sprintf("Error rendering template %q: %s",
+			tmplName,
+			err.Error())
+		srv.sendErrorMessage(w, msg)
+	}
+} // func (srv *Server) handleASNDetails(w http.ResponseWriter, r *http.Request)
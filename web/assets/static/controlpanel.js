@@ -1,6 +1,6 @@
 // /home/krylon/go/src/github.com/blicero/guang/frontend/html/static/controlpanel.js
 // -*- mode: javascript; coding: utf-8; -*-
-// Time-stamp: <2026-02-03 15:43:37 krylon>
+// Time-stamp: <2026-10-19 14:28:08 krylon>
 // Copyright 2022 Benjamin Walkenhorst
 
 'use strict'
@@ -10,6 +10,7 @@ var count = {
     'GeneratorName':    0,
     'Scanner':          0,
     'XFR':              0,
+    'Geo':              0,
 }
 
 const cntID = {
@@ -17,6 +18,7 @@ const cntID = {
     'GeneratorName':    '#cnt_gen_name',
     'Scanner':          '#cnt_scan',
     'XFR':              '#cnt_xfr',
+    'Geo':              '#cnt_geo',
 }
 
 const amtID = {
@@ -24,12 +26,13 @@ const amtID = {
     'GeneratorName':      '#amt_gen_name',
     'Scanner':            '#amt_scan',
     'XFR':                '#amt_xfr',
+    'Geo':                '#amt_geo',
 }
 
 function workerSpawn(fac) {
     const amt = $(amtID[fac])[0].value
     const addr = `/ajax/spawn_worker/${subsystems[fac]}/${amt}`
-    const req = $.get(addr,
+    const req = $.post(addr,
                       {},
                       (res) => {
                           if (res.Status) {
@@ -54,7 +57,7 @@ function workerStop(fac) {
     const amt = $(amtID[fac])[0].value
     const addr = `/ajax/stop_worker/${subsystems[fac]}/${amt}`
 
-    const req = $.get(
+    const req = $.post(
         addr,
         {},
         (res) => {
@@ -87,7 +90,8 @@ function loadWorkerCount() {
             (res) => {
                 if (res.Status) {
                     for (const [fac, id] of Object.entries(cntID)) {
-                        $(id)[0].innerHTML = res[fac]
+                        // Cells for disabled subsystems are missing.
+                        $(id).html(res[fac])
                     }
                 } else {
                     const msg = `${res.Timestamp} - Error requesting worker count: ${res.Message}`
@@ -107,3 +111,50 @@ function loadWorkerCount() {
         window.setTimeout(loadWorkerCount, 2500)
     }
 } // function loadWorkerCount()
+
+// watchWorkerCount keeps the worker counts up to date with the events the
+// server pushes to us. If the browser cannot do that, we fall back to
+// polling.
+function watchWorkerCount() {
+    if (!defined(window.EventSource)) {
+        loadWorkerCount()
+        return
+    }
+
+    onEvent('WorkerCount', (ev) => {
+        const id = cntID[ev.subsystem]
+
+        if (defined(id)) {
+            $(id).html(ev.count || 0)
+        }
+    })
+
+    // Give the other scripts on the page a chance to register their
+    // handlers before we connect.
+    window.setTimeout(connectEvents, 0)
+} // function watchWorkerCount()
+
+// cacheReset clears the Generator's address cache, so it will try all
+// addresses again.
+function cacheReset() {
+    if (!confirm('Forget all addresses the Generator has looked at?')) {
+        return
+    }
+
+    $.post(
+        '/ajax/cache_reset',
+        {},
+        (res) => {
+            if (res.Status) {
+                window.location.reload()
+            } else {
+                alert(res.Message)
+            }
+        },
+        'json'
+    ).fail((reply, status, txt) => {
+        const msg = `Failed to reset cache: ${status} -- ${reply} -- ${txt}`
+        console.log(msg)
+        alert(msg)
+    })
+} // function cacheReset()
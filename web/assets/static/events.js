@@ -0,0 +1,153 @@
+// /home/krylon/go/src/github.com/blicero/guangng/web/assets/static/events.js
+// -*- mode: javascript; coding: utf-8; -*-
+// Time-stamp: <2026-10-19 22:57:12 krylon>
+// Copyright 2026 Benjamin Walkenhorst
+
+'use strict'
+
+// The server pushes Events to us via /events. Anyone interested in a
+// particular kind of Event registers a handler with onEvent before
+// connectEvents is called.
+
+const eventKinds = [
+    'WorkerCount',
+    'HostAdded',
+    'ProbeSuccess',
+    'XFRFinished',
+    'SubsystemState',
+]
+
+const eventHandlers = {}
+
+let eventSource = null
+
+function onEvent(kind, handler) {
+    if (!defined(eventHandlers[kind])) {
+        eventHandlers[kind] = []
+    }
+
+    eventHandlers[kind].push(handler)
+} // function onEvent(kind, handler)
+
+function connectEvents() {
+    if (defined(eventSource)) {
+        return
+    } else if (!defined(window.EventSource)) {
+        console.log('This browser does not support Server-Sent Events')
+        return
+    }
+
+    eventSource = new EventSource('/events')
+
+    for (const kind of eventKinds) {
+        eventSource.addEventListener(kind, (msg) => {
+            const handlers = eventHandlers[kind]
+
+            if (!defined(handlers)) {
+                return
+            }
+
+            let ev = undefined
+
+            try {
+                ev = JSON.parse(msg.data)
+            } catch (e) {
+                console.log(`Cannot parse ${kind} event: ${e} -- ${msg.data}`)
+                return
+            }
+
+            for (const h of handlers) {
+                h(ev)
+            }
+        })
+    }
+
+    // The browser reconnects on its own, we just make a note of it.
+    eventSource.onerror = () => {
+        console.log('Event stream was interrupted')
+    }
+} // function connectEvents()
+
+//////////////////////////////////////////////////////////////////////////////
+/// Activity feed ////////////////////////////////////////////////////////////
+//////////////////////////////////////////////////////////////////////////////
+
+const feedMaxItems = 50
+
+// Rates are computed over a sliding window of this many milliseconds.
+const rateWindow = 60 * 1000
+
+const rateHistory = {
+    'HostAdded':    [],
+    'ProbeSuccess': [],
+    'XFRFinished':  [],
+}
+
+function describeEvent(kind, ev) {
+    switch (kind) {
+    case 'HostAdded':
+        return `New Host ${ev.name || '(unnamed)'} (${ev.addr}) from ${ev.source}`
+    case 'ProbeSuccess':
+        return `Got one: ${ev.name || ev.addr}:${ev.port} -- ${ev.response || ''}`
+    case 'XFRFinished':
+        return ev.success
+            ? `Zone transfer of ${ev.zone} succeeded, ${ev.count || 0} records`
+            : `Zone transfer of ${ev.zone} failed`
+    case 'SubsystemState':
+        return `${ev.subsystem} was ${ev.active ? 'started' : 'stopped'}`
+    default:
+        return kind
+    }
+} // function describeEvent(kind, ev)
+
+function appendFeed(kind, ev) {
+    const feed = $('#activity_feed')
+
+    if (feed.length === 0) {
+        return
+    }
+
+    const item = $('<li></li>')
+    const stamp = $('<time></time>').text(timeStampString(new Date(ev.timestamp)))
+
+    item.addClass(`feed-${kind}`)
+    item.append(stamp)
+    item.append(document.createTextNode(' ' + describeEvent(kind, ev)))
+    feed.prepend(item)
+
+    feed.children().slice(feedMaxItems).remove()
+} // function appendFeed(kind, ev)
+
+function updateRates() {
+    const cutoff = Date.now() - rateWindow
+
+    for (const [kind, stamps] of Object.entries(rateHistory)) {
+        while (stamps.length > 0 && stamps[0] < cutoff) {
+            stamps.shift()
+        }
+
+        $(`#rate_${kind}`).text(stamps.length)
+    }
+} // function updateRates()
+
+function initActivityFeed() {
+    for (const kind of ['HostAdded', 'ProbeSuccess', 'XFRFinished', 'SubsystemState']) {
+        onEvent(kind, (ev) => {
+            appendFeed(kind, ev)
+
+            if (defined(rateHistory[kind])) {
+                rateHistory[kind].push(Date.now())
+            }
+        })
+    }
+
+    onEvent('WorkerCount', (ev) => {
+        $(`.sub-workers[data-subsystem="${ev.subsystem}"]`).text(ev.count || 0)
+    })
+
+    onEvent('SubsystemState', (ev) => {
+        $(`.sub-active[data-subsystem="${ev.subsystem}"]`).text(ev.active ? 'true' : 'false')
+    })
+
+    window.setInterval(updateRates, 2000)
+} // function initActivityFeed()
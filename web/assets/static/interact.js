@@ -1,4 +1,4 @@
-// Time-stamp: <2026-01-30 14:54:20 krylon>
+// Time-stamp: <2026-10-20 13:29:40 krylon>
 // -*- mode: javascript; coding: utf-8; -*-
 // Copyright 2015-2020 Benjamin Walkenhorst <krylon@gmx.net>
 //
@@ -57,6 +57,26 @@ function fmtDuration (seconds) {
     }
 } // function fmtDuration(seconds)
 
+// initCSRF makes jQuery send the CSRF token of our session along with every
+// request that changes something on the server.
+function initCSRF () {
+    const meta = $('meta[name="csrf-token"]')[0]
+
+    if (!defined(meta)) {
+        return
+    }
+
+    const token = meta.content
+
+    $.ajaxSetup({
+        beforeSend: function (xhr, settings) {
+            if (settings.type !== 'GET' && settings.type !== 'HEAD') {
+                xhr.setRequestHeader('X-CSRF-Token', token)
+            }
+        }
+    })
+} // function initCSRF()
+
 function beaconLoop () {
     try {
         if (settings.beacon.active) {
@@ -0,0 +1,383 @@
+// /home/krylon/go/src/github.com/blicero/guangng/web/auth.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 12:40:03 krylon>
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/blicero/guangng/auth"
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/model"
+)
+
+const (
+	sessionCookie   = "guangng_session"
+	sessionLifetime = time.Hour * 24 * 7
+	csrfHeader      = "X-CSRF-Token"
+	csrfField       = "csrf_token"
+)
+
+// dummyHash is compared against when a login attempt names a User that does
+// not exist, so the response time does not tell whether a User exists.
+var dummyHash, _ = auth.HashPassword("there is no such user")
+
+type ctxKey int
+
+const ctxKeyAuth ctxKey = iota
+
+// authInfo describes who is making a request. Requests authenticated by an
+// API token have no Session.
+type authInfo struct {
+	User    *model.User
+	Session *model.Session
+	Token   *model.APIToken
+}
+
+// getAuth returns the authInfo the auth middleware attached to a request.
+func getAuth(r *http.Request) *authInfo {
+	var info, _ = r.Context().Value(ctxKeyAuth).(*authInfo)
+
+	return info
+} // func getAuth(r *http.Request) *authInfo
+
+// isPublic returns true for the paths that can be accessed without logging
+// in.
+func isPublic(path string) bool {
+	return path == "/login" ||
+		path == "/favicon.ico" ||
+		strings.HasPrefix(path, "/static/")
+} // func isPublic(path string) bool
+
+// wantsHTML returns true if a request was (probably) made by a browser
+// navigating to a page, as opposed to a script or an AJAX call.
+func wantsHTML(r *http.Request) bool {
+	return r.Method == http.MethodGet &&
+		r.Header.Get("Authorization") == "" &&
+		!strings.HasPrefix(r.URL.Path, "/ajax/") &&
+		r.URL.Path != "/metrics" &&
+		r.URL.Path != "/events"
+} // func wantsHTML(r *http.Request) bool
+
+// authenticate figures out which User a request was made by, if any. An API
+// token passed as a Bearer token takes precedence over the session cookie.
+func (srv *Server) authenticate(r *http.Request) (*authInfo, error) {
+	var (
+		err    error
+		cookie *http.Cookie
+		db     *database.Database
+		info   = new(authInfo)
+	)
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if hdr := r.Header.Get("Authorization"); hdr != "" {
+		var tok, ok = strings.CutPrefix(hdr, "Bearer ")
+
+		if !ok || tok == "" {
+			return nil, nil
+		} else if info.Token, err = db.TokenGetByHash(auth.HashToken(tok)); err != nil || info.Token == nil {
+			return nil, err
+		} else if err = db.TokenTouch(info.Token); err != nil {
+			srv.log.Printf("[ERROR] Cannot record use of API token %s: %s\n",
+				info.Token.Name,
+				err.Error())
+		}
+
+		info.User = info.Token.User
+		return info, nil
+	}
+
+	if cookie, err = r.Cookie(sessionCookie); err != nil || cookie.Value == "" {
+		return nil, nil
+	} else if info.Session, err = db.SessionGetByToken(auth.HashToken(cookie.Value)); err != nil || info.Session == nil {
+		return nil, err
+	}
+
+	info.User = info.Session.User
+	return info, nil
+} // func (srv *Server) authenticate(r *http.Request) (*authInfo, error)
+
+// authMiddleware makes sure all requests except those for public resources
+// come from an authenticated User.
+func (srv *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isPublic(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var (
+			err  error
+			info *authInfo
+		)
+
+		if info, err = srv.authenticate(r); err != nil {
+			srv.sendErrorMessage(w,
+				fmt.Sprintf("Failed to authenticate request: %s", err.Error()))
+			return
+		} else if info == nil {
+			srv.log.Printf("[INFO] Unauthenticated request for %s from %s\n",
+				r.URL.EscapedPath(),
+				r.RemoteAddr)
+			if wantsHTML(r) {
+				var dst = "/login?next=" + url.QueryEscape(r.URL.RequestURI())
+				http.Redirect(w, r, dst, http.StatusSeeOther)
+			} else {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="guangng"`)
+				http.Error(w, "Authentication required", http.StatusUnauthorized)
+			}
+			return
+		}
+
+		var ctx = context.WithValue(r.Context(), ctxKeyAuth, info)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+} // func (srv *Server) authMiddleware(next http.Handler) http.Handler
+
+// checkCSRF verifies the CSRF token of a state-changing request. Requests
+// authenticated by an API token are not subject to CSRF, since browsers
+// do not send those on their own.
+func checkCSRF(r *http.Request, info *authInfo) error {
+	if info.Session == nil {
+		return nil
+	}
+
+	var tok = r.Header.Get(csrfHeader)
+
+	if tok == "" {
+		tok = r.PostFormValue(csrfField)
+	}
+
+	return auth.CheckCSRF(info.Session.CSRF, tok)
+} // func checkCSRF(r *http.Request, info *authInfo) error
+
+// requireOperator wraps a handler that changes the state of the application.
+// It only lets through POST requests by Users with the Operator role and a
+// valid CSRF token.
+func (srv *Server) requireOperator(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err  error
+			info = getAuth(r)
+		)
+
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		} else if info == nil || !info.User.Role.CanOperate() {
+			srv.log.Printf("[INFO] Refusing %s to %s: not an operator\n",
+				r.URL.EscapedPath(),
+				r.RemoteAddr)
+			http.Error(w, "Operator role required", http.StatusForbidden)
+			return
+		} else if err = checkCSRF(r, info); err != nil {
+			srv.log.Printf("[INFO] Refusing %s to %s (%s): %s\n",
+				r.URL.EscapedPath(),
+				info.User.Name,
+				r.RemoteAddr,
+				err.Error())
+			http.Error(w, "Invalid CSRF token", http.StatusForbidden)
+			return
+		}
+
+		h(w, r)
+	}
+} // func (srv *Server) requireOperator(h http.HandlerFunc) http.HandlerFunc
+
+// safeRedirect returns the target to send a User to after logging in. Only
+// local paths are allowed, so the login form cannot be abused to send
+// people elsewhere.
+func safeRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") ||
+		strings.HasPrefix(next, "//") ||
+		strings.HasPrefix(next, "/\\") {
+		return "/main"
+	}
+
+	return next
+} // func safeRedirect(next string) string
+
+func (srv *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle %s request for %s\n",
+		r.Method,
+		r.URL.EscapedPath())
+
+	const tmplName = "login"
+
+	var (
+		err  error
+		msg  string
+		tmpl *template.Template
+		data = tmplDataLogin{
+			tmplDataBase: tmplDataBase{
+				Title: "Login",
+				URL:   r.URL.String(),
+			},
+			Next: safeRedirect(r.FormValue("next")),
+		}
+	)
+
+	if r.Method == http.MethodPost {
+		var (
+			name = r.PostFormValue("name")
+			pw   = r.PostFormValue("password")
+		)
+
+		if data.Error, err = srv.login(w, r, name, pw); err != nil {
+			srv.sendErrorMessage(w, err.Error())
+			return
+		} else if data.Error == "" {
+			http.Redirect(w, r, data.Next, http.StatusSeeOther)
+			return
+		}
+
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+
+	if tmpl = srv.tmpl.Lookup(tmplName); tmpl == nil {
+		msg = fmt.Sprintf("Could not find template %q", tmplName)
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	w.Header().Set("Cache-Control", noCache)
+	if err = tmpl.Execute(w, &data); err != nil {
+		msg = fmt.Sprintf("Error rendering template %q: %s",
+			tmplName,
+			err.Error())
+		srv.sendErrorMessage(w, msg)
+	}
+} // func (srv *Server) handleLogin(w http.ResponseWriter, r *http.Request)
+
+// login checks a User's credentials and, if they are valid, creates a new
+// Session and sets the session cookie. If the credentials are not valid,
+// it returns a message to display to the User.
+func (srv *Server) login(w http.ResponseWriter, r *http.Request, name, pw string) (string, error) {
+	const badLogin = "Invalid user name or password"
+	var (
+		err        error
+		user       *model.User
+		db         *database.Database
+		token, csf string
+		sess       *model.Session
+	)
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if user, err = db.UserGetByName(name); err != nil {
+		return "", err
+	} else if user == nil {
+		auth.CheckPassword(dummyHash, pw)
+		srv.log.Printf("[INFO] Failed login from %s: unknown user %q\n",
+			r.RemoteAddr,
+			name)
+		return badLogin, nil
+	} else if !auth.CheckPassword(user.PwHash, pw) {
+		srv.log.Printf("[INFO] Failed login from %s: wrong password for %s\n",
+			r.RemoteAddr,
+			name)
+		return badLogin, nil
+	}
+
+	if token, _, err = auth.NewToken(); err != nil {
+		return "", err
+	} else if csf, _, err = auth.NewToken(); err != nil {
+		return "", err
+	}
+
+	sess = &model.Session{
+		User:      user,
+		TokenHash: auth.HashToken(token),
+		CSRF:      csf,
+		Expires:   time.Now().Add(sessionLifetime),
+	}
+
+	if _, err = db.SessionPurge(); err != nil {
+		srv.log.Printf("[ERROR] Cannot purge expired sessions: %s\n",
+			err.Error())
+	}
+
+	if err = db.SessionAdd(sess); err != nil {
+		return "", err
+	} else if err = db.UserSetLastLogin(user); err != nil {
+		srv.log.Printf("[ERROR] Cannot record login of %s: %s\n",
+			user.Name,
+			err.Error())
+	}
+
+	srv.log.Printf("[INFO] User %s logged in from %s\n",
+		user.Name,
+		r.RemoteAddr)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  sess.Expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return "", nil
+} // func (srv *Server) login(w http.ResponseWriter, r *http.Request, name, pw string) (string, error)
+
+func (srv *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s\n",
+		r.URL.EscapedPath())
+
+	var (
+		err  error
+		db   *database.Database
+		info = getAuth(r)
+	)
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	} else if info.Session == nil {
+		http.Error(w, "Not logged in with a session", http.StatusBadRequest)
+		return
+	} else if err = checkCSRF(r, info); err != nil {
+		http.Error(w, "Invalid CSRF token", http.StatusForbidden)
+		return
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if err = db.SessionDelete(info.Session); err != nil {
+		srv.sendErrorMessage(w,
+			fmt.Sprintf("Cannot delete session: %s", err.Error()))
+		return
+	}
+
+	srv.log.Printf("[INFO] User %s logged out\n", info.User.Name)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+} // func (srv *Server) handleLogout(w http.ResponseWriter, r *http.Request)
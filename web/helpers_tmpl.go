@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 12. 12. 2018 by Benjamin Walkenhorst
 // (c) 2018 Benjamin Walkenhorst
-// Time-stamp: <2026-01-30 19:14:03 krylon>
+// Time-stamp: <2026-10-20 20:20:11 krylon>
 
 package web
 
@@ -39,6 +39,7 @@ var funcmap = template.FuncMap{
 	"fmt_time":         formatTime,
 	"fmt_time_minute":  formatTimeMinute,
 	"fmt_float":        formatFloat,
+	"percent":          percent,
 	"current_year":     currentYear,
 	"minutes":          minutes,
 	"lower":            lower,
@@ -177,6 +178,11 @@ func formatFloat(f float64) string {
 	return fmt.Sprintf("%.1f", f)
 } // func formatFloat(f float64) string
 
+// percent formats a fraction between 0 and 1 as a percentage.
+func percent(f float64) string {
+	return fmt.Sprintf("%.0f%%", f*100)
+} // func percent(f float64) string
+
 func currentYear() string {
 	var year = time.Now().Year()
 	return strconv.Itoa(year)
@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 04. 09. 2019 by Benjamin Walkenhorst
 // (c) 2019 Benjamin Walkenhorst
-// Time-stamp: <2026-01-26 16:33:23 krylon>
+// Time-stamp: <2026-10-19 14:28:08 krylon>
 //
 // Helper functions for use by the HTTP request handlers
 
@@ -11,9 +11,16 @@ package web
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/events"
+	"github.com/blicero/guangng/model/hsrc"
+	"github.com/blicero/guangng/model/subsystem"
 )
 
 func errJSON(msg string) []byte { // nolint: unused,deadcode
@@ -32,6 +39,22 @@ func jsonEscape(i string) string { // nolint: unused
 	return string(b[1 : len(b)-1])
 }
 
+// writeEvent sends an Event in the format of Server-Sent Events.
+// The Kind of the Event becomes the event type, the payload is JSON.
+func writeEvent(w io.Writer, ev *events.Event) error {
+	var (
+		err  error
+		data []byte
+	)
+
+	if data, err = json.Marshal(ev); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Kind, data)
+	return err
+} // func writeEvent(w io.Writer, ev *events.Event) error
+
 // func getMimeType(path string) (string, error) {
 // 	var (
 // 		fh      *os.File
@@ -56,10 +79,90 @@ func jsonEscape(i string) string { // nolint: unused
 // }
 // func getMimeType(path string) (string, error)
 
-func (srv *Server) baseData(title string, r *http.Request) tmplDataBase { // nolint: unused
-	return tmplDataBase{
-		Title: title,
-		Debug: common.Debug,
-		URL:   r.URL.String(),
+// baseData fills in the data every page needs: the state of the
+// subsystems and the User the page is rendered for.
+func (srv *Server) baseData(title string, r *http.Request) tmplDataBase {
+	var data = tmplDataBase{
+		Title:      title,
+		Debug:      common.Debug,
+		URL:        r.URL.String(),
+		Subsystems: subsystem.AllSubsystems(),
 	}
+
+	if srv.nx != nil {
+		data.GenActive = srv.nx.GetActiveFlag(subsystem.Generator)
+		data.XFRActive = srv.nx.GetActiveFlag(subsystem.XFR)
+		data.ScanActive = srv.nx.GetActiveFlag(subsystem.Scanner)
+		data.GeoActive = srv.nx.GetActiveFlag(subsystem.Geo)
+		data.GeoEnabled = srv.nx.GeoEnabled()
+		data.GenAddrCnt = srv.nx.GetWorkerCount(subsystem.GeneratorAddress)
+		data.GenNameCnt = srv.nx.GetWorkerCount(subsystem.GeneratorName)
+		data.XFRCnt = srv.nx.GetWorkerCount(subsystem.XFR)
+		data.ScanCnt = srv.nx.GetWorkerCount(subsystem.Scanner)
+		data.GeoCnt = srv.nx.GetWorkerCount(subsystem.Geo)
+
+		if cs, ok := srv.nx.CacheStats(); ok {
+			data.Cache = &cs
+		}
+	}
+
+	if info := getAuth(r); info != nil {
+		data.User = info.User
+		if info.Session != nil {
+			data.CSRFToken = info.Session.CSRF
+		}
+	}
+
+	return data
 } // func (srv *Server) baseData(title string, r *http.Request) tmplDataBase
+
+// parseHostFilter extracts a HostFilter from the query parameters of a
+// request. The host list and the export handlers share it, so both always
+// agree on which Hosts a given URL refers to.
+func parseHostFilter(r *http.Request, defaultLimit int) (*database.HostFilter, error) {
+	var (
+		err    error
+		num    int64
+		params = r.URL.Query()
+		filter = &database.HostFilter{
+			Name:    params.Get("name"),
+			Country: strings.ToUpper(strings.TrimSpace(params.Get("country"))),
+			Limit:   defaultLimit,
+		}
+	)
+
+	if s := params.Get("source"); s != "" {
+		if num, err = strconv.ParseInt(s, 10, 8); err != nil {
+			return nil, fmt.Errorf("cannot parse source %q: %w", s, err)
+		}
+		filter.Source = hsrc.HostSource(num)
+	}
+
+	if s := params.Get("port"); s != "" {
+		if num, err = strconv.ParseInt(s, 10, 32); err != nil || num < 0 || num > 65535 {
+			return nil, fmt.Errorf("invalid port number %q", s)
+		}
+		filter.Port = uint16(num)
+	}
+
+	if s := params.Get("asn"); s != "" {
+		// Accept "AS1234" as well as "1234".
+		var asn uint64
+
+		if asn, err = strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(s), "AS"), 10, 32); err != nil {
+			return nil, fmt.Errorf("invalid AS number %q", s)
+		}
+		filter.ASN = uint32(asn)
+	}
+
+	if s := params.Get("limit"); s != "" {
+		if num, err = strconv.ParseInt(s, 10, 32); err != nil {
+			return nil, fmt.Errorf("cannot parse limit %q: %w", s, err)
+		} else if num < 1 {
+			num = -1
+		}
+		filter.Limit = int(num)
+	}
+
+	return filter, nil
+} // func parseHostFilter(r *http.Request, defaultLimit int) (*database.HostFilter, error)
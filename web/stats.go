@@ -0,0 +1,433 @@
+// /home/krylon/go/src/github.com/blicero/guangng/web/stats.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-21 11:55:23 krylon>
+
+package web
+
+import (
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/blicero/guangng/common"
+	"github.com/blicero/guangng/database"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// statsTopPorts is the number of ports we list per country.
+	statsTopPorts = 10
+	// statsTopNets is the number of /16s and /24s we list in the yield
+	// statistics.
+	statsTopNets = 250
+)
+
+// statColumn describes one column of a statistics table. Numeric columns
+// are sorted by number rather than alphabetically.
+type statColumn struct {
+	Key     string
+	Title   string
+	Numeric bool
+}
+
+// statCell is one value in a statistics table. Text is what we display
+// and export, Num is what we sort numeric columns by.
+type statCell struct {
+	Text string
+	Num  int64
+	Link string
+}
+
+func textCell(s, link string) statCell {
+	return statCell{Text: s, Link: link}
+} // func textCell(s, link string) statCell
+
+func numCell(n int64, link string) statCell {
+	return statCell{Text: strconv.FormatInt(n, 10), Num: n, Link: link}
+} // func numCell(n int64, link string) statCell
+
+func pctCell(f float64) statCell {
+	return statCell{Text: fmt.Sprintf("%.1f%%", f*100), Num: int64(f * 1e6)}
+} // func pctCell(f float64) statCell
+
+// statView is one of the statistics pages.
+type statView struct {
+	Name    string
+	Title   string
+	Columns []statColumn
+	load    func(db *database.Database) ([][]statCell, error)
+}
+
+// column returns the index of the column with the given key, or -1.
+func (v *statView) column(key string) int {
+	return slices.IndexFunc(v.Columns, func(c statColumn) bool { return c.Key == key })
+} // func (v *statView) column(key string) int
+
+var statViews = []*statView{
+	{
+		Name:  "country",
+		Title: "Countries",
+		Columns: []statColumn{
+			{Key: "code", Title: "Code"},
+			{Key: "country", Title: "Country"},
+			{Key: "hosts", Title: "# Hosts", Numeric: true},
+			{Key: "services", Title: "# Services", Numeric: true},
+		},
+		load: loadStatsCountry,
+	},
+	{
+		Name:  "city",
+		Title: "Cities",
+		Columns: []statColumn{
+			{Key: "code", Title: "Country"},
+			{Key: "city", Title: "City"},
+			{Key: "hosts", Title: "# Hosts", Numeric: true},
+			{Key: "services", Title: "# Services", Numeric: true},
+		},
+		load: loadStatsCity,
+	},
+	{
+		Name:  "ports",
+		Title: "Top ports",
+		Columns: []statColumn{
+			{Key: "code", Title: "Country"},
+			{Key: "port", Title: "Port", Numeric: true},
+			{Key: "services", Title: "# Services", Numeric: true},
+		},
+		load: loadStatsPorts,
+	},
+	{
+		Name:  "source",
+		Title: "Sources",
+		Columns: []statColumn{
+			{Key: "code", Title: "Country"},
+			{Key: "source", Title: "Source"},
+			{Key: "hosts", Title: "# Hosts", Numeric: true},
+		},
+		load: loadStatsSource,
+	},
+	{
+		Name:  "asn",
+		Title: "Networks",
+		Columns: []statColumn{
+			{Key: "asn", Title: "AS", Numeric: true},
+			{Key: "org", Title: "Organization"},
+			{Key: "hosts", Title: "# Hosts", Numeric: true},
+			{Key: "services", Title: "# Services", Numeric: true},
+		},
+		load: loadStatsASN,
+	},
+	{
+		Name:  "yield",
+		Title: "Generator yield",
+		Columns: []statColumn{
+			{Key: "net", Title: "Network", Numeric: true},
+			{Key: "attempts", Title: "# Addresses", Numeric: true},
+			{Key: "hits", Title: "# Hosts", Numeric: true},
+			{Key: "blocked", Title: "# Blacklisted", Numeric: true},
+			{Key: "yield", Title: "Yield", Numeric: true},
+		},
+		load: loadStatsYield,
+	},
+}
+
+func loadStatsCountry(db *database.Database) ([][]statCell, error) {
+	var list, err = db.StatsByCountry()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rows = make([][]statCell, len(list))
+
+	for i, c := range list {
+		rows[i] = []statCell{
+			textCell(c.CountryCode, ""),
+			textCell(c.Country, ""),
+			numCell(c.HostCnt, "/hosts?country="+c.CountryCode),
+			numCell(c.SvcCnt, ""),
+		}
+	}
+
+	return rows, nil
+} // func loadStatsCountry(db *database.Database) ([][]statCell, error)
+
+func loadStatsCity(db *database.Database) ([][]statCell, error) {
+	var list, err = db.StatsByCity()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rows = make([][]statCell, len(list))
+
+	for i, c := range list {
+		rows[i] = []statCell{
+			textCell(c.CountryCode, "/hosts?country="+c.CountryCode),
+			textCell(c.City, ""),
+			numCell(c.HostCnt, ""),
+			numCell(c.SvcCnt, ""),
+		}
+	}
+
+	return rows, nil
+} // func loadStatsCity(db *database.Database) ([][]statCell, error)
+
+func loadStatsPorts(db *database.Database) ([][]statCell, error) {
+	var list, err = db.StatsTopPorts(statsTopPorts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rows = make([][]statCell, len(list))
+
+	for i, p := range list {
+		rows[i] = []statCell{
+			textCell(p.CountryCode, "/hosts?country="+p.CountryCode),
+			numCell(int64(p.Port), ""),
+			numCell(p.Count, fmt.Sprintf("/hosts?country=%s&port=%d", p.CountryCode, p.Port)),
+		}
+	}
+
+	return rows, nil
+} // func loadStatsPorts(db *database.Database) ([][]statCell, error)
+
+func loadStatsSource(db *database.Database) ([][]statCell, error) {
+	var list, err = db.StatsBySource()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rows = make([][]statCell, len(list))
+
+	for i, s := range list {
+		rows[i] = []statCell{
+			textCell(s.CountryCode, "/hosts?country="+s.CountryCode),
+			textCell(s.Source.String(), ""),
+			numCell(s.HostCnt, fmt.Sprintf("/hosts?country=%s&source=%d", s.CountryCode, s.Source)),
+		}
+	}
+
+	return rows, nil
+} // func loadStatsSource(db *database.Database) ([][]statCell, error)
+
+func loadStatsASN(db *database.Database) ([][]statCell, error) {
+	var list, err = db.ASNGetAll(-1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rows = make([][]statCell, len(list))
+
+	for i, a := range list {
+		rows[i] = []statCell{
+			numCell(int64(a.ASN), fmt.Sprintf("/asn/%d", a.ASN)),
+			textCell(a.Org, ""),
+			numCell(a.HostCnt, fmt.Sprintf("/hosts?asn=%d", a.ASN)),
+			numCell(a.SvcCnt, ""),
+		}
+	}
+
+	return rows, nil
+} // func loadStatsASN(db *database.Database) ([][]statCell, error)
+
+func loadStatsYield(db *database.Database) ([][]statCell, error) {
+	var rows = make([][]statCell, 0, 2*statsTopNets)
+
+	for _, bits := range []uint8{16, 24} {
+		var list, err = db.NetYieldGetTop(bits, statsTopNets)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, y := range list {
+			// Sort /16s before the /24s in them.
+			rows = append(rows, []statCell{
+				{Text: y.Net().String(), Num: int64(y.Prefix)<<8 | int64(y.Bits)},
+				numCell(y.Attempts, ""),
+				numCell(y.Hits, ""),
+				numCell(y.Blocked, ""),
+				pctCell(y.Yield()),
+			})
+		}
+	}
+
+	return rows, nil
+} // func loadStatsYield(db *database.Database) ([][]statCell, error)
+
+// sortStats sorts the rows by the given column. If the key is unknown, the
+// rows stay in the order the Database returned them in.
+func sortStats(v *statView, rows [][]statCell, key string, desc bool) {
+	var idx = v.column(key)
+
+	if idx < 0 {
+		return
+	}
+
+	var numeric = v.Columns[idx].Numeric
+
+	slices.SortStableFunc(rows, func(a, b []statCell) int {
+		var res int
+
+		if numeric {
+			res = cmp.Compare(a[idx].Num, b[idx].Num)
+		} else {
+			res = strings.Compare(a[idx].Text, b[idx].Text)
+		}
+
+		if desc {
+			return -res
+		}
+		return res
+	})
+} // func sortStats(v *statView, rows [][]statCell, key string, desc bool)
+
+// loadStatView looks up the view requested and loads its rows, sorted as
+// the query string asks for.
+func (srv *Server) loadStatView(r *http.Request) (*statView, [][]statCell, error) {
+	var (
+		err    error
+		rows   [][]statCell
+		db     *database.Database
+		name   = mux.Vars(r)["view"]
+		params = r.URL.Query()
+		idx    = slices.IndexFunc(statViews, func(v *statView) bool { return v.Name == name })
+	)
+
+	if idx < 0 {
+		return nil, nil, nil
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if rows, err = statViews[idx].load(db); err != nil {
+		return nil, nil, fmt.Errorf("cannot load statistics %q: %w", name, err)
+	}
+
+	sortStats(statViews[idx], rows, params.Get("sort"), params.Get("desc") != "")
+
+	return statViews[idx], rows, nil
+} // func (srv *Server) loadStatView(r *http.Request) (*statView, [][]statCell, error)
+
+// handleStats shows one of the statistics tables.
+func (srv *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handling request for %s\n", r.RequestURI)
+	const tmplName = "stats"
+
+	var (
+		err    error
+		msg    string
+		tmpl   *template.Template
+		params = r.URL.Query()
+		data   = tmplDataStats{
+			tmplDataBase: srv.baseData("Statistics", r),
+			Views:        statViews,
+			Sort:         params.Get("sort"),
+			Desc:         params.Get("desc") != "",
+		}
+	)
+
+	if tmpl = srv.tmpl.Lookup(tmplName); tmpl == nil {
+		msg = fmt.Sprintf("Could not find template %q", tmplName)
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if data.View, data.Rows, err = srv.loadStatView(r); err != nil {
+		msg = err.Error()
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if data.View == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	data.Title = "Statistics: " + data.View.Title
+
+	w.Header().Set("Cache-Control", noCache)
+	if err = tmpl.Execute(w, &data); err != nil {
+		msg = fmt.Sprintf("Error rendering template %q: %s",
+			tmplName,
+			err.Error())
+		srv.sendErrorMessage(w, msg)
+	}
+} // func (srv *Server) handleStats(w http.ResponseWriter, r *http.Request)
+
+// handleStatsCSV delivers one of the statistics tables as a CSV file,
+// sorted the same way as the page it is linked from.
+func (srv *Server) handleStatsCSV(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handling request for %s\n", r.RequestURI)
+
+	var (
+		err  error
+		view *statView
+		rows [][]statCell
+		out  *csv.Writer
+	)
+
+	if view, rows, err = srv.loadStatView(r); err != nil {
+		srv.log.Printf("[ERROR] %s\n", err.Error())
+		srv.sendErrorMessage(w, err.Error())
+		return
+	} else if view == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	var filename = fmt.Sprintf("%s_stats_%s_%s.csv",
+		strings.ToLower(common.AppName),
+		view.Name,
+		time.Now().Format("20060102_150405"))
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Cache-Control", noCache)
+	w.WriteHeader(200)
+
+	out = csv.NewWriter(w)
+
+	var record = make([]string, len(view.Columns))
+
+	for i, c := range view.Columns {
+		record[i] = c.Title
+	}
+
+	if err = out.Write(record); err != nil {
+		srv.log.Printf("[ERROR] Cannot write CSV header: %s\n", err.Error())
+		return
+	}
+
+	for _, row := range rows {
+		for i, cell := range row {
+			record[i] = cell.Text
+		}
+
+		if err = out.Write(record); err != nil {
+			srv.log.Printf("[ERROR] Cannot write CSV to %s: %s\n",
+				r.RemoteAddr,
+				err.Error())
+			return
+		}
+	}
+
+	out.Flush()
+	if err = out.Error(); err != nil {
+		srv.log.Printf("[ERROR] Cannot write CSV to %s: %s\n",
+			r.RemoteAddr,
+			err.Error())
+	}
+} // func (srv *Server) handleStatsCSV(w http.ResponseWriter, r *http.Request)
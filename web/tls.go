@@ -0,0 +1,289 @@
+// /home/krylon/go/src/github.com/blicero/guangng/web/tls.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 16:21:47 krylon>
+
+package web
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/blicero/guangng/common"
+)
+
+const (
+	// certCheckInterval is how often we look at the certificate files to
+	// see if they have changed.
+	certCheckInterval = time.Second * 30
+	// selfSignedLifetime is how long a self-signed certificate is valid.
+	selfSignedLifetime = time.Hour * 24 * 365
+	// selfSignedRenew is how long before it expires we replace a
+	// self-signed certificate.
+	selfSignedRenew = time.Hour * 24 * 30
+)
+
+// certLoader hands the TLS certificate to the http.Server and reloads it
+// from disk when the files change, so a renewed certificate can be put in
+// place without restarting the application.
+type certLoader struct {
+	log      *log.Logger
+	certFile string
+	keyFile  string
+	auto     bool // Generate a self-signed certificate if needed
+	hosts    []string
+	lock     sync.Mutex
+	cert     *tls.Certificate
+	modTime  time.Time
+	checked  time.Time
+}
+
+func newCertLoader(l *log.Logger, certFile, keyFile string, hosts []string) (*certLoader, error) {
+	var (
+		err error
+		cl  = &certLoader{
+			log:      l,
+			certFile: certFile,
+			keyFile:  keyFile,
+			hosts:    hosts,
+		}
+	)
+
+	if certFile == "" && keyFile == "" {
+		cl.auto = true
+		cl.certFile = common.CertPath
+		cl.keyFile = common.KeyPath
+	} else if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("need both a certificate and a key file, got %q and %q",
+			certFile,
+			keyFile)
+	}
+
+	if err = cl.reload(true); err != nil {
+		return nil, err
+	}
+
+	return cl, nil
+} // func newCertLoader(l *log.Logger, certFile, keyFile string, hosts []string) (*certLoader, error)
+
+// getCertificate is used as the GetCertificate callback of the tls.Config.
+func (cl *certLoader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cl.lock.Lock()
+	defer cl.lock.Unlock()
+
+	if time.Since(cl.checked) >= certCheckInterval {
+		if err := cl.reload(false); err != nil {
+			// Keep using the certificate we have.
+			cl.log.Printf("[ERROR] Cannot reload TLS certificate: %s\n",
+				err.Error())
+		}
+	}
+
+	return cl.cert, nil
+} // func (cl *certLoader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error)
+
+// reload loads the certificate if the files have changed since we last
+// loaded it, or if force is true. In auto mode, it creates a new
+// self-signed certificate if there is none or it is about to expire.
+// The caller must hold the lock, unless it is the constructor.
+func (cl *certLoader) reload(force bool) error {
+	var (
+		err  error
+		info os.FileInfo
+		cert tls.Certificate
+	)
+
+	cl.checked = time.Now()
+
+	if cl.auto && cl.needNewCert() {
+		if err = cl.generate(); err != nil {
+			return err
+		}
+		force = true
+	}
+
+	if info, err = os.Stat(cl.certFile); err != nil {
+		return err
+	} else if !force && !info.ModTime().After(cl.modTime) {
+		return nil
+	} else if cert, err = tls.LoadX509KeyPair(cl.certFile, cl.keyFile); err != nil {
+		return fmt.Errorf("cannot load certificate %s: %w",
+			cl.certFile,
+			err)
+	}
+
+	cl.log.Printf("[INFO] Loaded TLS certificate from %s\n", cl.certFile)
+	cl.cert = &cert
+	cl.modTime = info.ModTime()
+	return nil
+} // func (cl *certLoader) reload(force bool) error
+
+// needNewCert returns true if the self-signed certificate does not exist,
+// cannot be parsed, or expires soon.
+func (cl *certLoader) needNewCert() bool {
+	var (
+		err  error
+		raw  []byte
+		blk  *pem.Block
+		cert *x509.Certificate
+	)
+
+	if raw, err = os.ReadFile(cl.certFile); err != nil {
+		return true
+	} else if blk, _ = pem.Decode(raw); blk == nil {
+		return true
+	} else if cert, err = x509.ParseCertificate(blk.Bytes); err != nil {
+		return true
+	}
+
+	return time.Until(cert.NotAfter) < selfSignedRenew
+} // func (cl *certLoader) needNewCert() bool
+
+// generate creates a self-signed certificate for the given hosts and writes
+// it and its key to disk.
+func (cl *certLoader) generate() error {
+	var (
+		err       error
+		key       *ecdsa.PrivateKey
+		serial    *big.Int
+		der, kder []byte
+		now       = time.Now()
+		tmpl      = x509.Certificate{
+			Subject: pkix.Name{
+				Organization: []string{common.AppName},
+				CommonName:   hostname(),
+			},
+			NotBefore:             now.Add(-time.Hour),
+			NotAfter:              now.Add(selfSignedLifetime),
+			KeyUsage:              x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			BasicConstraintsValid: true,
+		}
+	)
+
+	cl.log.Printf("[INFO] Generating self-signed TLS certificate in %s\n",
+		cl.certFile)
+
+	for _, h := range cl.hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else if h != "" {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+
+	if key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+		return fmt.Errorf("cannot generate key: %w", err)
+	} else if serial, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128)); err != nil {
+		return fmt.Errorf("cannot generate serial number: %w", err)
+	}
+
+	tmpl.SerialNumber = serial
+
+	if der, err = x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key); err != nil {
+		return fmt.Errorf("cannot create certificate: %w", err)
+	} else if kder, err = x509.MarshalPKCS8PrivateKey(key); err != nil {
+		return fmt.Errorf("cannot marshal private key: %w", err)
+	}
+
+	// Write the key first, so we never have a certificate on disk
+	// without its matching key.
+	if err = writePEM(cl.keyFile, "PRIVATE KEY", kder, 0600); err != nil {
+		return err
+	}
+
+	return writePEM(cl.certFile, "CERTIFICATE", der, 0644)
+} // func (cl *certLoader) generate() error
+
+func writePEM(path, kind string, data []byte, mode os.FileMode) error {
+	var (
+		err error
+		fh  *os.File
+		tmp = path + ".tmp"
+	)
+
+	if fh, err = os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode); err != nil {
+		return fmt.Errorf("cannot open %s: %w", tmp, err)
+	} else if err = pem.Encode(fh, &pem.Block{Type: kind, Bytes: data}); err != nil {
+		fh.Close() // nolint: errcheck
+		return fmt.Errorf("cannot write %s: %w", tmp, err)
+	} else if err = fh.Close(); err != nil {
+		return fmt.Errorf("cannot close %s: %w", tmp, err)
+	} else if err = os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("cannot rename %s to %s: %w", tmp, path, err)
+	}
+
+	return nil
+} // func writePEM(path, kind string, data []byte, mode os.FileMode) error
+
+// UseTLS makes the Server speak HTTPS. If certFile and keyFile are empty,
+// it uses a self-signed certificate it keeps in common.BaseDir, creating
+// it if necessary. If redirectAddr is not empty, the Server also listens
+// for plain HTTP on that address and redirects all requests to HTTPS.
+func (srv *Server) UseTLS(certFile, keyFile, redirectAddr string) error {
+	var (
+		err   error
+		cl    *certLoader
+		hosts = []string{"localhost", hostname(), "127.0.0.1", "::1"}
+	)
+
+	if h, _, e := net.SplitHostPort(srv.addr); e == nil && h != "" {
+		hosts = append(hosts, h)
+	}
+
+	if cl, err = newCertLoader(srv.log, certFile, keyFile, hosts); err != nil {
+		srv.log.Printf("[ERROR] Cannot set up TLS: %s\n", err.Error())
+		return err
+	}
+
+	srv.certs = cl
+	srv.web.TLSConfig = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: cl.getCertificate,
+	}
+
+	if redirectAddr != "" {
+		srv.redirect = &http.Server{
+			Addr:     redirectAddr,
+			ErrorLog: srv.log,
+			Handler:  http.HandlerFunc(srv.handleRedirectHTTPS),
+		}
+	}
+
+	return nil
+} // func (srv *Server) UseTLS(certFile, keyFile, redirectAddr string) error
+
+// handleRedirectHTTPS sends clients that talk plain HTTP to the HTTPS
+// address of the Server.
+func (srv *Server) handleRedirectHTTPS(w http.ResponseWriter, r *http.Request) {
+	var (
+		host, port, _ = net.SplitHostPort(srv.addr)
+		reqHost       = r.Host
+	)
+
+	if h, _, err := net.SplitHostPort(reqHost); err == nil {
+		reqHost = h
+	}
+
+	if reqHost == "" {
+		reqHost = host
+	}
+
+	var target = "https://" + net.JoinHostPort(reqHost, port) + r.URL.RequestURI()
+
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+} // func (srv *Server) handleRedirectHTTPS(w http.ResponseWriter, r *http.Request)
@@ -2,14 +2,18 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 06. 05. 2020 by Benjamin Walkenhorst
 // (c) 2020 Benjamin Walkenhorst
-// Time-stamp: <2026-02-10 19:00:33 krylon>
+// Time-stamp: <2026-10-19 15:02:25 krylon>
 //
 // This file contains data structures to be passed to HTML templates.
 
 package web
 
 import (
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/export"
 	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/hsrc"
+	"github.com/blicero/guangng/model/outcome"
 	"github.com/blicero/guangng/model/subsystem"
 )
 
@@ -21,15 +25,26 @@ type tmplDataBase struct { // nolint: unused
 	GenActive  bool
 	XFRActive  bool
 	ScanActive bool
+	GeoActive  bool
+	GeoEnabled bool
 	GenAddrCnt int
 	GenNameCnt int
 	XFRCnt     int
 	ScanCnt    int
+	GeoCnt     int
 	HostCnt    int64
 	ZoneCnt    int64
 	PortCnt    int64
+	Cache      *model.CacheStats
+	User       *model.User
+	CSRFToken  string
 }
 
+// CanOperate returns true if the current User may start and stop workers.
+func (d *tmplDataBase) CanOperate() bool {
+	return d.User != nil && d.User.Role.CanOperate()
+} // func (d *tmplDataBase) CanOperate() bool
+
 // HostGenCnt returns the total number of workers in the Generator subsystem.
 func (d *tmplDataBase) HostGenCnt() int {
 	return d.GenAddrCnt + d.GenNameCnt
@@ -37,6 +52,8 @@ func (d *tmplDataBase) HostGenCnt() int {
 
 type tmplDataIndex struct { // nolint: unused,deadcode
 	tmplDataBase
+	Resolvers []model.ResolverStats
+	Outcomes  []outcome.Outcome
 }
 
 type tmplDataByPort struct {
@@ -55,3 +72,55 @@ func (d *tmplDataByPort) TotalResponses() int64 {
 
 	return int64(cnt)
 } // func (d *tmplDataByPort) TotalResponses() int64
+
+type tmplDataHosts struct {
+	tmplDataBase
+	Hosts   []*model.Host
+	Filter  *database.HostFilter
+	Query   string
+	Sources []hsrc.HostSource
+	Formats []export.Format
+}
+
+type tmplDataASNList struct {
+	tmplDataBase
+	Networks []*model.ASNInfo
+}
+
+type tmplDataASN struct {
+	tmplDataBase
+	Info     *model.ASNInfo
+	Products []*model.ProductCount
+	Hosts    []*model.Host
+}
+
+type tmplDataZones struct {
+	tmplDataBase
+	Open   []*model.XFRResult
+	Recent []*model.XFRResult
+}
+
+type tmplDataZone struct {
+	tmplDataBase
+	Zone      *model.Zone
+	Servers   []*model.XFRResult
+	Changes   []*model.ZoneRecord
+	Records   []*model.ZoneRecord
+	RecordCnt int
+	Mail      *model.MailPolicy
+}
+
+type tmplDataStats struct {
+	tmplDataBase
+	Views []*statView
+	View  *statView
+	Rows  [][]statCell
+	Sort  string
+	Desc  bool
+}
+
+type tmplDataLogin struct {
+	tmplDataBase
+	Next  string
+	Error string
+}
@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 26. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-02-09 14:44:04 krylon>
+// Time-stamp: <2026-10-19 15:02:25 krylon>
 
 // Package web provides a web-based UI.
 package web
@@ -15,10 +15,13 @@ import (
 	"io"
 	"io/fs"
 	"log"
+	"maps"
 	"net/http"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"text/template"
@@ -26,7 +29,12 @@ import (
 
 	"github.com/blicero/guangng/common"
 	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/events"
+	"github.com/blicero/guangng/export"
 	"github.com/blicero/guangng/logdomain"
+	"github.com/blicero/guangng/metrics"
+	"github.com/blicero/guangng/model/hsrc"
+	"github.com/blicero/guangng/model/outcome"
 	"github.com/blicero/guangng/model/subsystem"
 	"github.com/blicero/guangng/nexus"
 	"github.com/gorilla/mux"
@@ -53,6 +61,8 @@ type Server struct {
 	web       http.Server
 	mimeTypes map[string]string
 	nx        *nexus.Nexus
+	certs     *certLoader
+	redirect  *http.Server
 }
 
 // Create returns a new web Server.
@@ -86,6 +96,8 @@ func Create(addr string, nx *nexus.Nexus) (*Server, error) {
 		return nil, err
 	}
 
+	srv.checkUsers()
+
 	var templates []fs.DirEntry
 	var tmplRe = regexp.MustCompile("[.]tmpl$")
 
@@ -127,11 +139,27 @@ func Create(addr string, nx *nexus.Nexus) (*Server, error) {
 	srv.web.ErrorLog = srv.log
 	srv.web.Handler = srv.router
 
+	srv.router.Use(srv.authMiddleware)
+
 	// Register URL handlers
+	srv.router.HandleFunc("/login", srv.handleLogin).Methods(http.MethodGet, http.MethodPost)
+	srv.router.HandleFunc("/logout", srv.handleLogout)
 	srv.router.HandleFunc("/favicon.ico", srv.handleFavIco)
 	srv.router.HandleFunc("/static/{file}", srv.handleStaticFile)
 	srv.router.HandleFunc("/{index:(?i:index|main|start)$}", srv.handleMain)
 	srv.router.HandleFunc("/by_port", srv.handleByPort)
+	srv.router.HandleFunc("/hosts", srv.handleHosts)
+	srv.router.HandleFunc("/asn", srv.handleASNList)
+	srv.router.HandleFunc("/asn/{asn:[0-9]+}", srv.handleASNDetails)
+	srv.router.HandleFunc("/zones", srv.handleZones)
+	srv.router.HandleFunc("/zone/{id:[0-9]+}", srv.handleZoneDetails)
+	srv.router.HandleFunc("/zone/{id:[0-9]+}/file", srv.handleZoneFile)
+	srv.router.HandleFunc("/stats/{view:[a-z]+}", srv.handleStats)
+	srv.router.HandleFunc("/stats/{view:[a-z]+}/csv", srv.handleStatsCSV)
+	srv.router.HandleFunc("/export/{format:(?:xml|jsonl|csv)$}", srv.handleExport)
+	srv.router.HandleFunc("/export/mail/{format:(?:jsonl|csv)$}", srv.handleExportMail)
+	srv.router.HandleFunc("/metrics", srv.handleMetrics)
+	srv.router.HandleFunc("/events", srv.handleEvents)
 
 	// AJAX Handlers
 	srv.router.HandleFunc(
@@ -139,10 +167,14 @@ func Create(addr string, nx *nexus.Nexus) (*Server, error) {
 		srv.handleLoadWorkerCount)
 	srv.router.HandleFunc(
 		"/ajax/spawn_worker/{subsys:(?:\\d+)}/{cnt:(?:\\d+)$}",
-		srv.handleSpawnWorker)
+		srv.requireOperator(srv.handleSpawnWorker))
 	srv.router.HandleFunc(
 		"/ajax/stop_worker/{subsys:(?:\\d+)}/{cnt:(?:\\d+)$}",
-		srv.handleStopWorker)
+		srv.requireOperator(srv.handleStopWorker))
+
+	srv.router.HandleFunc(
+		"/ajax/cache_reset",
+		srv.requireOperator(srv.handleCacheReset))
 
 	srv.router.HandleFunc(
 		"/ajax/beacon",
@@ -151,6 +183,23 @@ func Create(addr string, nx *nexus.Nexus) (*Server, error) {
 	return srv, nil
 } // func Create(addr string, nx *nexus.Nexus) (*Server, error)
 
+// checkUsers warns if there are no Users, because then nobody can log in.
+func (srv *Server) checkUsers() {
+	var (
+		err error
+		cnt int64
+		db  = srv.pool.Get()
+	)
+
+	defer srv.pool.Put(db)
+
+	if cnt, err = db.UserGetCnt(); err != nil {
+		srv.log.Printf("[ERROR] Cannot count Users: %s\n", err.Error())
+	} else if cnt == 0 {
+		srv.log.Println("[WARN] There are no Users, nobody can log into the web interface. Use the \"user add\" subcommand to create one.")
+	}
+} // func (srv *Server) checkUsers()
+
 // IsActive returns the Server's active flag.
 func (srv *Server) IsActive() bool {
 	return srv.active.Load()
@@ -171,10 +220,19 @@ func (srv *Server) Run() {
 	srv.active.Store(true)
 	defer srv.active.Store(false)
 
-	srv.log.Printf("[INFO] Web frontend is going online at %s\n", srv.addr)
-	http.Handle("/", srv.router)
+	if srv.certs == nil {
+		srv.log.Printf("[INFO] Web frontend is going online at http://%s\n", srv.addr)
+		err = srv.web.ListenAndServe()
+	} else {
+		if srv.redirect != nil {
+			go srv.runRedirect()
+		}
+
+		srv.log.Printf("[INFO] Web frontend is going online at https://%s\n", srv.addr)
+		err = srv.web.ListenAndServeTLS("", "")
+	}
 
-	if err = srv.web.ListenAndServe(); err != nil {
+	if err != nil {
 		if err.Error() != "http: Server closed" {
 			srv.log.Printf("[ERROR] ListenAndServe returned an error: %s\n",
 				err.Error())
@@ -184,6 +242,19 @@ func (srv *Server) Run() {
 	}
 } // func (srv *Server) Run()
 
+// runRedirect runs the plain HTTP listener that redirects clients to HTTPS.
+func (srv *Server) runRedirect() {
+	var err error
+
+	srv.log.Printf("[INFO] Redirecting HTTP requests on %s to HTTPS\n",
+		srv.redirect.Addr)
+
+	if err = srv.redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		srv.log.Printf("[ERROR] HTTP redirect listener failed: %s\n",
+			err.Error())
+	}
+} // func (srv *Server) runRedirect()
+
 //////////////////////////////////////////////////////////////////////////////
 /// Handle requests //////////////////////////////////////////////////////////
 //////////////////////////////////////////////////////////////////////////////
@@ -200,19 +271,8 @@ func (srv *Server) handleMain(w http.ResponseWriter, req *http.Request) {
 		db   *database.Database
 		tmpl *template.Template
 		data = tmplDataIndex{
-			tmplDataBase: tmplDataBase{
-				Title:      "Main",
-				Debug:      common.Debug,
-				URL:        req.URL.String(),
-				Subsystems: subsystem.AllSubsystems(),
-				GenActive:  srv.nx.GetActiveFlag(subsystem.Generator),
-				XFRActive:  srv.nx.GetActiveFlag(subsystem.XFR),
-				ScanActive: srv.nx.GetActiveFlag(subsystem.Scanner),
-				GenAddrCnt: srv.nx.GetWorkerCount(subsystem.GeneratorAddress),
-				GenNameCnt: srv.nx.GetWorkerCount(subsystem.GeneratorName),
-				XFRCnt:     srv.nx.GetWorkerCount(subsystem.XFR),
-				ScanCnt:    srv.nx.GetWorkerCount(subsystem.Scanner),
-			},
+			tmplDataBase: srv.baseData("Main", req),
+			Outcomes:     outcome.AllOutcomes(),
 		}
 	)
 
@@ -223,6 +283,10 @@ func (srv *Server) handleMain(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if srv.nx != nil {
+		data.Resolvers = srv.nx.ResolverStats()
+	}
+
 	db = srv.pool.Get()
 	defer srv.pool.Put(db)
 
@@ -257,19 +321,7 @@ func (srv *Server) handleByPort(w http.ResponseWriter, r *http.Request) {
 		db   *database.Database
 		tmpl *template.Template
 		data = tmplDataByPort{
-			tmplDataBase: tmplDataBase{
-				Title:      "Services by Port",
-				Debug:      common.Debug,
-				URL:        r.URL.String(),
-				Subsystems: subsystem.AllSubsystems(),
-				GenActive:  srv.nx.GetActiveFlag(subsystem.Generator),
-				XFRActive:  srv.nx.GetActiveFlag(subsystem.XFR),
-				ScanActive: srv.nx.GetActiveFlag(subsystem.Scanner),
-				GenAddrCnt: srv.nx.GetWorkerCount(subsystem.GeneratorAddress),
-				GenNameCnt: srv.nx.GetWorkerCount(subsystem.GeneratorName),
-				XFRCnt:     srv.nx.GetWorkerCount(subsystem.XFR),
-				ScanCnt:    srv.nx.GetWorkerCount(subsystem.Scanner),
-			},
+			tmplDataBase: srv.baseData("Services by Port", r),
 		}
 	)
 
@@ -305,6 +357,301 @@ func (srv *Server) handleByPort(w http.ResponseWriter, r *http.Request) {
 	}
 } // func (srv *Server) handleByPort(w http.ResponseWriter, r *http.Request)
 
+func (srv *Server) handleHosts(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handling request for %s\n", r.RequestURI)
+	const (
+		tmplName     = "hosts"
+		defaultLimit = 250
+	)
+
+	var (
+		err  error
+		msg  string
+		db   *database.Database
+		tmpl *template.Template
+		data = tmplDataHosts{
+			tmplDataBase: srv.baseData("Hosts", r),
+			Query:        r.URL.RawQuery,
+			Sources:      hsrc.AllSources(),
+			Formats:      export.AllFormats(),
+		}
+	)
+
+	if tmpl = srv.tmpl.Lookup(tmplName); tmpl == nil {
+		msg = fmt.Sprintf("Could not find template %q", tmplName)
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if data.Filter, err = parseHostFilter(r, defaultLimit); err != nil {
+		msg = fmt.Sprintf("Invalid filter: %s", err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if data.Hosts, err = db.HostGetFiltered(data.Filter); err != nil {
+		msg = fmt.Sprintf("Failed to get Hosts: %s", err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	w.Header().Set("Cache-Control", noCache)
+	if err = tmpl.Execute(w, &data); err != nil {
+		msg = fmt.Sprintf("Error rendering template %q: %s",
+			tmplName,
+			err.Error())
+		srv.sendErrorMessage(w, msg)
+	}
+} // func (srv *Server) handleHosts(w http.ResponseWriter, r *http.Request)
+
+// handleExport delivers the Hosts matching the same filter parameters
+// the host list uses as a download in one of the export formats.
+func (srv *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handling request for %s\n", r.RequestURI)
+
+	var (
+		err    error
+		msg    string
+		db     *database.Database
+		ex     *export.Exporter
+		format export.Format
+		filter *database.HostFilter
+		cnt    int64
+		vars   = mux.Vars(r)
+	)
+
+	if format, err = export.ParseFormat(vars["format"]); err != nil {
+		msg = err.Error()
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if filter, err = parseHostFilter(r, -1); err != nil {
+		msg = fmt.Sprintf("Invalid filter: %s", err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if ex, err = export.New(db); err != nil {
+		msg = fmt.Sprintf("Cannot create Exporter: %s", err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	var filename = fmt.Sprintf("%s_export_%s.%s",
+		strings.ToLower(common.AppName),
+		time.Now().Format("20060102_150405"),
+		format.Extension())
+
+	w.Header().Set("Content-Type", format.MimeType())
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Cache-Control", noCache)
+	w.WriteHeader(200)
+
+	// Once we have started streaming, there is no way to tell the client
+	// something went wrong other than cutting the output short.
+	if cnt, err = ex.Export(w, format, filter); err != nil {
+		srv.log.Printf("[ERROR] Export to %s failed after %d Hosts: %s\n",
+			r.RemoteAddr,
+			cnt,
+			err.Error())
+	}
+} // func (srv *Server) handleExport(w http.ResponseWriter, r *http.Request)
+
+// handleExportMail delivers the e-mail security policies of all zones as a
+// download.
+func (srv *Server) handleExportMail(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handling request for %s\n", r.RequestURI)
+
+	var (
+		err    error
+		msg    string
+		db     *database.Database
+		ex     *export.Exporter
+		format export.Format
+		cnt    int64
+		vars   = mux.Vars(r)
+	)
+
+	if format, err = export.ParseFormat(vars["format"]); err != nil {
+		msg = err.Error()
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if ex, err = export.New(db); err != nil {
+		msg = fmt.Sprintf("Cannot create Exporter: %s", err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	var filename = fmt.Sprintf("%s_mail_%s.%s",
+		strings.ToLower(common.AppName),
+		time.Now().Format("20060102_150405"),
+		format.Extension())
+
+	w.Header().Set("Content-Type", format.MimeType())
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Cache-Control", noCache)
+	w.WriteHeader(200)
+
+	if cnt, err = ex.ExportMail(w, format); err != nil {
+		srv.log.Printf("[ERROR] Export of mail policies to %s failed after %d zones: %s\n",
+			r.RemoteAddr,
+			cnt,
+			err.Error())
+	}
+} // func (srv *Server) handleExportMail(w http.ResponseWriter, r *http.Request)
+
+// handleMetrics delivers runtime statistics in the Prometheus text format.
+func (srv *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handling request for %s\n", r.RequestURI)
+
+	var (
+		err     error
+		workers = metrics.NewGauge("workers",
+			"Number of active workers per subsystem",
+			"subsystem")
+		queues = metrics.NewGauge("queue_depth",
+			"Number of items waiting in a subsystem's queue",
+			"subsystem",
+			"queue")
+	)
+
+	// Without a Nexus (e.g. when testing), there are no subsystems to
+	// ask, but the counters are still worth looking at.
+	if srv.nx != nil {
+		for _, sub := range subsystem.AllSubsystems() {
+			var depths map[string]int
+
+			if sub == subsystem.None {
+				continue
+			}
+
+			workers.Set(float64(srv.nx.GetWorkerCount(sub)), sub.String())
+
+			depths = srv.nx.GetQueueDepths(sub)
+			for _, q := range slices.Sorted(maps.Keys(depths)) {
+				queues.Set(float64(depths[q]), sub.String(), q)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", noCache)
+	w.WriteHeader(200)
+
+	if err = metrics.Write(w, workers, queues); err != nil {
+		srv.log.Printf("[ERROR] Failed to send metrics to %s: %s\n",
+			r.RemoteAddr,
+			err.Error())
+	}
+} // func (srv *Server) handleMetrics(w http.ResponseWriter, r *http.Request)
+
+// sseKeepAlive is the interval at which we send a comment to clients of the
+// event stream when nothing else happens, so proxies don't cut the
+// connection.
+const sseKeepAlive = time.Second * 15
+
+// handleEvents streams Events from the subsystems to the client as
+// Server-Sent Events.
+func (srv *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handling request for %s\n", r.RequestURI)
+
+	var (
+		err     error
+		ok      bool
+		id      int64
+		flusher http.Flusher
+		evQ     <-chan *events.Event
+		ticker  *time.Ticker
+	)
+
+	if flusher, ok = w.(http.Flusher); !ok {
+		srv.log.Println("[CANTHAPPEN] ResponseWriter does not support flushing")
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", noCache)
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(200)
+
+	id, evQ = events.Subscribe(64)
+	defer events.Unsubscribe(id)
+
+	ticker = time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+
+	// Tell the client how many workers there are, so it does not have to
+	// wait for the next change.
+	if srv.nx != nil {
+		for _, sub := range []subsystem.ID{
+			subsystem.GeneratorAddress,
+			subsystem.GeneratorName,
+			subsystem.XFR,
+			subsystem.Scanner,
+			subsystem.Geo,
+		} {
+			var ev = &events.Event{
+				Kind:      events.WorkerCount,
+				Timestamp: time.Now(),
+				Subsystem: sub,
+				SubName:   sub.String(),
+				Count:     srv.nx.GetWorkerCount(sub),
+			}
+
+			if err = writeEvent(w, ev); err != nil {
+				return
+			}
+		}
+	}
+
+	if _, err = io.WriteString(w, ": connected\n\n"); err != nil {
+		return
+	}
+
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err = io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case ev, ok := <-evQ:
+			if !ok {
+				return
+			} else if err = writeEvent(w, ev); err != nil {
+				srv.log.Printf("[DEBUG] Failed to send Event to %s: %s\n",
+					r.RemoteAddr,
+					err.Error())
+				return
+			}
+		}
+
+		flusher.Flush()
+	}
+} // func (srv *Server) handleEvents(w http.ResponseWriter, r *http.Request)
+
 //////////////////////////////////////////////////////////////////////////////
 /// AJAX handlers ////////////////////////////////////////////////////////////
 //////////////////////////////////////////////////////////////////////////////
@@ -324,6 +671,7 @@ func (srv *Server) handleLoadWorkerCount(w http.ResponseWriter, r *http.Request)
 	res.GeneratorName = srv.nx.GetWorkerCount(subsystem.GeneratorName)
 	res.XFR = srv.nx.GetWorkerCount(subsystem.XFR)
 	res.Scanner = srv.nx.GetWorkerCount(subsystem.Scanner)
+	res.Geo = srv.nx.GetWorkerCount(subsystem.Geo)
 	res.Status = true
 
 	var outbuf []byte
@@ -474,6 +822,43 @@ RESPOND:
 	w.Write(outbuf) // nolint: errcheck
 } // func (srv *Server) handleStopWorker(w http.ResponseWriter, r *http.Request)
 
+func (srv *Server) handleCacheReset(w http.ResponseWriter, r *http.Request) {
+	var (
+		err error
+		res = ajaxData{
+			Timestamp: time.Now(),
+		}
+	)
+
+	srv.log.Printf("[TRACE] Handling request for %s\n", r.RequestURI)
+
+	if err = srv.nx.CacheReset(); err != nil {
+		res.Message = fmt.Sprintf("Failed to reset address cache: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+	} else {
+		res.Status = true
+		res.Message = "Address cache was reset"
+		srv.log.Printf("[INFO] Address cache was reset by %s\n",
+			r.RemoteAddr)
+	}
+
+	var outbuf []byte
+
+	if outbuf, err = json.Marshal(&res); err != nil {
+		res.Message = fmt.Sprintf("Error serializing Response to %s: %s",
+			r.RemoteAddr,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+	}
+
+	w.Header().Set("Content-Length", strconv.FormatInt(int64(len(outbuf)), 10))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", noCache)
+	w.WriteHeader(200)
+	w.Write(outbuf) // nolint: errcheck
+} // func (srv *Server) handleCacheReset(w http.ResponseWriter, r *http.Request)
+
 func (srv *Server) handleBeacon(w http.ResponseWriter, r *http.Request) {
 	// It doesn't bother me enough to do anything about it other
 	// than writing this comment, but this method is probably
@@ -0,0 +1,231 @@
+// /home/krylon/go/src/github.com/blicero/guangng/web/zones.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:02:25 krylon>
+
+package web
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/model"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// zoneOpenLimit is the number of open name servers we list at most.
+	zoneOpenLimit = 250
+	// zoneRecentLimit is the number of recent transfer attempts we show.
+	zoneRecentLimit = 100
+	// zoneRecordLimit is the number of records we show on the page of a
+	// zone, the zone file has all of them.
+	zoneRecordLimit = 1000
+	// zoneChangeLimit is the number of changes to a zone we show.
+	zoneChangeLimit = 200
+)
+
+// handleZones shows which name servers let us transfer their zones, and
+// how the most recent transfer attempts went.
+func (srv *Server) handleZones(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handling request for %s\n", r.RequestURI)
+	const tmplName = "zones"
+
+	var (
+		err  error
+		msg  string
+		db   *database.Database
+		tmpl *template.Template
+		data = tmplDataZones{
+			tmplDataBase: srv.baseData("Zones", r),
+		}
+	)
+
+	if tmpl = srv.tmpl.Lookup(tmplName); tmpl == nil {
+		msg = fmt.Sprintf("Could not find template %q", tmplName)
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if data.Open, err = db.XFRResultGetOpen(zoneOpenLimit); err != nil {
+		msg = fmt.Sprintf("Failed to get open name servers: %s", err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if data.Recent, err = db.XFRResultGetRecent(zoneRecentLimit); err != nil {
+		msg = fmt.Sprintf("Failed to get recent zone transfers: %s", err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	w.Header().Set("Cache-Control", noCache)
+	if err = tmpl.Execute(w, &data); err != nil {
+		msg = fmt.Sprintf("Error rendering template %q: %s",
+			tmplName,
+			err.Error())
+		srv.sendErrorMessage(w, msg)
+	}
+} // func (srv *Server) handleZones(w http.ResponseWriter, r *http.Request)
+
+// loadZone looks up the zone whose ID is in the request's URL. If the zone
+// does not exist or the ID is invalid, it sends an error response and
+// returns nil.
+func (srv *Server) loadZone(w http.ResponseWriter, r *http.Request, db *database.Database) *model.Zone {
+	var (
+		err  error
+		id   int64
+		msg  string
+		zone *model.Zone
+		vars = mux.Vars(r)
+	)
+
+	if id, err = strconv.ParseInt(vars["id"], 10, 64); err != nil {
+		msg = fmt.Sprintf("Invalid zone ID %q: %s", vars["id"], err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return nil
+	} else if zone, err = db.XFRGetByID(id); err != nil {
+		msg = fmt.Sprintf("Failed to get zone #%d: %s", id, err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return nil
+	} else if zone == nil {
+		http.NotFound(w, r)
+		return nil
+	}
+
+	return zone
+} // func (srv *Server) loadZone(w http.ResponseWriter, r *http.Request, db *database.Database) *model.Zone
+
+// handleZoneDetails shows the records of a zone we transferred, the
+// changes since the first transfer, how each of its name servers
+// responded, and what we make of its e-mail security policy.
+func (srv *Server) handleZoneDetails(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handling request for %s\n", r.RequestURI)
+	const tmplName = "zone"
+
+	var (
+		err  error
+		msg  string
+		db   *database.Database
+		tmpl *template.Template
+		data = tmplDataZone{
+			tmplDataBase: srv.baseData("Zone", r),
+		}
+	)
+
+	if tmpl = srv.tmpl.Lookup(tmplName); tmpl == nil {
+		msg = fmt.Sprintf("Could not find template %q", tmplName)
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if data.Zone = srv.loadZone(w, r, db); data.Zone == nil {
+		return
+	} else if data.Servers, err = db.XFRResultGetByZone(data.Zone); err != nil {
+		msg = fmt.Sprintf("Failed to get transfer attempts of %s: %s", data.Zone.Name, err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if data.Changes, err = db.ZoneRecordGetChanges(data.Zone, zoneChangeLimit); err != nil {
+		msg = fmt.Sprintf("Failed to get changes to %s: %s", data.Zone.Name, err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if data.Records, err = db.ZoneRecordGetCurrent(data.Zone); err != nil {
+		msg = fmt.Sprintf("Failed to get records of %s: %s", data.Zone.Name, err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if data.Mail, err = db.MailPolicyGetByZone(data.Zone); err != nil {
+		msg = fmt.Sprintf("Failed to get mail policy of %s: %s", data.Zone.Name, err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	data.RecordCnt = len(data.Records)
+	if len(data.Records) > zoneRecordLimit {
+		data.Records = data.Records[:zoneRecordLimit]
+	}
+
+	data.Title = data.Zone.Name
+
+	w.Header().Set("Cache-Control", noCache)
+	if err = tmpl.Execute(w, &data); err != nil {
+		msg = fmt.Sprintf("Error rendering template %q: %s",
+			tmplName,
+			err.Error())
+		srv.sendErrorMessage(w, msg)
+	}
+} // func (srv *Server) handleZoneDetails(w http.ResponseWriter, r *http.Request)
+
+// handleZoneFile sends the records of a zone as a master file, in the
+// format described in RFC 1035, section 5.
+func (srv *Server) handleZoneFile(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handling request for %s\n", r.RequestURI)
+
+	var (
+		err     error
+		msg     string
+		db      *database.Database
+		zone    *model.Zone
+		records []*model.ZoneRecord
+		out     *bufio.Writer
+	)
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if zone = srv.loadZone(w, r, db); zone == nil {
+		return
+	} else if records, err = db.ZoneRecordGetCurrent(zone); err != nil {
+		msg = fmt.Sprintf("Failed to get records of %s: %s", zone.Name, err.Error())
+		srv.log.Printf("[ERROR] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if len(records) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/dns; charset=utf-8")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", zone.Name+".zone"))
+	w.Header().Set("Cache-Control", noCache)
+	w.WriteHeader(200)
+
+	out = bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "; Zone %s, transferred by %s on %s\n", // nolint: errcheck
+		zone.Name,
+		appStringFunc(),
+		zone.Finished.Format(time.RFC3339))
+	fmt.Fprintf(out, "$ORIGIN %s.\n", strings.TrimSuffix(zone.Name, ".")) // nolint: errcheck
+
+	for _, rec := range records {
+		fmt.Fprintln(out, rec.String()) // nolint: errcheck
+	}
+
+	if err = out.Flush(); err != nil {
+		srv.log.Printf("[ERROR] Failed to send zone file of %s: %s\n",
+			zone.Name,
+			err.Error())
+	}
+} // func (srv *Server) handleZoneFile(w http.ResponseWriter, r *http.Request)
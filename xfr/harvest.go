@@ -0,0 +1,160 @@
+// /home/krylon/go/src/github.com/blicero/guangng/xfr/harvest.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:56:18 krylon>
+
+package xfr
+
+import (
+	"net"
+	"strings"
+
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/events"
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/hsrc"
+	"github.com/blicero/guangng/model/probe"
+	dns "github.com/tonnerre/golang-dns"
+)
+
+// srvService splits the owner of an SRV record, e.g.
+// "_xmpp-server._tcp.example.com.", into the service and the protocol.
+// It returns false if owner does not look like that, or if the protocol
+// is neither TCP nor UDP.
+func srvService(owner string) (string, string, bool) {
+	var labels = strings.SplitN(strings.ToLower(owner), ".", 3)
+
+	if len(labels) < 3 ||
+		!strings.HasPrefix(labels[0], "_") ||
+		!strings.HasPrefix(labels[1], "_") {
+		return "", "", false
+	}
+
+	var (
+		svc   = labels[0][1:]
+		proto = labels[1][1:]
+	)
+
+	if svc == "" || (proto != "tcp" && proto != "udp") {
+		return "", "", false
+	}
+
+	return svc, proto, true
+} // func srvService(owner string) (string, string, bool)
+
+// resolve looks up the addresses of name, leaving out blacklisted ones.
+func (x *XFR) resolve(name string) []net.IP {
+	var (
+		err      error
+		addrList []string
+		result   []net.IP
+	)
+
+	if x.blName.Match(name) {
+		return nil
+	} else if addrList, err = net.LookupHost(name); err != nil {
+		x.log.Printf("[TRACE] Failed to lookup %s: %s\n",
+			name,
+			err.Error())
+		return nil
+	}
+
+	for _, s := range addrList {
+		var addr = net.ParseIP(s)
+
+		if addr != nil && !x.blAddr.Match(addr) {
+			result = append(result, addr)
+		}
+	}
+
+	return result
+} // func (x *XFR) resolve(name string) []net.IP
+
+// harvestSRV adds the target of an SRV record as a Host and queues the
+// port it names for the Scanner, using the Probe that fits the service.
+// Services we cannot probe over the protocol the record names are only
+// added as Hosts.
+func (x *XFR) harvestSRV(srv *dns.SRV) {
+	var (
+		svc, proto string
+		ok         bool
+		p          probe.Probe
+	)
+
+	// A target of "." means the service is decidedly not available.
+	if srv.Target == "." {
+		return
+	} else if svc, proto, ok = srvService(srv.Header().Name); !ok {
+		x.log.Printf("[TRACE] Ignore SRV record with unexpected owner %s\n",
+			srv.Header().Name)
+		return
+	}
+
+	p = probe.ForService(svc)
+
+	for _, addr := range x.resolve(srv.Target) {
+		var host = &model.Host{
+			Name:   srv.Target,
+			Addr:   addr,
+			Source: hsrc.XFR,
+		}
+
+		if srv.Port == 0 || (proto == "udp" && !p.UDP()) {
+			x.hostQ <- host
+			continue
+		}
+
+		x.portQ <- &model.PendingPort{
+			Host:  host,
+			Port:  srv.Port,
+			Proto: proto,
+			Probe: p,
+		}
+	}
+} // func (x *XFR) harvestSRV(srv *dns.SRV)
+
+// harvestCNAME adds the Hosts the target of a CNAME record resolves to.
+func (x *XFR) harvestCNAME(c *dns.CNAME) {
+	for _, addr := range x.resolve(c.Target) {
+		x.hostQ <- &model.Host{
+			Name:   c.Target,
+			Addr:   addr,
+			Source: hsrc.XFR,
+		}
+	}
+} // func (x *XFR) harvestCNAME(c *dns.CNAME)
+
+// addPort adds the Host of p to the database unless we know it already,
+// and queues the port for the Scanner.
+func (x *XFR) addPort(db *database.Database, p *model.PendingPort) {
+	var (
+		err  error
+		host *model.Host
+	)
+
+	if host, err = db.HostGetByAddr(p.Host.Addr); err != nil {
+		x.log.Printf("[ERROR] Failed to look up Host %s: %s\n",
+			p.Host.AStr(),
+			err.Error())
+		return
+	} else if host != nil {
+		p.Host = host
+	} else if err = db.HostAdd(p.Host); err != nil {
+		x.log.Printf("[ERROR] Failed to add Host %s (%s) to database: %s\n",
+			p.Host.Name,
+			p.Host.AStr(),
+			err.Error())
+		return
+	} else {
+		events.Host(p.Host)
+	}
+
+	if err = db.PortQueueAdd(p.Host, p.Port, p.Proto, p.Probe); err != nil {
+		x.log.Printf("[ERROR] Failed to queue port %d/%s on %s: %s\n",
+			p.Port,
+			p.Proto,
+			p.Host.AStr(),
+			err.Error())
+	}
+} // func (x *XFR) addPort(db *database.Database, p *model.PendingPort)
@@ -0,0 +1,48 @@
+// /home/krylon/go/src/github.com/blicero/guangng/xfr/harvest_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:28:27 krylon>
+
+package xfr
+
+import (
+	"testing"
+
+	"github.com/blicero/guangng/model/probe"
+)
+
+func TestSRVService(t *testing.T) {
+	for _, c := range []struct {
+		owner, svc, proto string
+		ok                bool
+		p                 probe.Probe
+	}{
+		{"_xmpp-server._tcp.straylight.test.", "xmpp-server", "tcp", true, probe.XMPPServer},
+		{"_HTTP._TCP.straylight.test.", "http", "tcp", true, probe.HTTP},
+		{"_domain._udp.straylight.test.", "domain", "udp", true, probe.DNS},
+		{"_ldap._tcp.dc._msdcs.straylight.test.", "ldap", "tcp", true, probe.LDAP},
+		{"_sip._tcp.straylight.test.", "sip", "tcp", true, probe.SIP},
+		{"_sip._sctp.straylight.test.", "", "", false, probe.Auto},
+		{"_sip.straylight.test.", "", "", false, probe.Auto},
+		{"www.straylight.test.", "", "", false, probe.Auto},
+	} {
+		var svc, proto, ok = srvService(c.owner)
+
+		if ok != c.ok || svc != c.svc || proto != c.proto {
+			t.Errorf("srvService(%q) = %q, %q, %t, expected %q, %q, %t",
+				c.owner,
+				svc,
+				proto,
+				ok,
+				c.svc,
+				c.proto,
+				c.ok)
+		} else if p := probe.ForService(svc); ok && p != c.p {
+			t.Errorf("Probe for %s is %s, expected %s",
+				svc,
+				p,
+				c.p)
+		}
+	}
+} // func TestSRVService(t *testing.T)
@@ -0,0 +1,55 @@
+// /home/krylon/go/src/github.com/blicero/guangng/xfr/mailpolicy.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:02:25 krylon>
+
+package xfr
+
+import (
+	"fmt"
+
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/mailsec"
+	"github.com/blicero/guangng/model"
+)
+
+// checkMail analyzes the e-mail security policy of the zone z, based on the
+// records we have stored for it, and saves the result.
+func (x *XFR) checkMail(db *database.Database, z *model.Zone) error {
+	var (
+		err     error
+		records []*model.ZoneRecord
+		pol     *model.MailPolicy
+		lookup  = func(name string) []*model.ZoneRecord {
+			var list, lerr = db.ZoneRecordGetByOwner(name, "TXT")
+
+			if lerr != nil || len(list) == 0 {
+				return nil
+			}
+
+			return list
+		}
+	)
+
+	if records, err = db.ZoneRecordGetCurrent(z); err != nil {
+		return err
+	} else if pol = mailsec.Analyze(z.Name, records, lookup); pol == nil {
+		return db.MailPolicyDelete(z)
+	} else if err = db.Begin(); err != nil {
+		return fmt.Errorf("cannot start transaction: %w", err)
+	} else if err = db.MailPolicySet(z, pol); err != nil {
+		db.Rollback() // nolint: errcheck
+		return err
+	} else if err = db.Commit(); err != nil {
+		return fmt.Errorf("cannot commit transaction: %w", err)
+	}
+
+	if pol.IsWeak() {
+		x.log.Printf("[DEBUG] Mail policy of %s is weak: %v\n",
+			z.Name,
+			pol.Weaknesses)
+	}
+
+	return nil
+} // func (x *XFR) checkMail(db *database.Database, z *model.Zone) error
@@ -0,0 +1,210 @@
+// /home/krylon/go/src/github.com/blicero/guangng/xfr/nsec.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:29:17 krylon>
+
+package xfr
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/metrics"
+	"github.com/blicero/guangng/model"
+	"github.com/blicero/guangng/model/dnssec"
+	"github.com/blicero/guangng/model/hsrc"
+	dns "github.com/tonnerre/golang-dns"
+)
+
+// Most zones refuse to be transferred, but a zone signed with plain NSEC
+// hands out its names anyway: asking for the NSEC record of a name tells us
+// the next name in the zone, so starting at the apex, we can follow the
+// chain until it leads back to the apex.
+//
+// maxWalk limits the number of names we take from one chain. Servers that
+// sign on the fly can make up a new NSEC record for every query, and we do
+// not want to get stuck in one of those.
+const maxWalk = 10000
+
+var errChainBroken = errors.New("NSEC chain is broken")
+
+// nsecQuery asks a name server for the NSEC record of name.
+type nsecQuery func(name string) (*dns.Msg, error)
+
+// nsecQueryFor returns an nsecQuery that sends its queries to srv.
+func (x *XFR) nsecQueryFor(srv net.IP) nsecQuery {
+	var ns = net.JoinHostPort(srv.String(), "53")
+
+	return func(name string) (*dns.Msg, error) {
+		var (
+			err      error
+			msg, res *dns.Msg
+		)
+
+		msg = new(dns.Msg)
+		msg.SetQuestion(name, dns.TypeNSEC)
+		msg.SetEdns0(4096, true)
+
+		if res, _, err = x.res.Exchange(msg, ns); err != nil {
+			return nil, fmt.Errorf("failed to query %s for NSEC of %s: %w",
+				ns,
+				name,
+				err)
+		}
+
+		return res, nil
+	}
+} // func (x *XFR) nsecQueryFor(srv net.IP) nsecQuery
+
+// signingMode tells how a zone is signed from the answer to a query for the
+// NSEC record of its apex. A zone signed with NSEC3 has no NSEC record, so
+// the server sends the NSEC3 record proving that instead. If the server
+// did not answer the query properly, the result is 0.
+func signingMode(msg *dns.Msg) dnssec.Mode {
+	var mode = dnssec.Unsigned
+
+	if msg.Rcode != dns.RcodeSuccess {
+		return 0
+	}
+
+	for _, rr := range slices.Concat(msg.Answer, msg.Ns) {
+		switch rr.(type) {
+		case *dns.NSEC3:
+			return dnssec.NSEC3
+		case *dns.NSEC:
+			mode = dnssec.NSEC
+		}
+	}
+
+	return mode
+} // func signingMode(msg *dns.Msg) dnssec.Mode
+
+// nsecNext returns the next name the NSEC record of name in msg points to,
+// in lower case.
+func nsecNext(name string, msg *dns.Msg) (string, bool) {
+	for _, rr := range slices.Concat(msg.Answer, msg.Ns) {
+		if n, ok := rr.(*dns.NSEC); ok && strings.EqualFold(n.Hdr.Name, name) {
+			return strings.ToLower(dns.Fqdn(n.NextDomain)), true
+		}
+	}
+
+	return "", false
+} // func nsecNext(name string, msg *dns.Msg) (string, bool)
+
+// walkNSEC follows the NSEC chain of zone, starting at the apex, and returns
+// the names it passes, up to limit of them. If the walk fails halfway, the
+// names found until then are returned along with the error.
+func walkNSEC(zone string, query nsecQuery, limit int) ([]string, error) {
+	var (
+		apex  = strings.ToLower(dns.Fqdn(zone))
+		name  = apex
+		seen  = map[string]bool{apex: true}
+		names []string
+	)
+
+	for len(names) < limit {
+		var (
+			err  error
+			msg  *dns.Msg
+			next string
+			ok   bool
+		)
+
+		if msg, err = query(name); err != nil {
+			return names, err
+		} else if next, ok = nsecNext(name, msg); !ok {
+			return names, fmt.Errorf("%w: no NSEC record for %s", errChainBroken, name)
+		} else if next == apex {
+			return names, nil
+		} else if seen[next] ||
+			!dns.IsSubDomain(apex, next) ||
+			// "Black lies" (RFC 4470 style online signing) point
+			// every name to the one right below it.
+			strings.HasPrefix(next, `\000.`) {
+			return names, fmt.Errorf("%w: %s points to %s", errChainBroken, name, next)
+		}
+
+		seen[next] = true
+		names = append(names, next)
+		name = next
+	}
+
+	return names, fmt.Errorf("NSEC chain of %s has more than %d names", zone, limit)
+} // func walkNSEC(zone string, query nsecQuery, limit int) ([]string, error)
+
+// checkNSEC finds out how z is signed, asking the name servers at addrs,
+// and records it. If the zone is signed with plain NSEC, we could not
+// transfer it, and walking NSEC chains is enabled, we walk the chain and
+// add the Hosts the names in the zone resolve to.
+func (x *XFR) checkNSEC(db *database.Database, z *model.Zone, addrs []net.IP, transferred bool) {
+	var (
+		err   error
+		mode  dnssec.Mode
+		msg   *dns.Msg
+		query nsecQuery
+		names []string
+	)
+
+	for _, srv := range addrs {
+		query = x.nsecQueryFor(srv)
+
+		if msg, err = query(dns.Fqdn(z.Name)); err != nil {
+			x.log.Printf("[DEBUG] %s\n", err.Error())
+		} else if mode = signingMode(msg); mode != 0 {
+			break
+		}
+	}
+
+	if mode == 0 {
+		x.log.Printf("[DEBUG] Cannot tell how zone %s is signed\n",
+			z.Name)
+		return
+	}
+
+	metrics.XFRDNSSEC.With(mode.String()).Inc()
+
+	if err = db.XFRSetDNSSEC(z, mode); err != nil {
+		x.log.Printf("[ERROR] Failed to record DNSSEC mode of zone %s: %s\n",
+			z.Name,
+			err.Error())
+	}
+
+	if transferred || mode != dnssec.NSEC || !x.nsecWalk {
+		return
+	}
+
+	names, err = walkNSEC(z.Name, query, maxWalk)
+
+	if err != nil {
+		x.log.Printf("[DEBUG] Walking NSEC chain of %s stopped after %d names: %s\n",
+			z.Name,
+			len(names),
+			err.Error())
+	} else {
+		x.log.Printf("[DEBUG] Found %d names in NSEC chain of %s\n",
+			len(names),
+			z.Name)
+	}
+
+	metrics.NSECNames.Add(uint64(len(names)))
+
+	for _, name := range names {
+		// Wildcards have no addresses of their own.
+		if strings.HasPrefix(name, "*.") {
+			continue
+		}
+
+		for _, addr := range x.resolve(name) {
+			x.hostQ <- &model.Host{
+				Name:   name,
+				Addr:   addr,
+				Source: hsrc.NSEC,
+			}
+		}
+	}
+} // func (x *XFR) checkNSEC(db *database.Database, z *model.Zone, addrs []net.IP, transferred bool)
@@ -0,0 +1,86 @@
+// /home/krylon/go/src/github.com/blicero/guangng/xfr/retry.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:38:32 krylon>
+
+package xfr
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/blicero/guangng/model/outcome"
+)
+
+// Zones do not stay the same forever, so we transfer the ones we got once
+// again every refreshInterval to pick up new Hosts. If a transfer fails
+// because of a timeout or a SERVFAIL, chances are it will work later on, so
+// we try again soon, backing off exponentially from retryBase up to
+// retryMax. If the servers simply refuse, asking again soon is pointless,
+// but policies do change, so we come back after refusedInterval. A zone
+// without name servers is probably being set up or torn down, so we look
+// at it again after refreshInterval to see which way it went.
+// xfrLease is how long a zone is not handed out again once an attempt has
+// started.
+const (
+	refreshInterval = time.Hour * 24 * 7
+	refusedInterval = time.Hour * 24 * 90
+	retryBase       = time.Hour
+	retryMax        = time.Hour * 24 * 7
+	xfrLease        = time.Hour
+)
+
+// zoneOutcome sums up the outcomes of a transfer attempt against all the
+// servers of a zone. One server giving us the zone is all we need. Failing
+// that, if any server had a transient problem, the whole attempt is
+// considered a transient failure.
+func zoneOutcome(list []outcome.Outcome) outcome.Outcome {
+	var res = outcome.Refused
+
+	for _, o := range list {
+		if o == outcome.Success {
+			return outcome.Success
+		} else if o.Transient() {
+			res = o
+		}
+	}
+
+	return res
+} // func zoneOutcome(list []outcome.Outcome) outcome.Outcome
+
+// lookupOutcome classifies a failure to look up the name servers of a zone.
+// If the zone does not exist (anymore), there is no point in hurrying.
+func lookupOutcome(err error) outcome.Outcome {
+	var derr *net.DNSError
+
+	if errors.As(err, &derr) && derr.IsNotFound {
+		return outcome.NXDomain
+	} else if errors.As(err, &derr) && derr.Timeout() {
+		return outcome.Timeout
+	}
+
+	return outcome.ServFail
+} // func lookupOutcome(err error) outcome.Outcome
+
+// schedule returns when to attempt to transfer a zone again, given the
+// outcome of the current attempt and the number of attempts that failed
+// before it, along with the new number of failures.
+func schedule(o outcome.Outcome, failures int, now time.Time) (time.Time, int) {
+	if o == outcome.Success {
+		return now.Add(refreshInterval), 0
+	} else if o == outcome.Empty {
+		return now.Add(refreshInterval), failures + 1
+	} else if !o.Transient() {
+		return now.Add(refusedInterval), failures + 1
+	}
+
+	var delay = retryBase
+
+	for i := 0; i < failures && delay < retryMax; i++ {
+		delay *= 2
+	}
+
+	return now.Add(min(delay, retryMax)), failures + 1
+} // func schedule(o outcome.Outcome, failures int, now time.Time) (time.Time, int)
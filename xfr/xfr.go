@@ -2,7 +2,7 @@
 // -*- mode: go; coding: utf-8; -*-
 // Created on 20. 01. 2026 by Benjamin Walkenhorst
 // (c) 2026 Benjamin Walkenhorst
-// Time-stamp: <2026-02-05 14:56:49 krylon>
+// Time-stamp: <2026-10-19 15:38:32 krylon>
 
 // Package xfr handles zone transfers, an attempt to get more Hosts into the
 // database, as the Generator itself is kind of slow.
@@ -22,9 +22,12 @@ import (
 	"github.com/blicero/guangng/blacklist"
 	"github.com/blicero/guangng/common"
 	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/events"
 	"github.com/blicero/guangng/logdomain"
+	"github.com/blicero/guangng/metrics"
 	"github.com/blicero/guangng/model"
 	"github.com/blicero/guangng/model/hsrc"
+	"github.com/blicero/guangng/model/outcome"
 	"github.com/blicero/guangng/model/subsystem"
 	"github.com/blicero/krylib"
 	dns "github.com/tonnerre/golang-dns"
@@ -37,22 +40,27 @@ type XFR struct {
 	xcnt      atomic.Int32
 	idCounter atomic.Int64
 	goalCnt   int
+	nsecWalk  bool
 	cmdQ      chan bool
 	xfrQ      chan *model.Zone
 	hostQ     chan *model.Host
+	portQ     chan *model.PendingPort
 	res       *dns.Client
 	pool      *database.Pool
 	blName    *blacklist.BlacklistName
 	blAddr    *blacklist.BlacklistAddr
 }
 
-// New returns a new XFR instance.
-func New(cnt int) (*XFR, error) {
+// New returns a new XFR instance. If nsecWalk is true, it enumerates the
+// names of zones that refuse to be transferred, but are signed with plain
+// NSEC.
+func New(cnt int, nsecWalk bool) (*XFR, error) {
 	var (
 		err  error
 		xcnt = max(cnt, 2)
 		x    = &XFR{
-			goalCnt: cnt,
+			goalCnt:  cnt,
+			nsecWalk: nsecWalk,
 		}
 	)
 
@@ -68,6 +76,7 @@ func New(cnt int) (*XFR, error) {
 	x.cmdQ = make(chan bool, xcnt)
 	x.xfrQ = make(chan *model.Zone, xcnt)
 	x.hostQ = make(chan *model.Host, xcnt)
+	x.portQ = make(chan *model.PendingPort, xcnt)
 	x.res = new(dns.Client)
 	x.blAddr = blacklist.NewBlacklistAddr()
 	x.blName = blacklist.NewBlacklistName()
@@ -75,7 +84,7 @@ func New(cnt int) (*XFR, error) {
 	x.res.Net = "tcp"
 
 	return x, nil
-} // func New(cnt int) (*XFR, error)
+} // func New(cnt int, nsecWalk bool) (*XFR, error)
 
 func (x *XFR) getID() int {
 	var val = x.idCounter.Add(1)
@@ -91,6 +100,7 @@ func (x *XFR) IsActive() bool {
 // worker goroutines.
 func (x *XFR) Start() {
 	x.active.Store(true)
+	events.State(subsystem.XFR, true)
 
 	go x.hostWorker()
 	go x.xfrFeeder()
@@ -103,6 +113,7 @@ func (x *XFR) Start() {
 // Stop clears the XFR engine's active flag (if set).
 func (x *XFR) Stop() {
 	x.active.Store(false)
+	events.State(subsystem.XFR, false)
 } // func (x *XFR) Stop()
 
 // StartOne starts an additional worker.
@@ -123,6 +134,15 @@ func (x *XFR) System() subsystem.ID {
 	return subsystem.XFR
 } // func (x *XFR) System() subsystem.ID
 
+// QueueDepths returns the number of items waiting in the XFR engine's queues.
+func (x *XFR) QueueDepths() map[string]int {
+	return map[string]int{
+		"xfrQ":  len(x.xfrQ),
+		"hostQ": len(x.hostQ),
+		"portQ": len(x.portQ),
+	}
+} // func (x *XFR) QueueDepths() map[string]int
+
 // hostWorker collects the Hosts that come out of a successful zone transfer
 // and stores them in the Database.
 func (x *XFR) hostWorker() {
@@ -151,7 +171,11 @@ func (x *XFR) hostWorker() {
 					h.Name,
 					h.AStr(),
 					err.Error())
+			} else {
+				events.Host(h)
 			}
+		case p := <-x.portQ:
+			x.addPort(db, p)
 		}
 	}
 } // func (x *XFR) hostWorker()
@@ -179,7 +203,7 @@ func (x *XFR) xfrFeeder() {
 			batchSize = int(x.xcnt.Load())
 		)
 
-		x.log.Printf("[TRACE] Query for up to %d unfinished XFRs\n", batchSize)
+		x.log.Printf("[TRACE] Query for up to %d XFRs that are due\n", batchSize)
 		if batchSize == 0 {
 			if !x.active.Load() {
 				return
@@ -188,15 +212,15 @@ func (x *XFR) xfrFeeder() {
 			continue
 		}
 
-		if xlist, err = db.XFRGetUnfinished(batchSize); err != nil {
-			x.log.Printf("[ERROR] Failed to get %d unfinished XFRs: %s\n",
+		if xlist, err = db.XFRGetDue(batchSize); err != nil {
+			x.log.Printf("[ERROR] Failed to get %d XFRs that are due: %s\n",
 				batchSize,
 				err.Error())
 			x.active.Store(false)
 			return
 		} else if len(xlist) == 0 {
 			delay = min(delay+1, 10)
-			x.log.Println("[DEBUG] No unfinished XFRs were found, maybe next time...")
+			x.log.Println("[DEBUG] No XFRs are due, maybe next time...")
 			time.Sleep(common.ActiveTimeout * time.Duration(krylib.Fibonacci(delay)))
 			continue
 		}
@@ -231,8 +255,10 @@ func (x *XFR) xfrWorker(id int) {
 		ticker *time.Ticker
 	)
 
-	x.xcnt.Add(1)
-	defer x.xcnt.Add(-1)
+	events.Workers(subsystem.XFR, int(x.xcnt.Add(1)))
+	defer func() {
+		events.Workers(subsystem.XFR, int(x.xcnt.Add(-1)))
+	}()
 
 	ticker = time.NewTicker(common.ActiveTimeout)
 	defer ticker.Stop()
@@ -260,6 +286,32 @@ func (x *XFR) xfrWorker(id int) {
 	}
 } // func (x *XFR) xfrWorker(id int)
 
+// xfrOutcome classifies the result of a zone transfer attempt against one
+// server. A server that does not want us to have the zone usually answers
+// with REFUSED or NOTAUTH and no records, which the dns package reports as a
+// missing SOA record.
+func xfrOutcome(cnt int64, err error) outcome.Outcome {
+	var nerr net.Error
+
+	switch {
+	case err == nil && cnt > 0:
+		return outcome.Success
+	case err == nil:
+		return outcome.Empty
+	case errors.Is(err, dns.ErrSoa):
+		return outcome.Refused
+	case errors.As(err, &nerr) && nerr.Timeout():
+		return outcome.Timeout
+	default:
+		return outcome.Error
+	}
+} // func xfrOutcome(cnt int64, err error) outcome.Outcome
+
+// doXFR attempts to transfer a zone from every address of every one of its
+// name servers and records the outcome for each of them. Only the first
+// successful transfer is processed, the others merely tell us which servers
+// are open. When we are done, the zone is scheduled for another attempt,
+// depending on how this one went.
 func (x *XFR) doXFR(z *model.Zone) (int64, error) {
 	x.log.Printf("[DEBUG] Attempt AXFR of %s...\n",
 		z.Name)
@@ -270,12 +322,17 @@ func (x *XFR) doXFR(z *model.Zone) (int64, error) {
 		cnt    int64
 		status bool
 		soa    []*net.NS
+		olist  []outcome.Outcome
+		alist  []net.IP
+		zo     = outcome.Refused
 	)
 
 	db = x.pool.Get()
 	defer x.pool.Put(db)
 
-	if err = db.XFRStart(z); err != nil {
+	metrics.XFRAttempts.Inc()
+
+	if err = db.XFRStart(z, time.Now().Add(xfrLease)); err != nil {
 		x.log.Printf("[ERROR] Failed to register XFR of %s in database: %s\n",
 			z.Name,
 			err.Error())
@@ -283,22 +340,36 @@ func (x *XFR) doXFR(z *model.Zone) (int64, error) {
 	}
 
 	defer func() {
-		var ex error
-		if ex = db.XFRFinish(z, status); ex != nil {
+		var (
+			ex             error
+			next, failures = schedule(zo, z.Failures, time.Now())
+		)
+
+		x.log.Printf("[DEBUG] AXFR of %s: %s, %d failures in a row, next attempt at %s\n",
+			z.Name,
+			zo,
+			failures,
+			next.Format(common.TimestampFormat))
+
+		if ex = db.XFRFinish(z, status, next, failures); ex != nil {
 			x.log.Printf("[ERROR] Failed to register XFR of %s as finished: %s\n",
 				z.Name,
 				ex.Error())
 		}
+
+		events.XFR(z, status, cnt)
 	}()
 
 	if soa, err = net.LookupNS(z.Name); err != nil {
 		x.log.Printf("[ERROR] failed to find nameservers for %s: %s\n",
 			z.Name,
 			err.Error())
+		zo = lookupOutcome(err)
 		return 0, err
 	} else if len(soa) == 0 {
 		x.log.Printf("[TRACE] No nameservers were found for %s.\n",
 			z.Name)
+		zo = outcome.Empty
 		return 0, nil
 	} else if common.Debug {
 		var servers = make([]string, len(soa))
@@ -312,22 +383,82 @@ func (x *XFR) doXFR(z *model.Zone) (int64, error) {
 			strings.Join(servers, ", "))
 	}
 
-SOA_LOOP:
 	for _, ns := range soa {
+		var addrs []net.IP
+
 		if ns == nil {
 			continue
+		} else if addrs, err = net.LookupIP(ns.Host); err != nil {
+			x.log.Printf("[DEBUG] Cannot resolve name server %s of %s: %s\n",
+				ns.Host,
+				z.Name,
+				err.Error())
+			olist = append(olist, lookupOutcome(err))
+			continue
 		}
-		cnt, err = x.queryXFR(z, net.ParseIP(ns.Host))
-		if err == nil {
-			status = true
-			break SOA_LOOP
+
+		alist = append(alist, addrs...)
+
+		for _, addr := range addrs {
+			var (
+				n       int64
+				xer     error
+				records []*model.ZoneRecord
+				res     = &model.XFRResult{
+					NS:   ns.Host,
+					Addr: addr,
+				}
+			)
+
+			n, records, xer = x.queryXFR(z, addr, !status)
+			res.Outcome = xfrOutcome(n, xer)
+			res.RRCnt = n
+			olist = append(olist, res.Outcome)
+			metrics.XFRServerAttempts.With(res.Outcome.String()).Inc()
+
+			x.log.Printf("[DEBUG] AXFR of %s from %s (%s): %s, %d RRs\n",
+				z.Name,
+				ns.Host,
+				addr,
+				res.Outcome,
+				n)
+
+			if res.Open() && !status {
+				status = true
+				cnt = n
+				metrics.XFRSuccesses.Inc()
+
+				if err = x.storeZone(db, z, records); err != nil {
+					x.log.Printf("[ERROR] Failed to store records of zone %s: %s\n",
+						z.Name,
+						err.Error())
+				} else if err = x.checkMail(db, z); err != nil {
+					x.log.Printf("[ERROR] Failed to check mail policy of zone %s: %s\n",
+						z.Name,
+						err.Error())
+				}
+			}
+
+			if err = db.XFRResultAdd(z, res); err != nil {
+				x.log.Printf("[ERROR] Failed to record AXFR result of %s from %s: %s\n",
+					z.Name,
+					addr,
+					err.Error())
+			}
 		}
 	}
 
+	zo = zoneOutcome(olist)
+	x.checkNSEC(db, z, alist, status)
+
 	return cnt, nil
 } // func (x *XFR) doXFR(z *model.Zone) (int64, error)
 
-func (x *XFR) queryXFR(z *model.Zone, srv net.IP) (int64, error) {
+// queryXFR attempts a zone transfer from srv and returns the number of
+// records received. If harvest is true, it also returns the records, saves
+// them to the spool file, adds the Hosts they point to to the database, and
+// queues the ports SRV records name for the Scanner.
+func (x *XFR) queryXFR(z *model.Zone, srv net.IP, harvest bool) (int64, []*model.ZoneRecord, error) {
 	var (
 		err     error
 		cnt     int64
@@ -335,10 +466,12 @@ func (x *XFR) queryXFR(z *model.Zone, srv net.IP) (int64, error) {
 		envQ    chan *dns.Envelope
 		dbgPath string
 		dbgFh   *os.File
+		records []*model.ZoneRecord
+		seen    = make(map[string]bool)
 	)
 
 	if srv == nil {
-		return 0, errors.New("nameserver is nil")
+		return 0, nil, errors.New("nameserver is nil")
 	}
 
 	// ...
@@ -347,24 +480,26 @@ func (x *XFR) queryXFR(z *model.Zone, srv net.IP) (int64, error) {
 		srv,
 		z.Name)
 
-	dbgPath = filepath.Join(common.XfrDbgPath, z.Name)
-	if dbgFh, err = os.Create(dbgPath); err != nil {
-		var xerr = fmt.Errorf("failed to create spool file for AXFR of %s: %s",
-			z.Name,
-			err)
-		x.log.Printf("[ERROR] %s\n",
-			xerr.Error())
-		return 0, xerr
-	}
-
-	defer func() {
-		dbgFh.Close() // nolint: errcheck
-		if cnt == 0 {
-			os.Remove(dbgPath) // nolint: errcheck
+	if harvest {
+		dbgPath = filepath.Join(common.XfrDbgPath, z.Name)
+		if dbgFh, err = os.Create(dbgPath); err != nil {
+			var xerr = fmt.Errorf("failed to create spool file for AXFR of %s: %s",
+				z.Name,
+				err)
+			x.log.Printf("[ERROR] %s\n",
+				xerr.Error())
+			return 0, nil, xerr
 		}
-	}()
 
-	var ns = fmt.Sprintf("[%s]:53", srv)
+		defer func() {
+			dbgFh.Close() // nolint: errcheck
+			if cnt == 0 {
+				os.Remove(dbgPath) // nolint: errcheck
+			}
+		}()
+	}
+
+	var ns = net.JoinHostPort(srv.String(), "53")
 
 	if envQ, err = x.res.TransferIn(&xfrMsg, ns); err != nil {
 		var xerr = fmt.Errorf("failed to get AXFR of %s from %s: %w",
@@ -373,7 +508,7 @@ func (x *XFR) queryXFR(z *model.Zone, srv net.IP) (int64, error) {
 			err,
 		)
 		x.log.Printf("[DEBUG] %s\n", xerr.Error())
-		return 0, xerr
+		return 0, nil, xerr
 	}
 
 	for envelope := range envQ {
@@ -387,14 +522,27 @@ func (x *XFR) queryXFR(z *model.Zone, srv net.IP) (int64, error) {
 
 	RR_LOOP:
 		for _, rr := range envelope.RR {
+			// Failed lookups of the names in the zone must not count
+			// against the transfer itself, so they get their own error.
 			var (
+				lerr     error
 				addrList []string
 				host     = new(model.Host)
 			)
 
+			cnt++
+
+			if !harvest {
+				continue RR_LOOP
+			}
+
 			fmt.Fprintln(dbgFh, rr.String()) // nolint: errcheck
 
-			cnt++
+			// The SOA record comes at the beginning and the end.
+			if rec := rrToRecord(rr); !seen[rec.Key()] {
+				seen[rec.Key()] = true
+				records = append(records, rec)
+			}
 
 			switch t := rr.(type) {
 			case *dns.A:
@@ -410,10 +558,10 @@ func (x *XFR) queryXFR(z *model.Zone, srv net.IP) (int64, error) {
 				host.Name = rr.Header().Name
 				if x.blName.Match(host.Name) {
 					continue RR_LOOP
-				} else if addrList, err = net.LookupHost(host.Name); err != nil {
+				} else if addrList, lerr = net.LookupHost(host.Name); lerr != nil {
 					x.log.Printf("[TRACE] Failed to lookup NS %s: %s\n",
 						host.Name,
-						err.Error())
+						lerr.Error())
 					continue RR_LOOP
 				}
 
@@ -435,7 +583,7 @@ func (x *XFR) queryXFR(z *model.Zone, srv net.IP) (int64, error) {
 				host.Name = rr.Header().Name
 				if x.blName.Match(host.Name) {
 					continue RR_LOOP
-				} else if addrList, err = net.LookupHost(host.Name); err != nil {
+				} else if addrList, lerr = net.LookupHost(host.Name); lerr != nil {
 					continue RR_LOOP
 				}
 
@@ -458,10 +606,14 @@ func (x *XFR) queryXFR(z *model.Zone, srv net.IP) (int64, error) {
 				if !x.blAddr.Match(host.Addr) && !x.blName.Match(host.Name) {
 					x.hostQ <- host
 				}
+			case *dns.SRV:
+				x.harvestSRV(t)
+			case *dns.CNAME:
+				x.harvestCNAME(t)
 			}
 
 		}
 	}
 
-	return cnt, err
-} // func (x *XFR) queryXFR(z *model.Zone, srv net.IP) (int64, error)
+	return cnt, records, err
+} // func (x *XFR) queryXFR(z *model.Zone, srv net.IP, harvest bool) (int64, []*model.ZoneRecord, error)
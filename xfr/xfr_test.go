@@ -0,0 +1,206 @@
+// /home/krylon/go/src/github.com/blicero/guangng/xfr/xfr_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 15:38:32 krylon>
+
+package xfr
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blicero/guangng/model/dnssec"
+	"github.com/blicero/guangng/model/outcome"
+	dns "github.com/tonnerre/golang-dns"
+)
+
+func TestXFROutcome(t *testing.T) {
+	for _, c := range []struct {
+		cnt int64
+		err error
+		o   outcome.Outcome
+	}{
+		{42, nil, outcome.Success},
+		{0, nil, outcome.Empty},
+		{0, dns.ErrSoa, outcome.Refused},
+		{0, fmt.Errorf("failed to get AXFR: %w", dns.ErrSoa), outcome.Refused},
+		{0, &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}, outcome.Timeout},
+		{17, &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}, outcome.Timeout},
+		{0, errors.New("connection refused"), outcome.Error},
+	} {
+		if o := xfrOutcome(c.cnt, c.err); o != c.o {
+			t.Errorf("xfrOutcome(%d, %v) = %s, expected %s",
+				c.cnt,
+				c.err,
+				o,
+				c.o)
+		}
+	}
+} // func TestXFROutcome(t *testing.T)
+
+func TestZoneOutcome(t *testing.T) {
+	for _, c := range []struct {
+		list []outcome.Outcome
+		o    outcome.Outcome
+	}{
+		{nil, outcome.Refused},
+		{[]outcome.Outcome{outcome.Refused, outcome.Success}, outcome.Success},
+		{[]outcome.Outcome{outcome.Timeout, outcome.Success}, outcome.Success},
+		{[]outcome.Outcome{outcome.Refused, outcome.Timeout}, outcome.Timeout},
+		{[]outcome.Outcome{outcome.Error, outcome.Empty}, outcome.Refused},
+	} {
+		if o := zoneOutcome(c.list); o != c.o {
+			t.Errorf("zoneOutcome(%v) = %s, expected %s",
+				c.list,
+				o,
+				c.o)
+		}
+	}
+} // func TestZoneOutcome(t *testing.T)
+
+func TestSchedule(t *testing.T) {
+	var now = time.Now()
+
+	for _, c := range []struct {
+		o        outcome.Outcome
+		failures int
+		delay    time.Duration
+		cnt      int
+	}{
+		{outcome.Success, 3, refreshInterval, 0},
+		{outcome.Refused, 0, refusedInterval, 1},
+		{outcome.NXDomain, 5, refusedInterval, 6},
+		{outcome.Empty, 2, refreshInterval, 3},
+		{outcome.Timeout, 0, retryBase, 1},
+		{outcome.ServFail, 1, retryBase * 2, 2},
+		{outcome.Timeout, 4, retryBase * 16, 5},
+		{outcome.Timeout, 40, retryMax, 41},
+	} {
+		var next, cnt = schedule(c.o, c.failures, now)
+
+		if d := next.Sub(now); d != c.delay || cnt != c.cnt {
+			t.Errorf("schedule(%s, %d) = (%s, %d), expected (%s, %d)",
+				c.o,
+				c.failures,
+				d,
+				cnt,
+				c.delay,
+				c.cnt)
+		}
+	}
+} // func TestSchedule(t *testing.T)
+
+func nsecMsg(owner, next string) *dns.Msg {
+	var msg = new(dns.Msg)
+
+	msg.Answer = []dns.RR{
+		&dns.NSEC{
+			Hdr: dns.RR_Header{
+				Name:   owner,
+				Rrtype: dns.TypeNSEC,
+				Class:  dns.ClassINET,
+			},
+			NextDomain: next,
+		},
+	}
+
+	return msg
+} // func nsecMsg(owner, next string) *dns.Msg
+
+func TestSigningMode(t *testing.T) {
+	var (
+		nsec3   = new(dns.Msg)
+		refused = nsecMsg("example.com.", "www.example.com.")
+	)
+
+	nsec3.Ns = []dns.RR{
+		&dns.NSEC3{Hdr: dns.RR_Header{Name: "abc.example.com.", Rrtype: dns.TypeNSEC3}},
+	}
+	refused.Rcode = dns.RcodeRefused
+
+	for _, c := range []struct {
+		msg  *dns.Msg
+		mode dnssec.Mode
+	}{
+		{new(dns.Msg), dnssec.Unsigned},
+		{nsecMsg("example.com.", "www.example.com."), dnssec.NSEC},
+		{nsec3, dnssec.NSEC3},
+		{refused, 0},
+	} {
+		if m := signingMode(c.msg); m != c.mode {
+			t.Errorf("signingMode(%s) = %s, expected %s",
+				c.msg,
+				m,
+				c.mode)
+		}
+	}
+} // func TestSigningMode(t *testing.T)
+
+func TestWalkNSEC(t *testing.T) {
+	// chainQuery answers queries from the NSEC chain described by
+	// chain, which maps each name to the next one.
+	var chainQuery = func(chain map[string]string) nsecQuery {
+		return func(name string) (*dns.Msg, error) {
+			if next, ok := chain[name]; ok {
+				return nsecMsg(strings.ToUpper(name), next), nil
+			}
+
+			return new(dns.Msg), nil
+		}
+	}
+
+	var (
+		err   error
+		names []string
+		good  = map[string]string{
+			"example.com.":      "a.example.com.",
+			"a.example.com.":    "*.b.example.com.",
+			"*.b.example.com.":  "MAIL.example.com.",
+			"mail.example.com.": "example.com.",
+		}
+	)
+
+	if names, err = walkNSEC("Example.COM", chainQuery(good), 10); err != nil {
+		t.Errorf("Failed to walk NSEC chain: %s", err.Error())
+	} else if !slices.Equal(names, []string{"a.example.com.", "*.b.example.com.", "mail.example.com."}) {
+		t.Errorf("Unexpected names from NSEC chain: %v", names)
+	}
+
+	if names, err = walkNSEC("example.com", chainQuery(good), 2); err == nil {
+		t.Error("Walking NSEC chain did not stop at the limit")
+	} else if len(names) != 2 {
+		t.Errorf("Got %d names from NSEC chain, expected 2", len(names))
+	}
+
+	for _, chain := range []map[string]string{
+		{
+			"example.com.":   "a.example.com.",
+			"a.example.com.": "b.example.com.",
+			"b.example.com.": "a.example.com.",
+		},
+		{
+			"example.com.":   "a.example.com.",
+			"a.example.com.": `\000.a.example.com.`,
+		},
+		{
+			"example.com.":   "a.example.com.",
+			"a.example.com.": "example.org.",
+		},
+		{
+			"example.com.": "a.example.com.",
+		},
+	} {
+		if _, err = walkNSEC("example.com", chainQuery(chain), 10); !errors.Is(err, errChainBroken) {
+			t.Errorf("Expected broken chain for %v, got %v",
+				chain,
+				err)
+		}
+	}
+} // func TestWalkNSEC(t *testing.T)
@@ -0,0 +1,139 @@
+// /home/krylon/go/src/github.com/blicero/guangng/xfr/zonestore.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:51:04 krylon>
+
+package xfr
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blicero/guangng/database"
+	"github.com/blicero/guangng/model"
+	dns "github.com/tonnerre/golang-dns"
+)
+
+// rrToRecord converts a resource record from a zone transfer to the form
+// we store it in.
+func rrToRecord(rr dns.RR) *model.ZoneRecord {
+	var hdr = rr.Header()
+
+	return &model.ZoneRecord{
+		Owner: strings.ToLower(hdr.Name),
+		Type:  dns.Type(hdr.Rrtype).String(),
+		TTL:   hdr.Ttl,
+		RData: strings.TrimPrefix(rr.String(), hdr.String()),
+	}
+} // func rrToRecord(rr dns.RR) *model.ZoneRecord
+
+// zoneDiff is the difference between two copies of a zone.
+type zoneDiff struct {
+	added   []*model.ZoneRecord
+	removed []*model.ZoneRecord
+	// retimed maps records whose TTL has changed to the new TTL.
+	retimed map[*model.ZoneRecord]uint32
+}
+
+func (d *zoneDiff) empty() bool {
+	return len(d.added) == 0 && len(d.removed) == 0 && len(d.retimed) == 0
+} // func (d *zoneDiff) empty() bool
+
+// diffRecords compares the records we have stored for a zone with the ones
+// we just received.
+func diffRecords(stored, fresh []*model.ZoneRecord) *zoneDiff {
+	var (
+		d     = &zoneDiff{retimed: make(map[*model.ZoneRecord]uint32)}
+		byKey = make(map[string]*model.ZoneRecord, len(stored))
+	)
+
+	for _, r := range stored {
+		byKey[r.Key()] = r
+	}
+
+	for _, r := range fresh {
+		var old, ok = byKey[r.Key()]
+
+		if !ok {
+			d.added = append(d.added, r)
+			continue
+		} else if old.TTL != r.TTL {
+			d.retimed[old] = r.TTL
+		}
+
+		delete(byKey, r.Key())
+	}
+
+	for _, r := range stored {
+		if _, ok := byKey[r.Key()]; ok {
+			d.removed = append(d.removed, r)
+		}
+	}
+
+	return d
+} // func diffRecords(stored, fresh []*model.ZoneRecord) *zoneDiff
+
+// storeZone saves the records of a zone we just transferred. If we have
+// transferred the zone before, only the changes are written: new records
+// are added, records that are gone are marked as removed.
+func (x *XFR) storeZone(db *database.Database, z *model.Zone, records []*model.ZoneRecord) error {
+	var (
+		err    error
+		stored []*model.ZoneRecord
+		diff   *zoneDiff
+		now    = time.Now()
+	)
+
+	if stored, err = db.ZoneRecordGetCurrent(z); err != nil {
+		return err
+	} else if diff = diffRecords(stored, records); diff.empty() {
+		x.log.Printf("[DEBUG] Zone %s has not changed since the last transfer.\n",
+			z.Name)
+		return nil
+	} else if err = db.Begin(); err != nil {
+		return fmt.Errorf("cannot start transaction: %w", err)
+	}
+
+	for _, r := range diff.added {
+		r.Added = now
+		if err = db.ZoneRecordAdd(z, r); err != nil {
+			goto ROLLBACK
+		}
+	}
+
+	for _, r := range diff.removed {
+		if err = db.ZoneRecordRemove(r); err != nil {
+			goto ROLLBACK
+		}
+	}
+
+	for r, ttl := range diff.retimed {
+		if err = db.ZoneRecordSetTTL(r, ttl); err != nil {
+			goto ROLLBACK
+		}
+	}
+
+	if err = db.Commit(); err != nil {
+		return fmt.Errorf("cannot commit transaction: %w", err)
+	}
+
+	if len(stored) == 0 {
+		x.log.Printf("[INFO] Stored %d records of zone %s.\n",
+			len(diff.added),
+			z.Name)
+	} else {
+		x.log.Printf("[INFO] Zone %s has changed: %d records added, %d removed, %d with a new TTL.\n",
+			z.Name,
+			len(diff.added),
+			len(diff.removed),
+			len(diff.retimed))
+	}
+
+	return nil
+
+ROLLBACK:
+	db.Rollback() // nolint: errcheck
+	return err
+} // func (x *XFR) storeZone(db *database.Database, z *model.Zone, records []*model.ZoneRecord) error
@@ -0,0 +1,63 @@
+// /home/krylon/go/src/github.com/blicero/guangng/xfr/zonestore_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:51:04 krylon>
+
+package xfr
+
+import (
+	"net"
+	"testing"
+
+	"github.com/blicero/guangng/model"
+	dns "github.com/tonnerre/golang-dns"
+)
+
+func TestRRToRecord(t *testing.T) {
+	var rec = rrToRecord(&dns.A{
+		Hdr: dns.RR_Header{
+			Name:   "Wintermute.Straylight.test.",
+			Rrtype: dns.TypeA,
+			Class:  dns.ClassINET,
+			Ttl:    3600,
+		},
+		A: net.ParseIP("192.0.2.1"),
+	})
+
+	if rec.Owner != "wintermute.straylight.test." || rec.Type != "A" || rec.TTL != 3600 || rec.RData != "192.0.2.1" {
+		t.Errorf("Unexpected record: %q %q %d %q", rec.Owner, rec.Type, rec.TTL, rec.RData)
+	}
+} // func TestRRToRecord(t *testing.T)
+
+func TestDiffRecords(t *testing.T) {
+	var (
+		stored = []*model.ZoneRecord{
+			{Owner: "a.test.", Type: "A", TTL: 300, RData: "192.0.2.1"},
+			{Owner: "b.test.", Type: "A", TTL: 300, RData: "192.0.2.2"},
+			{Owner: "c.test.", Type: "MX", TTL: 300, RData: "10 mail.test."},
+		}
+		fresh = []*model.ZoneRecord{
+			{Owner: "a.test.", Type: "A", TTL: 300, RData: "192.0.2.1"},
+			{Owner: "b.test.", Type: "A", TTL: 600, RData: "192.0.2.2"},
+			{Owner: "c.test.", Type: "MX", TTL: 300, RData: "20 mail.test."},
+		}
+		d = diffRecords(stored, fresh)
+	)
+
+	if len(d.added) != 1 || d.added[0] != fresh[2] {
+		t.Errorf("Unexpected records added: %v", d.added)
+	}
+
+	if len(d.removed) != 1 || d.removed[0] != stored[2] {
+		t.Errorf("Unexpected records removed: %v", d.removed)
+	}
+
+	if len(d.retimed) != 1 || d.retimed[stored[1]] != 600 {
+		t.Errorf("Unexpected TTL changes: %v", d.retimed)
+	}
+
+	if d = diffRecords(fresh, fresh); !d.empty() {
+		t.Error("Identical copies of a zone are not equal")
+	}
+} // func TestDiffRecords(t *testing.T)